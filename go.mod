@@ -51,8 +51,10 @@ replace (
 )
 
 require (
-	github.com/containerd/containerd v1.5.10 // indirect
+	github.com/aws/aws-sdk-go v1.38.65
+	github.com/containerd/containerd v1.5.10
 	github.com/containernetworking/cni v0.8.1
+	github.com/docker/distribution v2.7.1+incompatible
 	github.com/ehazlett/simplelog v0.0.0-20200226020431-d374894e92a4
 	github.com/emicklei/go-restful v2.10.0+incompatible
 	github.com/gin-gonic/gin v1.7.0 // indirect
@@ -72,8 +74,11 @@ require (
 	github.com/mitchellh/mapstructure v1.4.2
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.16.0
+	github.com/opencontainers/go-digest v1.0.0
+	github.com/opencontainers/image-spec v1.0.2
 	github.com/openshift/api v0.0.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/rancher/apiserver v0.0.0-20211025232108-df28932a5627
 	github.com/rancher/dynamiclistener v0.3.1-0.20211104200948-cd5d71f2fe95
 	github.com/rancher/lasso v0.0.0-20210709145333-6c6cd7fd6607
@@ -83,6 +88,7 @@ require (
 	github.com/rancher/steve v0.0.0-20220126170519-376e30bba7be
 	github.com/rancher/system-upgrade-controller/pkg/apis v0.0.0-20210727200656-10b094e30007
 	github.com/rancher/wrangler v0.8.11-0.20211214201934-f5aa5d9f2e81
+	github.com/robfig/cron v1.2.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/tidwall/gjson v1.9.3
@@ -44,6 +44,13 @@ func main() {
 			Value:       8443,
 			Destination: &options.HTTPSListenPort,
 		},
+		cli.IntFlag{
+			Name:        "metrics-port",
+			EnvVar:      "HARVESTER_SERVER_METRICS_PORT",
+			Usage:       "Prometheus metrics listen port, the metrics are disabled if it's 0",
+			Value:       9800,
+			Destination: &options.MetricsListenPort,
+		},
 		cli.StringFlag{
 			Name:        "namespace",
 			EnvVar:      "NAMESPACE",
@@ -0,0 +1,293 @@
+package backup
+
+// A file restore mounts a volume backup read-only in a helper pod, the files are browsed and downloaded
+// by running find, cat and tar in the pod. The helper pod is created by the backup controller in the harvester
+// system namespace once the volume is added to the file restore request of the VM backup, the partitions of
+// a block volume are mounted to /mnt/files/partition<N>, or /mnt/files/disk if the volume isn't partitioned.
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/retry"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// the access time is recorded at most once in the interval to avoid updating the pod for every request
+const accessRecordInterval = time.Minute
+
+type fileRestorer struct {
+	namespace  string
+	restConfig *rest.Config
+	clientSet  kubernetes.Interface
+	pods       ctlcorev1.PodClient
+	podCache   ctlcorev1.PodCache
+	vmBackups  ctlharvesterv1.VirtualMachineBackupClient
+}
+
+func getVolumeBackup(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) (*harvesterv1.VolumeBackup, error) {
+	if vmBackup.Status == nil {
+		return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("VM backup %s/%s is not ready", vmBackup.Namespace, vmBackup.Name))
+	}
+	for i, vb := range vmBackup.Status.VolumeBackups {
+		if vb.VolumeName != volumeName {
+			continue
+		}
+		if vb.Name == nil || vb.ReadyToUse == nil || !*vb.ReadyToUse {
+			return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("backup of volume %s is not ready", volumeName))
+		}
+		return &vmBackup.Status.VolumeBackups[i], nil
+	}
+	return nil, apierror.NewAPIError(validation.NotFound, fmt.Sprintf("volume %s is not in VM backup %s/%s", volumeName, vmBackup.Namespace, vmBackup.Name))
+}
+
+// cleanPath returns the absolute path in the helper pod of a path in the volume backup
+func cleanPath(filePath string) (string, error) {
+	cleaned := path.Clean("/" + filePath)
+	if strings.ContainsRune(cleaned, 0) {
+		return "", apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("invalid path %q", filePath))
+	}
+	return path.Join(ctlbackup.FileRestoreRoot, cleaned), nil
+}
+
+// getPod returns the ready helper pod of the volume backup, the file restore is requested if the pod doesn't exist
+func (r *fileRestorer) getPod(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) (*corev1.Pod, error) {
+	if _, err := getVolumeBackup(vmBackup, volumeName); err != nil {
+		return nil, err
+	}
+
+	pod, err := r.podCache.Get(r.namespace, ctlbackup.GetFileRestoreName(vmBackup, volumeName))
+	if apierrors.IsNotFound(err) {
+		if err := r.request(vmBackup, volumeName); err != nil {
+			return nil, err
+		}
+		return nil, apierror.NewAPIError(validation.Conflict, fmt.Sprintf("volume %s is being restored for browsing, retry later", volumeName))
+	} else if err != nil {
+		return nil, err
+	}
+
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return nil, apierror.NewAPIError(validation.Conflict, fmt.Sprintf("file restore of volume %s is being cleaned up, retry later", volumeName))
+	}
+	if !isPodReady(pod) {
+		return nil, apierror.NewAPIError(validation.Conflict, fmt.Sprintf("volume %s is being restored for browsing, retry later", volumeName))
+	}
+	return pod, r.recordAccess(pod)
+}
+
+// request adds the volume to the file restore request of the VM backup, the backup controller restores it
+func (r *fileRestorer) request(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vmBackup, err := r.vmBackups.Get(vmBackup.Namespace, vmBackup.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		volumeNames, err := ctlbackup.GetFileRestoreRequest(vmBackup)
+		if err != nil {
+			return err
+		}
+		for _, v := range volumeNames {
+			if v == volumeName {
+				return nil
+			}
+		}
+
+		vmBackupCpy := vmBackup.DeepCopy()
+		if err := ctlbackup.SetFileRestoreRequest(vmBackupCpy, append(volumeNames, volumeName)); err != nil {
+			return err
+		}
+		_, err = r.vmBackups.Update(vmBackupCpy)
+		return err
+	})
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAccess postpones the idle cleanup of the helper pod
+func (r *fileRestorer) recordAccess(pod *corev1.Pod) error {
+	pod, err := r.podCache.Get(pod.Namespace, pod.Name)
+	if err != nil {
+		return err
+	}
+	if accessed, err := time.Parse(time.RFC3339, pod.Annotations[util.AnnotationFileRestoreAccessed]); err == nil && time.Since(accessed) < accessRecordInterval {
+		return nil
+	}
+	podCpy := pod.DeepCopy()
+	if podCpy.Annotations == nil {
+		podCpy.Annotations = map[string]string{}
+	}
+	podCpy.Annotations[util.AnnotationFileRestoreAccessed] = time.Now().UTC().Format(time.RFC3339)
+	if _, err := r.pods.Update(podCpy); err != nil && !apierrors.IsConflict(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *fileRestorer) exec(pod *corev1.Pod, command []string, stdout io.Writer) error {
+	req := r.clientSet.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).
+		SubResource("exec").VersionedParams(&corev1.PodExecOptions{
+		Container: ctlbackup.FileRestoreContainerName,
+		Command:   command,
+		Stdout:    true,
+		Stderr:    true,
+	}, k8sscheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(r.restConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// resolvePath resolves the symbolic links of a path in the helper pod, a link in the guest filesystem
+// can't point out of the volume backup.
+func (r *fileRestorer) resolvePath(pod *corev1.Pod, filePath string) (string, error) {
+	podPath, err := cleanPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	var stdout bytes.Buffer
+	if err := r.exec(pod, []string{"realpath", "-e", "--", podPath}, &stdout); err != nil {
+		return "", apierror.NewAPIError(validation.NotFound, fmt.Sprintf("path %s is not found", filePath))
+	}
+	resolved := strings.TrimSuffix(stdout.String(), "\n")
+	if resolved != ctlbackup.FileRestoreRoot && !strings.HasPrefix(resolved, ctlbackup.FileRestoreRoot+"/") {
+		return "", apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("path %s is out of the volume backup", filePath))
+	}
+	return resolved, nil
+}
+
+func (r *fileRestorer) browse(rw http.ResponseWriter, vmBackup *harvesterv1.VirtualMachineBackup, input BrowseFilesInput) error {
+	pod, err := r.getPod(vmBackup, input.VolumeName)
+	if err != nil {
+		return err
+	}
+	dir, err := r.resolvePath(pod, input.Path)
+	if err != nil {
+		return err
+	}
+
+	var stdout bytes.Buffer
+	if err := r.exec(pod, []string{"find", dir, "-mindepth", "1", "-maxdepth", "1", "-printf", `%y\t%s\t%T@\t%f\0`}, &stdout); err != nil {
+		return fmt.Errorf("failed to list %s: %w", input.Path, err)
+	}
+
+	output := BrowseFilesOutput{
+		Path:    path.Clean("/" + input.Path),
+		Entries: parseFileEntries(stdout.String()),
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	return json.NewEncoder(rw).Encode(output)
+}
+
+// parseFileEntries parses the NUL separated entries printed by find in the format of "%y\t%s\t%T@\t%f"
+func parseFileEntries(output string) []FileEntry {
+	entries := []FileEntry{}
+	for _, line := range strings.Split(output, "\x00") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		modTime, _ := strconv.ParseFloat(fields[2], 64)
+		entries = append(entries, FileEntry{
+			Name:    fields[3],
+			Type:    getFileType(fields[0]),
+			Size:    size,
+			ModTime: metav1.Unix(int64(modTime), 0),
+		})
+	}
+	return entries
+}
+
+func getFileType(findType string) string {
+	switch findType {
+	case "f":
+		return "file"
+	case "d":
+		return "directory"
+	case "l":
+		return "symlink"
+	default:
+		return "other"
+	}
+}
+
+// download streams a regular file as it is, or a gzipped tarball of the files and directories
+func (r *fileRestorer) download(rw http.ResponseWriter, vmBackup *harvesterv1.VirtualMachineBackup, input DownloadFilesInput) error {
+	pod, err := r.getPod(vmBackup, input.VolumeName)
+	if err != nil {
+		return err
+	}
+
+	var resolved []string
+	for _, p := range input.Paths {
+		podPath, err := r.resolvePath(pod, p)
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, podPath)
+	}
+
+	var command []string
+	if len(resolved) == 1 && r.isRegularFile(pod, resolved[0]) {
+		command = []string{"cat", "--", resolved[0]}
+		rw.Header().Set("Content-Type", "application/octet-stream")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(resolved[0])))
+	} else {
+		command = []string{"tar", "-czf", "-", "-C", ctlbackup.FileRestoreRoot, "--"}
+		for _, p := range resolved {
+			command = append(command, strings.TrimPrefix(strings.TrimPrefix(p, ctlbackup.FileRestoreRoot), "/"))
+		}
+		rw.Header().Set("Content-Type", "application/gzip")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.tar.gz", vmBackup.Name, input.VolumeName)))
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	if err := r.exec(pod, command, rw); err != nil {
+		// the response is partially written, the client finds the truncated content
+		logrus.Errorf("failed to download files from VM backup %s/%s: %v", vmBackup.Namespace, vmBackup.Name, err)
+	}
+	return r.recordAccess(pod)
+}
+
+func (r *fileRestorer) isRegularFile(pod *corev1.Pod, podPath string) bool {
+	var stdout bytes.Buffer
+	if err := r.exec(pod, []string{"find", podPath, "-maxdepth", "0", "-printf", "%y"}, &stdout); err != nil {
+		return false
+	}
+	return stdout.String() == "f"
+}
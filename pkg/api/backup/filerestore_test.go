@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_cleanPath(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "root",
+			path:     "",
+			expected: "/mnt/files",
+		},
+		{
+			name:     "partition",
+			path:     "/partition1/etc/hosts",
+			expected: "/mnt/files/partition1/etc/hosts",
+		},
+		{
+			name:     "relative path",
+			path:     "partition1/etc",
+			expected: "/mnt/files/partition1/etc",
+		},
+		{
+			name:     "out of the volume",
+			path:     "/partition1/../../../etc/passwd",
+			expected: "/mnt/files/etc/passwd",
+		},
+	}
+
+	for _, tc := range testCases {
+		cleaned, err := cleanPath(tc.path)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expected, cleaned, tc.name)
+	}
+}
+
+func Test_parseFileEntries(t *testing.T) {
+	output := "d\t4096\t1640995200.0000000000\tetc\x00f\t12\t1640995200.5000000000\tfile\twith tab\x00l\t7\t1640995200.0000000000\tlib\x00"
+	assert.Equal(t, []FileEntry{
+		{Name: "etc", Type: "directory", Size: 4096, ModTime: metav1.Unix(1640995200, 0)},
+		{Name: "file\twith tab", Type: "file", Size: 12, ModTime: metav1.Unix(1640995200, 0)},
+		{Name: "lib", Type: "symlink", Size: 7, ModTime: metav1.Unix(1640995200, 0)},
+	}, parseFileEntries(output))
+	assert.Equal(t, []FileEntry{}, parseFileEntries(""))
+}
@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/wrangler/pkg/data/convert"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+)
+
+const (
+	actionVerify        = "verify"
+	actionBrowseFiles   = "browseFiles"
+	actionDownloadFiles = "downloadFiles"
+)
+
+func Formatter(request *types.APIRequest, resource *types.RawResource) {
+	resource.Actions = make(map[string]string, 1)
+	if request.AccessControl.CanUpdate(request, resource.APIObject, resource.Schema) != nil {
+		return
+	}
+
+	vmBackup := &harvesterv1.VirtualMachineBackup{}
+	if err := convert.ToObj(resource.APIObject.Data(), vmBackup); err != nil {
+		return
+	}
+
+	if canVerify(vmBackup) {
+		resource.AddAction(request, actionVerify)
+	}
+
+	if isBackupReady(vmBackup) {
+		resource.AddAction(request, actionBrowseFiles)
+		resource.AddAction(request, actionDownloadFiles)
+	}
+}
+
+func isBackupReady(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	return vmBackup.DeletionTimestamp == nil && vmBackup.Status != nil &&
+		vmBackup.Status.ReadyToUse != nil && *vmBackup.Status.ReadyToUse
+}
+
+func canVerify(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	if vmBackup.Spec.Type == harvesterv1.Snapshot || !isBackupReady(vmBackup) {
+		return false
+	}
+	return !ctlbackup.IsBackupVerifying(vmBackup)
+}
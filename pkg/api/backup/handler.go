@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"k8s.io/client-go/util/retry"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+)
+
+type ActionHandler struct {
+	vmBackups     ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache
+	fileRestorer  *fileRestorer
+}
+
+// ServeHTTP writes the error of the action, the action writes its own response on success
+func (h ActionHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if err := h.do(rw, req); err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*apierror.APIError); ok {
+			status = e.Code.Status
+		}
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(err.Error()))
+	}
+}
+
+func (h *ActionHandler) do(rw http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	action := vars["action"]
+	name := vars["name"]
+	namespace := vars["namespace"]
+
+	// the actions read the backup contents with the service account of the API
+	if err := checkBackupAccess(types.GetAPIContext(r.Context()), namespace, name); err != nil {
+		return err
+	}
+
+	switch action {
+	case actionVerify:
+		var input VerifyBackupInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		if input.Timeout != nil && input.Timeout.Duration <= 0 {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `timeout` must be positive")
+		}
+		if err := h.verify(namespace, name, input); err != nil {
+			return err
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	case actionBrowseFiles:
+		var input BrowseFilesInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		if input.VolumeName == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `volumeName` is required")
+		}
+		vmBackup, err := h.vmBackupCache.Get(namespace, name)
+		if err != nil {
+			return err
+		}
+		return h.fileRestorer.browse(rw, vmBackup, input)
+	case actionDownloadFiles:
+		var input DownloadFilesInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		if input.VolumeName == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `volumeName` is required")
+		}
+		if len(input.Paths) == 0 {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `paths` is required")
+		}
+		vmBackup, err := h.vmBackupCache.Get(namespace, name)
+		if err != nil {
+			return err
+		}
+		return h.fileRestorer.download(rw, vmBackup, input)
+	default:
+		return apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
+	}
+}
+
+// checkBackupAccess checks the caller is allowed to update the VM backup, it fails closed without the access control
+func checkBackupAccess(apiOp *types.APIRequest, namespace, name string) error {
+	if apiOp == nil || apiOp.AccessControl == nil {
+		return apierror.NewAPIError(validation.PermissionDenied, "can't check the access to the VM backup")
+	}
+	if err := apiOp.AccessControl.CanDo(apiOp, vmBackupSchemaID, "update", namespace, name); err != nil {
+		return apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("can't update VM backup %s/%s", namespace, name))
+	}
+	return nil
+}
+
+func (h *ActionHandler) verify(namespace, name string, input VerifyBackupInput) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vmBackup, err := h.vmBackupCache.Get(namespace, name)
+		if err != nil {
+			return err
+		}
+		if !canVerify(vmBackup) {
+			return apierror.NewAPIError(validation.InvalidAction, fmt.Sprintf("VM backup %s/%s is not ready to verify", namespace, name))
+		}
+
+		vmBackupCpy := vmBackup.DeepCopy()
+		if err := ctlbackup.SetVerifyRequest(vmBackupCpy, harvesterv1.BackupVerification{
+			ReadinessProbe: input.ReadinessProbe,
+			Timeout:        input.Timeout,
+		}); err != nil {
+			return err
+		}
+		_, err = h.vmBackups.Update(vmBackupCpy)
+		return err
+	})
+}
@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAccessControl grants the update of the VM backups in the namespace
+type fakeAccessControl struct {
+	types.AccessControl
+	namespace string
+}
+
+func (a fakeAccessControl) CanDo(apiOp *types.APIRequest, resource, verb, namespace, name string) error {
+	if resource == vmBackupSchemaID && verb == "update" && namespace == a.namespace {
+		return nil
+	}
+	return fmt.Errorf("forbidden")
+}
+
+func Test_checkBackupAccess(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		apiOp       *types.APIRequest
+		expectError bool
+	}{
+		{
+			name:        "no api context",
+			expectError: true,
+		},
+		{
+			name:        "no access control",
+			apiOp:       &types.APIRequest{},
+			expectError: true,
+		},
+		{
+			name:  "allowed to update the backup",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{namespace: "default"}},
+		},
+		{
+			name:        "backup of another tenant",
+			apiOp:       &types.APIRequest{AccessControl: fakeAccessControl{namespace: "tenant"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := checkBackupAccess(tc.apiOp, "default", "backup")
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+	}
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/steve/pkg/schema"
+	"github.com/rancher/steve/pkg/server"
+	"github.com/rancher/wrangler/pkg/schemas"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/harvester/harvester/pkg/config"
+)
+
+const (
+	vmBackupSchemaID = "harvesterhci.io.virtualmachinebackup"
+)
+
+func RegisterSchema(scaled *config.Scaled, server *server.Server, options config.Options) error {
+	server.BaseSchemas.MustImportAndCustomize(VerifyBackupInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(BrowseFilesInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(DownloadFilesInput{}, nil)
+
+	clientSet, err := kubernetes.NewForConfig(server.RESTConfig)
+	if err != nil {
+		return err
+	}
+	pods := scaled.CoreFactory.Core().V1().Pod()
+	vmBackups := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+	actionHandler := ActionHandler{
+		vmBackups:     vmBackups,
+		vmBackupCache: vmBackups.Cache(),
+		fileRestorer: &fileRestorer{
+			namespace:  options.Namespace,
+			restConfig: server.RESTConfig,
+			clientSet:  clientSet,
+			pods:       pods,
+			podCache:   pods.Cache(),
+			vmBackups:  vmBackups,
+		},
+	}
+	t := schema.Template{
+		ID: vmBackupSchemaID,
+		Customize: func(s *types.APISchema) {
+			s.ResourceActions = map[string]schemas.Action{
+				actionVerify: {
+					Input: "verifyBackupInput",
+				},
+				actionBrowseFiles: {
+					Input: "browseFilesInput",
+				},
+				actionDownloadFiles: {
+					Input: "downloadFilesInput",
+				},
+			}
+			s.ActionHandlers = map[string]http.Handler{
+				actionVerify:        &actionHandler,
+				actionBrowseFiles:   &actionHandler,
+				actionDownloadFiles: &actionHandler,
+			}
+		},
+		Formatter: Formatter,
+	}
+	server.SchemaFactory.AddTemplate(t)
+	return nil
+}
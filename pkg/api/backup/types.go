@@ -0,0 +1,34 @@
+package backup
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+type VerifyBackupInput struct {
+	ReadinessProbe *kubevirtv1.Probe `json:"readinessProbe,omitempty"`
+	Timeout        *metav1.Duration  `json:"timeout,omitempty"`
+}
+
+type BrowseFilesInput struct {
+	VolumeName string `json:"volumeName"`
+	Path       string `json:"path"`
+}
+
+type DownloadFilesInput struct {
+	VolumeName string   `json:"volumeName"`
+	Paths      []string `json:"paths"`
+}
+
+type BrowseFilesOutput struct {
+	Path    string      `json:"path"`
+	Entries []FileEntry `json:"entries"`
+}
+
+// FileEntry is a file in the volume backup, the partitions of the volume are the directories under "/"
+type FileEntry struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Size    int64       `json:"size"`
+	ModTime metav1.Time `json:"modTime"`
+}
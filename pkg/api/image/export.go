@@ -0,0 +1,178 @@
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+
+	apisv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
+	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+)
+
+const (
+	actionDownload             = "download"
+	actionExportToBackupTarget = "exportToBackupTarget"
+)
+
+// ExportActionHandler downloads the images and exports them to the backup targets
+type ExportActionHandler struct {
+	Images            v1beta1.VirtualMachineImageClient
+	ImageCache        v1beta1.VirtualMachineImageCache
+	BackupTargetCache v1beta1.BackupTargetCache
+	Exporter          *export.Exporter
+}
+
+// ServeHTTP writes the error of the action, the action writes its own response on success
+func (h ExportActionHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if err := h.do(rw, req); err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*apierror.APIError); ok {
+			status = e.Code.Status
+		}
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(err.Error()))
+	}
+}
+
+func (h ExportActionHandler) do(rw http.ResponseWriter, req *http.Request) error {
+	vars := mux.Vars(req)
+	namespace := vars["namespace"]
+	name := vars["name"]
+
+	switch vars["action"] {
+	case actionDownload:
+		var input DownloadInput
+		if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		format, err := getExportFormat(input.Format)
+		if err != nil {
+			return err
+		}
+		return h.download(rw, req, namespace, name, format)
+	case actionExportToBackupTarget:
+		var input ExportToBackupTargetInput
+		if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		format, err := getExportFormat(input.Format)
+		if err != nil {
+			return err
+		}
+		if input.BackupTargetName == "" {
+			input.BackupTargetName = apisv1beta1.DefaultBackupTargetName
+		}
+		if err := h.exportToBackupTarget(namespace, name, input.BackupTargetName, format); err != nil {
+			return err
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	default:
+		return apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
+	}
+}
+
+// getExportFormat returns the format of the exported file, it's qcow2 by default
+func getExportFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return apisv1beta1.ImageExportFormatQCOW2, nil
+	case apisv1beta1.ImageExportFormatRaw, apisv1beta1.ImageExportFormatQCOW2:
+		return format, nil
+	default:
+		return "", apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("unsupported format %q, it must be raw or qcow2", format))
+	}
+}
+
+func (h ExportActionHandler) getImportedImage(namespace, name string) (*apisv1beta1.VirtualMachineImage, error) {
+	image, err := h.ImageCache.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if image.DeletionTimestamp != nil || !apisv1beta1.ImageImported.IsTrue(image) {
+		return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("image %s/%s is not imported", namespace, name))
+	}
+	return image, nil
+}
+
+// download streams the image through the helper pod, it's a conflict until the helper pod is ready and
+// the image is prepared in the format, so that a failure is reported before the response is written.
+func (h ExportActionHandler) download(rw http.ResponseWriter, req *http.Request, namespace, name, format string) error {
+	image, err := h.getImportedImage(namespace, name)
+	if err != nil {
+		return err
+	}
+	pod, err := h.Exporter.GetPod(image)
+	if err == nil {
+		var size int64
+		if size, err = h.Exporter.Prepare(pod, image, format); err == nil && size > 0 {
+			rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+	}
+	if errors.Is(err, export.ErrNotReady) {
+		return apierror.NewAPIError(validation.Conflict, fmt.Sprintf("image %s/%s is being prepared for download, retry later", namespace, name))
+	} else if err != nil {
+		return err
+	}
+
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.GetFileName(image, format)))
+	rw.WriteHeader(http.StatusOK)
+	if err := h.Exporter.Stream(req.Context(), pod, image, format, rw); err != nil {
+		// the response is partially written, the client finds the truncated content
+		logrus.Errorf("failed to download image %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// exportToBackupTarget requests the image controller to export the image, the export replaces the previous one
+// of the same format in the backup target
+func (h ExportActionHandler) exportToBackupTarget(namespace, name, backupTargetName, format string) error {
+	if _, err := h.BackupTargetCache.Get(backupTargetName); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("backup target %s is not configured", backupTargetName))
+		}
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		image, err := h.getImportedImage(namespace, name)
+		if err != nil {
+			return err
+		}
+
+		imageCpy := image.DeepCopy()
+		requested := apisv1beta1.VirtualMachineImageExport{
+			BackupTarget: backupTargetName,
+			Format:       format,
+			Path:         export.GetBackupStorePath(image, format),
+			State:        apisv1beta1.ImageExportStatePending,
+		}
+		found := false
+		for i, e := range imageCpy.Status.Exports {
+			if e.BackupTarget != backupTargetName || e.Format != format {
+				continue
+			}
+			if e.State == apisv1beta1.ImageExportStatePending || e.State == apisv1beta1.ImageExportStateExporting {
+				return apierror.NewAPIError(validation.Conflict, fmt.Sprintf("image %s/%s is being exported to backup target %s", namespace, name, backupTargetName))
+			}
+			imageCpy.Status.Exports[i] = requested
+			found = true
+		}
+		if !found {
+			imageCpy.Status.Exports = append(imageCpy.Status.Exports, requested)
+		}
+		_, err = h.Images.Update(imageCpy)
+		return err
+	})
+}
@@ -0,0 +1,107 @@
+package image
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+
+	apisv1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	actionDeprecate = "deprecate"
+	actionRollback  = "rollback"
+)
+
+// FamilyActionHandler deprecates the versions of the image families and rolls the families back to a version
+type FamilyActionHandler struct {
+	Images     v1beta1.VirtualMachineImageClient
+	ImageCache v1beta1.VirtualMachineImageCache
+}
+
+func (h FamilyActionHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if err := h.do(req); err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*apierror.APIError); ok {
+			status = e.Code.Status
+		}
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(err.Error()))
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (h FamilyActionHandler) do(req *http.Request) error {
+	vars := mux.Vars(req)
+	namespace := vars["namespace"]
+	name := vars["name"]
+
+	switch vars["action"] {
+	case actionDeprecate:
+		return h.setDeprecated(namespace, name, true)
+	case actionRollback:
+		return h.rollback(namespace, name)
+	default:
+		return apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
+	}
+}
+
+func (h FamilyActionHandler) getVersion(namespace, name string) (*apisv1beta1.VirtualMachineImage, error) {
+	image, err := h.ImageCache.Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if image.Spec.Family == "" {
+		return nil, apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("image %s/%s is not in an image family", namespace, name))
+	}
+	return image, nil
+}
+
+func (h FamilyActionHandler) setDeprecated(namespace, name string, deprecated bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		image, err := h.getVersion(namespace, name)
+		if err != nil || image.Spec.Deprecated == deprecated {
+			return err
+		}
+		imageCpy := image.DeepCopy()
+		imageCpy.Spec.Deprecated = deprecated
+		_, err = h.Images.Update(imageCpy)
+		return err
+	})
+}
+
+// rollback makes the version the latest ready version of its family by deprecating the versions created after it,
+// the VMs created from the family afterwards use the version
+func (h FamilyActionHandler) rollback(namespace, name string) error {
+	image, err := h.getVersion(namespace, name)
+	if err != nil {
+		return err
+	}
+	if image.DeletionTimestamp != nil || !apisv1beta1.ImageImported.IsTrue(image) {
+		return apierror.NewAPIError(validation.InvalidState, fmt.Sprintf("image %s/%s is not imported", namespace, name))
+	}
+
+	images, err := h.ImageCache.List(namespace, labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, newer := range util.GetNewerImageVersions(images, image) {
+		if err := h.setDeprecated(newer.Namespace, newer.Name, true); err != nil {
+			return err
+		}
+	}
+	if err := h.setDeprecated(namespace, name, false); err != nil {
+		return err
+	}
+	logrus.Infof("image family %s/%s is rolled back to version %s", namespace, image.Spec.Family, image.Spec.Version)
+	return nil
+}
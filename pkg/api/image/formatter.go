@@ -2,10 +2,13 @@ package image
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -22,10 +25,15 @@ import (
 	"github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctllhv1beta1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
 	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/checksum"
+	"github.com/harvester/harvester/pkg/util/imageformat"
 )
 
 const (
 	actionUpload = "upload"
+
+	// checksumParam is the expected checksum of the uploaded file in the upload request
+	checksumParam = "checksum"
 )
 
 func Formatter(request *types.APIRequest, resource *types.RawResource) {
@@ -37,6 +45,27 @@ func Formatter(request *types.APIRequest, resource *types.RawResource) {
 	if resource.APIObject.Data().String("spec", "sourceType") == apisv1beta1.VirtualMachineImageSourceTypeUpload {
 		resource.AddAction(request, actionUpload)
 	}
+	if isImported(resource) {
+		resource.AddAction(request, actionDownload)
+		resource.AddAction(request, actionExportToBackupTarget)
+	}
+	if resource.APIObject.Data().String("spec", "family") != "" {
+		if !resource.APIObject.Data().Bool("spec", "deprecated") {
+			resource.AddAction(request, actionDeprecate)
+		}
+		if isImported(resource) {
+			resource.AddAction(request, actionRollback)
+		}
+	}
+}
+
+func isImported(resource *types.RawResource) bool {
+	for _, c := range resource.APIObject.Data().Slice("status", "conditions") {
+		if c.String("type") == string(apisv1beta1.ImageImported) && c.String("status") == "True" {
+			return true
+		}
+	}
+	return false
 }
 
 type UploadActionHandler struct {
@@ -80,9 +109,14 @@ func (h UploadActionHandler) uploadImage(rw http.ResponseWriter, req *http.Reque
 		return err
 	}
 
+	reason := "UploadFailed"
 	defer func() {
 		if err != nil {
-			if updateErr := h.updateImportedConditionOnConflict(image, "False", "UploadFailed", err.Error()); updateErr != nil {
+			message := err.Error()
+			if apiErr, ok := err.(*apierror.APIError); ok {
+				message = apiErr.Message
+			}
+			if updateErr := h.updateImportedConditionOnConflict(image, "False", reason, message); updateErr != nil {
 				logrus.Error(err)
 			}
 		}
@@ -94,16 +128,40 @@ func (h UploadActionHandler) uploadImage(rw http.ResponseWriter, req *http.Reque
 		return err
 	}
 
+	// the format is detected from the beginning of the uploaded file, the body is then streamed to longhorn
+	var body io.ReadCloser
+	var contentType string
+	var copyResult <-chan error
+	if body, contentType, copyResult, err = h.prepareUpload(image, req); err != nil {
+		return err
+	}
+
 	uploadUrl := fmt.Sprintf("http://longhorn-backend.longhorn-system:9500/v1/backingimages/%s-%s", namespace, name)
-	uploadReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, uploadUrl, req.Body)
+	uploadReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, uploadUrl, body)
 	if err != nil {
 		return fmt.Errorf("failed to create the upload request: %w", err)
 	}
-	uploadReq.Header = req.Header
-	uploadReq.URL.RawQuery = req.URL.RawQuery
+	uploadReq.Header = req.Header.Clone()
+	uploadReq.Header.Set("Content-Type", contentType)
+	uploadReq.Header.Del("Content-Length")
+	query := req.URL.Query()
+	query.Del(checksumParam)
+	uploadReq.URL.RawQuery = query.Encode()
 
 	var urlErr *url.Error
 	uploadResp, err := h.httpClient.Do(uploadReq)
+	// unblock the streaming if longhorn responds before reading the whole file
+	_ = body.Close()
+	// the upload is aborted at the end of a file whose checksum doesn't match,
+	// so longhorn never gets a complete file to mark the backing image ready
+	if copyErr := <-copyResult; errors.Is(copyErr, checksum.ErrMismatch) {
+		if err == nil {
+			_ = uploadResp.Body.Close()
+		}
+		reason = "ChecksumMismatch"
+		err = apierror.NewAPIError(validation.InvalidBodyContent, copyErr.Error())
+		return err
+	}
 	if errors.As(err, &urlErr) {
 		// Trim the "POST http://xxx" implementation detail for the error
 		// set the err var and it will be recorded in image condition in the defer function
@@ -114,19 +172,95 @@ func (h UploadActionHandler) uploadImage(rw http.ResponseWriter, req *http.Reque
 	}
 	defer uploadResp.Body.Close()
 
-	body, err := ioutil.ReadAll(uploadResp.Body)
+	respBody, err := ioutil.ReadAll(uploadResp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 	if uploadResp.StatusCode >= http.StatusBadRequest {
 		// err will be recorded in image condition in the defer function
-		err = fmt.Errorf("upload failed: %s", string(body))
+		err = fmt.Errorf("upload failed: %s", string(respBody))
 		return err
 	}
 
 	return nil
 }
 
+// prepareUpload records the format and the expected checksum of the file in the multipart upload request, and returns
+// the body to forward to longhorn. The files longhorn can't use as backing images are rejected. The returned channel
+// receives the result of streaming the file once it's done, a checksum mismatch is reported as checksum.ErrMismatch.
+func (h UploadActionHandler) prepareUpload(image *apisv1beta1.VirtualMachineImage, req *http.Request) (io.ReadCloser, string, <-chan error, error) {
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("invalid upload request: %v", err))
+	}
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("invalid upload request: %v", err))
+	}
+	if part.FormName() != "chunk" {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent, "the file must be uploaded in the chunk field")
+	}
+
+	expected, err := h.getExpectedChecksum(image, req, part.FileName())
+	if err != nil {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+
+	size, _ := strconv.ParseInt(req.URL.Query().Get("size"), 10, 64)
+	info, file, err := imageformat.Peek(part, size)
+	if err != nil {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+	if info.Format.NeedsConversion() {
+		return nil, "", nil, apierror.NewAPIError(validation.InvalidBodyContent,
+			fmt.Sprintf("the %s image must be converted to qcow2 or raw before uploading, or be imported from a URL to convert it", info.Format))
+	}
+	if err := h.updateImageOnConflict(image, func(toUpdate *apisv1beta1.VirtualMachineImage) {
+		toUpdate.Status.Format = string(info.Format)
+		toUpdate.Status.VirtualSize = info.VirtualSize
+		if expected != nil {
+			toUpdate.Status.Checksum = expected.String()
+		}
+	}); err != nil {
+		return nil, "", nil, err
+	}
+	if expected != nil {
+		file = checksum.NewVerifier(file, expected)
+	}
+
+	r, w := io.Pipe()
+	m := multipart.NewWriter(w)
+	result := make(chan error, 1)
+	go func() {
+		defer part.Close()
+		chunk, err := m.CreateFormFile("chunk", part.FileName())
+		if err == nil {
+			_, err = io.Copy(chunk, file)
+		}
+		if err == nil {
+			err = m.Close()
+		}
+		w.CloseWithError(err)
+		result <- err
+	}()
+	return r, m.FormDataContentType(), result, nil
+}
+
+// getExpectedChecksum returns the checksum in the request, or the one of the image.
+// It's nil if there is no checksum to verify.
+func (h UploadActionHandler) getExpectedChecksum(image *apisv1beta1.VirtualMachineImage, req *http.Request, fileName string) (*checksum.Checksum, error) {
+	if value := req.URL.Query().Get(checksumParam); value != "" {
+		return checksum.Parse(value)
+	}
+	if image.Status.Checksum != "" {
+		return checksum.Parse(image.Status.Checksum)
+	}
+	if image.Spec.ChecksumURL != "" {
+		return checksum.FindInURL(req.Context(), &h.httpClient, image.Spec.ChecksumURL, fileName)
+	}
+	return nil, nil
+}
+
 func (h UploadActionHandler) waitForBackingImageDataSourceReady(name string) error {
 	retry := 30
 	for i := 0; i < retry; i++ {
@@ -149,6 +283,15 @@ func (h UploadActionHandler) waitForBackingImageDataSourceReady(name string) err
 
 func (h UploadActionHandler) updateImportedConditionOnConflict(image *apisv1beta1.VirtualMachineImage,
 	status, reason, message string) error {
+	return h.updateImageOnConflict(image, func(toUpdate *apisv1beta1.VirtualMachineImage) {
+		apisv1beta1.ImageImported.SetStatus(toUpdate, status)
+		apisv1beta1.ImageImported.Reason(toUpdate, reason)
+		apisv1beta1.ImageImported.Message(toUpdate, message)
+	})
+}
+
+func (h UploadActionHandler) updateImageOnConflict(image *apisv1beta1.VirtualMachineImage,
+	mutate func(toUpdate *apisv1beta1.VirtualMachineImage)) error {
 	retry := 3
 	for i := 0; i < retry; i++ {
 		current, err := h.ImageCache.Get(image.Namespace, image.Name)
@@ -159,9 +302,7 @@ func (h UploadActionHandler) updateImportedConditionOnConflict(image *apisv1beta
 			return nil
 		}
 		toUpdate := current.DeepCopy()
-		apisv1beta1.ImageImported.SetStatus(toUpdate, status)
-		apisv1beta1.ImageImported.Reason(toUpdate, reason)
-		apisv1beta1.ImageImported.Message(toUpdate, message)
+		mutate(toUpdate)
 		if reflect.DeepEqual(current, toUpdate) {
 			return nil
 		}
@@ -171,5 +312,5 @@ func (h UploadActionHandler) updateImportedConditionOnConflict(image *apisv1beta
 		}
 		time.Sleep(2 * time.Second)
 	}
-	return errors.New("failed to update image uploaded condition, max retries exceeded")
+	return errors.New("failed to update image, max retries exceeded")
 }
@@ -7,17 +7,46 @@ import (
 	"github.com/rancher/steve/pkg/schema"
 	"github.com/rancher/steve/pkg/server"
 	"github.com/rancher/wrangler/pkg/schemas"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
 )
 
 func RegisterSchema(scaled *config.Scaled, server *server.Server, options config.Options) error {
+	server.BaseSchemas.MustImportAndCustomize(DownloadInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(ExportToBackupTargetInput{}, nil)
+
+	clientSet, err := kubernetes.NewForConfig(server.RESTConfig)
+	if err != nil {
+		return err
+	}
+	images := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
+	exportActionHandler := ExportActionHandler{
+		Images:            images,
+		ImageCache:        images.Cache(),
+		BackupTargetCache: scaled.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
+		Exporter: export.NewExporter(server.RESTConfig, clientSet, scaled.CoreFactory.Core().V1().Pod(),
+			scaled.CoreFactory.Core().V1().PersistentVolumeClaim()),
+	}
+	familyActionHandler := FamilyActionHandler{
+		Images:     images,
+		ImageCache: images.Cache(),
+	}
 	t := schema.Template{
 		ID: "harvesterhci.io.virtualmachineimage",
 		Customize: func(s *types.APISchema) {
 			s.Formatter = Formatter
 			s.ResourceActions = map[string]schemas.Action{
 				actionUpload: {},
+				actionDownload: {
+					Input: "downloadInput",
+				},
+				actionExportToBackupTarget: {
+					Input: "exportToBackupTargetInput",
+				},
+				actionDeprecate: {},
+				actionRollback:  {},
 			}
 			s.ActionHandlers = map[string]http.Handler{
 				actionUpload: UploadActionHandler{
@@ -27,6 +56,10 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 					BackingImageDataSources:     scaled.LonghornFactory.Longhorn().V1beta1().BackingImageDataSource(),
 					BackingImageDataSourceCache: scaled.LonghornFactory.Longhorn().V1beta1().BackingImageDataSource().Cache(),
 				},
+				actionDownload:             exportActionHandler,
+				actionExportToBackupTarget: exportActionHandler,
+				actionDeprecate:            familyActionHandler,
+				actionRollback:             familyActionHandler,
 			}
 		},
 	}
@@ -0,0 +1,11 @@
+package image
+
+type DownloadInput struct {
+	// Format is raw or qcow2, the qcow2 file is compressed
+	Format string `json:"format,omitempty"`
+}
+
+type ExportToBackupTargetInput struct {
+	BackupTargetName string `json:"backupTargetName,omitempty"`
+	Format           string `json:"format,omitempty"`
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rancher/steve/pkg/server"
 
+	"github.com/harvester/harvester/pkg/api/backup"
 	"github.com/harvester/harvester/pkg/api/image"
 	"github.com/harvester/harvester/pkg/api/keypair"
 	"github.com/harvester/harvester/pkg/api/node"
@@ -33,5 +34,6 @@ func Setup(ctx context.Context, server *server.Server, controllers *server.Contr
 		vmtemplate.RegisterSchema,
 		vm.RegisterSchema,
 		node.RegisterSchema,
-		volume.RegisterSchema)
+		volume.RegisterSchema,
+		backup.RegisterSchema)
 }
@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	defaultBatchConcurrency = 5
+	maxBatchConcurrency     = 50
+)
+
+// vmBatchActionHandler applies an action to all the VMs matching a namespace and a label selector. Every VM goes
+// through the same path as the action on a single VM, so the prechecks of the action are enforced for each of them.
+type vmBatchActionHandler struct {
+	vmActionHandler *vmActionHandler
+}
+
+func (h vmBatchActionHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	output, err := h.do(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*apierror.APIError); ok {
+			status = e.Code.Status
+		}
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(err.Error()))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(rw).Encode(output)
+}
+
+func (h vmBatchActionHandler) do(req *http.Request) (*BatchOutput, error) {
+	var input BatchInput
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+	}
+	selector, err := validateBatchInput(&input)
+	if err != nil {
+		return nil, apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+
+	apiOp := types.GetAPIContext(req.Context())
+	if apiOp == nil || apiOp.AccessControl == nil {
+		return nil, apierror.NewAPIError(validation.PermissionDenied, "can't check the access to the VMs")
+	}
+	vms, err := h.vmActionHandler.vmCache.List(input.Namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	vms = filterAuthorizedVMs(apiOp, vms)
+	sort.Slice(vms, func(i, j int) bool {
+		if vms[i].Namespace != vms[j].Namespace {
+			return vms[i].Namespace < vms[j].Namespace
+		}
+		return vms[i].Name < vms[j].Name
+	})
+	operate := func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+		return h.vmActionHandler.batchOperate(ctx, vm, input)
+	}
+
+	output := runBatch(req.Context(), vms, input.Concurrency, operate)
+	logrus.Infof("%s %d VMs selected by namespace %q and label selector %q, %d failed",
+		input.Action, len(output.Results), input.Namespace, input.LabelSelector, output.Failed)
+	return output, nil
+}
+
+// filterAuthorizedVMs returns the VMs the user can update, the others are left out of the batch and its output so
+// that the VMs the user can't see aren't revealed
+func filterAuthorizedVMs(apiOp *types.APIRequest, vms []*kubevirtv1.VirtualMachine) []*kubevirtv1.VirtualMachine {
+	authorized := make([]*kubevirtv1.VirtualMachine, 0, len(vms))
+	for _, vm := range vms {
+		if err := apiOp.AccessControl.CanDo(apiOp, vmSchemaID, "update", vm.Namespace, vm.Name); err == nil {
+			authorized = append(authorized, vm)
+		}
+	}
+	return authorized
+}
+
+// validateBatchInput validates the input and sets the default concurrency, it returns the selector of the VMs
+func validateBatchInput(input *BatchInput) (labels.Selector, error) {
+	switch input.Action {
+	case startVM, stopVM, restartVM, softReboot:
+		if input.NodeName != "" {
+			return nil, fmt.Errorf("nodeName is only supported by the %s action", migrate)
+		}
+	case migrate:
+	default:
+		return nil, fmt.Errorf("unsupported action %q, it must be one of %s, %s, %s, %s and %s",
+			input.Action, startVM, stopVM, restartVM, softReboot, migrate)
+	}
+	// an empty request would select all the VMs of the cluster
+	if input.Namespace == "" && input.LabelSelector == "" {
+		return nil, fmt.Errorf("at least one of namespace and labelSelector is required")
+	}
+	if input.Concurrency < 0 || input.Concurrency > maxBatchConcurrency {
+		return nil, fmt.Errorf("concurrency must be between 1 and %d", maxBatchConcurrency)
+	}
+	if input.Concurrency == 0 {
+		input.Concurrency = defaultBatchConcurrency
+	}
+	selector, err := metav1.ParseToLabelSelector(input.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labelSelector %q: %w", input.LabelSelector, err)
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+func (h *vmActionHandler) batchOperate(ctx context.Context, vm *kubevirtv1.VirtualMachine, input BatchInput) error {
+	switch input.Action {
+	case migrate:
+		return h.migrate(ctx, vm.Namespace, vm.Name, input.NodeName)
+	case softReboot:
+		return h.subresourceOperate(ctx, vmiResource, vm.Namespace, vm.Name, input.Action)
+	default:
+		return h.subresourceOperate(ctx, vmResource, vm.Namespace, vm.Name, input.Action)
+	}
+}
+
+// runBatch operates the VMs with at most concurrency VMs at the same time, the results are in the order of the VMs
+func runBatch(ctx context.Context, vms []*kubevirtv1.VirtualMachine, concurrency int,
+	operate func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error) *BatchOutput {
+	output := &BatchOutput{
+		Results: make([]BatchResult, len(vms)),
+	}
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, concurrency)
+	for i, vm := range vms {
+		output.Results[i] = BatchResult{
+			Namespace: vm.Namespace,
+			Name:      vm.Name,
+		}
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(result *BatchResult, vm *kubevirtv1.VirtualMachine) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			if err := operate(ctx, vm); err != nil {
+				result.Error = err.Error()
+			}
+		}(&output.Results[i], vm)
+	}
+	wg.Wait()
+
+	for _, result := range output.Results {
+		if result.Error != "" {
+			output.Failed++
+		}
+	}
+	return output
+}
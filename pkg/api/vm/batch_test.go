@@ -0,0 +1,130 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func Test_validateBatchInput(t *testing.T) {
+	var testCases = []struct {
+		name                string
+		input               BatchInput
+		expectedError       bool
+		expectedConcurrency int
+		matchedLabels       labels.Set
+	}{
+		{
+			name:                "stop the VMs in a namespace",
+			input:               BatchInput{Action: stopVM, Namespace: "default"},
+			expectedConcurrency: defaultBatchConcurrency,
+			matchedLabels:       labels.Set{"app": "web"},
+		},
+		{
+			name:                "migrate the VMs matching a label selector",
+			input:               BatchInput{Action: migrate, LabelSelector: "app=web,tier in (frontend)", Concurrency: 2, NodeName: "node1"},
+			expectedConcurrency: 2,
+			matchedLabels:       labels.Set{"app": "web", "tier": "frontend"},
+		},
+		{
+			name:          "unsupported action",
+			input:         BatchInput{Action: pauseVM, Namespace: "default"},
+			expectedError: true,
+		},
+		{
+			name:          "node name of a start action",
+			input:         BatchInput{Action: startVM, Namespace: "default", NodeName: "node1"},
+			expectedError: true,
+		},
+		{
+			name:          "all VMs of the cluster",
+			input:         BatchInput{Action: restartVM},
+			expectedError: true,
+		},
+		{
+			name:          "too large concurrency",
+			input:         BatchInput{Action: softReboot, Namespace: "default", Concurrency: maxBatchConcurrency + 1},
+			expectedError: true,
+		},
+		{
+			name:          "invalid label selector",
+			input:         BatchInput{Action: startVM, LabelSelector: "app in web"},
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		input := tc.input
+		selector, err := validateBatchInput(&input)
+		if tc.expectedError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedConcurrency, input.Concurrency, tc.name)
+		assert.True(t, selector.Matches(tc.matchedLabels), tc.name)
+	}
+}
+
+func Test_runBatch(t *testing.T) {
+	var vms []*kubevirtv1.VirtualMachine
+	for _, name := range []string{"vm1", "vm2", "vm3", "vm4", "vm5"} {
+		vms = append(vms, &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}})
+	}
+
+	var (
+		lock          sync.Mutex
+		running       int
+		maxConcurrent int
+	)
+	operate := func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+		lock.Lock()
+		running++
+		if running > maxConcurrent {
+			maxConcurrent = running
+		}
+		lock.Unlock()
+		defer func() {
+			lock.Lock()
+			running--
+			lock.Unlock()
+		}()
+		if vm.Name == "vm3" {
+			return errors.New("can not start the VM")
+		}
+		return nil
+	}
+
+	output := runBatch(context.Background(), vms, 2, operate)
+	assert.LessOrEqual(t, maxConcurrent, 2)
+	assert.Equal(t, 1, output.Failed)
+	assert.Len(t, output.Results, len(vms))
+	for i, result := range output.Results {
+		assert.Equal(t, vms[i].Name, result.Name)
+		if result.Name == "vm3" {
+			assert.Equal(t, "can not start the VM", result.Error)
+		} else {
+			assert.Empty(t, result.Error)
+		}
+	}
+}
+
+func Test_filterAuthorizedVMs(t *testing.T) {
+	vms := []*kubevirtv1.VirtualMachine{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "vm2"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm3"}},
+	}
+	apiOp := &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"update"}, granted: map[string][]string{
+		"default": {vmSchemaID},
+	}}}
+
+	authorized := filterAuthorizedVMs(apiOp, vms)
+	assert.Equal(t, []*kubevirtv1.VirtualMachine{vms[0], vms[2]}, authorized)
+}
@@ -0,0 +1,416 @@
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/apiserver/pkg/types"
+	wranglername "github.com/rancher/wrangler/pkg/name"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/builder"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	cloneModeFull   = "full"
+	cloneModeLinked = "linked"
+
+	kubevirtAnnotationPrefix = "kubevirt.io/"
+)
+
+// cloneTargetSchemaIDs are the resources a clone creates in the target namespace, the API creates them with its own
+// service account so the caller must be allowed to create them.
+var cloneTargetSchemaIDs = []string{vmSchemaID, secretSchemaID, pvcSchemaID}
+
+// vmClone is what a clone of a VM creates
+type vmClone struct {
+	vm *kubevirtv1.VirtualMachine
+	// snapshots are the snapshots of the volumes of the VM in the namespace of the VM, the volumes of a linked clone
+	// are created from them
+	snapshots []*snapshotv1.VolumeSnapshot
+	// secrets are the cloud-init and credential secrets of the VM, keyed by their names, and the names of their copies
+	secrets map[string]string
+}
+
+// clone copies the VM to a new VM along with its volumes and secrets. The volumes are copied by CSI volume cloning
+// for a full clone, or created from volume snapshots for a linked clone. The VMs in other namespaces are linked clones,
+// the snapshots are bound to the target namespace by the VM controller once they're ready.
+func (h *vmActionHandler) clone(apiOp *types.APIRequest, namespace, name string, input CloneInput) error {
+	if input.TargetNamespace == "" {
+		input.TargetNamespace = namespace
+	}
+	if input.Mode == "" {
+		input.Mode = cloneModeFull
+		if input.TargetNamespace != namespace {
+			input.Mode = cloneModeLinked
+		}
+	}
+	if input.Mode != cloneModeFull && input.Mode != cloneModeLinked {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("unsupported mode %q, it must be full or linked", input.Mode))
+	}
+	if input.Mode == cloneModeFull && input.TargetNamespace != namespace {
+		return apierror.NewAPIError(validation.InvalidBodyContent, "the volumes can't be fully cloned to another namespace, use the linked mode instead")
+	}
+
+	if err := checkCloneAccess(apiOp, input.TargetNamespace); err != nil {
+		return err
+	}
+	if err := checkSourceAccess(apiOp, vmSchemaID, "update", namespace, name); err != nil {
+		return err
+	}
+	// the volumes of a linked clone are created from in-cluster snapshots
+	if input.Mode == cloneModeLinked {
+		if err := util.CheckLocalSnapshotSupported(h.longhornSettingCache); err != nil {
+			return apierror.NewAPIError(validation.InvalidState, err.Error())
+		}
+	}
+
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return err
+	}
+	if _, err := h.vmCache.Get(input.TargetNamespace, input.TargetVMName); err == nil {
+		return apierror.NewAPIError(validation.Conflict, fmt.Sprintf("VM %s/%s already exists", input.TargetNamespace, input.TargetVMName))
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	pvcs := map[string]*corev1.PersistentVolumeClaim{}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := h.pvcCache.Get(namespace, volume.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			return err
+		}
+		pvcs[pvc.Name] = pvc
+	}
+
+	clone, err := newVMClone(vm, pvcs, input)
+	if err != nil {
+		return apierror.NewAPIError(validation.InvalidBodyContent, err.Error())
+	}
+	if err := checkCloneSourceAccess(apiOp, namespace, pvcs, clone.secrets); err != nil {
+		return err
+	}
+
+	created, err := h.createVMClone(clone, namespace)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("VM %s/%s is cloned to %s/%s in %s mode", namespace, name, created.Namespace, created.Name, input.Mode)
+	return nil
+}
+
+// checkCloneAccess checks the caller is allowed to create the resources of the clone in the target namespace
+func checkCloneAccess(apiOp *types.APIRequest, namespace string) error {
+	if apiOp == nil || apiOp.AccessControl == nil {
+		return apierror.NewAPIError(validation.PermissionDenied, "can't check the access to the target namespace")
+	}
+	for _, schemaID := range cloneTargetSchemaIDs {
+		if err := apiOp.AccessControl.CanDo(apiOp, schemaID, "create", namespace, ""); err != nil {
+			return apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("can't create %s in namespace %s", schemaID, namespace))
+		}
+	}
+	return nil
+}
+
+// checkCloneSourceAccess checks the caller is allowed to read the volumes and the secrets copied by the clone,
+// they're read with the service account of the API.
+func checkCloneSourceAccess(apiOp *types.APIRequest, namespace string, pvcs map[string]*corev1.PersistentVolumeClaim, secrets map[string]string) error {
+	for pvcName := range pvcs {
+		if err := checkSourceAccess(apiOp, pvcSchemaID, "get", namespace, pvcName); err != nil {
+			return err
+		}
+	}
+	for secretName := range secrets {
+		if err := checkSourceAccess(apiOp, secretSchemaID, "get", namespace, secretName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSourceAccess(apiOp *types.APIRequest, schemaID, verb, namespace, name string) error {
+	if apiOp == nil || apiOp.AccessControl == nil {
+		return apierror.NewAPIError(validation.PermissionDenied, "can't check the access to the source VM")
+	}
+	if err := apiOp.AccessControl.CanDo(apiOp, schemaID, verb, namespace, name); err != nil {
+		return apierror.NewAPIError(validation.PermissionDenied, fmt.Sprintf("can't %s %s %s/%s", verb, schemaID, namespace, name))
+	}
+	return nil
+}
+
+// createVMClone creates the snapshots, the secrets and the VM of the clone. The objects created by the call are
+// removed if the clone fails, so that the clone can be retried.
+func (h *vmActionHandler) createVMClone(clone *vmClone, sourceNamespace string) (vm *kubevirtv1.VirtualMachine, err error) {
+	var (
+		snapshots []*snapshotv1.VolumeSnapshot
+		secrets   []string
+	)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if vm != nil {
+			if rollbackErr := h.vms.Delete(vm.Namespace, vm.Name, &metav1.DeleteOptions{}); rollbackErr != nil && !apierrors.IsNotFound(rollbackErr) {
+				logrus.Errorf("failed to remove cloned VM %s/%s: %v", vm.Namespace, vm.Name, rollbackErr)
+			}
+			vm = nil
+		}
+		for _, name := range secrets {
+			if rollbackErr := h.secretClient.Delete(clone.vm.Namespace, name, &metav1.DeleteOptions{}); rollbackErr != nil && !apierrors.IsNotFound(rollbackErr) {
+				logrus.Errorf("failed to remove cloned secret %s/%s: %v", clone.vm.Namespace, name, rollbackErr)
+			}
+		}
+		for _, snapshot := range snapshots {
+			if rollbackErr := h.snapshots.Delete(snapshot.Namespace, snapshot.Name, &metav1.DeleteOptions{}); rollbackErr != nil && !apierrors.IsNotFound(rollbackErr) {
+				logrus.Errorf("failed to remove clone snapshot %s/%s: %v", snapshot.Namespace, snapshot.Name, rollbackErr)
+			}
+		}
+	}()
+
+	for _, snapshot := range clone.snapshots {
+		// a snapshot left by a failed clone is reused
+		if _, err := h.snapshots.Create(snapshot); apierrors.IsAlreadyExists(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to create snapshot %s/%s: %w", snapshot.Namespace, snapshot.Name, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	for sourceName, targetName := range clone.secrets {
+		copied, err := h.copyCloneSecret(sourceNamespace, sourceName, clone.vm.Namespace, targetName)
+		if err != nil {
+			return nil, err
+		}
+		if copied {
+			secrets = append(secrets, targetName)
+		}
+	}
+	if vm, err = h.vms.Create(clone.vm); err != nil {
+		return nil, err
+	}
+	// the secrets are removed along with the cloned VM
+	for _, targetName := range clone.secrets {
+		if err := h.setCloneSecretOwner(vm, targetName); err != nil {
+			return vm, err
+		}
+	}
+	return vm, nil
+}
+
+// copyCloneSecret copies the secret and returns whether it's created. An existing secret with the same data is reused,
+// it's left by a clone which failed to be rolled back, but a different one isn't overwritten.
+func (h *vmActionHandler) copyCloneSecret(sourceNamespace, sourceName, targetNamespace, targetName string) (bool, error) {
+	secret, err := h.secretCache.Get(sourceNamespace, sourceName)
+	if err != nil {
+		return false, err
+	}
+	_, err = h.secretClient.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      targetName,
+			Namespace: targetNamespace,
+		},
+		Type: secret.Type,
+		Data: secret.Data,
+	})
+	if err == nil {
+		return true, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+	existing, err := h.secretClient.Get(targetNamespace, targetName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if len(existing.OwnerReferences) > 0 || !reflect.DeepEqual(existing.Data, secret.Data) {
+		return false, apierror.NewAPIError(validation.Conflict, fmt.Sprintf("secret %s/%s already exists", targetNamespace, targetName))
+	}
+	return false, nil
+}
+
+func (h *vmActionHandler) setCloneSecretOwner(vm *kubevirtv1.VirtualMachine, name string) error {
+	secret, err := h.secretClient.Get(vm.Namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	toUpdate := secret.DeepCopy()
+	toUpdate.OwnerReferences = append(toUpdate.OwnerReferences, metav1.OwnerReference{
+		APIVersion: kubevirtv1.SchemeGroupVersion.String(),
+		Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+		Name:       vm.Name,
+		UID:        vm.UID,
+	})
+	_, err = h.secretClient.Update(toUpdate)
+	return err
+}
+
+// newVMClone returns the clone of the VM. The MAC addresses are removed to be regenerated, and the hostname is the
+// name of the cloned VM so that cloud-init sees a new instance.
+func newVMClone(vm *kubevirtv1.VirtualMachine, pvcs map[string]*corev1.PersistentVolumeClaim, input CloneInput) (*vmClone, error) {
+	if len(vm.Spec.DataVolumeTemplates) > 0 {
+		return nil, fmt.Errorf("VM %s/%s with data volume templates can't be cloned", vm.Namespace, vm.Name)
+	}
+
+	cloned := removeMacAddresses(vm)
+	cloned.ObjectMeta = metav1.ObjectMeta{
+		Name:        input.TargetVMName,
+		Namespace:   input.TargetNamespace,
+		Labels:      cloned.Labels,
+		Annotations: map[string]string{},
+	}
+	for key, value := range vm.Annotations {
+		if strings.HasPrefix(key, kubevirtAnnotationPrefix) || key == util.RemovedPVCsAnnotationKey {
+			continue
+		}
+		cloned.Annotations[key] = value
+	}
+	cloned.Status = kubevirtv1.VirtualMachineStatus{}
+	if cloned.Spec.RunStrategy != nil {
+		runStrategy := kubevirtv1.RunStrategyHalted
+		if input.Start {
+			runStrategy = kubevirtv1.RunStrategyRerunOnFailure
+		}
+		cloned.Spec.RunStrategy = &runStrategy
+	} else {
+		cloned.Spec.Running = pointer.BoolPtr(input.Start)
+	}
+
+	template := cloned.Spec.Template
+	if template.ObjectMeta.Labels[builder.LabelKeyVirtualMachineName] != "" {
+		template.ObjectMeta.Labels[builder.LabelKeyVirtualMachineName] = input.TargetVMName
+	}
+	if template.Spec.Hostname != "" {
+		template.Spec.Hostname = input.TargetVMName
+	}
+
+	clone := &vmClone{
+		vm:      cloned,
+		secrets: map[string]string{},
+	}
+	var volumeClaimTemplates []*corev1.PersistentVolumeClaim
+	for i, volume := range template.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			pvc, ok := pvcs[volume.PersistentVolumeClaim.ClaimName]
+			if !ok {
+				return nil, fmt.Errorf("volume %s of VM %s/%s is not found", volume.PersistentVolumeClaim.ClaimName, vm.Namespace, vm.Name)
+			}
+			pvcTemplate, snapshot := newClonePVC(pvc, vm, volume.Name, input)
+			if snapshot != nil {
+				clone.snapshots = append(clone.snapshots, snapshot)
+			}
+			template.Spec.Volumes[i].PersistentVolumeClaim.ClaimName = pvcTemplate.Name
+			volumeClaimTemplates = append(volumeClaimTemplates, pvcTemplate)
+		case volume.DataVolume != nil:
+			return nil, fmt.Errorf("VM %s/%s with data volumes can't be cloned", vm.Namespace, vm.Name)
+		case volume.CloudInitNoCloud != nil:
+			clone.cloneSecretRef(volume.CloudInitNoCloud.UserDataSecretRef, input.TargetVMName, volume.Name, "userdata")
+			clone.cloneSecretRef(volume.CloudInitNoCloud.NetworkDataSecretRef, input.TargetVMName, volume.Name, "networkdata")
+		case volume.CloudInitConfigDrive != nil:
+			clone.cloneSecretRef(volume.CloudInitConfigDrive.UserDataSecretRef, input.TargetVMName, volume.Name, "userdata")
+			clone.cloneSecretRef(volume.CloudInitConfigDrive.NetworkDataSecretRef, input.TargetVMName, volume.Name, "networkdata")
+		}
+	}
+	for i, credential := range template.Spec.AccessCredentials {
+		if sshPublicKey := credential.SSHPublicKey; sshPublicKey != nil && sshPublicKey.Source.Secret != nil {
+			sshPublicKey.Source.Secret.SecretName = clone.cloneSecret(sshPublicKey.Source.Secret.SecretName,
+				input.TargetVMName, fmt.Sprintf("credential-%d", i), "sshpublickey")
+		}
+		if userPassword := credential.UserPassword; userPassword != nil && userPassword.Source.Secret != nil {
+			userPassword.Source.Secret.SecretName = clone.cloneSecret(userPassword.Source.Secret.SecretName,
+				input.TargetVMName, fmt.Sprintf("credential-%d", i), "userpassword")
+		}
+	}
+
+	delete(cloned.Annotations, util.AnnotationVolumeClaimTemplates)
+	if len(volumeClaimTemplates) > 0 {
+		bytes, err := json.Marshal(volumeClaimTemplates)
+		if err != nil {
+			return nil, err
+		}
+		cloned.Annotations[util.AnnotationVolumeClaimTemplates] = string(bytes)
+	}
+	return clone, nil
+}
+
+func (c *vmClone) cloneSecretRef(ref *corev1.LocalObjectReference, vmName, volumeName, suffix string) {
+	if ref != nil {
+		ref.Name = c.cloneSecret(ref.Name, vmName, volumeName, suffix)
+	}
+}
+
+func (c *vmClone) cloneSecret(name, vmName, volumeName, suffix string) string {
+	if cloned, ok := c.secrets[name]; ok {
+		return cloned
+	}
+	cloned := wranglername.SafeConcatName(vmName, volumeName, suffix)
+	c.secrets[name] = cloned
+	return cloned
+}
+
+// newClonePVC returns the template of the cloned PVC, and the snapshot it's created from for a linked clone
+func newClonePVC(pvc *corev1.PersistentVolumeClaim, vm *kubevirtv1.VirtualMachine, volumeName string, input CloneInput) (*corev1.PersistentVolumeClaim, *snapshotv1.VolumeSnapshot) {
+	pvcTemplate := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        wranglername.SafeConcatName(input.TargetVMName, volumeName),
+			Annotations: map[string]string{},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        pvc.Spec.Resources,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+		},
+	}
+	if imageID := pvc.Annotations[util.AnnotationImageID]; imageID != "" {
+		pvcTemplate.Annotations[util.AnnotationImageID] = imageID
+	}
+
+	if input.Mode == cloneModeFull {
+		pvcTemplate.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			Kind: "PersistentVolumeClaim",
+			Name: pvc.Name,
+		}
+		return pvcTemplate, nil
+	}
+
+	snapshot := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      wranglername.SafeConcatName("vmclone", input.TargetNamespace, input.TargetVMName, volumeName),
+			Namespace: vm.Namespace,
+			Labels: map[string]string{
+				util.LabelVMCloneNamespace: input.TargetNamespace,
+				util.LabelVMCloneName:      input.TargetVMName,
+			},
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: pointer.StringPtr(pvc.Name),
+			},
+			VolumeSnapshotClassName: pointer.StringPtr(settings.LocalVolumeSnapshotClass.Get()),
+		},
+	}
+	// the snapshot in another namespace has the same name
+	pvcTemplate.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: pointer.StringPtr(snapshotv1.SchemeGroupVersion.Group),
+		Kind:     "VolumeSnapshot",
+		Name:     snapshot.Name,
+	}
+	return pvcTemplate, snapshot
+}
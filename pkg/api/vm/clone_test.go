@@ -0,0 +1,263 @@
+package vm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/apiserver/pkg/types"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/builder"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func newCloneSourceVM() *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				util.AnnotationVolumeClaimTemplates:       `[{"metadata":{"name":"vm-disk-0"}}]`,
+				util.RemovedPVCsAnnotationKey:             "vm-disk-0",
+				"kubevirt.io/latest-observed-api-version": "v1",
+				"description": "web server",
+			},
+		},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Running: pointer.BoolPtr(true),
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{builder.LabelKeyVirtualMachineName: "vm"},
+				},
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Hostname: "vm",
+					Domain: kubevirtv1.DomainSpec{
+						Devices: kubevirtv1.Devices{
+							Interfaces: []kubevirtv1.Interface{{Name: "default", MacAddress: "52:54:00:00:00:01"}},
+						},
+					},
+					Volumes: []kubevirtv1.Volume{
+						{
+							Name: "disk-0",
+							VolumeSource: kubevirtv1.VolumeSource{
+								PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+									PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "vm-disk-0"},
+								},
+							},
+						},
+						{
+							Name: "cloudinitdisk",
+							VolumeSource: kubevirtv1.VolumeSource{
+								CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
+									UserDataSecretRef:    &corev1.LocalObjectReference{Name: "vm-cloudinit"},
+									NetworkDataSecretRef: &corev1.LocalObjectReference{Name: "vm-cloudinit"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_newVMClone(t *testing.T) {
+	pvcs := map[string]*corev1.PersistentVolumeClaim{
+		"vm-disk-0": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "vm-disk-0",
+				Namespace:   "default",
+				Annotations: map[string]string{util.AnnotationImageID: "default/image-ubuntu", "pv.kubernetes.io/bind-completed": "yes"},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+				StorageClassName: pointer.StringPtr("longhorn-image-ubuntu"),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		},
+	}
+	var testCases = []struct {
+		name               string
+		input              CloneInput
+		expectedNamespace  string
+		expectedSnapshots  int
+		expectedDataSource string
+	}{
+		{
+			name:               "full clone",
+			input:              CloneInput{TargetVMName: "vm-clone", TargetNamespace: "default", Mode: cloneModeFull},
+			expectedNamespace:  "default",
+			expectedDataSource: `"dataSource":{"apiGroup":null,"kind":"PersistentVolumeClaim","name":"vm-disk-0"}`,
+		},
+		{
+			name:               "linked clone to another namespace",
+			input:              CloneInput{TargetVMName: "vm-clone", TargetNamespace: "dev", Mode: cloneModeLinked, Start: true},
+			expectedNamespace:  "dev",
+			expectedSnapshots:  1,
+			expectedDataSource: `"dataSource":{"apiGroup":"snapshot.storage.k8s.io","kind":"VolumeSnapshot","name":"vmclone-dev-vm-clone-disk-0"}`,
+		},
+	}
+	for _, tc := range testCases {
+		clone, err := newVMClone(newCloneSourceVM(), pvcs, tc.input)
+		assert.Nil(t, err, tc.name)
+
+		vm := clone.vm
+		assert.Equal(t, "vm-clone", vm.Name, tc.name)
+		assert.Equal(t, tc.expectedNamespace, vm.Namespace, tc.name)
+		assert.Equal(t, "web", vm.Labels["app"], tc.name)
+		assert.Equal(t, "web server", vm.Annotations["description"], tc.name)
+		assert.NotContains(t, vm.Annotations, "kubevirt.io/latest-observed-api-version", tc.name)
+		assert.NotContains(t, vm.Annotations, util.RemovedPVCsAnnotationKey, tc.name)
+		assert.Equal(t, tc.input.Start, *vm.Spec.Running, tc.name)
+		assert.Equal(t, "vm-clone", vm.Spec.Template.ObjectMeta.Labels[builder.LabelKeyVirtualMachineName], tc.name)
+		assert.Equal(t, "vm-clone", vm.Spec.Template.Spec.Hostname, tc.name)
+		assert.Empty(t, vm.Spec.Template.Spec.Domain.Devices.Interfaces[0].MacAddress, tc.name)
+
+		volumes := vm.Spec.Template.Spec.Volumes
+		assert.Equal(t, "vm-clone-disk-0", volumes[0].PersistentVolumeClaim.ClaimName, tc.name)
+		assert.Equal(t, "vm-clone-cloudinitdisk-userdata", volumes[1].CloudInitNoCloud.UserDataSecretRef.Name, tc.name)
+		assert.Equal(t, "vm-clone-cloudinitdisk-userdata", volumes[1].CloudInitNoCloud.NetworkDataSecretRef.Name, tc.name)
+		assert.Equal(t, map[string]string{"vm-cloudinit": "vm-clone-cloudinitdisk-userdata"}, clone.secrets, tc.name)
+
+		volumeClaimTemplates := vm.Annotations[util.AnnotationVolumeClaimTemplates]
+		assert.Contains(t, volumeClaimTemplates, `"name":"vm-clone-disk-0"`, tc.name)
+		assert.Contains(t, volumeClaimTemplates, `"storageClassName":"longhorn-image-ubuntu"`, tc.name)
+		assert.Contains(t, volumeClaimTemplates, tc.expectedDataSource, tc.name)
+		assert.NotContains(t, volumeClaimTemplates, "pv.kubernetes.io/bind-completed", tc.name)
+
+		assert.Len(t, clone.snapshots, tc.expectedSnapshots, tc.name)
+		for _, snapshot := range clone.snapshots {
+			assert.Equal(t, "default", snapshot.Namespace, tc.name)
+			assert.Equal(t, "vm-disk-0", *snapshot.Spec.Source.PersistentVolumeClaimName, tc.name)
+			assert.Equal(t, "dev", snapshot.Labels[util.LabelVMCloneNamespace], tc.name)
+			assert.Equal(t, "vm-clone", snapshot.Labels[util.LabelVMCloneName], tc.name)
+		}
+	}
+}
+
+func Test_newVMClone_dataVolumes(t *testing.T) {
+	vm := newCloneSourceVM()
+	vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{{ObjectMeta: metav1.ObjectMeta{Name: "dv"}}}
+	_, err := newVMClone(vm, nil, CloneInput{TargetVMName: "vm-clone", TargetNamespace: "default", Mode: cloneModeFull})
+	assert.NotNil(t, err)
+}
+
+// fakeAccessControl grants the verbs of the resources in the namespaces
+type fakeAccessControl struct {
+	types.AccessControl
+	verbs   []string
+	granted map[string][]string
+}
+
+func (a fakeAccessControl) CanDo(apiOp *types.APIRequest, resource, verb, namespace, name string) error {
+	for _, r := range a.granted[namespace] {
+		for _, v := range a.verbs {
+			if r == resource && v == verb {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("forbidden")
+}
+
+func Test_checkCloneAccess(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		apiOp       *types.APIRequest
+		expectError bool
+	}{
+		{
+			name:        "no access control",
+			apiOp:       &types.APIRequest{},
+			expectError: true,
+		},
+		{
+			name: "allowed to create all the resources",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"create"}, granted: map[string][]string{
+				"target": {vmSchemaID, secretSchemaID, pvcSchemaID},
+			}}},
+		},
+		{
+			name: "not allowed to create secrets",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"create"}, granted: map[string][]string{
+				"target": {vmSchemaID, pvcSchemaID},
+			}}},
+			expectError: true,
+		},
+		{
+			name: "allowed in another namespace",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"create"}, granted: map[string][]string{
+				"default": {vmSchemaID, secretSchemaID, pvcSchemaID},
+			}}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := checkCloneAccess(tc.apiOp, "target")
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+	}
+}
+
+func Test_checkCloneSourceAccess(t *testing.T) {
+	pvcs := map[string]*corev1.PersistentVolumeClaim{"disk-0": {}}
+	secrets := map[string]string{"vm-cloudinit": "clone-cloudinit"}
+	var testCases = []struct {
+		name        string
+		apiOp       *types.APIRequest
+		expectError bool
+	}{
+		{
+			name:        "no access control",
+			apiOp:       &types.APIRequest{},
+			expectError: true,
+		},
+		{
+			name: "allowed to read the volumes and the secrets",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"get"}, granted: map[string][]string{
+				"source": {secretSchemaID, pvcSchemaID},
+			}}},
+		},
+		{
+			name: "not allowed to read the secrets",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"get"}, granted: map[string][]string{
+				"source": {pvcSchemaID},
+			}}},
+			expectError: true,
+		},
+		{
+			name: "source namespace is forbidden",
+			apiOp: &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"get", "update", "create"}, granted: map[string][]string{
+				"target": {vmSchemaID, secretSchemaID, pvcSchemaID},
+			}}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := checkCloneSourceAccess(tc.apiOp, "source", pvcs, secrets)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+	}
+}
+
+func Test_clone_forbiddenSource(t *testing.T) {
+	apiOp := &types.APIRequest{AccessControl: fakeAccessControl{verbs: []string{"get", "update", "create"}, granted: map[string][]string{
+		"target": {vmSchemaID, secretSchemaID, pvcSchemaID},
+	}}}
+	h := &vmActionHandler{}
+	err := h.clone(apiOp, "source", "vm", CloneInput{TargetNamespace: "target", TargetVMName: "clone", Mode: cloneModeLinked})
+	assert.Error(t, err)
+	apiErr, ok := err.(*apierror.APIError)
+	assert.True(t, ok)
+	assert.Equal(t, validation.PermissionDenied, apiErr.Code)
+}
@@ -26,8 +26,18 @@ const (
 	createTemplate = "createTemplate"
 	addVolume      = "addVolume"
 	removeVolume   = "removeVolume"
+
+	snapshotVM      = "snapshot"
+	restoreSnapshot = "restoreSnapshot"
+	cloneVM         = "clone"
+	resizeVM        = "resize"
+	batchVM         = "batch"
 )
 
+func collectionFormatter(request *types.APIRequest, collection *types.GenericCollection) {
+	collection.AddAction(request, batchVM)
+}
+
 type vmformatter struct {
 	vmiCache ctlkubevirtv1.VirtualMachineInstanceCache
 }
@@ -94,9 +104,40 @@ func (vf *vmformatter) formatter(request *types.APIRequest, resource *types.RawR
 		resource.AddAction(request, restoreVM)
 	}
 
+	if vf.canDoBackup(vm, vmi) {
+		resource.AddAction(request, snapshotVM)
+	}
+
+	// a snapshot can be restored to a new VM while the VM is running,
+	// restoring to the VM itself is validated by the restore webhook
+	if vm.Status.SnapshotInProgress == nil {
+		resource.AddAction(request, restoreSnapshot)
+	}
+
 	if vf.canCreateTemplate(vmi) {
 		resource.AddAction(request, createTemplate)
 	}
+
+	if canClone(vm) {
+		resource.AddAction(request, cloneVM)
+	}
+
+	if !canAbortMigrate(vmi) {
+		resource.AddAction(request, resizeVM)
+	}
+}
+
+// canClone returns true if the volumes of the VM can be copied, the VMs with data volumes are not supported
+func canClone(vm *kubevirtv1.VirtualMachine) bool {
+	if len(vm.Spec.DataVolumeTemplates) > 0 || vm.Status.SnapshotInProgress != nil {
+		return false
+	}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.DataVolume != nil {
+			return false
+		}
+	}
+	return true
 }
 
 func canEjectCdRom(vm *kubevirtv1.VirtualMachine) bool {
@@ -11,11 +11,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/apiserver/pkg/types"
 	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	wranglername "github.com/rancher/wrangler/pkg/name"
 	"github.com/rancher/wrangler/pkg/schemas/validation"
 	"github.com/rancher/wrangler/pkg/slice"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -24,9 +26,11 @@ import (
 
 	volumeapi "github.com/harvester/harvester/pkg/api/volume"
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
-	"github.com/harvester/harvester/pkg/settings"
+	ctllonghornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
+	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1beta1"
 	"github.com/harvester/harvester/pkg/util"
 )
 
@@ -49,11 +53,13 @@ type vmActionHandler struct {
 	backups                   ctlharvesterv1.VirtualMachineBackupClient
 	backupCache               ctlharvesterv1.VirtualMachineBackupCache
 	restores                  ctlharvesterv1.VirtualMachineRestoreClient
-	settingCache              ctlharvesterv1.SettingCache
+	backupTargetCache         ctlharvesterv1.BackupTargetCache
 	nodeCache                 ctlcorev1.NodeCache
 	pvcCache                  ctlcorev1.PersistentVolumeClaimCache
 	secretClient              ctlcorev1.SecretClient
 	secretCache               ctlcorev1.SecretCache
+	snapshots                 ctlsnapshotv1.VolumeSnapshotClient
+	longhornSettingCache      ctllonghornv1.SettingCache
 	virtSubresourceRestClient rest.Interface
 	virtRestClient            rest.Interface
 }
@@ -115,14 +121,42 @@ func (h *vmActionHandler) doAction(rw http.ResponseWriter, r *http.Request) erro
 			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter backup name is required")
 		}
 
-		if err := h.checkBackupTargetConfigured(); err != nil {
+		if input.BackupTargetName == "" {
+			input.BackupTargetName = harvesterv1.DefaultBackupTargetName
+		}
+		if err := h.checkBackupTargetConfigured(input.BackupTargetName); err != nil {
 			return err
 		}
 
-		if err := h.createVMBackup(name, namespace, input); err != nil {
+		if err := h.createVMBackup(name, namespace, input.Name, harvesterv1.Backup, input.BackupTargetName); err != nil {
 			return err
 		}
 		return nil
+	case snapshotVM:
+		var input SnapshotInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+
+		if input.Name == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter snapshot name is required")
+		}
+		if err := util.CheckLocalSnapshotSupported(h.longhornSettingCache); err != nil {
+			return apierror.NewAPIError(validation.InvalidState, err.Error())
+		}
+
+		return h.createVMBackup(name, namespace, input.Name, harvesterv1.Snapshot, "")
+	case restoreSnapshot:
+		var input RestoreSnapshotInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+
+		if input.Name == "" || input.SnapshotName == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter name and snapshotName are required")
+		}
+
+		return h.restoreSnapshot(name, namespace, input)
 	case restoreVM:
 		var input RestoreInput
 		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -133,7 +167,11 @@ func (h *vmActionHandler) doAction(rw http.ResponseWriter, r *http.Request) erro
 			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter name and backupName are required")
 		}
 
-		if err := h.checkBackupTargetConfigured(); err != nil {
+		backup, err := h.backupCache.Get(namespace, input.BackupName)
+		if err != nil {
+			return err
+		}
+		if err := h.checkBackupTargetConfigured(ctlbackup.GetBackupTargetName(backup)); err != nil {
 			return err
 		}
 
@@ -169,6 +207,21 @@ func (h *vmActionHandler) doAction(rw http.ResponseWriter, r *http.Request) erro
 			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `volumeName` are required")
 		}
 		return h.removeVolume(r.Context(), namespace, name, input)
+	case cloneVM:
+		var input CloneInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		if input.TargetVMName == "" {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `targetVMName` is required")
+		}
+		return h.clone(types.GetAPIContext(r.Context()), namespace, name, input)
+	case resizeVM:
+		var input ResizeInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Failed to decode request body: "+err.Error())
+		}
+		return h.resize(namespace, name, input)
 	default:
 		return apierror.NewAPIError(validation.InvalidAction, "Unsupported action")
 	}
@@ -362,11 +415,11 @@ func (h *vmActionHandler) abortMigration(namespace, name string) error {
 	return nil
 }
 
-func (h *vmActionHandler) createVMBackup(vmName, vmNamespace string, input BackupInput) error {
+func (h *vmActionHandler) createVMBackup(vmName, vmNamespace, backupName string, backupType harvesterv1.BackupType, backupTargetName string) error {
 	apiGroup := kubevirtv1.SchemeGroupVersion.Group
 	backup := &harvesterv1.VirtualMachineBackup{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      input.Name,
+			Name:      backupName,
 			Namespace: vmNamespace,
 		},
 		Spec: harvesterv1.VirtualMachineBackupSpec{
@@ -375,10 +428,53 @@ func (h *vmActionHandler) createVMBackup(vmName, vmNamespace string, input Backu
 				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
 				Name:     vmName,
 			},
+			Type:             backupType,
+			BackupTargetName: backupTargetName,
 		},
 	}
 	if _, err := h.backups.Create(backup); err != nil {
-		return fmt.Errorf("failed to create VM backup, error: %s", err.Error())
+		return fmt.Errorf("failed to create VM %s, error: %s", backupType, err.Error())
+	}
+	return nil
+}
+
+// restoreSnapshot restores a VM snapshot to the VM itself, or to a new VM if NewVMName is set
+func (h *vmActionHandler) restoreSnapshot(vmName, vmNamespace string, input RestoreSnapshotInput) error {
+	snapshot, err := h.backupCache.Get(vmNamespace, input.SnapshotName)
+	if err != nil {
+		return err
+	}
+	if snapshot.Spec.Type != harvesterv1.Snapshot {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("%s is not a VM snapshot", input.SnapshotName))
+	}
+	if snapshot.Spec.Source.Name != vmName {
+		return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("snapshot %s doesn't belong to VM %s", input.SnapshotName, vmName))
+	}
+
+	targetName := vmName
+	if input.NewVMName != "" {
+		targetName = input.NewVMName
+	}
+
+	apiGroup := kubevirtv1.SchemeGroupVersion.Group
+	restore := &harvesterv1.VirtualMachineRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      input.Name,
+			Namespace: vmNamespace,
+		},
+		Spec: harvesterv1.VirtualMachineRestoreSpec{
+			Target: corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+				Name:     targetName,
+			},
+			VirtualMachineBackupName:      input.SnapshotName,
+			VirtualMachineBackupNamespace: vmNamespace,
+			NewVM:                         input.NewVMName != "",
+		},
+	}
+	if _, err := h.restores.Create(restore); err != nil {
+		return fmt.Errorf("failed to create restore, error: %s", err.Error())
 	}
 	return nil
 }
@@ -411,19 +507,14 @@ func (h *vmActionHandler) restoreBackup(vmName, vmNamespace string, input Restor
 	return nil
 }
 
-func (h *vmActionHandler) checkBackupTargetConfigured() error {
-	targetSetting, err := h.settingCache.Get(settings.BackupTargetSettingName)
-	if err == nil && harvesterv1.SettingConfigured.IsTrue(targetSetting) {
-		// backup target may be reset to initial/default, the SettingConfigured.IsTrue meets
-		target, err := settings.DecodeBackupTarget(targetSetting.Value)
-		if err != nil {
-			return err
-		}
-		if !target.IsDefaultBackupTarget() {
-			return nil
+func (h *vmActionHandler) checkBackupTargetConfigured(name string) error {
+	if _, err := h.backupTargetCache.Get(name); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("backup target %s is not configured", name))
 		}
+		return err
 	}
-	return fmt.Errorf("backup target is invalid")
+	return nil
 }
 
 func getMigrationUID(vmi *kubevirtv1.VirtualMachineInstance) string {
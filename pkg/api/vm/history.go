@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	migrationHistoryLink = "migrationHistory"
+)
+
+// migrationHistoryHandler returns the latest migrations of a VM from the oldest to the latest
+type migrationHistoryHandler struct {
+	vmCache ctlkubevirtv1.VirtualMachineCache
+}
+
+func (h migrationHistoryHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	history, err := h.do(req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*apierror.APIError); ok {
+			status = e.Code.Status
+		}
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(err.Error()))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(rw).Encode(history)
+}
+
+func (h migrationHistoryHandler) do(req *http.Request) ([]util.MigrationRecord, error) {
+	vars := mux.Vars(req)
+	vm, err := h.vmCache.Get(vars["namespace"], vars["name"])
+	if apierrors.IsNotFound(err) {
+		return nil, apierror.NewAPIError(validation.NotFound, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+	history, err := util.GetMigrationHistory(vm)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		history = []util.MigrationRecord{}
+	}
+	return history, nil
+}
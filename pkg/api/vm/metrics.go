@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+var (
+	migrationsDesc = prometheus.NewDesc(
+		"harvester_vm_migrations",
+		"Number of the migrations in the migration histories of the VMs by source node, target node and result",
+		[]string{"source_node", "target_node", "result"}, nil,
+	)
+	lastMigrationDurationDesc = prometheus.NewDesc(
+		"harvester_vm_last_migration_duration_seconds",
+		"Duration of the latest migration of a VM",
+		[]string{"namespace", "name", "source_node", "target_node", "result"}, nil,
+	)
+)
+
+type migrationKey struct {
+	sourceNode string
+	targetNode string
+	result     string
+}
+
+// migrationCollector collects the metrics of the migrations from the migration histories of the VMs, so that every
+// replica of the API server reports the same metrics
+type migrationCollector struct {
+	vmCache ctlkubevirtv1.VirtualMachineCache
+}
+
+func (c *migrationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- migrationsDesc
+	ch <- lastMigrationDurationDesc
+}
+
+func (c *migrationCollector) Collect(ch chan<- prometheus.Metric) {
+	vms, err := c.vmCache.List("", labels.Everything())
+	if err != nil {
+		logrus.Errorf("failed to list VMs for the migration metrics: %v", err)
+		return
+	}
+	counts, latest := collectMigrations(vms)
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(migrationsDesc, prometheus.GaugeValue, float64(count),
+			key.sourceNode, key.targetNode, key.result)
+	}
+	for vm, record := range latest {
+		ch <- prometheus.MustNewConstMetric(lastMigrationDurationDesc, prometheus.GaugeValue, record.Duration(),
+			vm.Namespace, vm.Name, record.SourceNode, record.TargetNode, record.Result)
+	}
+}
+
+// collectMigrations returns the number of the migrations by source node, target node and result,
+// and the latest migration of each VM
+func collectMigrations(vms []*kubevirtv1.VirtualMachine) (map[migrationKey]int, map[*kubevirtv1.VirtualMachine]util.MigrationRecord) {
+	counts := map[migrationKey]int{}
+	latest := map[*kubevirtv1.VirtualMachine]util.MigrationRecord{}
+	for _, vm := range vms {
+		history, err := util.GetMigrationHistory(vm)
+		if err != nil {
+			logrus.Warn(err)
+			continue
+		}
+		for _, record := range history {
+			counts[migrationKey{sourceNode: record.SourceNode, targetNode: record.TargetNode, result: record.Result}]++
+		}
+		if len(history) > 0 {
+			latest[vm] = history[len(history)-1]
+		}
+	}
+	return counts, latest
+}
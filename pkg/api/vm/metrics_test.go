@@ -0,0 +1,48 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_collectMigrations(t *testing.T) {
+	vm1 := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm1"},
+	}
+	vm2 := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm2"},
+	}
+	vm3 := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "vm3",
+			Annotations: map[string]string{util.AnnotationMigrationHistory: "invalid"},
+		},
+	}
+	records := []struct {
+		vm     *kubevirtv1.VirtualMachine
+		record util.MigrationRecord
+	}{
+		{vm1, util.MigrationRecord{UID: "1", SourceNode: "node1", TargetNode: "node2", Result: util.MigrationResultFailed}},
+		{vm1, util.MigrationRecord{UID: "2", SourceNode: "node1", TargetNode: "node2", Result: util.MigrationResultSucceeded}},
+		{vm2, util.MigrationRecord{UID: "3", SourceNode: "node1", TargetNode: "node2", Result: util.MigrationResultFailed}},
+	}
+	for _, r := range records {
+		_, err := util.AddMigrationRecord(r.vm, r.record)
+		assert.Nil(t, err)
+	}
+
+	counts, latest := collectMigrations([]*kubevirtv1.VirtualMachine{vm1, vm2, vm3})
+	assert.Equal(t, map[migrationKey]int{
+		{sourceNode: "node1", targetNode: "node2", result: util.MigrationResultFailed}:    2,
+		{sourceNode: "node1", targetNode: "node2", result: util.MigrationResultSucceeded}: 1,
+	}, counts)
+	assert.Len(t, latest, 2)
+	assert.Equal(t, "2", latest[vm1].UID)
+	assert.Equal(t, "3", latest[vm2].UID)
+}
@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rancher/apiserver/pkg/apierror"
+	"github.com/rancher/wrangler/pkg/schemas/validation"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// defaultReservedMemory is the memory reserved for QEMU by the VM mutator if the VM doesn't set it
+var defaultReservedMemory = resource.MustParse("100Mi")
+
+// resize changes the vCPUs and the memory limit of the VM. The requests and the guest memory are recalculated
+// from the new limits by the VM mutator. KubeVirt can't change the CPU or the memory of a running VMI, neither live
+// nor by migrating it into a new shape, so a running VM is marked as restart required until it runs with the new shape.
+func (h *vmActionHandler) resize(namespace, name string, input ResizeInput) error {
+	maxCPUs, err := getMaxCPUs()
+	if err != nil {
+		return err
+	}
+	if err := validateResizeInput(input, maxCPUs); err != nil {
+		return err
+	}
+
+	vm, err := h.vmCache.Get(namespace, name)
+	if err != nil {
+		return err
+	}
+	toUpdate, err := newResizedVM(vm, input)
+	if err != nil {
+		return err
+	}
+
+	vmi, err := h.vmiCache.Get(namespace, name)
+	if err == nil && !vmi.IsFinal() && !util.HasSameShape(toUpdate, vmi) {
+		toUpdate.Annotations[util.AnnotationRestartRequired] = "true"
+	} else {
+		delete(toUpdate.Annotations, util.AnnotationRestartRequired)
+	}
+	if equality.Semantic.DeepEqual(vm.Spec, toUpdate.Spec) && util.IsRestartRequired(vm) == util.IsRestartRequired(toUpdate) {
+		return nil
+	}
+	_, err = h.vms.Update(toUpdate)
+	return err
+}
+
+func getMaxCPUs() (int, error) {
+	value := settings.VMMaxCPUs.Get()
+	if value == "" {
+		return 0, nil
+	}
+	maxCPUs, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse setting %s: %w", settings.VMMaxCPUsSettingName, err)
+	}
+	return maxCPUs, nil
+}
+
+// validateResizeInput validates the input with the maximum vCPUs, 0 means unlimited
+func validateResizeInput(input ResizeInput, maxCPUs int) error {
+	if input.CPU == 0 && input.Memory == "" {
+		return apierror.NewAPIError(validation.InvalidBodyContent, "At least one of `cpu` and `memory` is required")
+	}
+	if input.CPU < 0 {
+		return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `cpu` can't be negative")
+	}
+	if maxCPUs > 0 && input.CPU > maxCPUs {
+		return apierror.NewAPIError(validation.InvalidBodyContent,
+			fmt.Sprintf("Parameter `cpu` can't be greater than the maximum %d of setting %s", maxCPUs, settings.VMMaxCPUsSettingName))
+	}
+	if input.Memory != "" {
+		memory, err := resource.ParseQuantity(input.Memory)
+		if err != nil {
+			return apierror.NewAPIError(validation.InvalidBodyContent, fmt.Sprintf("Invalid memory %s: %v", input.Memory, err))
+		}
+		if memory.Sign() <= 0 {
+			return apierror.NewAPIError(validation.InvalidBodyContent, "Parameter `memory` must be positive")
+		}
+	}
+	return nil
+}
+
+// newResizedVM returns a copy of the VM with the vCPUs and the limits in the input
+func newResizedVM(vm *kubevirtv1.VirtualMachine, input ResizeInput) (*kubevirtv1.VirtualMachine, error) {
+	toUpdate := vm.DeepCopy()
+	if toUpdate.Spec.Template == nil {
+		return nil, fmt.Errorf("VM %s/%s has no template", vm.Namespace, vm.Name)
+	}
+	if toUpdate.Annotations == nil {
+		toUpdate.Annotations = map[string]string{}
+	}
+	domain := &toUpdate.Spec.Template.Spec.Domain
+	if domain.Resources.Limits == nil {
+		domain.Resources.Limits = corev1.ResourceList{}
+	}
+
+	if input.CPU > 0 {
+		// the sockets and the threads per core are kept, the vCPUs are resized by the cores per socket
+		topology := util.GetCPUTopology(domain.CPU)
+		vcpusPerCore := topology.Sockets * topology.Threads
+		if uint32(input.CPU)%vcpusPerCore != 0 {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent,
+				fmt.Sprintf("Parameter `cpu` must be a multiple of %d, the %d sockets times the %d threads per core of the VM",
+					vcpusPerCore, topology.Sockets, topology.Threads))
+		}
+		if domain.CPU == nil {
+			domain.CPU = &kubevirtv1.CPU{}
+		}
+		domain.CPU.Cores = uint32(input.CPU) / vcpusPerCore
+		domain.Resources.Limits[corev1.ResourceCPU] = *resource.NewQuantity(int64(input.CPU), resource.DecimalSI)
+	}
+
+	if input.Memory != "" {
+		memory, err := resource.ParseQuantity(input.Memory)
+		if err != nil {
+			return nil, err
+		}
+		reservedMemory := defaultReservedMemory
+		if value := vm.Annotations[util.AnnotationReservedMemory]; value != "" {
+			if reservedMemory, err = resource.ParseQuantity(value); err != nil {
+				return nil, err
+			}
+		}
+		if memory.Cmp(reservedMemory) <= 0 {
+			return nil, apierror.NewAPIError(validation.InvalidBodyContent,
+				fmt.Sprintf("Parameter `memory` must be greater than the reserved memory %s", reservedMemory.String()))
+		}
+		domain.Resources.Limits[corev1.ResourceMemory] = memory
+	}
+	return toUpdate, nil
+}
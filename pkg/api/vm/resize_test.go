@@ -0,0 +1,123 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_validateResizeInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       ResizeInput
+		maxCPUs     int
+		expectedErr bool
+	}{
+		{
+			name:        "empty input",
+			input:       ResizeInput{},
+			expectedErr: true,
+		},
+		{
+			name:        "negative cpu",
+			input:       ResizeInput{CPU: -1},
+			expectedErr: true,
+		},
+		{
+			name:        "more cpu than the maximum",
+			input:       ResizeInput{CPU: 8},
+			maxCPUs:     4,
+			expectedErr: true,
+		},
+		{
+			name:        "unlimited cpu",
+			input:       ResizeInput{CPU: 8},
+			expectedErr: false,
+		},
+		{
+			name:        "invalid memory",
+			input:       ResizeInput{Memory: "8G8"},
+			expectedErr: true,
+		},
+		{
+			name:        "negative memory",
+			input:       ResizeInput{Memory: "-1Gi"},
+			expectedErr: true,
+		},
+		{
+			name:        "cpu and memory",
+			input:       ResizeInput{CPU: 4, Memory: "8Gi"},
+			maxCPUs:     4,
+			expectedErr: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateResizeInput(tc.input, tc.maxCPUs)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_newResizedVM(t *testing.T) {
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Domain: kubevirtv1.DomainSpec{
+						CPU: &kubevirtv1.CPU{Cores: 1},
+						Resources: kubevirtv1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("2Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resized, err := newResizedVM(vm, ResizeInput{CPU: 4})
+	assert.Nil(t, err)
+	domain := resized.Spec.Template.Spec.Domain
+	assert.Equal(t, uint32(4), domain.CPU.Cores)
+	assert.Equal(t, "4", domain.Resources.Limits.Cpu().String())
+	assert.Equal(t, "2Gi", domain.Resources.Limits.Memory().String(), "memory is kept")
+	assert.Equal(t, uint32(1), vm.Spec.Template.Spec.Domain.CPU.Cores, "the VM isn't changed")
+
+	vm.Spec.Template.Spec.Domain.CPU = &kubevirtv1.CPU{Sockets: 2, Cores: 1, Threads: 2}
+	resized, err = newResizedVM(vm, ResizeInput{CPU: 8})
+	assert.Nil(t, err)
+	domain = resized.Spec.Template.Spec.Domain
+	assert.Equal(t, kubevirtv1.CPU{Sockets: 2, Cores: 2, Threads: 2}, *domain.CPU, "sockets and threads are kept")
+	assert.Equal(t, "8", domain.Resources.Limits.Cpu().String())
+
+	_, err = newResizedVM(vm, ResizeInput{CPU: 6})
+	assert.Error(t, err, "cpu isn't a multiple of the sockets times the threads")
+	vm.Spec.Template.Spec.Domain.CPU = &kubevirtv1.CPU{Cores: 1}
+
+	resized, err = newResizedVM(vm, ResizeInput{Memory: "8Gi"})
+	assert.Nil(t, err)
+	domain = resized.Spec.Template.Spec.Domain
+	assert.Equal(t, uint32(1), domain.CPU.Cores, "cpu is kept")
+	assert.Equal(t, "8Gi", domain.Resources.Limits.Memory().String())
+
+	_, err = newResizedVM(vm, ResizeInput{Memory: "100Mi"})
+	assert.Error(t, err, "memory isn't greater than the default reserved memory")
+
+	vm.Annotations = map[string]string{util.AnnotationReservedMemory: "1Gi"}
+	_, err = newResizedVM(vm, ResizeInput{Memory: "512Mi"})
+	assert.Error(t, err, "memory isn't greater than the reserved memory")
+}
@@ -3,6 +3,7 @@ package vm
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rancher/apiserver/pkg/types"
 	"github.com/rancher/steve/pkg/schema"
 	"github.com/rancher/steve/pkg/server"
@@ -17,7 +18,9 @@ import (
 )
 
 const (
-	vmSchemaID = "kubevirt.io.virtualmachine"
+	vmSchemaID     = "kubevirt.io.virtualmachine"
+	secretSchemaID = "secret"
+	pvcSchemaID    = "persistentvolumeclaim"
 )
 
 var (
@@ -31,22 +34,29 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 	server.BaseSchemas.MustImportAndCustomize(EjectCdRomActionInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(BackupInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(RestoreInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(SnapshotInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(RestoreSnapshotInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(MigrateInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(CreateTemplateInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(AddVolumeInput{}, nil)
 	server.BaseSchemas.MustImportAndCustomize(RemoveVolumeInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(CloneInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(ResizeInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(BatchInput{}, nil)
+	server.BaseSchemas.MustImportAndCustomize(BatchOutput{}, nil)
 
 	vms := scaled.VirtFactory.Kubevirt().V1().VirtualMachine()
 	vmis := scaled.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
 	vmims := scaled.VirtFactory.Kubevirt().V1().VirtualMachineInstanceMigration()
 	backups := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
 	restores := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore()
-	settings := scaled.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	backupTargets := scaled.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 	nodes := scaled.CoreFactory.Core().V1().Node()
 	pvcs := scaled.CoreFactory.Core().V1().PersistentVolumeClaim()
 	secrets := scaled.CoreFactory.Core().V1().Secret()
 	vmt := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplate()
 	vmtv := scaled.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
+	snapshots := scaled.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshot()
 
 	copyConfig := rest.CopyConfig(server.RESTConfig)
 	copyConfig.GroupVersion = &kubevirtSubResouceGroupVersion
@@ -73,15 +83,23 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 		backups:                   backups,
 		backupCache:               backups.Cache(),
 		restores:                  restores,
-		settingCache:              settings.Cache(),
+		backupTargetCache:         backupTargets.Cache(),
 		nodeCache:                 nodes.Cache(),
 		pvcCache:                  pvcs.Cache(),
 		secretClient:              secrets,
 		secretCache:               secrets.Cache(),
+		snapshots:                 snapshots,
+		longhornSettingCache:      scaled.LonghornFactory.Longhorn().V1beta1().Setting().Cache(),
 		virtSubresourceRestClient: virtSubresourceClient,
 		virtRestClient:            virtv1Client.RESTClient(),
 	}
 
+	if err := prometheus.Register(&migrationCollector{vmCache: vms.Cache()}); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return err
+		}
+	}
+
 	vmformatter := vmformatter{
 		vmiCache: vmis.Cache(),
 	}
@@ -112,6 +130,12 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 				createTemplate: &actionHandler,
 				addVolume:      &actionHandler,
 				removeVolume:   &actionHandler,
+
+				snapshotVM:      &actionHandler,
+				restoreSnapshot: &actionHandler,
+				cloneVM:         &actionHandler,
+				resizeVM:        &actionHandler,
+				batchVM:         vmBatchActionHandler{vmActionHandler: &actionHandler},
 			}
 			apiSchema.ResourceActions = map[string]schemas.Action{
 				startVM:    {},
@@ -133,6 +157,12 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 				restoreVM: {
 					Input: "restoreInput",
 				},
+				snapshotVM: {
+					Input: "snapshotInput",
+				},
+				restoreSnapshot: {
+					Input: "restoreSnapshotInput",
+				},
 				createTemplate: {
 					Input: "createTemplateInput",
 				},
@@ -142,6 +172,22 @@ func RegisterSchema(scaled *config.Scaled, server *server.Server, options config
 				removeVolume: {
 					Input: "removeVolumeInput",
 				},
+				cloneVM: {
+					Input: "cloneInput",
+				},
+				resizeVM: {
+					Input: "resizeInput",
+				},
+			}
+			apiSchema.CollectionActions = map[string]schemas.Action{
+				batchVM: {
+					Input:  "batchInput",
+					Output: "batchOutput",
+				},
+			}
+			apiSchema.CollectionFormatter = collectionFormatter
+			apiSchema.LinkHandlers = map[string]http.Handler{
+				migrationHistoryLink: migrationHistoryHandler{vmCache: vms.Cache()},
 			}
 		},
 		Formatter: vmformatter.formatter,
@@ -12,7 +12,8 @@ type EjectCdRomActionInput struct {
 }
 
 type BackupInput struct {
-	Name string `json:"name"`
+	Name             string `json:"name"`
+	BackupTargetName string `json:"backupTargetName,omitempty"`
 }
 
 type RestoreInput struct {
@@ -20,6 +21,17 @@ type RestoreInput struct {
 	BackupName string `json:"backupName"`
 }
 
+type SnapshotInput struct {
+	Name string `json:"name"`
+}
+
+type RestoreSnapshotInput struct {
+	Name         string `json:"name"`
+	SnapshotName string `json:"snapshotName"`
+	// NewVMName restores the snapshot to a new VM instead of replacing the current one
+	NewVMName string `json:"newVMName,omitempty"`
+}
+
 type MigrateInput struct {
 	NodeName string `json:"nodeName"`
 }
@@ -37,3 +49,48 @@ type AddVolumeInput struct {
 type RemoveVolumeInput struct {
 	DiskName string `json:"diskName"`
 }
+
+type CloneInput struct {
+	TargetVMName string `json:"targetVMName"`
+	// TargetNamespace is the namespace of the cloned VM, it's the namespace of the VM by default
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// Mode is "full" to copy the volumes by CSI volume cloning, or "linked" to create the volumes from snapshots.
+	// It's full by default, the clones in other namespaces are linked.
+	Mode string `json:"mode,omitempty"`
+	// Start starts the cloned VM, it's stopped by default
+	Start bool `json:"start,omitempty"`
+}
+
+type ResizeInput struct {
+	// CPU is the number of vCPUs, it's a multiple of the sockets times the threads per core of the VM, which are
+	// kept while the cores per socket are resized. It's limited by the vm-max-cpus setting.
+	CPU int `json:"cpu,omitempty"`
+	// Memory is the memory limit of the VM, e.g. 8Gi
+	Memory string `json:"memory,omitempty"`
+}
+
+type BatchInput struct {
+	// Action is one of start, stop, restart, softreboot and migrate
+	Action string `json:"action"`
+	// Namespace is the namespace of the VMs, the VMs in all namespaces are selected if it's empty
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector selects the VMs by their labels, at least one of namespace and labelSelector is required
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Concurrency is the number of VMs operated at the same time
+	Concurrency int `json:"concurrency,omitempty"`
+	// NodeName is the target node of the migrate action
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+type BatchResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Error is the reason why the action fails on the VM, it's empty if the action succeeds
+	Error string `json:"error,omitempty"`
+}
+
+type BatchOutput struct {
+	Results []BatchResult `json:"results"`
+	// Failed is the number of the VMs the action fails on
+	Failed int `json:"failed"`
+}
@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 )
 
 const (
@@ -17,6 +18,23 @@ const (
 
 	// ConditionProgressing is the "progressing" condition type
 	BackupConditionProgressing condition.Cond = "InProgress"
+
+	// BackupConditionQuiesced records whether the guest filesystems were frozen while the volumes were snapshotted
+	BackupConditionQuiesced condition.Cond = "Quiesced"
+
+	// BackupConditionVerified records the result of the latest verification of the backup
+	BackupConditionVerified condition.Cond = "Verified"
+)
+
+// BackupType defines where the volume data of a VirtualMachineBackup is stored
+type BackupType string
+
+const (
+	// Backup ships the volume data to the backup target
+	Backup BackupType = "backup"
+
+	// Snapshot keeps the volume data as CSI VolumeSnapshots in the cluster
+	Snapshot BackupType = "snapshot"
 )
 
 // DeletionPolicy defines that to do with resources when VirtualMachineRestore is deleted
@@ -36,6 +54,7 @@ const (
 // +kubebuilder:resource:shortName=vmbackup;vmbackups,scope=Namespaced
 // +kubebuilder:printcolumn:name="SOURCE_KIND",type=string,JSONPath=`.spec.source.kind`
 // +kubebuilder:printcolumn:name="SOURCE_NAME",type=string,JSONPath=`.spec.source.name`
+// +kubebuilder:printcolumn:name="TYPE",type=string,JSONPath=`.spec.type`
 // +kubebuilder:printcolumn:name="READY_TO_USE",type=boolean,JSONPath=`.status.readyToUse`
 // +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="ERROR",type=date,JSONPath=`.status.error.message`
@@ -52,6 +71,76 @@ type VirtualMachineBackup struct {
 
 type VirtualMachineBackupSpec struct {
 	Source corev1.TypedLocalObjectReference `json:"source"`
+
+	// +optional
+	// +kubebuilder:default:="backup"
+	// +kubebuilder:validation:Enum=backup;snapshot
+	Type BackupType `json:"type,omitempty"`
+
+	// BackupTargetName selects the BackupTarget the volume data are shipped to,
+	// the default target is used if it's empty. It doesn't apply to snapshots.
+	// +optional
+	BackupTargetName string `json:"backupTargetName,omitempty"`
+
+	// Quiesce freezes the guest filesystems through the qemu guest agent while the volume snapshots are taken,
+	// the backup is crash-consistent if it's not set.
+	// +optional
+	Quiesce *BackupQuiesce `json:"quiesce,omitempty"`
+}
+
+// BackupQuiesce defines how the guest is quiesced before its volumes are snapshotted, the hooks run as root in the guest
+// so setting them requires the permission to update the source VM
+type BackupQuiesce struct {
+	// Timeout is the longest time the guest filesystems stay frozen, they are thawed by KubeVirt once it's exceeded.
+	// Defaults to 5 minutes.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// PreHook runs in the guest before the filesystems are frozen, e.g. to flush the tables of a database
+	// +optional
+	PreHook *BackupHook `json:"preHook,omitempty"`
+
+	// PostHook runs in the guest after the filesystems are thawed
+	// +optional
+	PostHook *BackupHook `json:"postHook,omitempty"`
+}
+
+// BackupHookErrorPolicy defines what to do when a backup hook fails
+type BackupHookErrorPolicy string
+
+const (
+	// BackupHookFail fails the backup when the hook fails
+	BackupHookFail BackupHookErrorPolicy = "Fail"
+
+	// BackupHookContinue records the hook failure and continues the backup
+	BackupHookContinue BackupHookErrorPolicy = "Continue"
+)
+
+// BackupHook is a command executed in the guest through the qemu guest agent
+type BackupHook struct {
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// Timeout of the command, defaults to 1 minute
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// OnError only applies to the pre hook, a failed post hook is recorded in the Quiesced condition
+	// +optional
+	// +kubebuilder:default:="Fail"
+	// +kubebuilder:validation:Enum=Fail;Continue
+	OnError BackupHookErrorPolicy `json:"onError,omitempty"`
+}
+
+// BackupHookStatus is a hook command running in the guest
+type BackupHookStatus struct {
+	// Name is preHook or postHook
+	Name string `json:"name"`
+
+	// PID is the process of the command in the guest
+	PID int `json:"pid"`
+
+	StartTime metav1.Time `json:"startTime"`
 }
 
 // VirtualMachineBackupStatus is the status for a VirtualMachineBackup resource
@@ -63,7 +152,7 @@ type VirtualMachineBackupStatus struct {
 	CreationTime *metav1.Time `json:"creationTime,omitempty"`
 
 	// +optional
-	BackupTarget *BackupTarget `json:"backupTarget,omitempty"`
+	BackupTarget *BackupTargetInfo `json:"backupTarget,omitempty"`
 
 	// +kubebuilder:validation:Required
 	// SourceSpec contains the vm spec source of the backup target
@@ -75,18 +164,88 @@ type VirtualMachineBackupStatus struct {
 	// +optional
 	SecretBackups []SecretBackup `json:"secretBackups,omitempty"`
 
+	// ParentBackupName is the previous backup of the same VM in the backup target,
+	// the volume data of this backup are incremental against it.
+	// +optional
+	ParentBackupName string `json:"parentBackupName,omitempty"`
+
+	// TotalSize is the sum of the volume backup sizes in bytes, it's the amount of data a restore reads
+	// +optional
+	TotalSize int64 `json:"totalSize,omitempty"`
+
+	// TotalDeltaSize is the sum of the volume backup delta sizes in bytes,
+	// it's the amount of data this backup added to the backup target
+	// +optional
+	TotalDeltaSize int64 `json:"totalDeltaSize,omitempty"`
+
 	// +optional
 	ReadyToUse *bool `json:"readyToUse,omitempty"`
 
+	// RunningHook is the hook command running in the guest, the backup waits until it exits or times out
+	// +optional
+	RunningHook *BackupHookStatus `json:"runningHook,omitempty"`
+
 	// +optional
 	Error *Error `json:"error,omitempty"`
 
+	// EncryptionKeyID is the key of the backup target encrypting the backup metadata and the secret backups
+	// +optional
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+
+	// Progress is the overall progress of uploading the volume data of the VM
+	// +optional
+	Progress *TransferProgress `json:"progress,omitempty"`
+
+	// Verification is the latest verification of the backup, its result is the Verified condition
+	// +optional
+	Verification *BackupVerificationStatus `json:"verification,omitempty"`
+
 	// +optional
 	Conditions []Condition `json:"conditions,omitempty"`
 }
 
-// BackupTarget is where VM Backup stores
-type BackupTarget struct {
+// BackupVerification defines how a backup is verified. The backup is restored as a new VM
+// in a throwaway namespace with the networks detached, and the VM is booted.
+type BackupVerification struct {
+	// ReadinessProbe of the verification VM, the VM is verified once the guest agent is connected if it's not set
+	// +optional
+	ReadinessProbe *kubevirtv1.Probe `json:"readinessProbe,omitempty"`
+
+	// Timeout of the verification, defaults to 15 minutes
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// BackupVerifyRequest is set in the harvesterhci.io/backupVerifyRequest annotation of the backup by the verify action
+type BackupVerifyRequest struct {
+	// ID identifies the request, a backup is verified again when the ID changes
+	ID string `json:"id"`
+
+	BackupVerification `json:",inline"`
+}
+
+type BackupVerificationStatus struct {
+	// RequestID is the ID of the verify request
+	// +optional
+	RequestID string `json:"requestID,omitempty"`
+
+	// Namespace is the throwaway namespace the backup is restored in, it's removed once the verification completes
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// BackupTargetInfo is where VM Backup stores
+type BackupTargetInfo struct {
+	// Name of the BackupTarget
+	// +optional
+	Name string `json:"name,omitempty"`
+
 	Endpoint     string `json:"endpoint,omitempty"`
 	BucketName   string `json:"bucketName,omitempty"`
 	BucketRegion string `json:"bucketRegion,omitempty"`
@@ -118,6 +277,23 @@ type VolumeBackup struct {
 	// +optional
 	LonghornBackupName *string `json:"longhornBackupName,omitempty"`
 
+	// ParentLonghornBackupName is the longhorn backup of the same volume this one is incremental against
+	// +optional
+	ParentLonghornBackupName *string `json:"parentLonghornBackupName,omitempty"`
+
+	// Size is the amount of volume data kept by the longhorn backup in bytes
+	// +optional
+	Size *int64 `json:"size,omitempty"`
+
+	// DeltaSize is the amount of volume data which isn't in the parent longhorn backup in bytes,
+	// it's recalculated when the parent is deleted and the chain is consolidated.
+	// +optional
+	DeltaSize *int64 `json:"deltaSize,omitempty"`
+
+	// Progress is sourced from the longhorn backup of the volume
+	// +optional
+	Progress *TransferProgress `json:"progress,omitempty"`
+
 	// +optional
 	ReadyToUse *bool `json:"readyToUse,omitempty"`
 
@@ -132,6 +308,10 @@ type SecretBackup struct {
 
 	// +optional
 	Data map[string][]byte `json:"data,omitempty"`
+
+	// EncryptionKeyID is the key of the backup target encrypting the data, the data are plain if it's empty
+	// +optional
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
 }
 
 type PersistentVolumeClaimSourceSpec struct {
@@ -179,6 +359,50 @@ type VirtualMachineRestoreSpec struct {
 
 	// +optional
 	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Mode is crossCluster when the backup is synced from a backup target shared with another cluster,
+	// the references of the backup are remapped through Mapping.
+	// +optional
+	// +kubebuilder:validation:Enum=inCluster;crossCluster
+	Mode RestoreMode `json:"mode,omitempty"`
+
+	// Mapping only applies to the crossCluster mode
+	// +optional
+	Mapping *RestoreMapping `json:"mapping,omitempty"`
+}
+
+// RestoreMode defines where the backup comes from
+type RestoreMode string
+
+const (
+	// RestoreModeInCluster restores the backup with the namespaces, storage classes and networks it references
+	RestoreModeInCluster RestoreMode = "inCluster"
+
+	// RestoreModeCrossCluster restores the backup of another cluster for disaster recovery,
+	// the references which are named differently in this cluster are remapped
+	RestoreModeCrossCluster RestoreMode = "crossCluster"
+)
+
+// RestoreMapping is the table of the resource names of the source cluster to the names in this cluster,
+// the references which aren't in the table are kept as they are.
+type RestoreMapping struct {
+	// Namespaces maps the namespaces of the namespaced references, e.g. images and networks.
+	// The namespace of the backup should be mapped to the namespace of the restore if it's in the table.
+	// +optional
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+
+	// StorageClasses maps the storage classes of the volumes which aren't created from an image
+	// +optional
+	StorageClasses map[string]string `json:"storageClasses,omitempty"`
+
+	// Images maps the images the volumes are created from in the form of <namespace>/<name>,
+	// the volumes use the backing storage class of the mapped image.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// Networks maps the Multus networks of the VM in the form of <namespace>/<name>
+	// +optional
+	Networks map[string]string `json:"networks,omitempty"`
 }
 
 // VirtualMachineRestoreStatus is the spec for a VirtualMachineRestore resource
@@ -192,6 +416,10 @@ type VirtualMachineRestoreStatus struct {
 	// +optional
 	DeletedVolumes []string `json:"deletedVolumes,omitempty"`
 
+	// Progress is the overall progress of restoring the volume data of the VM
+	// +optional
+	Progress *TransferProgress `json:"progress,omitempty"`
+
 	// +optional
 	Complete *bool `json:"complete,omitempty"`
 
@@ -208,4 +436,31 @@ type VolumeRestore struct {
 	PersistentVolumeClaim PersistentVolumeClaimSourceSpec `json:"persistentVolumeClaimSpec,omitempty"`
 
 	VolumeBackupName string `json:"volumeBackupName,omitempty"`
+
+	// Progress is sourced from the longhorn engine restoring the volume
+	// +optional
+	Progress *TransferProgress `json:"progress,omitempty"`
+}
+
+// TransferProgress is the progress of transferring volume data between the cluster and the backup target
+type TransferProgress struct {
+	// Percentage of the data transferred
+	Percentage int `json:"percentage"`
+
+	// +optional
+	BytesTransferred int64 `json:"bytesTransferred,omitempty"`
+
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+
+	// Throughput is the average bytes transferred per second since the start
+	// +optional
+	Throughput int64 `json:"throughput,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// EstimatedCompletionTime is extrapolated from the throughput, it's unset before any data is transferred
+	// +optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
 }
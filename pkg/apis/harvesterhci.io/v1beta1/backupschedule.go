@@ -0,0 +1,126 @@
+package v1beta1
+
+import (
+	"github.com/rancher/wrangler/pkg/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// BackupScheduleConditionReady is set to false when the schedule spec can't be handled, e.g. an invalid cron expression
+	BackupScheduleConditionReady condition.Cond = "Ready"
+
+	// BackupScheduleConditionLastRun records the result of the latest scheduled run, it is unknown until the backups
+	// of the run are ready, and false if a backup can't be created or fails
+	BackupScheduleConditionLastRun condition.Cond = "LastRun"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=vmbackupschedule;vmbackupschedules,scope=Namespaced
+// +kubebuilder:printcolumn:name="SCHEDULE",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="SUSPEND",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="LAST_SCHEDULE",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VirtualMachineBackupSchedule creates VirtualMachineBackups of the selected VMs periodically
+// and prunes the old ones according to the retention policy.
+type VirtualMachineBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VirtualMachineBackupScheduleSpec `json:"spec"`
+
+	// +optional
+	Status VirtualMachineBackupScheduleStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineBackupScheduleSpec struct {
+	// Schedule in standard cron format, e.g. "0 2 * * *" runs at 02:00 every day.
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// +kubebuilder:validation:Required
+	VMSelector VirtualMachineSelector `json:"vmSelector"`
+
+	// BackupTargetName is the BackupTarget of the scheduled backups, the default target is used if it's empty
+	// +optional
+	BackupTargetName string `json:"backupTargetName,omitempty"`
+
+	// +optional
+	Retention BackupRetentionPolicy `json:"retention,omitempty"`
+
+	// +optional
+	Verification *ScheduledVerification `json:"verification,omitempty"`
+}
+
+// ScheduledVerification verifies the latest ready backup of each selected VM periodically
+type ScheduledVerification struct {
+	// Schedule in standard cron format
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	BackupVerification `json:",inline"`
+}
+
+// VirtualMachineSelector selects VMs in the namespace of the schedule,
+// a VM is selected if it matches either the names or the label selector.
+type VirtualMachineSelector struct {
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// BackupRetentionPolicy defines how many scheduled backups are kept for each VM.
+// A backup is kept if any of the rules keeps it, all backups are kept if no rule is set.
+type BackupRetentionPolicy struct {
+	// KeepLast keeps the latest n backups
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepDaily keeps the latest backup of each day for the last n days which have a backup
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	KeepDaily int `json:"keepDaily,omitempty"`
+
+	// KeepWeekly keeps the latest backup of each week for the last n weeks which have a backup
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+}
+
+type VirtualMachineBackupScheduleStatus struct {
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+
+	// +optional
+	LastRunBackups []ScheduledBackup `json:"lastRunBackups,omitempty"`
+
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// ScheduledBackup is the result of a scheduled run for a VM
+type ScheduledBackup struct {
+	VMName string `json:"vmName"`
+
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// +optional
+	Skipped bool `json:"skipped,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
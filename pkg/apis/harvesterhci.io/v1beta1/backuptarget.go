@@ -0,0 +1,102 @@
+package v1beta1
+
+import (
+	"github.com/rancher/wrangler/pkg/condition"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultBackupTargetName is the BackupTarget mapped from the backup-target setting,
+	// it's used by VirtualMachineBackups which don't select a target.
+	DefaultBackupTargetName = "default"
+)
+
+var (
+	// BackupTargetConditionAvailable is set to false when the backup store can't be reached
+	BackupTargetConditionAvailable condition.Cond = "Available"
+)
+
+// BackupTargetType is the kind of backup store
+type BackupTargetType string
+
+const (
+	BackupTargetTypeS3  BackupTargetType = "s3"
+	BackupTargetTypeNFS BackupTargetType = "nfs"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=bt;bts,scope=Cluster
+// +kubebuilder:printcolumn:name="TYPE",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="ENDPOINT",type=string,JSONPath=`.spec.endpoint`
+// +kubebuilder:printcolumn:name="BUCKET",type=string,JSONPath=`.spec.bucketName`
+// +kubebuilder:printcolumn:name="LAST_SYNCED",type=date,JSONPath=`.status.lastSyncedTime`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupTarget is a S3 or NFS store where VirtualMachineBackups are kept.
+// The target named "default" is managed from the backup-target setting.
+type BackupTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BackupTargetSpec `json:"spec"`
+
+	// +optional
+	Status BackupTargetStatus `json:"status,omitempty"`
+}
+
+type BackupTargetSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=s3;nfs
+	Type BackupTargetType `json:"type"`
+
+	// Endpoint is the NFS export, or the S3 service endpoint if it isn't AWS
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// +optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// +optional
+	BucketRegion string `json:"bucketRegion,omitempty"`
+
+	// +optional
+	VirtualHostedStyle bool `json:"virtualHostedStyle,omitempty"`
+
+	// CredentialSecret refers to the secret holding the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of a S3 target,
+	// AWS_CERT can be set for a S3 service with a self-signed certificate.
+	// +optional
+	CredentialSecret *corev1.SecretReference `json:"credentialSecret,omitempty"`
+
+	// Encryption keeps the VM backups in the target encrypted at rest, the metadata and the secret data are encrypted
+	// with the keys of the target, and only the VMs with longhorn encrypted volumes can be backed up to the target
+	// +optional
+	Encryption *BackupTargetEncryption `json:"encryption,omitempty"`
+}
+
+// BackupTargetEncryption encrypts the VM backup metadata and the embedded secret data with AES-256-GCM.
+// The keys never encrypt the volume data, which are shipped by longhorn as they are stored, so the target refuses
+// the backups of the volumes which aren't longhorn encrypted volumes, whose data are encrypted with the crypto secret
+// of their storage class.
+type BackupTargetEncryption struct {
+	// KeySecret refers to the secret holding the keys, every entry is a 32-byte key named by its key ID.
+	// The keys of the existing backups are kept in the secret when a new key is added for rotation,
+	// a cluster can't restore a backup without its key.
+	// +kubebuilder:validation:Required
+	KeySecret corev1.SecretReference `json:"keySecret"`
+
+	// ActiveKeyID is the key encrypting new backups, the existing backups are re-encrypted with it
+	// +kubebuilder:validation:Required
+	ActiveKeyID string `json:"activeKeyID"`
+}
+
+type BackupTargetStatus struct {
+	// LastSyncedTime is the last time the VM backup metadata were synced from the target
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
@@ -15,12 +15,24 @@ const (
 	VirtualMachineImageSourceTypeDownload     = "download"
 	VirtualMachineImageSourceTypeUpload       = "upload"
 	VirtualMachineImageSourceTypeExportVolume = "export-from-volume"
+	VirtualMachineImageSourceTypeOCI          = "oci"
+	VirtualMachineImageSourceTypeBackupTarget = "backup-target"
+
+	ImageExportFormatRaw   = "raw"
+	ImageExportFormatQCOW2 = "qcow2"
+
+	ImageExportStatePending   = "Pending"
+	ImageExportStateExporting = "Exporting"
+	ImageExportStateCompleted = "Completed"
+	ImageExportStateFailed    = "Failed"
 )
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:resource:shortName=vmimage;vmimages,scope=Namespaced
 // +kubebuilder:printcolumn:name="DISPLAY-NAME",type=string,JSONPath=`.spec.displayName`
+// +kubebuilder:printcolumn:name="FAMILY",type=string,priority=8,JSONPath=`.spec.family`
+// +kubebuilder:printcolumn:name="VERSION",type=string,priority=8,JSONPath=`.spec.version`
 // +kubebuilder:printcolumn:name="SIZE",type=integer,JSONPath=`.status.size`
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=`.metadata.creationTimestamp`
 
@@ -40,7 +52,7 @@ type VirtualMachineImageSpec struct {
 	DisplayName string `json:"displayName"`
 
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=download;upload;export-from-volume
+	// +kubebuilder:validation:Enum=download;upload;export-from-volume;oci;backup-target
 	SourceType string `json:"sourceType"`
 
 	// +optional
@@ -52,8 +64,118 @@ type VirtualMachineImageSpec struct {
 	// +optional
 	URL string `json:"url"`
 
+	// Mirrors are the URLs of the same file as the url, they're tried in order when the url can't be downloaded
+	// +optional
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// BandwidthLimit limits the download bandwidth in bytes per second, e.g. 10Mi. It overrides the
+	// image-download-bandwidth-limit setting, 0 means unlimited.
+	// +optional
+	BandwidthLimit string `json:"bandwidthLimit,omitempty"`
+
+	// Checksum is the expected digest of the image, <algorithm>:<digest> or a digest whose algorithm is detected
+	// from its length. The supported algorithms are md5, sha1, sha256 and sha512.
 	// +optional
 	Checksum string `json:"checksum"`
+
+	// ChecksumURL is the URL of a checksum file like SHA256SUMS, the checksum of the image is looked up by the file
+	// name of the url, or by the name of the uploaded file
+	// +optional
+	ChecksumURL string `json:"checksumUrl,omitempty"`
+
+	// OCI is the registry artifact or containerDisk the image is pulled from when the source type is "oci"
+	// +optional
+	OCI *VirtualMachineImageOCISource `json:"oci,omitempty"`
+
+	// BackupTarget is the image exported to a backup target the image is imported from when the source type is "backup-target"
+	// +optional
+	BackupTarget *VirtualMachineImageBackupTargetSource `json:"backupTarget,omitempty"`
+
+	// StorageClassParameters are the parameters of the storage class of the image, the unset ones are taken
+	// from the default-vm-image-storage-class-parameters setting. Changing them applies to the volumes created afterwards.
+	// +optional
+	StorageClassParameters *ImageStorageClassParameters `json:"storageClassParameters,omitempty"`
+
+	// Sharing grants the VMs in other namespaces the use of the image
+	// +optional
+	Sharing *VirtualMachineImageSharing `json:"sharing,omitempty"`
+
+	// Family groups the versions of an image, e.g. ubuntu-22.04. The VMs referencing the family by the
+	// harvesterhci.io/imageFamily annotation of their volume claim templates are created from its latest ready version.
+	// +optional
+	Family string `json:"family,omitempty"`
+
+	// Version of the image in its family, it's required if the family is set
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Deprecated excludes the version when resolving the latest version of its family, the VMs created from it
+	// keep running
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// VirtualMachineImageSharing is the namespaces other than its own whose VMs can use an image
+type VirtualMachineImageSharing struct {
+	// ClusterWide shares the image with all the namespaces
+	// +optional
+	ClusterWide bool `json:"clusterWide,omitempty"`
+
+	// Namespaces are the namespaces the image is shared with
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// VirtualMachineImageOCISource is a disk image in a container registry, it's either an OCI artifact
+// with the disk as its layer, or a containerDisk image with the disk file in the /disk directory.
+type VirtualMachineImageOCISource struct {
+	// Reference is the image reference, e.g. registry.example.com/images/ubuntu:20.04
+	// +kubebuilder:validation:Required
+	Reference string `json:"reference"`
+
+	// Digest pins the manifest the disk is pulled from, the tag of the reference is ignored if it's set
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// PullSecretName is a kubernetes.io/dockerconfigjson secret in the namespace of the image
+	// +optional
+	PullSecretName string `json:"pullSecretName,omitempty"`
+}
+
+// VirtualMachineImageBackupTargetSource is an image exported to a backup target by the exportToBackupTarget action
+type VirtualMachineImageBackupTargetSource struct {
+	// Name is the name of the BackupTarget
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Path is the directory of the exported image in the backup target, e.g. harvester/vmimages/default/ubuntu.qcow2
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+}
+
+// ImageStorageClassParameters are the longhorn parameters of the volumes created from an image
+type ImageStorageClassParameters struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	NumberOfReplicas int `json:"numberOfReplicas,omitempty"`
+
+	// StaleReplicaTimeout is how many minutes a failed replica is kept before it's removed
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	StaleReplicaTimeout int `json:"staleReplicaTimeout,omitempty"`
+
+	// NodeSelector schedules the replicas to the nodes with all the longhorn node tags
+	// +optional
+	NodeSelector []string `json:"nodeSelector,omitempty"`
+
+	// DiskSelector schedules the replicas to the disks with all the longhorn disk tags
+	// +optional
+	DiskSelector []string `json:"diskSelector,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=disabled;best-effort
+	DataLocality string `json:"dataLocality,omitempty"`
 }
 
 type VirtualMachineImageStatus struct {
@@ -66,13 +188,95 @@ type VirtualMachineImageStatus struct {
 	// +optional
 	Size int64 `json:"size,omitempty"`
 
+	// Format is the detected format of the imported file, the vmdk, vhd, vhdx and ova images are converted to qcow2
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// VirtualSize is the size of the disk in the image in bytes
+	// +optional
+	VirtualSize int64 `json:"virtualSize,omitempty"`
+
 	// +optional
 	StorageClassName string `json:"storageClassName,omitempty"`
 
+	// OCIDigest is the digest of the manifest the disk is pulled from when the source type is "oci"
+	// +optional
+	OCIDigest string `json:"ociDigest,omitempty"`
+
+	// Checksum is the expected checksum of the image resolved from the checksum or the checksum url of the spec,
+	// in the <algorithm>:<digest> form
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// Usage is what references the image and the space its backing image consumes
+	// +optional
+	Usage *VirtualMachineImageUsage `json:"usage,omitempty"`
+
+	// Exports are the exports of the image to the backup targets, there is one export per backup target and format.
+	// The exported images aren't encrypted even if the backup target encrypts the VM backups.
+	// +optional
+	Exports []VirtualMachineImageExport `json:"exports,omitempty"`
+
 	// +optional
 	Conditions []Condition `json:"conditions,omitempty"`
 }
 
+// VirtualMachineImageExport is the export of an image to a backup target
+type VirtualMachineImageExport struct {
+	BackupTarget string `json:"backupTarget"`
+
+	// +kubebuilder:validation:Enum=raw;qcow2
+	Format string `json:"format"`
+
+	// Path is the directory of the exported image in the backup target
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// +kubebuilder:validation:Enum=Pending;Exporting;Completed;Failed
+	State string `json:"state"`
+
+	// Size is the size in bytes of the exported file
+	// +optional
+	Size int64 `json:"size,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// VirtualMachineImageUsage is what references an image, the references are in the <namespace>/<name> form
+type VirtualMachineImageUsage struct {
+	// +optional
+	VirtualMachines []string `json:"virtualMachines,omitempty"`
+
+	// +optional
+	Volumes []string `json:"volumes,omitempty"`
+
+	// +optional
+	TemplateVersions []string `json:"templateVersions,omitempty"`
+
+	// Nodes are the nodes with a copy of the backing image
+	// +optional
+	Nodes []ImageNodeUsage `json:"nodes,omitempty"`
+
+	// UnusedSince is when the image stopped being referenced, it's empty while the image is in use
+	// +optional
+	UnusedSince *metav1.Time `json:"unusedSince,omitempty"`
+}
+
+// ImageNodeUsage is the space consumed by the backing image of an image on a node
+type ImageNodeUsage struct {
+	NodeName string `json:"nodeName"`
+
+	// Size is the total size in bytes of the backing image files on the disks of the node
+	Size int64 `json:"size"`
+}
+
 type Condition struct {
 	// Type of the condition.
 	Type condition.Cond `json:"type"`
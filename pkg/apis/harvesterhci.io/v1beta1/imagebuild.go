@@ -0,0 +1,116 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ImageBuildPhaseBuilding  = "Building"
+	ImageBuildPhaseExporting = "Exporting"
+	ImageBuildPhaseSucceeded = "Succeeded"
+	ImageBuildPhaseFailed    = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=vmimagebuild;vmimagebuilds,scope=Namespaced
+// +kubebuilder:printcolumn:name="BASE_IMAGE",type=string,JSONPath=`.spec.baseImage`
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="IMAGE",type=string,JSONPath=`.status.imageName`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VirtualMachineImageBuild builds an image by booting a temporary VM from a base image, customizing it by cloud-init
+// and exporting its root disk to a new image once the VM powers itself off. The temporary VM and its volumes are
+// removed when the build completes.
+type VirtualMachineImageBuild struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VirtualMachineImageBuildSpec `json:"spec"`
+
+	// +optional
+	Status VirtualMachineImageBuildStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineImageBuildSpec struct {
+	// BaseImage is the image the VM boots from, the name of an image in the namespace of the build,
+	// or <namespace>/<name> of an image shared with the namespace
+	// +kubebuilder:validation:Required
+	BaseImage string `json:"baseImage"`
+
+	// UserData is the cloud-init user data customizing the VM, it must power off the VM when it's done,
+	// e.g. by the power_state module
+	// +kubebuilder:validation:Required
+	UserData string `json:"userData"`
+
+	// +optional
+	NetworkData string `json:"networkData,omitempty"`
+
+	// NetworkName is the <namespace>/<name> of the VM network of the VM, the VM uses the pod network if it's empty
+	// +optional
+	NetworkName string `json:"networkName,omitempty"`
+
+	// CPU is the number of cores of the VM, it's 2 by default
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CPU int `json:"cpu,omitempty"`
+
+	// Memory of the VM, it's 2Gi by default
+	// +optional
+	Memory string `json:"memory,omitempty"`
+
+	// DiskSize is the size of the root disk, it's the virtual size of the base image by default
+	// +optional
+	DiskSize string `json:"diskSize,omitempty"`
+
+	// Timeout is how long the VM may run before it powers off, it's 1h by default
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Image VirtualMachineImageBuildOutput `json:"image"`
+}
+
+// VirtualMachineImageBuildOutput is the image built by a VirtualMachineImageBuild
+type VirtualMachineImageBuildOutput struct {
+	// DisplayName of the image is suffixed by the version, e.g. ubuntu-golden-20220301-020000
+	// +kubebuilder:validation:Required
+	DisplayName string `json:"displayName"`
+
+	// Version of the image, it's the creation time of the build by default
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Family the image is added to as a new version
+	// +optional
+	Family string `json:"family,omitempty"`
+
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+type VirtualMachineImageBuildStatus struct {
+	// +optional
+	// +kubebuilder:validation:Enum=Building;Exporting;Succeeded;Failed
+	Phase string `json:"phase,omitempty"`
+
+	// VirtualMachineName is the temporary VM customizing the base image
+	// +optional
+	VirtualMachineName string `json:"virtualMachineName,omitempty"`
+
+	// ImageName is the built image in the namespace of the build
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
@@ -0,0 +1,85 @@
+package v1beta1
+
+import (
+	"github.com/rancher/wrangler/pkg/condition"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// MigrationPolicyApplied is false when the policy couldn't be applied to KubeVirt, e.g. the MigrationPolicies
+	// feature gate of KubeVirt is disabled
+	MigrationPolicyApplied condition.Cond = "Applied"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=mp;mps,scope=Cluster
+// +kubebuilder:printcolumn:name="BANDWIDTH",type=string,JSONPath=`.spec.bandwidthPerMigration`
+// +kubebuilder:printcolumn:name="AUTO_CONVERGE",type=boolean,JSONPath=`.spec.allowAutoConverge`
+// +kubebuilder:printcolumn:name="POST_COPY",type=boolean,JSONPath=`.spec.allowPostCopy`
+// +kubebuilder:printcolumn:name="APPLIED",type=string,JSONPath=`.status.conditions[?(@.type=="Applied")].status`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MigrationPolicy tunes the live migrations of the VMs it selects, the VMs not selected by any policy are migrated
+// with the defaults of KubeVirt. It applies to the migrations started from the API and the ones started by the
+// maintenance mode of nodes alike.
+type MigrationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigrationPolicySpec   `json:"spec"`
+	Status MigrationPolicyStatus `json:"status,omitempty"`
+}
+
+type MigrationPolicySpec struct {
+	// Selectors select the VMs of the policy. A VM matching multiple policies is migrated with the one matching most
+	// of its labels, the labels of the VM take precedence over the labels of its namespace.
+	// +kubebuilder:validation:Required
+	Selectors MigrationPolicySelectors `json:"selectors"`
+
+	// BandwidthPerMigration limits the network bandwidth of each migration, e.g. 512Mi
+	// +optional
+	BandwidthPerMigration *resource.Quantity `json:"bandwidthPerMigration,omitempty"`
+
+	// CompletionTimeoutPerGiB is the time in seconds per GiB of memory a migration can take before it's aborted
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CompletionTimeoutPerGiB *int64 `json:"completionTimeoutPerGiB,omitempty"`
+
+	// AllowAutoConverge throttles the CPU of the VM when its memory is dirtied faster than it's transferred
+	// +optional
+	AllowAutoConverge *bool `json:"allowAutoConverge,omitempty"`
+
+	// AllowPostCopy switches the VM to the target node before all of its memory is transferred when the migration
+	// doesn't converge, the VM is lost if the migration fails after the switch
+	// +optional
+	AllowPostCopy *bool `json:"allowPostCopy,omitempty"`
+
+	// ParallelMigrationsPerCluster limits the number of migrations of the selected VMs running at the same time
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ParallelMigrationsPerCluster *uint32 `json:"parallelMigrationsPerCluster,omitempty"`
+
+	// ParallelOutboundMigrationsPerNode limits the number of migrations of the selected VMs running from the same
+	// node at the same time
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ParallelOutboundMigrationsPerNode *uint32 `json:"parallelOutboundMigrationsPerNode,omitempty"`
+}
+
+type MigrationPolicySelectors struct {
+	// NamespaceSelector selects the VMs by the labels of their namespaces
+	// +optional
+	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"`
+
+	// VirtualMachineSelector selects the VMs by the labels of their VMIs, which are the labels of the VM templates
+	// +optional
+	VirtualMachineSelector map[string]string `json:"virtualMachineSelector,omitempty"`
+}
+
+type MigrationPolicyStatus struct {
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
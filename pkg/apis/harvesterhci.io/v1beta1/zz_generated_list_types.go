@@ -77,6 +77,23 @@ func NewSetting(namespace, name string, obj Setting) *Setting {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// BackupTargetList is a list of BackupTarget resources
+type BackupTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []BackupTarget `json:"items"`
+}
+
+func NewBackupTarget(namespace, name string, obj BackupTarget) *BackupTarget {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("BackupTarget").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // UpgradeList is a list of Upgrade resources
 type UpgradeList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -128,6 +145,23 @@ func NewVirtualMachineBackup(namespace, name string, obj VirtualMachineBackup) *
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// VirtualMachineBackupScheduleList is a list of VirtualMachineBackupSchedule resources
+type VirtualMachineBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualMachineBackupSchedule `json:"items"`
+}
+
+func NewVirtualMachineBackupSchedule(namespace, name string, obj VirtualMachineBackupSchedule) *VirtualMachineBackupSchedule {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("VirtualMachineBackupSchedule").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // VirtualMachineRestoreList is a list of VirtualMachineRestore resources
 type VirtualMachineRestoreList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -162,6 +196,40 @@ func NewVirtualMachineImage(namespace, name string, obj VirtualMachineImage) *Vi
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// VirtualMachineImageBuildList is a list of VirtualMachineImageBuild resources
+type VirtualMachineImageBuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualMachineImageBuild `json:"items"`
+}
+
+func NewVirtualMachineImageBuild(namespace, name string, obj VirtualMachineImageBuild) *VirtualMachineImageBuild {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("VirtualMachineImageBuild").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigrationPolicyList is a list of MigrationPolicy resources
+type MigrationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MigrationPolicy `json:"items"`
+}
+
+func NewMigrationPolicy(namespace, name string, obj MigrationPolicy) *MigrationPolicy {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("MigrationPolicy").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // VirtualMachineTemplateList is a list of VirtualMachineTemplate resources
 type VirtualMachineTemplateList struct {
 	metav1.TypeMeta `json:",inline"`
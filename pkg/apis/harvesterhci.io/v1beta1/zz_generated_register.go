@@ -28,14 +28,18 @@ import (
 )
 
 var (
+	BackupTargetResourceName                  = "backuptargets"
 	KeyPairResourceName                       = "keypairs"
+	MigrationPolicyResourceName               = "migrationpolicies"
 	PreferenceResourceName                    = "preferences"
 	SettingResourceName                       = "settings"
 	SupportBundleResourceName                 = "supportbundles"
 	UpgradeResourceName                       = "upgrades"
 	VersionResourceName                       = "versions"
 	VirtualMachineBackupResourceName          = "virtualmachinebackups"
+	VirtualMachineBackupScheduleResourceName  = "virtualmachinebackupschedules"
 	VirtualMachineImageResourceName           = "virtualmachineimages"
+	VirtualMachineImageBuildResourceName      = "virtualmachineimagebuilds"
 	VirtualMachineRestoreResourceName         = "virtualmachinerestores"
 	VirtualMachineTemplateResourceName        = "virtualmachinetemplates"
 	VirtualMachineTemplateVersionResourceName = "virtualmachinetemplateversions"
@@ -62,8 +66,12 @@ var (
 // Adds the list of known types to Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&BackupTarget{},
+		&BackupTargetList{},
 		&KeyPair{},
 		&KeyPairList{},
+		&MigrationPolicy{},
+		&MigrationPolicyList{},
 		&Preference{},
 		&PreferenceList{},
 		&Setting{},
@@ -76,8 +84,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VersionList{},
 		&VirtualMachineBackup{},
 		&VirtualMachineBackupList{},
+		&VirtualMachineBackupSchedule{},
+		&VirtualMachineBackupScheduleList{},
 		&VirtualMachineImage{},
 		&VirtualMachineImageList{},
+		&VirtualMachineImageBuild{},
+		&VirtualMachineImageBuildList{},
 		&VirtualMachineRestore{},
 		&VirtualMachineRestoreList{},
 		&VirtualMachineTemplate{},
@@ -32,11 +32,15 @@ func main() {
 					harvesterv1.KeyPair{},
 					harvesterv1.Preference{},
 					harvesterv1.Setting{},
+					harvesterv1.BackupTarget{},
 					harvesterv1.Upgrade{},
 					harvesterv1.Version{},
 					harvesterv1.VirtualMachineBackup{},
+					harvesterv1.VirtualMachineBackupSchedule{},
 					harvesterv1.VirtualMachineRestore{},
 					harvesterv1.VirtualMachineImage{},
+					harvesterv1.VirtualMachineImageBuild{},
+					harvesterv1.MigrationPolicy{},
 					harvesterv1.VirtualMachineTemplate{},
 					harvesterv1.VirtualMachineTemplateVersion{},
 					harvesterv1.SupportBundle{},
@@ -83,6 +87,8 @@ func main() {
 					longhornv1.Volume{},
 					longhornv1.Setting{},
 					longhornv1.Backup{},
+					longhornv1.Engine{},
+					longhornv1.Node{},
 				},
 				GenerateClients: true,
 			},
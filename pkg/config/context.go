@@ -46,6 +46,9 @@ type Options struct {
 	HTTPListenPort  int
 	HTTPSListenPort int
 
+	// MetricsListenPort serves the Prometheus metrics, it's only exposed in the cluster
+	MetricsListenPort int
+
 	RancherEmbedded bool
 	RancherURL      string
 	HCIMode         bool
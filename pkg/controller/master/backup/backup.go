@@ -10,13 +10,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"reflect"
 	"time"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
-	"github.com/longhorn/backupstore"
 	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -32,7 +30,6 @@ import (
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
 	ctllonghornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
 	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1beta1"
-	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 )
 
@@ -62,6 +59,15 @@ func RegisterBackup(ctx context.Context, management *config.Management, opts con
 	snapshots := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshot()
 	snapshotContents := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshotContent()
 	snapshotClass := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshotClass()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+	pods := management.CoreFactory.Core().V1().Pod()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
+	longhornSettings := management.LonghornFactory.Longhorn().V1beta1().Setting()
+
+	quiescer, err := newGuestQuiescer(management.RestConfig, management.ClientSet, pods.Cache())
+	if err != nil {
+		return err
+	}
 
 	vmBackupController := &Handler{
 		vmBackups:            vmBackups,
@@ -71,6 +77,7 @@ func RegisterBackup(ctx context.Context, management *config.Management, opts con
 		secretCache:          secrets.Cache(),
 		vms:                  vms,
 		vmsCache:             vms.Cache(),
+		vmiCache:             vmis.Cache(),
 		volumeCache:          volumes.Cache(),
 		volumes:              volumes,
 		lhbackupCache:        lhbackups.Cache(),
@@ -80,6 +87,10 @@ func RegisterBackup(ctx context.Context, management *config.Management, opts con
 		snapshotContentCache: snapshotContents.Cache(),
 		snapshotClassCache:   snapshotClass.Cache(),
 		recorder:             management.NewRecorder(backupControllerName, "", ""),
+		quiescer:             quiescer,
+		backupTargetCache:    backupTargets.Cache(),
+		activator:            getTargetActivator(management),
+		longhornSettingCache: longhornSettings.Cache(),
 	}
 
 	vmBackups.OnChange(ctx, backupControllerName, vmBackupController.OnBackupChange)
@@ -95,6 +106,7 @@ type Handler struct {
 	vmBackupController   ctlharvesterv1.VirtualMachineBackupController
 	vms                  ctlkubevirtv1.VirtualMachineClient
 	vmsCache             ctlkubevirtv1.VirtualMachineCache
+	vmiCache             ctlkubevirtv1.VirtualMachineInstanceCache
 	pvcCache             ctlcorev1.PersistentVolumeClaimCache
 	secretCache          ctlcorev1.SecretCache
 	volumeCache          ctllonghornv1.VolumeCache
@@ -106,6 +118,10 @@ type Handler struct {
 	snapshotContentCache ctlsnapshotv1.VolumeSnapshotContentCache
 	snapshotClassCache   ctlsnapshotv1.VolumeSnapshotClassCache
 	recorder             record.EventRecorder
+	quiescer             *guestQuiescer
+	backupTargetCache    ctlharvesterv1.BackupTargetCache
+	activator            *targetActivator
+	longhornSettingCache ctllonghornv1.SettingCache
 }
 
 // OnBackupChange handles vm backup object on change and reconcile vm backup status
@@ -114,22 +130,38 @@ func (h *Handler) OnBackupChange(key string, vmBackup *harvesterv1.VirtualMachin
 		return nil, nil
 	}
 
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
-	if err != nil {
-		return nil, err
-	}
+	logrus.Debugf("OnBackupChange: vmBackup name:%s, type:%s", vmBackup.Name, vmBackup.Spec.Type)
 
-	logrus.Debugf("OnBackupChange: vmBackup name:%s, target:%s:%s", vmBackup.Name, target.Type, target.Endpoint)
+	// the backup is synced from a target without its key, there is nothing to reconcile until the key is added
+	if isEncryptionKeyMissing(vmBackup) {
+		return nil, nil
+	}
 
+	var err error
 	if isBackupReady(vmBackup) {
+		// snapshots are kept in the cluster, there is nothing to upload
+		if isVMSnapshot(vmBackup) {
+			return nil, nil
+		}
+
 		// We've changed backup target information to status since v1.0.0.
 		// For backport to v0.3.0, we move backup target information from annotation to status.
 		if vmBackup, err = h.configureBackupTargetOnStatus(vmBackup); err != nil {
 			return nil, err
 		}
 
+		// the key of the backup target is rotated, encrypt the secret backups with the new key before uploading
+		if rotated, err := h.rotateEncryptionKey(vmBackup); err != nil || rotated {
+			return nil, err
+		}
+
 		// generate vm backup metadata and upload to backup target
-		return nil, h.uploadVMBackupMetadata(vmBackup, target)
+		if err := h.uploadVMBackupMetadata(vmBackup); err != nil {
+			return nil, err
+		}
+
+		// calculate how much data the backup added to the backup target
+		return nil, h.syncBackupSizes(vmBackup)
 	}
 
 	// set vmBackup init status
@@ -143,6 +175,20 @@ func (h *Handler) OnBackupChange(key string, vmBackup *harvesterv1.VirtualMachin
 			return nil, h.setStatusError(vmBackup, fmt.Errorf("vm %s/%s is being deleted", vmBackup.Namespace, vmBackup.Spec.Source.Name))
 		}
 
+		var target *harvesterv1.BackupTarget
+		if isVMSnapshot(vmBackup) {
+			if err := util.CheckLocalSnapshotSupported(h.longhornSettingCache); err != nil {
+				return nil, h.setStatusError(vmBackup, err)
+			}
+		} else {
+			if target, err = h.backupTargetCache.Get(GetBackupTargetName(vmBackup)); err != nil {
+				return nil, h.setStatusError(vmBackup, fmt.Errorf("can't get backup target %s: %w", GetBackupTargetName(vmBackup), err))
+			}
+			if err := h.checkVolumeEncryption(sourceVM, target); err != nil {
+				return nil, h.setStatusError(vmBackup, err)
+			}
+		}
+
 		// check if the VM is running, if not make sure the volumes are mounted to the host
 		if !sourceVM.Status.Ready || !sourceVM.Status.Created {
 			if err := h.mountLonghornVolumes(sourceVM); err != nil {
@@ -153,8 +199,24 @@ func (h *Handler) OnBackupChange(key string, vmBackup *harvesterv1.VirtualMachin
 		return nil, h.initBackup(vmBackup, sourceVM, target)
 	}
 
+	// the volume data are transferred by the longhorn backup target, wait until it points at the target of this backup
+	if !isVMSnapshot(vmBackup) && !isBackupTargetClaimed(vmBackup) && GetVMBackupError(vmBackup) == nil {
+		return nil, h.claimBackupTarget(vmBackup)
+	}
+
 	// TODO, make sure status is initialized, and "Lock" the source VM by adding a finalizer and setting snapshotInProgress in status
 
+	// freeze the guest filesystems before the volume snapshots are created, and thaw them once the snapshots are taken
+	if vmBackup.Status.RunningHook != nil {
+		return nil, h.checkRunningHook(vmBackup)
+	}
+	if needsQuiesce(vmBackup) {
+		return nil, h.quiesceGuest(vmBackup)
+	}
+	if isGuestFrozen(vmBackup) && (isVolumeSnapshotsTaken(vmBackup) || GetVMBackupError(vmBackup) != nil) {
+		return nil, h.thawGuest(vmBackup)
+	}
+
 	// create volume snapshots if not exist
 	if err := h.reconcileVolumeSnapshots(vmBackup); err != nil {
 		return nil, h.setStatusError(vmBackup, err)
@@ -174,25 +236,102 @@ func (h *Handler) OnBackupRemove(key string, vmBackup *harvesterv1.VirtualMachin
 		return nil, nil
 	}
 
-	target, err := settings.DecodeBackupTarget(settings.BackupTargetSet.Get())
-	if err != nil {
+	if err := h.consolidateBackupChain(vmBackup); err != nil {
 		return nil, err
 	}
 
-	if err := h.deleteVMBackupMetadata(vmBackup, target); err != nil {
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
 
-	// Since VolumeSnapshot and VolumeSnapshotContent has finalizers,
-	// when we delete VM Backup and its backup target is not same as current backup target,
-	// VolumeSnapshot and VolumeSnapshotContent may not be deleted immediately.
+	if target != nil {
+		if err := h.deleteVMBackupMetadata(vmBackup, target); err != nil {
+			return nil, err
+		}
+	}
+
+	// The volume backups of a removed or re-configured target can't be reached by longhorn anymore.
+	// Since VolumeSnapshot and VolumeSnapshotContent has finalizers, they may not be deleted.
 	// We should force delete them to avoid that users re-config backup target back and associated LH Backup may be deleted.
+	if target == nil || !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+		return nil, h.forceDeleteVolumeSnapshotAndContent(vmBackup.Namespace, vmBackup.Status.VolumeBackups)
+	}
+	return nil, h.deleteVolumeSnapshots(vmBackup, target)
+}
+
+// deleteVolumeSnapshots deletes the VolumeSnapshots of the backup once longhorn points at its target,
+// so that longhorn deletes the volume backups from the target. The backup keeps its finalizer and holds
+// the target until the VolumeSnapshots are gone.
+func (h *Handler) deleteVolumeSnapshots(vmBackup *harvesterv1.VirtualMachineBackup, target *harvesterv1.BackupTarget) error {
+	var volumeSnapshots []*snapshotv1.VolumeSnapshot
+	for _, volumeBackup := range vmBackup.Status.VolumeBackups {
+		if volumeBackup.Name == nil {
+			continue
+		}
+		volumeSnapshot, err := h.getVolumeSnapshot(vmBackup.Namespace, *volumeBackup.Name)
+		if err != nil {
+			return err
+		}
+		if volumeSnapshot != nil {
+			volumeSnapshots = append(volumeSnapshots, volumeSnapshot)
+		}
+	}
+	if len(volumeSnapshots) == 0 {
+		return nil
+	}
+
+	activated, err := h.activator.activate(target)
+	if err != nil {
+		return err
+	}
+	if !activated {
+		return fmt.Errorf("waiting for longhorn to finish the operations of other backup targets before switching to %s", target.Name)
+	}
+
+	for _, volumeSnapshot := range volumeSnapshots {
+		if volumeSnapshot.DeletionTimestamp != nil {
+			continue
+		}
+		logrus.Debugf("delete volume snapshot %s/%s", volumeSnapshot.Namespace, volumeSnapshot.Name)
+		if err := h.snapshots.Delete(volumeSnapshot.Namespace, volumeSnapshot.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("waiting for the volume snapshots of vm backup %s/%s to be deleted", vmBackup.Namespace, vmBackup.Name)
+}
+
+// claimBackupTarget points the longhorn backup target at the target of the backup,
+// the backup waits if longhorn is transferring the data of another target.
+func (h *Handler) claimBackupTarget(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		return h.setStatusError(vmBackup, fmt.Errorf("can't get backup target %s: %w", GetBackupTargetName(vmBackup), err))
+	}
 	if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
-		if err := h.forceDeleteVolumeSnapshotAndContent(vmBackup.Namespace, vmBackup.Status.VolumeBackups); err != nil {
-			return nil, err
+		return h.setStatusError(vmBackup, fmt.Errorf("backup target %s has changed since the backup was created", target.Name))
+	}
+
+	activated, err := h.activator.activate(target)
+	if err != nil {
+		return err
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	if activated {
+		updateBackupCondition(vmBackupCpy, newProgressingCondition(corev1.ConditionTrue, "", "Operation in progress"))
+	} else {
+		updateBackupCondition(vmBackupCpy, newProgressingCondition(corev1.ConditionFalse, backupTargetBusyReason,
+			fmt.Sprintf("Waiting for longhorn to finish the backups or restores of other backup targets before switching to %s", target.Name)))
+		h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, waitBackupTargetInterval)
+	}
+
+	if !reflect.DeepEqual(vmBackup.Status, vmBackupCpy.Status) {
+		if _, err := h.vmBackups.Update(vmBackupCpy); err != nil {
+			return err
 		}
 	}
-	return nil, nil
+	return nil
 }
 
 func (h *Handler) getBackupSource(vmBackup *harvesterv1.VirtualMachineBackup) (*kubevirtv1.VirtualMachine, error) {
@@ -303,7 +442,7 @@ func (h *Handler) getSecretBackupFromSecret(namespace, name string) (*harvesterv
 }
 
 // initBackup initialize VM backup status and annotation
-func (h *Handler) initBackup(backup *harvesterv1.VirtualMachineBackup, vm *kubevirtv1.VirtualMachine, target *settings.BackupTarget) error {
+func (h *Handler) initBackup(backup *harvesterv1.VirtualMachineBackup, vm *kubevirtv1.VirtualMachine, target *harvesterv1.BackupTarget) error {
 	var err error
 	backupCpy := backup.DeepCopy()
 	backupCpy.Status = &harvesterv1.VirtualMachineBackupStatus{
@@ -328,10 +467,14 @@ func (h *Handler) initBackup(backup *harvesterv1.VirtualMachineBackup, vm *kubev
 		return err
 	}
 
-	backupCpy.Status.BackupTarget = &harvesterv1.BackupTarget{
-		Endpoint:     target.Endpoint,
-		BucketName:   target.BucketName,
-		BucketRegion: target.BucketRegion,
+	if target != nil {
+		backupCpy.Status.BackupTarget = newBackupTargetInfo(target)
+		if backupCpy.Status.ParentBackupName, err = h.getParentBackupName(backup); err != nil {
+			return err
+		}
+		if err := h.encryptSecretBackups(backupCpy, target); err != nil {
+			return err
+		}
 	}
 
 	if _, err := h.vmBackups.Update(backupCpy); err != nil {
@@ -418,7 +561,7 @@ func (h *Handler) getVolumeSnapshot(namespace, name string) (*snapshotv1.VolumeS
 func (h *Handler) createVolumeSnapshot(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackup harvesterv1.VolumeBackup) (*snapshotv1.VolumeSnapshot, error) {
 	logrus.Debugf("attempting to create VolumeSnapshot %s", *volumeBackup.Name)
 
-	sc, err := h.snapshotClassCache.Get(settings.VolumeSnapshotClass.Get())
+	sc, err := h.snapshotClassCache.Get(getVolumeSnapshotClassName(vmBackup))
 	if err != nil {
 		return nil, fmt.Errorf("%s/%s VolumeSnapshot requested but no storage class, err: %s",
 			vmBackup.Namespace, volumeBackup.PersistentVolumeClaim.ObjectMeta.Name, err.Error())
@@ -531,6 +674,9 @@ func (h *Handler) createVolumeSnapshotContent(
 
 func (h *Handler) setStatusError(vmBackup *harvesterv1.VirtualMachineBackup, err error) error {
 	vmBackupCpy := vmBackup.DeepCopy()
+	if vmBackupCpy.Status == nil {
+		vmBackupCpy.Status = &harvesterv1.VirtualMachineBackupStatus{}
+	}
 	vmBackupCpy.Status.Error = &harvesterv1.Error{
 		Time:    currentTime(),
 		Message: pointer.StringPtr(err.Error()),
@@ -544,117 +690,95 @@ func (h *Handler) setStatusError(vmBackup *harvesterv1.VirtualMachineBackup, err
 	return err
 }
 
-func (h *Handler) deleteVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBackup, target *settings.BackupTarget) error {
-	var err error
-	if target == nil {
-		if target, err = settings.DecodeBackupTarget(settings.BackupTargetSet.Get()); err != nil {
-			return err
-		}
-	}
-
-	// when backup target has been reset to default, skip following
-	if target.IsDefaultBackupTarget() {
-		logrus.Debugf("vmBackup delete:%s, backup target is default, skip", vmBackup.Name)
-		return nil
-	}
-
+func (h *Handler) deleteVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBackup, target *harvesterv1.BackupTarget) error {
 	if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
 		return nil
 	}
 
-	if target.Type == settings.S3BackupType {
-		secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
-		if err != nil {
-			return err
-		}
-		os.Setenv(AWSAccessKey, string(secret.Data[AWSAccessKey]))
-		os.Setenv(AWSSecretKey, string(secret.Data[AWSSecretKey]))
-		os.Setenv(AWSEndpoints, string(secret.Data[AWSEndpoints]))
-		os.Setenv(AWSCERT, string(secret.Data[AWSCERT]))
-	}
-
-	bsDriver, err := backupstore.GetBackupStoreDriver(ConstructEndpoint(target))
+	bsDriver, err := GetBackupStoreDriver(h.secretCache, target)
 	if err != nil {
 		return err
 	}
 
 	destURL := filepath.Join(metadataFolderPath, getVMBackupMetadataFileName(vmBackup.Namespace, vmBackup.Name))
 	if exist := bsDriver.FileExists(destURL); exist {
-		logrus.Debugf("delete vm backup metadata %s/%s in backup target %s", vmBackup.Namespace, vmBackup.Name, target.Type)
+		logrus.Debugf("delete vm backup metadata %s/%s in backup target %s", vmBackup.Namespace, vmBackup.Name, target.Name)
 		return bsDriver.Remove(destURL)
 	}
 
 	return nil
 }
 
-func (h *Handler) uploadVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBackup, target *settings.BackupTarget) error {
-	var err error
+func (h *Handler) uploadVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBackup) error {
 	// if users don't update VMBackup CRD, we may lose backup target data.
 	if vmBackup.Status.BackupTarget == nil {
 		return fmt.Errorf("no backup target in vmbackup.status")
 	}
 
-	if target == nil {
-		if target, err = settings.DecodeBackupTarget(settings.BackupTargetSet.Get()); err != nil {
-			return err
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		// the backup target has been removed, there is nowhere to upload
+		if apierrors.IsNotFound(err) {
+			return nil
 		}
-	}
-
-	// when current backup target is default, skip following steps
-	// if backup target is default, IsBackupTargetSame is true when vmBackup.Status.BackupTarget is also default value
-	if target.IsDefaultBackupTarget() {
-		return nil
+		return err
 	}
 
 	if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
 		return nil
 	}
 
-	if target.Type == settings.S3BackupType {
-		secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
-		if err != nil {
-			return err
-		}
-		os.Setenv(AWSAccessKey, string(secret.Data[AWSAccessKey]))
-		os.Setenv(AWSSecretKey, string(secret.Data[AWSSecretKey]))
-		os.Setenv(AWSEndpoints, string(secret.Data[AWSEndpoints]))
-		os.Setenv(AWSCERT, string(secret.Data[AWSCERT]))
+	bsDriver, err := GetBackupStoreDriver(h.secretCache, target)
+	if err != nil {
+		return err
 	}
 
-	bsDriver, err := backupstore.GetBackupStoreDriver(ConstructEndpoint(target))
+	keys, err := getEncryptionKeys(h.secretCache, target)
 	if err != nil {
 		return err
 	}
 
 	vmBackupMetadata := &VirtualMachineBackupMetadata{
-		Name:          vmBackup.Name,
-		Namespace:     vmBackup.Namespace,
-		BackupSpec:    vmBackup.Spec,
-		VMSourceSpec:  vmBackup.Status.SourceSpec,
-		VolumeBackups: sanitizeVolumeBackups(vmBackup.Status.VolumeBackups),
-		SecretBackups: vmBackup.Status.SecretBackups,
+		Name:             vmBackup.Name,
+		Namespace:        vmBackup.Namespace,
+		BackupSpec:       vmBackup.Spec,
+		VMSourceSpec:     vmBackup.Status.SourceSpec,
+		VolumeBackups:    sanitizeVolumeBackups(vmBackup.Status.VolumeBackups),
+		SecretBackups:    vmBackup.Status.SecretBackups,
+		ParentBackupName: vmBackup.Status.ParentBackupName,
 	}
 	if vmBackup.Namespace == "" {
 		vmBackupMetadata.Namespace = metav1.NamespaceDefault
 	}
 
-	j, err := json.Marshal(vmBackupMetadata)
-	if err != nil {
-		return err
-	}
-
 	shouldUpload := true
 	destURL := filepath.Join(metadataFolderPath, getVMBackupMetadataFileName(vmBackup.Namespace, vmBackup.Name))
 	if bsDriver.FileExists(destURL) {
-		if remoteVMBackupMetadata, err := loadBackupMetadataInBackupTarget(destURL, bsDriver); err != nil {
+		remoteVMBackupMetadata, err := loadBackupMetadataInBackupTarget(destURL, bsDriver)
+		if err != nil {
 			return err
-		} else if reflect.DeepEqual(vmBackupMetadata, remoteVMBackupMetadata) {
-			shouldUpload = false
+		}
+		// the metadata encrypted with a rotated key, or with a key which isn't in the secret, is uploaded again
+		if keys == nil || remoteVMBackupMetadata.EncryptionKeyID == keys.activeID {
+			if remoteVMBackupMetadata, err = decryptBackupMetadata(keys, remoteVMBackupMetadata); err == nil &&
+				reflect.DeepEqual(vmBackupMetadata, remoteVMBackupMetadata) {
+				shouldUpload = false
+			}
 		}
 	}
 
 	if shouldUpload {
-		logrus.Debugf("upload vm backup metadata %s/%s to backup target %s", vmBackup.Namespace, vmBackup.Name, target.Type)
+		if keys != nil {
+			if vmBackupMetadata, err = encryptBackupMetadata(keys, vmBackupMetadata); err != nil {
+				return err
+			}
+		}
+		j, err := json.Marshal(vmBackupMetadata)
+		if err != nil {
+			return err
+		}
+
+		logrus.Debugf("upload vm backup metadata %s/%s to backup target %s", vmBackup.Namespace, vmBackup.Name, target.Name)
 		if err := bsDriver.Write(destURL, bytes.NewReader(j)); err != nil {
 			return err
 		}
@@ -663,13 +787,22 @@ func (h *Handler) uploadVMBackupMetadata(vmBackup *harvesterv1.VirtualMachineBac
 	return nil
 }
 
+// sanitizeVolumeBackups returns a copy of the volume backups without the fields of the local cluster,
+// the sizes are calculated again by the cluster which syncs the metadata.
 func sanitizeVolumeBackups(volumeBackups []harvesterv1.VolumeBackup) []harvesterv1.VolumeBackup {
-	for i := 0; i < len(volumeBackups); i++ {
-		volumeBackups[i].ReadyToUse = nil
-		volumeBackups[i].CreationTime = nil
-		volumeBackups[i].Error = nil
-	}
-	return volumeBackups
+	var sanitized []harvesterv1.VolumeBackup
+	for _, volumeBackup := range volumeBackups {
+		vb := volumeBackup.DeepCopy()
+		vb.ReadyToUse = nil
+		vb.CreationTime = nil
+		vb.Error = nil
+		vb.ParentLonghornBackupName = nil
+		vb.Size = nil
+		vb.DeltaSize = nil
+		vb.Progress = nil
+		sanitized = append(sanitized, *vb)
+	}
+	return sanitized
 }
 
 func volumeToPVCMappings(volumes []kubevirtv1.Volume) map[string]string {
@@ -745,7 +878,7 @@ func (h *Handler) configureBackupTargetOnStatus(vmBackup *harvesterv1.VirtualMac
 
 	logrus.Debugf("configure backup target from annotation to status for vm backup %s/%s", vmBackup.Namespace, vmBackup.Name)
 	vmBackupCpy := vmBackup.DeepCopy()
-	vmBackupCpy.Status.BackupTarget = &harvesterv1.BackupTarget{
+	vmBackupCpy.Status.BackupTarget = &harvesterv1.BackupTargetInfo{
 		Endpoint:     vmBackup.Annotations[backupTargetAnnotation],
 		BucketName:   vmBackup.Annotations[backupBucketNameAnnotation],
 		BucketRegion: vmBackup.Annotations[backupBucketRegionAnnotation],
@@ -0,0 +1,217 @@
+package backup
+
+// The volume data of a VM backup are shipped by longhorn, which only uploads the blocks changed since
+// the last backup of the volume and refers to the unchanged blocks of the previous backups.
+// The blocks are reference counted in the backup store, so deleting a backup in the middle of a chain
+// doesn't break the backups after it. The chain is kept visible in the VM backup status:
+// every backup records its parent and how much data it added to the backup target.
+// When a backup is deleted, the chain is consolidated by re-parenting its children to its parent
+// and recalculating their delta sizes against the new parent.
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/longhorn/backupstore"
+	bsutil "github.com/longhorn/backupstore/util"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// getParentBackupName returns the latest ready backup of the same VM in the same backup target
+func (h *Handler) getParentBackupName(vmBackup *harvesterv1.VirtualMachineBackup) (string, error) {
+	vmBackups, err := h.vmBackupCache.List(vmBackup.Namespace, labels.Everything())
+	if err != nil {
+		return "", err
+	}
+	if parent := getLatestBackup(vmBackup, vmBackups); parent != nil {
+		return parent.Name, nil
+	}
+	return "", nil
+}
+
+func getLatestBackup(vmBackup *harvesterv1.VirtualMachineBackup, vmBackups []*harvesterv1.VirtualMachineBackup) *harvesterv1.VirtualMachineBackup {
+	var latest *harvesterv1.VirtualMachineBackup
+	for _, b := range vmBackups {
+		if b.Name == vmBackup.Name || b.DeletionTimestamp != nil || isVMSnapshot(b) || !isBackupReady(b) || b.Status.CreationTime == nil {
+			continue
+		}
+		if b.Spec.Source.Kind != vmBackup.Spec.Source.Kind || b.Spec.Source.Name != vmBackup.Spec.Source.Name ||
+			GetBackupTargetName(b) != GetBackupTargetName(vmBackup) {
+			continue
+		}
+		if latest == nil || latest.Status.CreationTime.Before(b.Status.CreationTime) {
+			latest = b
+		}
+	}
+	return latest
+}
+
+func needsBackupSizeSync(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		if vb.LonghornBackupName != nil && vb.DeltaSize == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// syncBackupSizes calculates the size and the delta size of the volume backups from the block lists in the backup target
+func (h *Handler) syncBackupSizes(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	if !needsBackupSizeSync(vmBackup) {
+		return nil
+	}
+
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+		return nil
+	}
+
+	var parent *harvesterv1.VirtualMachineBackup
+	if vmBackup.Status.ParentBackupName != "" {
+		if parent, err = h.vmBackupCache.Get(vmBackup.Namespace, vmBackup.Status.ParentBackupName); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		// the chain is consolidated once the parent is removed, which triggers the sync again
+		if parent != nil && parent.DeletionTimestamp != nil {
+			return nil
+		}
+	}
+
+	bsDriver, err := GetBackupStoreDriver(h.secretCache, target)
+	if err != nil {
+		return err
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	for i, vb := range vmBackupCpy.Status.VolumeBackups {
+		if vb.LonghornBackupName == nil || vb.DeltaSize != nil {
+			continue
+		}
+
+		volumeName := vb.PersistentVolumeClaim.Spec.VolumeName
+		lhBackup, err := loadLonghornBackup(bsDriver, volumeName, *vb.LonghornBackupName)
+		if err != nil {
+			return err
+		}
+
+		var parentLHBackup *backupstore.Backup
+		parentVolumeBackup := getParentVolumeBackup(parent, vb)
+		if parentVolumeBackup != nil {
+			if parentLHBackup, err = loadLonghornBackup(bsDriver, volumeName, *parentVolumeBackup.LonghornBackupName); err != nil {
+				return err
+			}
+			vmBackupCpy.Status.VolumeBackups[i].ParentLonghornBackupName = parentVolumeBackup.LonghornBackupName
+		}
+
+		size, deltaSize := lhBackup.Size, getDeltaSize(lhBackup, parentLHBackup)
+		vmBackupCpy.Status.VolumeBackups[i].Size = &size
+		vmBackupCpy.Status.VolumeBackups[i].DeltaSize = &deltaSize
+	}
+
+	vmBackupCpy.Status.TotalSize, vmBackupCpy.Status.TotalDeltaSize = 0, 0
+	for _, vb := range vmBackupCpy.Status.VolumeBackups {
+		if vb.Size != nil {
+			vmBackupCpy.Status.TotalSize += *vb.Size
+		}
+		if vb.DeltaSize != nil {
+			vmBackupCpy.Status.TotalDeltaSize += *vb.DeltaSize
+		}
+	}
+
+	_, err = h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// getParentVolumeBackup returns the volume backup of the parent VM backup which is taken from the same longhorn volume
+func getParentVolumeBackup(parent *harvesterv1.VirtualMachineBackup, volumeBackup harvesterv1.VolumeBackup) *harvesterv1.VolumeBackup {
+	if parent == nil || parent.Status == nil {
+		return nil
+	}
+	for i, vb := range parent.Status.VolumeBackups {
+		if vb.LonghornBackupName != nil && vb.PersistentVolumeClaim.Spec.VolumeName == volumeBackup.PersistentVolumeClaim.Spec.VolumeName {
+			return &parent.Status.VolumeBackups[i]
+		}
+	}
+	return nil
+}
+
+// getDeltaSize returns the size of the blocks which aren't referred by the parent backup,
+// a block is only stored once in the backup target no matter how many times it's referred.
+func getDeltaSize(backup, parent *backupstore.Backup) int64 {
+	parentBlocks := map[string]struct{}{}
+	if parent != nil {
+		for _, block := range parent.Blocks {
+			parentBlocks[block.BlockChecksum] = struct{}{}
+		}
+	}
+
+	newBlocks := map[string]struct{}{}
+	for _, block := range backup.Blocks {
+		if _, ok := parentBlocks[block.BlockChecksum]; !ok {
+			newBlocks[block.BlockChecksum] = struct{}{}
+		}
+	}
+	return int64(len(newBlocks)) * backupstore.DEFAULT_BLOCK_SIZE
+}
+
+// loadLonghornBackup reads the backup config with the block list of a longhorn backup,
+// it's stored in backupstore/volumes/<checksum[0:2]>/<checksum[2:4]>/<volume>/backups/backup_<name>.cfg
+func loadLonghornBackup(bsDriver backupstore.BackupStoreDriver, volumeName, backupName string) (*backupstore.Backup, error) {
+	checksum := bsutil.GetChecksum([]byte(volumeName))
+	filePath := filepath.Join(backupstore.GetBackupstoreBase(), backupstore.VOLUME_DIRECTORY,
+		checksum[0:backupstore.VOLUME_SEPARATE_LAYER1], checksum[backupstore.VOLUME_SEPARATE_LAYER1:backupstore.VOLUME_SEPARATE_LAYER2],
+		volumeName, backupstore.BACKUP_DIRECTORY, backupstore.BACKUP_CONFIG_PREFIX+backupName+backupstore.CFG_SUFFIX)
+	if !bsDriver.FileExists(filePath) {
+		return nil, fmt.Errorf("cannot find %v in backupstore", filePath)
+	}
+
+	rc, err := bsDriver.Read(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	backup := &backupstore.Backup{}
+	if err := json.NewDecoder(rc).Decode(backup); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// consolidateBackupChain re-parents the children of a removed backup to its parent,
+// their delta sizes are recalculated against the new parent.
+func (h *Handler) consolidateBackupChain(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	vmBackups, err := h.vmBackupCache.List(vmBackup.Namespace, labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, child := range vmBackups {
+		if child.Status == nil || child.Status.ParentBackupName != vmBackup.Name || GetBackupTargetName(child) != GetBackupTargetName(vmBackup) {
+			continue
+		}
+
+		logrus.Debugf("re-parent vm backup %s/%s from %s to %q", child.Namespace, child.Name, vmBackup.Name, vmBackup.Status.ParentBackupName)
+		childCpy := child.DeepCopy()
+		childCpy.Status.ParentBackupName = vmBackup.Status.ParentBackupName
+		childCpy.Status.TotalDeltaSize = 0
+		for i := range childCpy.Status.VolumeBackups {
+			childCpy.Status.VolumeBackups[i].ParentLonghornBackupName = nil
+			childCpy.Status.VolumeBackups[i].DeltaSize = nil
+		}
+		if _, err := h.vmBackups.Update(childCpy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/longhorn/backupstore"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_getLatestBackup(t *testing.T) {
+	now := time.Now()
+	newBackup := func(name, vmName, targetName string, backupType harvesterv1.BackupType, ready bool, created time.Time) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: harvesterv1.VirtualMachineBackupSpec{
+				Source:           corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: vmName},
+				Type:             backupType,
+				BackupTargetName: targetName,
+			},
+			Status: &harvesterv1.VirtualMachineBackupStatus{
+				ReadyToUse:   pointer.BoolPtr(ready),
+				CreationTime: &metav1.Time{Time: created},
+			},
+		}
+	}
+	vmBackup := newBackup("new", "vm", "", harvesterv1.Backup, false, now)
+
+	var testCases = []struct {
+		name      string
+		vmBackups []*harvesterv1.VirtualMachineBackup
+		expected  string
+	}{
+		{
+			name:      "no backups",
+			vmBackups: []*harvesterv1.VirtualMachineBackup{vmBackup},
+		},
+		{
+			name: "latest ready backup",
+			vmBackups: []*harvesterv1.VirtualMachineBackup{
+				vmBackup,
+				newBackup("first", "vm", "", harvesterv1.Backup, true, now.Add(-2*time.Hour)),
+				newBackup("second", "vm", harvesterv1.DefaultBackupTargetName, harvesterv1.Backup, true, now.Add(-time.Hour)),
+				newBackup("failed", "vm", "", harvesterv1.Backup, false, now.Add(-time.Minute)),
+			},
+			expected: "second",
+		},
+		{
+			name: "skip other VMs, targets and snapshots",
+			vmBackups: []*harvesterv1.VirtualMachineBackup{
+				vmBackup,
+				newBackup("first", "vm", "", harvesterv1.Backup, true, now.Add(-2*time.Hour)),
+				newBackup("other-vm", "vm2", "", harvesterv1.Backup, true, now.Add(-time.Hour)),
+				newBackup("other-target", "vm", "offsite", harvesterv1.Backup, true, now.Add(-time.Hour)),
+				newBackup("snapshot", "vm", "", harvesterv1.Snapshot, true, now.Add(-time.Hour)),
+			},
+			expected: "first",
+		},
+	}
+
+	for _, tc := range testCases {
+		latest := getLatestBackup(vmBackup, tc.vmBackups)
+		if tc.expected == "" {
+			assert.Nil(t, latest, tc.name)
+		} else if assert.NotNil(t, latest, tc.name) {
+			assert.Equal(t, tc.expected, latest.Name, tc.name)
+		}
+	}
+}
+
+func Test_getDeltaSize(t *testing.T) {
+	newLHBackup := func(checksums ...string) *backupstore.Backup {
+		backup := &backupstore.Backup{}
+		for i, checksum := range checksums {
+			backup.Blocks = append(backup.Blocks, backupstore.BlockMapping{Offset: int64(i) * backupstore.DEFAULT_BLOCK_SIZE, BlockChecksum: checksum})
+		}
+		return backup
+	}
+
+	var testCases = []struct {
+		name     string
+		backup   *backupstore.Backup
+		parent   *backupstore.Backup
+		expected int64
+	}{
+		{
+			name:     "full backup",
+			backup:   newLHBackup("a", "b", "c"),
+			expected: 3 * backupstore.DEFAULT_BLOCK_SIZE,
+		},
+		{
+			name:     "same blocks are stored once",
+			backup:   newLHBackup("a", "a", "b"),
+			expected: 2 * backupstore.DEFAULT_BLOCK_SIZE,
+		},
+		{
+			name:     "incremental backup",
+			backup:   newLHBackup("a", "d", "c"),
+			parent:   newLHBackup("a", "b", "c"),
+			expected: backupstore.DEFAULT_BLOCK_SIZE,
+		},
+		{
+			name:   "no change",
+			backup: newLHBackup("a", "b"),
+			parent: newLHBackup("a", "b", "c"),
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, getDeltaSize(tc.backup, tc.parent), tc.name)
+	}
+}
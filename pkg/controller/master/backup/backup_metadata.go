@@ -3,9 +3,10 @@ package backup
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	"github.com/longhorn/backupstore"
@@ -17,19 +18,18 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/config"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
-	ctllonghornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
-	"github.com/harvester/harvester/pkg/settings"
-	"github.com/harvester/harvester/pkg/util"
 )
 
 const (
 	metadataFolderPath           = "harvester/vmbackups/"
 	backupMetadataControllerName = "harvester-backup-metadata-controller"
+	metadataSyncInterval         = 5 * time.Minute
 )
 
 type VirtualMachineBackupMetadata struct {
@@ -39,87 +39,90 @@ type VirtualMachineBackupMetadata struct {
 	VMSourceSpec  *harvesterv1.VirtualMachineSourceSpec `json:"vmSourceSpec,omitempty"`
 	VolumeBackups []harvesterv1.VolumeBackup            `json:"volumeBackups,omitempty"`
 	SecretBackups []harvesterv1.SecretBackup            `json:"secretBackups,omitempty"`
+	// ParentBackupName keeps the backup chain when the backup is synced to another cluster
+	ParentBackupName string `json:"parentBackupName,omitempty"`
+	// EncryptionKeyID is the key of the backup target encrypting the metadata, the encrypted metadata
+	// only have the name and the namespace in plain, the other fields are in EncryptedData.
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+	EncryptedData   []byte `json:"encryptedData,omitempty"`
 }
 
 type MetadataHandler struct {
-	ctx                  context.Context
-	namespaces           ctlcorev1.NamespaceClient
-	namespaceCache       ctlcorev1.NamespaceCache
-	secretCache          ctlcorev1.SecretCache
-	vms                  ctlkubevirtv1.VirtualMachineController
-	longhornSettingCache ctllonghornv1.SettingCache
-	settings             ctlharvesterv1.SettingController
-	vmBackups            ctlharvesterv1.VirtualMachineBackupClient
-	vmBackupCache        ctlharvesterv1.VirtualMachineBackupCache
+	ctx            context.Context
+	namespaces     ctlcorev1.NamespaceClient
+	namespaceCache ctlcorev1.NamespaceCache
+	secretCache    ctlcorev1.SecretCache
+	vms            ctlkubevirtv1.VirtualMachineController
+	backupTargets  ctlharvesterv1.BackupTargetController
+	vmBackups      ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache  ctlharvesterv1.VirtualMachineBackupCache
 }
 
-// RegisterBackupMetadata register the setting controller and resync vm backup metadata when backup target change
+// RegisterBackupMetadata register the backup target controller and resync vm backup metadata from every backup target
 func RegisterBackupMetadata(ctx context.Context, management *config.Management, opts config.Options) error {
 	vmBackups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
-	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 	namespaces := management.CoreFactory.Core().V1().Namespace()
 	secrets := management.CoreFactory.Core().V1().Secret()
-	longhornSettings := management.LonghornFactory.Longhorn().V1beta1().Setting()
 	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
 
 	backupMetadataController := &MetadataHandler{
-		ctx:                  ctx,
-		namespaces:           namespaces,
-		namespaceCache:       namespaces.Cache(),
-		secretCache:          secrets.Cache(),
-		vms:                  vms,
-		longhornSettingCache: longhornSettings.Cache(),
-		settings:             settings,
-		vmBackups:            vmBackups,
-		vmBackupCache:        vmBackups.Cache(),
-	}
-
-	settings.OnChange(ctx, backupMetadataControllerName, backupMetadataController.OnBackupTargetChange)
+		ctx:            ctx,
+		namespaces:     namespaces,
+		namespaceCache: namespaces.Cache(),
+		secretCache:    secrets.Cache(),
+		vms:            vms,
+		backupTargets:  backupTargets,
+		vmBackups:      vmBackups,
+		vmBackupCache:  vmBackups.Cache(),
+	}
+
+	backupTargets.OnChange(ctx, backupMetadataControllerName, backupMetadataController.OnBackupTargetChange)
 	return nil
 }
 
-// OnBackupTargetChange resync vm metadata files when backup target change
-func (h *MetadataHandler) OnBackupTargetChange(key string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
-	if setting == nil || setting.DeletionTimestamp != nil ||
-		setting.Name != settings.BackupTargetSettingName || setting.Value == "" {
+// OnBackupTargetChange resync vm metadata files from the backup target periodically
+func (h *MetadataHandler) OnBackupTargetChange(key string, target *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if target == nil || target.DeletionTimestamp != nil {
 		return nil, nil
 	}
 
-	target, err := settings.DecodeBackupTarget(setting.Value)
-	if err != nil {
-		return setting, err
+	if harvesterv1.BackupTargetConditionAvailable.IsTrue(target) && target.Status.LastSyncedTime != nil {
+		if next := target.Status.LastSyncedTime.Add(metadataSyncInterval); next.After(time.Now()) {
+			h.backupTargets.EnqueueAfter(target.Name, time.Until(next))
+			return nil, nil
+		}
 	}
 
-	logrus.Debugf("backup target change, sync vm backup:%s:%s", target.Type, target.Endpoint)
+	logrus.Debugf("sync vm backup from backup target %s:%s:%s", target.Name, target.Spec.Type, target.Spec.Endpoint)
 
-	// when backup target is reset to default, do not trig sync
-	if target.IsDefaultBackupTarget() {
-		return nil, nil
+	targetCpy := target.DeepCopy()
+	if err := h.syncVMBackup(target); err != nil {
+		logrus.Errorf("can't sync vm backup metadata, target:%s, err: %v", target.Name, err)
+		harvesterv1.BackupTargetConditionAvailable.False(targetCpy)
+		harvesterv1.BackupTargetConditionAvailable.Reason(targetCpy, "SyncFailed")
+		harvesterv1.BackupTargetConditionAvailable.Message(targetCpy, err.Error())
+		h.backupTargets.EnqueueAfter(target.Name, 5*time.Second)
+	} else {
+		harvesterv1.BackupTargetConditionAvailable.True(targetCpy)
+		harvesterv1.BackupTargetConditionAvailable.Reason(targetCpy, "")
+		harvesterv1.BackupTargetConditionAvailable.Message(targetCpy, "")
+		targetCpy.Status.LastSyncedTime = currentTime()
 	}
 
-	if err = h.syncVMBackup(target); err != nil {
-		logrus.Errorf("can't sync vm backup metadata, target:%s:%s, err: %v", target.Type, target.Endpoint, err)
-		h.settings.EnqueueAfter(setting.Name, 5*time.Second)
-		return nil, nil
+	if !reflect.DeepEqual(target.Status, targetCpy.Status) {
+		return h.backupTargets.Update(targetCpy)
 	}
-
 	return nil, nil
 }
 
-func (h *MetadataHandler) syncVMBackup(target *settings.BackupTarget) error {
-	if target.Type == settings.S3BackupType {
-		secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
-		if err != nil {
-			return err
-		}
-		os.Setenv(AWSAccessKey, string(secret.Data[AWSAccessKey]))
-		os.Setenv(AWSSecretKey, string(secret.Data[AWSSecretKey]))
-		os.Setenv(AWSEndpoints, string(secret.Data[AWSEndpoints]))
-		os.Setenv(AWSCERT, string(secret.Data[AWSCERT]))
+func (h *MetadataHandler) syncVMBackup(target *harvesterv1.BackupTarget) error {
+	bsDriver, err := GetBackupStoreDriver(h.secretCache, target)
+	if err != nil {
+		return err
 	}
 
-	endpoint := ConstructEndpoint(target)
-	bsDriver, err := backupstore.GetBackupStoreDriver(endpoint)
+	keys, err := getEncryptionKeys(h.secretCache, target)
 	if err != nil {
 		return err
 	}
@@ -137,14 +140,29 @@ func (h *MetadataHandler) syncVMBackup(target *settings.BackupTarget) error {
 		if backupMetadata.Namespace == "" {
 			backupMetadata.Namespace = metav1.NamespaceDefault
 		}
-		if err := h.createVMBackupIfNotExist(*backupMetadata, target); err != nil {
+
+		keyID := backupMetadata.EncryptionKeyID
+		decrypted, err := decryptBackupMetadata(keys, backupMetadata)
+		if errors.Is(err, errEncryptionKeyMissing) {
+			// keep a VM backup to tell users the backup can't be restored without the key
+			if err := h.createUndecryptableVMBackup(*backupMetadata, target, err); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return fmt.Errorf("can't decrypt vm backup metadata %s: %w", fileName, err)
+		}
+		decrypted.Name, decrypted.Namespace = backupMetadata.Name, backupMetadata.Namespace
+		if err := h.createVMBackupIfNotExist(*decrypted, keyID, target); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (h *MetadataHandler) createVMBackupIfNotExist(backupMetadata VirtualMachineBackupMetadata, target *settings.BackupTarget) error {
+// createUndecryptableVMBackup creates a VM backup for the metadata encrypted with a missing key,
+// it only has the error and is filled in once the key is added to the secret of the backup target.
+func (h *MetadataHandler) createUndecryptableVMBackup(backupMetadata VirtualMachineBackupMetadata, target *harvesterv1.BackupTarget, keyErr error) error {
 	if _, err := h.vmBackupCache.Get(backupMetadata.Namespace, backupMetadata.Name); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	} else if err == nil {
@@ -154,23 +172,78 @@ func (h *MetadataHandler) createVMBackupIfNotExist(backupMetadata VirtualMachine
 	if err := h.createNamespaceIfNotExist(backupMetadata.Namespace); err != nil {
 		return err
 	}
-	if _, err := h.vmBackups.Create(&harvesterv1.VirtualMachineBackup{
+
+	vmBackup := &harvesterv1.VirtualMachineBackup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      backupMetadata.Name,
 			Namespace: backupMetadata.Namespace,
 		},
-		Spec: backupMetadata.BackupSpec,
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source: corev1.TypedLocalObjectReference{
+				APIGroup: pointer.StringPtr(kubevirtv1.SchemeGroupVersion.Group),
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+			},
+			Type:             harvesterv1.Backup,
+			BackupTargetName: target.Name,
+		},
 		Status: &harvesterv1.VirtualMachineBackupStatus{
-			ReadyToUse: pointer.BoolPtr(false),
-			BackupTarget: &harvesterv1.BackupTarget{
-				Endpoint:     target.Endpoint,
-				BucketName:   target.BucketName,
-				BucketRegion: target.BucketRegion,
+			ReadyToUse:      pointer.BoolPtr(false),
+			BackupTarget:    newBackupTargetInfo(target),
+			EncryptionKeyID: backupMetadata.EncryptionKeyID,
+			Error: &harvesterv1.Error{
+				Time:    currentTime(),
+				Message: pointer.StringPtr(keyErr.Error()),
 			},
-			SourceSpec:    backupMetadata.VMSourceSpec,
-			VolumeBackups: backupMetadata.VolumeBackups,
-			SecretBackups: backupMetadata.SecretBackups,
 		},
+	}
+	updateBackupCondition(vmBackup, newReadyCondition(corev1.ConditionFalse, backupReasonEncryptionKeyMissing, keyErr.Error()))
+	_, err := h.vmBackups.Create(vmBackup)
+	return err
+}
+
+func (h *MetadataHandler) createVMBackupIfNotExist(backupMetadata VirtualMachineBackupMetadata, keyID string, target *harvesterv1.BackupTarget) error {
+	existing, err := h.vmBackupCache.Get(backupMetadata.Namespace, backupMetadata.Name)
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return err
+	} else if !isEncryptionKeyMissing(existing) {
+		return nil
+	}
+
+	if err := h.createNamespaceIfNotExist(backupMetadata.Namespace); err != nil {
+		return err
+	}
+
+	// the target may be registered with another name in the cluster which uploaded the metadata
+	spec := backupMetadata.BackupSpec
+	spec.BackupTargetName = target.Name
+	status := &harvesterv1.VirtualMachineBackupStatus{
+		ReadyToUse:       pointer.BoolPtr(false),
+		BackupTarget:     newBackupTargetInfo(target),
+		SourceSpec:       backupMetadata.VMSourceSpec,
+		VolumeBackups:    backupMetadata.VolumeBackups,
+		SecretBackups:    backupMetadata.SecretBackups,
+		ParentBackupName: backupMetadata.ParentBackupName,
+		EncryptionKeyID:  keyID,
+	}
+
+	// the key of the backup synced before is added, fill in the backup with the decrypted metadata
+	if existing != nil {
+		existingCpy := existing.DeepCopy()
+		existingCpy.Spec = spec
+		existingCpy.Status = status
+		_, err := h.vmBackups.Update(existingCpy)
+		return err
+	}
+
+	if _, err := h.vmBackups.Create(&harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupMetadata.Name,
+			Namespace: backupMetadata.Namespace,
+		},
+		Spec:   spec,
+		Status: status,
 	}); err != nil {
 		return err
 	}
@@ -173,6 +173,7 @@ func (h *Handler) OnLHBackupChanged(key string, lhBackup *lhv1beta1.Backup) (*lh
 				vmBackupCpy.Status.VolumeBackups[i].LonghornBackupName = pointer.StringPtr(lhBackup.Name)
 			}
 		}
+		updateBackupProgress(vmBackupCpy, snapshot.Name, lhBackup)
 
 		if !reflect.DeepEqual(vmBackup.Status, vmBackupCpy.Status) {
 			if _, err := h.vmBackups.Update(vmBackupCpy); err != nil {
@@ -2,18 +2,23 @@ package backup
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/longhorn/backupstore"
 	longhornv1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
 	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/config"
@@ -35,40 +40,61 @@ const (
 	AWSEndpoints       = "AWS_ENDPOINTS"
 	AWSCERT            = "AWS_CERT"
 	VirtualHostedStyle = "VIRTUAL_HOSTED_STYLE"
+
+	backupTargetBusyReason   = "WaitingForBackupTarget"
+	waitBackupTargetInterval = 10 * time.Second
+
+	// the claim of the longhorn backup target is recorded in the annotations of its longhorn setting
+	backupTargetNameAnnotation      = "harvesterhci.io/backupTargetName"
+	backupTargetIdentityAnnotation  = "harvesterhci.io/backupTargetIdentity"
+	backupTargetClaimTimeAnnotation = "harvesterhci.io/backupTargetClaimTime"
+	// backupTargetClaimHold is how long the target can't be switched after it's claimed,
+	// it leaves the claimer time to record that it uses the target
+	backupTargetClaimHold = time.Minute
+)
+
+var (
+	activatorOnce sync.Once
+	activator     *targetActivator
 )
 
-// RegisterBackupTarget register the setting controller and reconsile longhorn setting when backup target changed
+// RegisterBackupTarget register the setting and backup target controllers, the backup-target setting is mapped to
+// the default BackupTarget, and the longhorn setting is reconciled with the BackupTarget in use.
 func RegisterBackupTarget(ctx context.Context, management *config.Management, opts config.Options) error {
 	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
 	secrets := management.CoreFactory.Core().V1().Secret()
-	longhornSettings := management.LonghornFactory.Longhorn().V1beta1().Setting()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
 
 	backupTargetController := &TargetHandler{
-		ctx:                  ctx,
-		longhornSettings:     longhornSettings,
-		longhornSettingCache: longhornSettings.Cache(),
-		secrets:              secrets,
-		secretCache:          secrets.Cache(),
-		vms:                  vms,
-		settings:             settings,
+		ctx:               ctx,
+		secrets:           secrets,
+		secretCache:       secrets.Cache(),
+		vms:               vms,
+		settings:          settings,
+		backupTargets:     backupTargets,
+		backupTargetCache: backupTargets.Cache(),
+		activator:         getTargetActivator(management),
 	}
 
 	settings.OnChange(ctx, backupTargetControllerName, backupTargetController.OnBackupTargetChange)
+	backupTargets.OnChange(ctx, backupTargetControllerName, backupTargetController.OnTargetChange)
+	backupTargets.OnRemove(ctx, backupTargetControllerName, backupTargetController.OnTargetRemove)
 	return nil
 }
 
 type TargetHandler struct {
-	ctx                  context.Context
-	longhornSettings     ctllonghornv1.SettingClient
-	longhornSettingCache ctllonghornv1.SettingCache
-	secrets              ctlcorev1.SecretClient
-	secretCache          ctlcorev1.SecretCache
-	vms                  ctlkubevirtv1.VirtualMachineController
-	settings             ctlharvesterv1.SettingClient
+	ctx               context.Context
+	secrets           ctlcorev1.SecretClient
+	secretCache       ctlcorev1.SecretCache
+	vms               ctlkubevirtv1.VirtualMachineController
+	settings          ctlharvesterv1.SettingClient
+	backupTargets     ctlharvesterv1.BackupTargetController
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	activator         *targetActivator
 }
 
-// OnBackupTargetChange handles backupTarget setting object on change
+// OnBackupTargetChange handles backupTarget setting object on change and maps it to the default BackupTarget
 func (h *TargetHandler) OnBackupTargetChange(key string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
 	if setting == nil || setting.DeletionTimestamp != nil ||
 		setting.Name != settings.BackupTargetSettingName || setting.Value == "" {
@@ -85,29 +111,32 @@ func (h *TargetHandler) OnBackupTargetChange(key string, setting *harvesterv1.Se
 	switch target.Type {
 	case settings.S3BackupType:
 		// Since S3 access key id and secret access key are stripped after S3 backup target has been verified
-		// in reUpdateBackupTargetSettingSecret
-		// stop the controller to reconcile it
+		// in reUpdateBackupTargetSettingSecret, only make sure the default target exists.
+		// The credentials are copied from the longhorn secret for the clusters upgraded from a single backup target.
 		if target.SecretAccessKey == "" && target.AccessKeyID == "" {
-			return nil, nil
+			if err = h.migrateDefaultBackupTargetSecret(); err != nil {
+				return nil, err
+			}
+			return nil, h.syncDefaultBackupTarget(target)
 		}
 
-		if err = h.updateLonghornTarget(target); err != nil {
+		if err = h.updateDefaultBackupTargetSecret(target); err != nil {
 			return nil, err
 		}
 
-		if err = h.updateBackupTargetSecret(target); err != nil {
+		if err = h.syncDefaultBackupTarget(target); err != nil {
 			return nil, err
 		}
 
 		return h.reUpdateBackupTargetSettingSecret(setting, target)
 
 	case settings.NFSBackupType:
-		if err = h.updateLonghornTarget(target); err != nil {
+		if err = h.syncDefaultBackupTarget(target); err != nil {
 			return nil, err
 		}
 
 		// delete the may existing previous secret of S3
-		if err = h.deleteBackupTargetSecret(target); err != nil {
+		if err = h.secrets.Delete(util.LonghornSystemNamespaceName, util.DefaultBackupTargetSecretName, nil); err != nil && !apierrors.IsNotFound(err) {
 			return nil, err
 		}
 
@@ -117,14 +146,14 @@ func (h *TargetHandler) OnBackupTargetChange(key string, setting *harvesterv1.Se
 		return nil, nil
 
 	default:
-		// reset backup target to default, then delete/update related settings
+		// reset backup target to default, the default BackupTarget is removed
 		if target.IsDefaultBackupTarget() {
-			if err = h.updateLonghornTarget(target); err != nil {
+			if err = h.backupTargets.Delete(harvesterv1.DefaultBackupTargetName, nil); err != nil && !apierrors.IsNotFound(err) {
 				return nil, err
 			}
 
 			// delete the may existing previous secret of S3
-			if err = h.deleteBackupTargetSecret(target); err != nil {
+			if err = h.secrets.Delete(util.LonghornSystemNamespaceName, util.DefaultBackupTargetSecretName, nil); err != nil && !apierrors.IsNotFound(err) {
 				return nil, err
 			}
 
@@ -135,6 +164,187 @@ func (h *TargetHandler) OnBackupTargetChange(key string, setting *harvesterv1.Se
 	}
 }
 
+// OnTargetChange keeps the longhorn credentials of the BackupTarget in use up to date,
+// and points longhorn at the default target when longhorn doesn't use any existing target.
+func (h *TargetHandler) OnTargetChange(key string, target *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if target == nil || target.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	active, err := h.activator.isActive(target)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, h.activator.refreshCredentials(target)
+	}
+
+	if target.Name != harvesterv1.DefaultBackupTargetName {
+		return nil, nil
+	}
+
+	known, err := h.isLonghornTargetKnown()
+	if err != nil || known {
+		return nil, err
+	}
+
+	activated, err := h.activator.activate(target)
+	if err != nil {
+		return nil, err
+	}
+	if !activated {
+		h.backupTargets.EnqueueAfter(target.Name, waitBackupTargetInterval)
+	}
+	return nil, nil
+}
+
+// OnTargetRemove resets the longhorn backup target if it's removed
+func (h *TargetHandler) OnTargetRemove(key string, target *harvesterv1.BackupTarget) (*harvesterv1.BackupTarget, error) {
+	if target == nil {
+		return nil, nil
+	}
+
+	active, err := h.activator.isActive(target)
+	if err != nil || !active {
+		return nil, err
+	}
+
+	logrus.Infof("backup target %s in use is removed, reset the longhorn backup target", target.Name)
+	if err := h.activator.reset(target); err != nil {
+		return nil, err
+	}
+	if target.Name != harvesterv1.DefaultBackupTargetName {
+		h.backupTargets.Enqueue(harvesterv1.DefaultBackupTargetName)
+	}
+	return nil, nil
+}
+
+// isLonghornTargetKnown checks whether the longhorn backup target is one of the BackupTargets
+func (h *TargetHandler) isLonghornTargetKnown() (bool, error) {
+	setting, err := h.activator.getLonghornTarget()
+	if err != nil || setting == nil || setting.Value == "" {
+		return false, err
+	}
+	if name := setting.Annotations[backupTargetNameAnnotation]; name != "" {
+		if _, err := h.backupTargetCache.Get(name); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	// the longhorn backup target isn't claimed by harvester, e.g. it's set before upgrading from a single backup target
+	url := setting.Value
+	targets, err := h.backupTargetCache.List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, target := range targets {
+		if GetBackupTargetURL(target) == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (h *TargetHandler) syncDefaultBackupTarget(target *settings.BackupTarget) error {
+	spec := harvesterv1.BackupTargetSpec{
+		Type:               harvesterv1.BackupTargetType(target.Type),
+		Endpoint:           target.Endpoint,
+		BucketName:         target.BucketName,
+		BucketRegion:       target.BucketRegion,
+		VirtualHostedStyle: target.VirtualHostedStyle,
+	}
+	if target.Type == settings.S3BackupType {
+		spec.CredentialSecret = &corev1.SecretReference{
+			Namespace: util.LonghornSystemNamespaceName,
+			Name:      util.DefaultBackupTargetSecretName,
+		}
+	}
+
+	defaultTarget, err := h.backupTargetCache.Get(harvesterv1.DefaultBackupTargetName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		_, err = h.backupTargets.Create(&harvesterv1.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: harvesterv1.DefaultBackupTargetName,
+			},
+			Spec: spec,
+		})
+		return err
+	}
+
+	// the encryption isn't in the setting, it's configured on the default target itself
+	spec.Encryption = defaultTarget.Spec.Encryption
+	if reflect.DeepEqual(defaultTarget.Spec, spec) {
+		return nil
+	}
+
+	// the backups in the previous target are not in the new one, sync the metadata again
+	targetCpy := defaultTarget.DeepCopy()
+	targetCpy.Spec = spec
+	targetCpy.Status = harvesterv1.BackupTargetStatus{}
+	_, err = h.backupTargets.Update(targetCpy)
+	return err
+}
+
+func (h *TargetHandler) updateDefaultBackupTargetSecret(target *settings.BackupTarget) error {
+	data := map[string]string{
+		AWSAccessKey: target.AccessKeyID,
+		AWSSecretKey: target.SecretAccessKey,
+		AWSCERT:      target.Cert,
+	}
+	return h.updateDefaultBackupTargetSecretData(data)
+}
+
+func (h *TargetHandler) migrateDefaultBackupTargetSecret() error {
+	if _, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.DefaultBackupTargetSecretName); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	logrus.Infof("copy the credentials of the backup-target setting to secret %s", util.DefaultBackupTargetSecretName)
+	return h.updateDefaultBackupTargetSecretData(map[string]string{
+		AWSAccessKey: string(secret.Data[AWSAccessKey]),
+		AWSSecretKey: string(secret.Data[AWSSecretKey]),
+		AWSCERT:      string(secret.Data[AWSCERT]),
+	})
+}
+
+func (h *TargetHandler) updateDefaultBackupTargetSecretData(data map[string]string) error {
+	secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.DefaultBackupTargetSecretName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		_, err = h.secrets.Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.DefaultBackupTargetSecretName,
+				Namespace: util.LonghornSystemNamespaceName,
+			},
+			StringData: data,
+		})
+		return err
+	}
+
+	secretCpy := secret.DeepCopy()
+	secretCpy.StringData = data
+	_, err = h.secrets.Update(secretCpy)
+	return err
+}
+
 func (h *TargetHandler) reUpdateBackupTargetSettingSecret(setting *harvesterv1.Setting, target *settings.BackupTarget) (*harvesterv1.Setting, error) {
 	// only do a second update when s3 with credentials
 	if target.Type != settings.S3BackupType {
@@ -158,30 +368,317 @@ func (h *TargetHandler) reUpdateBackupTargetSettingSecret(setting *harvesterv1.S
 	return h.settings.Update(settingCpy)
 }
 
-func (h *TargetHandler) updateLonghornTarget(backupTarget *settings.BackupTarget) error {
-	target, err := h.longhornSettingCache.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSettingName)
+// targetActivator points the longhorn backup target at the BackupTarget a backup or restore needs.
+// Longhorn can only use one backup target at a time, so it's only switched to another BackupTarget
+// when no backup or restore is transferring data with the current one.
+// The BackupTarget in use and the time it's claimed are recorded in the annotations of the longhorn setting, which
+// is updated with optimistic concurrency. The target isn't switched within backupTargetClaimHold after it's claimed,
+// so that the claimer has recorded that it uses the target before another one can be activated.
+type targetActivator struct {
+	longhornSettings     ctllonghornv1.SettingClient
+	longhornSettingCache ctllonghornv1.SettingCache
+	secrets              ctlcorev1.SecretClient
+	secretCache          ctlcorev1.SecretCache
+	vmBackups            ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache        ctlharvesterv1.VirtualMachineBackupCache
+	restores             ctlharvesterv1.VirtualMachineRestoreClient
+	pods                 ctlcorev1.PodClient
+	backupTargetCache    ctlharvesterv1.BackupTargetCache
+	lhbackups            ctllonghornv1.BackupClient
+}
+
+func getTargetActivator(management *config.Management) *targetActivator {
+	activatorOnce.Do(func() {
+		longhornSettings := management.LonghornFactory.Longhorn().V1beta1().Setting()
+		secrets := management.CoreFactory.Core().V1().Secret()
+		vmBackups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+		activator = &targetActivator{
+			longhornSettings:     longhornSettings,
+			longhornSettingCache: longhornSettings.Cache(),
+			secrets:              secrets,
+			secretCache:          secrets.Cache(),
+			vmBackups:            vmBackups,
+			vmBackupCache:        vmBackups.Cache(),
+			restores:             management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore(),
+			pods:                 management.CoreFactory.Core().V1().Pod(),
+			backupTargetCache:    management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget().Cache(),
+			lhbackups:            management.LonghornFactory.Longhorn().V1beta1().Backup(),
+		}
+	})
+	return activator
+}
+
+// getLonghornTarget returns the longhorn backup target setting from the cache, or nil if it doesn't exist
+func (a *targetActivator) getLonghornTarget() (*longhornv1.Setting, error) {
+	setting, err := a.longhornSettingCache.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSettingName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return setting, nil
+}
+
+func (a *targetActivator) isActive(target *harvesterv1.BackupTarget) (bool, error) {
+	setting, err := a.getLonghornTarget()
+	if err != nil {
+		return false, err
+	}
+	return isLonghornTargetOf(setting, target), nil
+}
+
+// isLonghornTargetOf checks whether the longhorn backup target is claimed by the BackupTarget with its current spec,
+// the URL alone doesn't identify an S3 target since it only has the bucket and region
+func isLonghornTargetOf(setting *longhornv1.Setting, target *harvesterv1.BackupTarget) bool {
+	return setting != nil && setting.Value != "" && setting.Value == GetBackupTargetURL(target) &&
+		setting.Annotations[backupTargetNameAnnotation] == target.Name &&
+		setting.Annotations[backupTargetIdentityAnnotation] == getBackupTargetIdentity(target)
+}
+
+// isLonghornTargetHeld checks whether the longhorn backup target was claimed within backupTargetClaimHold
+func isLonghornTargetHeld(setting *longhornv1.Setting) bool {
+	if setting == nil || setting.Value == "" {
+		return false
+	}
+	claimTime, err := time.Parse(time.RFC3339, setting.Annotations[backupTargetClaimTimeAnnotation])
+	return err == nil && currentTime().Sub(claimTime) < backupTargetClaimHold
+}
+
+// getBackupTargetIdentity returns a digest of the fields which identify where the data of the BackupTarget are stored
+func getBackupTargetIdentity(target *harvesterv1.BackupTarget) string {
+	identity := fmt.Sprintf("%s\n%s\n%s\n%s\n%t", target.Spec.Type, target.Spec.Endpoint, target.Spec.BucketName,
+		target.Spec.BucketRegion, target.Spec.VirtualHostedStyle)
+	if secret := target.Spec.CredentialSecret; secret != nil {
+		identity = fmt.Sprintf("%s\n%s/%s", identity, secret.Namespace, secret.Name)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(identity)))
+}
+
+// activate returns false if the longhorn backup target is used by the backups or restores of another target,
+// or it's claimed by another target within backupTargetClaimHold.
+// The setting is read from the API server and updated with its resource version, so only one of the concurrent
+// claims of different targets succeeds.
+func (a *targetActivator) activate(target *harvesterv1.BackupTarget) (bool, error) {
+	setting, err := a.longhornSettings.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSettingName, metav1.GetOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return err
+			return false, err
 		}
+		setting = nil
+	}
+	if isLonghornTargetOf(setting, target) {
+		return true, a.renewClaim(setting)
+	}
+	if isLonghornTargetHeld(setting) {
+		return false, nil
+	}
 
-		if _, err := h.longhornSettings.Create(&longhornv1.Setting{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      longhornBackupTargetSettingName,
-				Namespace: util.LonghornSystemNamespaceName,
-			},
-			Value: ConstructEndpoint(backupTarget),
-		}); err != nil {
-			return err
+	inUse, err := a.isUsedByOthers(target)
+	if err != nil || inUse {
+		return false, err
+	}
+
+	logrus.Infof("switch the longhorn backup target to %s", target.Name)
+	backupTarget, err := resolveBackupTarget(a.secretCache, target)
+	if err != nil {
+		return false, err
+	}
+	annotations := map[string]string{
+		backupTargetNameAnnotation:      target.Name,
+		backupTargetIdentityAnnotation:  getBackupTargetIdentity(target),
+		backupTargetClaimTimeAnnotation: currentTime().UTC().Format(time.RFC3339),
+	}
+	if err := a.updateLonghornTarget(setting, ConstructEndpoint(backupTarget), annotations); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+			logrus.Debugf("longhorn backup target is claimed concurrently, %s waits", target.Name)
+			return false, nil
 		}
+		return false, err
+	}
+	if backupTarget.Type == settings.S3BackupType {
+		return true, a.updateBackupTargetSecret(backupTarget)
+	}
+	return true, a.deleteBackupTargetSecret()
+}
+
+func (a *targetActivator) refreshCredentials(target *harvesterv1.BackupTarget) error {
+	if target.Spec.Type != harvesterv1.BackupTargetTypeS3 {
 		return nil
 	}
+	backupTarget, err := resolveBackupTarget(a.secretCache, target)
+	if err != nil {
+		return err
+	}
+	return a.updateBackupTargetSecret(backupTarget)
+}
 
-	targetCpy := target.DeepCopy()
-	targetCpy.Value = ConstructEndpoint(backupTarget)
+// renewClaim extends the claim of the active target, so that it isn't switched while it's still being claimed
+func (a *targetActivator) renewClaim(setting *longhornv1.Setting) error {
+	claimTime, err := time.Parse(time.RFC3339, setting.Annotations[backupTargetClaimTimeAnnotation])
+	if err == nil && currentTime().Sub(claimTime) < backupTargetClaimHold/2 {
+		return nil
+	}
+	settingCpy := setting.DeepCopy()
+	settingCpy.Annotations[backupTargetClaimTimeAnnotation] = currentTime().UTC().Format(time.RFC3339)
+	_, err = a.longhornSettings.Update(settingCpy)
+	return err
+}
 
-	if !reflect.DeepEqual(target, targetCpy) {
-		_, err := h.longhornSettings.Update(targetCpy)
+// reset clears the longhorn backup target if it's still claimed by the target
+func (a *targetActivator) reset(target *harvesterv1.BackupTarget) error {
+	setting, err := a.longhornSettings.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSettingName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !isLonghornTargetOf(setting, target) {
+		return nil
+	}
+	if err := a.updateLonghornTarget(setting, "", nil); err != nil {
+		return err
+	}
+	return a.deleteBackupTargetSecret()
+}
+
+// isUsedByOthers checks the backups, restores and file restores which are transferring data with another target.
+// The objects are listed from the API server since the ones updated by the previous claimer of the target
+// may not be in the cache yet.
+func (a *targetActivator) isUsedByOthers(target *harvesterv1.BackupTarget) (bool, error) {
+	vmBackups, err := a.vmBackups.List(metav1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for i := range vmBackups.Items {
+		vmBackup := &vmBackups.Items[i]
+		if isVMSnapshot(vmBackup) || vmBackup.Status == nil || vmBackup.Status.BackupTarget == nil {
+			continue
+		}
+		if vmBackup.DeletionTimestamp != nil {
+			deleting, err := a.isDeletingFromActiveTarget(vmBackup)
+			if err != nil {
+				return false, err
+			}
+			if deleting {
+				logrus.Debugf("longhorn backup target is used by the removal of vm backup %s/%s", vmBackup.Namespace, vmBackup.Name)
+				return true, nil
+			}
+			continue
+		}
+		if !isBackupTargetClaimed(vmBackup) || GetVMBackupError(vmBackup) != nil {
+			continue
+		}
+		if !IsBackupProgressing(vmBackup) || vmBackup.Status.BackupTarget == nil {
+			continue
+		}
+		if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+			logrus.Debugf("longhorn backup target is used by vm backup %s/%s", vmBackup.Namespace, vmBackup.Name)
+			return true, nil
+		}
+	}
+
+	restores, err := a.restores.List(metav1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for i := range restores.Items {
+		restore := &restores.Items[i]
+		if !isVMRestoreProgressing(restore) || restore.Status == nil || len(restore.Status.VolumeRestores) == 0 {
+			continue
+		}
+		vmBackup, err := a.vmBackupCache.Get(restore.Spec.VirtualMachineBackupNamespace, restore.Spec.VirtualMachineBackupName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if isVMSnapshot(vmBackup) || vmBackup.Status == nil || vmBackup.Status.BackupTarget == nil {
+			continue
+		}
+		if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+			logrus.Debugf("longhorn backup target is used by vm restore %s/%s", restore.Namespace, restore.Name)
+			return true, nil
+		}
+	}
+
+	// longhorn deletes the data of the removed backups from the target in the background
+	lhBackups, err := a.lhbackups.List(util.LonghornSystemNamespaceName, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for i := range lhBackups.Items {
+		if lhBackups.Items[i].DeletionTimestamp != nil {
+			logrus.Debugf("longhorn backup target is used by the removal of longhorn backup %s", lhBackups.Items[i].Name)
+			return true, nil
+		}
+	}
+
+	// the volume of a file restore is restored until the helper pod is ready
+	pods, err := a.pods.List(metav1.NamespaceAll, metav1.ListOptions{LabelSelector: util.LabelFileRestore})
+	if err != nil {
+		return false, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodFailed || isPodReady(pod) {
+			continue
+		}
+		if pod.Annotations[fileRestoreBackupTargetAnnotation] != target.Name {
+			logrus.Debugf("longhorn backup target is used by file restore %s/%s", pod.Namespace, pod.Name)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isDeletingFromActiveTarget checks whether the backup being removed deletes its volume backups from the
+// current longhorn backup target. The removals waiting for their target don't hold the current one.
+func (a *targetActivator) isDeletingFromActiveTarget(vmBackup *harvesterv1.VirtualMachineBackup) (bool, error) {
+	target, err := a.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+		return false, nil
+	}
+	return a.isActive(target)
+}
+
+// updateLonghornTarget sets the longhorn backup target and the annotations of its claim, the setting is created if
+// it's nil, otherwise the update fails with a conflict if it has changed since it was read
+func (a *targetActivator) updateLonghornTarget(setting *longhornv1.Setting, value string, annotations map[string]string) error {
+	if setting == nil {
+		_, err := a.longhornSettings.Create(&longhornv1.Setting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        longhornBackupTargetSettingName,
+				Namespace:   util.LonghornSystemNamespaceName,
+				Annotations: annotations,
+			},
+			Value: value,
+		})
+		return err
+	}
+
+	settingCpy := setting.DeepCopy()
+	settingCpy.Value = value
+	if settingCpy.Annotations == nil {
+		settingCpy.Annotations = map[string]string{}
+	}
+	for _, key := range []string{backupTargetNameAnnotation, backupTargetIdentityAnnotation, backupTargetClaimTimeAnnotation} {
+		delete(settingCpy.Annotations, key)
+	}
+	for key, value := range annotations {
+		settingCpy.Annotations[key] = value
+	}
+
+	if !reflect.DeepEqual(setting, settingCpy) {
+		_, err := a.longhornSettings.Update(settingCpy)
 		return err
 	}
 	return nil
@@ -210,12 +707,12 @@ func getBackupSecretData(target *settings.BackupTarget) (map[string]string, erro
 	return data, nil
 }
 
-func (h *TargetHandler) updateBackupTargetSecret(target *settings.BackupTarget) error {
+func (a *targetActivator) updateBackupTargetSecret(target *settings.BackupTarget) error {
 	backupSecretData, err := getBackupSecretData(target)
 	if err != nil {
 		return err
 	}
-	secret, err := h.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
+	secret, err := a.secretCache.Get(util.LonghornSystemNamespaceName, util.BackupTargetSecretName)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return err
@@ -228,42 +725,42 @@ func (h *TargetHandler) updateBackupTargetSecret(target *settings.BackupTarget)
 			},
 		}
 		newSecret.StringData = backupSecretData
-		if _, err = h.secrets.Create(newSecret); err != nil {
+		if _, err = a.secrets.Create(newSecret); err != nil {
 			return err
 		}
 	} else {
 		secretCpy := secret.DeepCopy()
 		secretCpy.StringData = backupSecretData
 		if !reflect.DeepEqual(secret.StringData, secretCpy.StringData) {
-			if _, err := h.secrets.Update(secretCpy); err != nil {
+			if _, err := a.secrets.Update(secretCpy); err != nil {
 				return err
 			}
 		}
 	}
 
-	return h.updateLonghornBackupTargetSecretSetting(target)
+	return a.updateLonghornBackupTargetSecretSetting()
 }
 
-func (h *TargetHandler) deleteBackupTargetSecret(target *settings.BackupTarget) error {
-	if err := h.secrets.Delete(util.LonghornSystemNamespaceName, util.BackupTargetSecretName, nil); err != nil && !apierrors.IsNotFound(err) {
+func (a *targetActivator) deleteBackupTargetSecret() error {
+	if err := a.secrets.Delete(util.LonghornSystemNamespaceName, util.BackupTargetSecretName, nil); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	if err := h.longhornSettings.Delete(util.LonghornSystemNamespaceName, longhornBackupTargetSecretSettingName, nil); err != nil && !apierrors.IsNotFound(err) {
+	if err := a.longhornSettings.Delete(util.LonghornSystemNamespaceName, longhornBackupTargetSecretSettingName, nil); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
 	return nil
 }
 
-func (h *TargetHandler) updateLonghornBackupTargetSecretSetting(target *settings.BackupTarget) error {
-	targetSecret, err := h.longhornSettingCache.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSecretSettingName)
+func (a *targetActivator) updateLonghornBackupTargetSecretSetting() error {
+	targetSecret, err := a.longhornSettingCache.Get(util.LonghornSystemNamespaceName, longhornBackupTargetSecretSettingName)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return err
 		}
 
-		if _, err := h.longhornSettings.Create(&longhornv1.Setting{
+		if _, err := a.longhornSettings.Create(&longhornv1.Setting{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      longhornBackupTargetSecretSettingName,
 				Namespace: util.LonghornSystemNamespaceName,
@@ -279,7 +776,7 @@ func (h *TargetHandler) updateLonghornBackupTargetSecretSetting(target *settings
 	targetSecCpy.Value = util.BackupTargetSecretName
 
 	if targetSecret.Value != targetSecCpy.Value {
-		if _, err := h.longhornSettings.Update(targetSecCpy); err != nil {
+		if _, err := a.longhornSettings.Update(targetSecCpy); err != nil {
 			return err
 		}
 	}
@@ -287,6 +784,53 @@ func (h *TargetHandler) updateLonghornBackupTargetSecretSetting(target *settings
 	return nil
 }
 
+// toSettingsBackupTarget converts the BackupTarget to the setting format without credentials
+func toSettingsBackupTarget(target *harvesterv1.BackupTarget) *settings.BackupTarget {
+	return &settings.BackupTarget{
+		Type:               settings.TargetType(target.Spec.Type),
+		Endpoint:           target.Spec.Endpoint,
+		BucketName:         target.Spec.BucketName,
+		BucketRegion:       target.Spec.BucketRegion,
+		VirtualHostedStyle: target.Spec.VirtualHostedStyle,
+	}
+}
+
+// resolveBackupTarget converts the BackupTarget to the setting format with the credentials in its secret
+func resolveBackupTarget(secretCache ctlcorev1.SecretCache, target *harvesterv1.BackupTarget) (*settings.BackupTarget, error) {
+	backupTarget := toSettingsBackupTarget(target)
+	if target.Spec.CredentialSecret == nil {
+		return backupTarget, nil
+	}
+
+	secret, err := secretCache.Get(target.Spec.CredentialSecret.Namespace, target.Spec.CredentialSecret.Name)
+	if err != nil {
+		return nil, fmt.Errorf("can't get credential secret of backup target %s: %w", target.Name, err)
+	}
+	backupTarget.AccessKeyID = string(secret.Data[AWSAccessKey])
+	backupTarget.SecretAccessKey = string(secret.Data[AWSSecretKey])
+	backupTarget.Cert = string(secret.Data[AWSCERT])
+	return backupTarget, nil
+}
+
+// GetBackupStoreDriver returns the driver to access the VM backup metadata in the target.
+// Each S3 driver holds the credentials of its own target, so drivers of different targets can be used concurrently.
+func GetBackupStoreDriver(secretCache ctlcorev1.SecretCache, target *harvesterv1.BackupTarget) (backupstore.BackupStoreDriver, error) {
+	backupTarget, err := resolveBackupTarget(secretCache, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if backupTarget.Type == settings.S3BackupType {
+		return newS3Driver(backupTarget)
+	}
+	return backupstore.GetBackupStoreDriver(ConstructEndpoint(backupTarget))
+}
+
+// GetBackupTargetURL returns the longhorn backup target URL of the BackupTarget
+func GetBackupTargetURL(target *harvesterv1.BackupTarget) string {
+	return ConstructEndpoint(toSettingsBackupTarget(target))
+}
+
 func ConstructEndpoint(target *settings.BackupTarget) string {
 	switch target.Type {
 	case settings.S3BackupType:
@@ -0,0 +1,173 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	longhornv1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_GetBackupTargetURL(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		spec     harvesterv1.BackupTargetSpec
+		expected string
+	}{
+		{
+			name: "s3",
+			spec: harvesterv1.BackupTargetSpec{
+				Type:         harvesterv1.BackupTargetTypeS3,
+				Endpoint:     "https://minio.example.com",
+				BucketName:   "backups",
+				BucketRegion: "us-east-1",
+			},
+			expected: "s3://backups@us-east-1/",
+		},
+		{
+			name: "nfs",
+			spec: harvesterv1.BackupTargetSpec{
+				Type:     harvesterv1.BackupTargetTypeNFS,
+				Endpoint: "10.0.0.1:/exports/backups",
+			},
+			expected: "nfs://10.0.0.1:/exports/backups",
+		},
+		{
+			name: "nfs with prefix",
+			spec: harvesterv1.BackupTargetSpec{
+				Type:     harvesterv1.BackupTargetTypeNFS,
+				Endpoint: "nfs://10.0.0.1:/exports/backups",
+			},
+			expected: "nfs://10.0.0.1:/exports/backups",
+		},
+	}
+
+	for _, tc := range testCases {
+		target := &harvesterv1.BackupTarget{Spec: tc.spec}
+		assert.Equal(t, tc.expected, GetBackupTargetURL(target), tc.name)
+	}
+}
+
+func Test_GetBackupTargetName(t *testing.T) {
+	backup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+	}
+	assert.Equal(t, harvesterv1.DefaultBackupTargetName, GetBackupTargetName(backup))
+
+	backup.Spec.BackupTargetName = "offsite"
+	assert.Equal(t, "offsite", GetBackupTargetName(backup))
+}
+
+func Test_isLonghornTargetOf(t *testing.T) {
+	newS3Target := func(name, endpoint, secretName string) *harvesterv1.BackupTarget {
+		return &harvesterv1.BackupTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: harvesterv1.BackupTargetSpec{
+				Type:             harvesterv1.BackupTargetTypeS3,
+				Endpoint:         endpoint,
+				BucketName:       "backups",
+				BucketRegion:     "us-east-1",
+				CredentialSecret: &corev1.SecretReference{Namespace: "longhorn-system", Name: secretName},
+			},
+		}
+	}
+	claimed := newS3Target("offsite", "https://minio.example.com", "offsite-credentials")
+	setting := &longhornv1.Setting{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				backupTargetNameAnnotation:     claimed.Name,
+				backupTargetIdentityAnnotation: getBackupTargetIdentity(claimed),
+			},
+		},
+		Value: GetBackupTargetURL(claimed),
+	}
+
+	var testCases = []struct {
+		name     string
+		setting  *longhornv1.Setting
+		target   *harvesterv1.BackupTarget
+		expected bool
+	}{
+		{
+			name:     "claimed target",
+			setting:  setting,
+			target:   claimed,
+			expected: true,
+		},
+		{
+			name:    "no longhorn setting",
+			target:  claimed,
+			setting: nil,
+		},
+		{
+			name:    "longhorn setting without claim",
+			setting: &longhornv1.Setting{Value: GetBackupTargetURL(claimed)},
+			target:  claimed,
+		},
+		{
+			name:    "another target with the same bucket and region",
+			setting: setting,
+			target:  newS3Target("aws", "https://s3.amazonaws.com", "aws-credentials"),
+		},
+		{
+			name:    "endpoint of the claimed target changed",
+			setting: setting,
+			target:  newS3Target("offsite", "https://minio2.example.com", "offsite-credentials"),
+		},
+		{
+			name:    "credential secret of the claimed target changed",
+			setting: setting,
+			target:  newS3Target("offsite", "https://minio.example.com", "other-credentials"),
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, isLonghornTargetOf(tc.setting, tc.target), tc.name)
+	}
+}
+
+func Test_isLonghornTargetHeld(t *testing.T) {
+	newSetting := func(value string, claimTime time.Time) *longhornv1.Setting {
+		return &longhornv1.Setting{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{backupTargetClaimTimeAnnotation: claimTime.UTC().Format(time.RFC3339)},
+			},
+			Value: value,
+		}
+	}
+
+	var testCases = []struct {
+		name     string
+		setting  *longhornv1.Setting
+		expected bool
+	}{
+		{
+			name: "no longhorn setting",
+		},
+		{
+			name:     "claimed recently",
+			setting:  newSetting("nfs://10.0.0.1:/exports/backups", time.Now().Add(-10*time.Second)),
+			expected: true,
+		},
+		{
+			name:    "claim expired",
+			setting: newSetting("nfs://10.0.0.1:/exports/backups", time.Now().Add(-2*backupTargetClaimHold)),
+		},
+		{
+			name:    "longhorn backup target reset",
+			setting: newSetting("", time.Now()),
+		},
+		{
+			name:    "no claim time",
+			setting: &longhornv1.Setting{Value: "nfs://10.0.0.1:/exports/backups"},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, isLonghornTargetHeld(tc.setting), tc.name)
+	}
+}
@@ -0,0 +1,321 @@
+package backup
+
+// The VM backup metadata and the secret backups of an encrypted backup target are sealed with AES-256-GCM.
+// A sealed value is the random nonce followed by the ciphertext, and the key ID is authenticated as
+// additional data so that a value can't be opened with another key of the same secret.
+// The metadata file keeps the name, the namespace and the key ID in plain, the rest is in EncryptedData,
+// so that a cluster without the key still knows which backup it can't restore.
+//
+// The keys of the target never encrypt the volume data, longhorn uploads the blocks of the volumes as they
+// are stored. The volume data are encrypted at rest only for longhorn encrypted volumes, which are encrypted
+// with the crypto secret of their storage class, so an encrypted target refuses the backups of the other volumes.
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	encryptionKeySize = 32
+
+	backupReasonEncryptionKeyMissing = "EncryptionKeyMissing"
+)
+
+// errEncryptionKeyMissing is returned when the key of an encrypted backup isn't in the key secret
+var errEncryptionKeyMissing = errors.New("encryption key is missing")
+
+// encryptionKeys are the keys of an encrypted backup target
+type encryptionKeys struct {
+	targetName string
+	secretName string
+	activeID   string
+	keys       map[string][]byte
+}
+
+// getEncryptionKeys returns the keys of the backup target, it's nil if the target isn't encrypted
+func getEncryptionKeys(secretCache ctlcorev1.SecretCache, target *harvesterv1.BackupTarget) (*encryptionKeys, error) {
+	encryption := target.Spec.Encryption
+	if encryption == nil {
+		return nil, nil
+	}
+
+	secret, err := secretCache.Get(encryption.KeySecret.Namespace, encryption.KeySecret.Name)
+	if err != nil {
+		return nil, fmt.Errorf("can't get encryption key secret of backup target %s: %w", target.Name, err)
+	}
+	if err := ValidateEncryptionKeys(secret.Data, encryption.ActiveKeyID); err != nil {
+		return nil, fmt.Errorf("invalid encryption key secret %s/%s of backup target %s: %w", secret.Namespace, secret.Name, target.Name, err)
+	}
+	return &encryptionKeys{
+		targetName: target.Name,
+		secretName: secret.Namespace + "/" + secret.Name,
+		activeID:   encryption.ActiveKeyID,
+		keys:       secret.Data,
+	}, nil
+}
+
+// ValidateEncryptionKeys checks the keys in the key secret are AES-256 keys, and the active key is one of them
+func ValidateEncryptionKeys(keys map[string][]byte, activeKeyID string) error {
+	for id, key := range keys {
+		if len(key) != encryptionKeySize {
+			return fmt.Errorf("key %q is %d bytes, the keys must be %d bytes", id, len(key), encryptionKeySize)
+		}
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return fmt.Errorf("active key %q is not in the secret", activeKeyID)
+	}
+	return nil
+}
+
+func (k *encryptionKeys) getAEAD(keyID string) (cipher.AEAD, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: the backup is encrypted with key %q, which is not in secret %s of backup target %s",
+			errEncryptionKeyMissing, keyID, k.secretName, k.targetName)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the data with the active key
+func (k *encryptionKeys) seal(plaintext []byte) ([]byte, error) {
+	aead, err := k.getAEAD(k.activeID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(k.activeID)), nil
+}
+
+// open decrypts the data sealed with the key
+func (k *encryptionKeys) open(keyID string, sealed []byte) ([]byte, error) {
+	aead, err := k.getAEAD(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt with key %q: %w", keyID, err)
+	}
+	return plaintext, nil
+}
+
+// encryptSecretBackups returns the secret backups encrypted with the active key,
+// the secret backups encrypted with another key are decrypted first for key rotation.
+func encryptSecretBackups(keys *encryptionKeys, secretBackups []harvesterv1.SecretBackup) ([]harvesterv1.SecretBackup, error) {
+	plain, err := decryptSecretBackups(keys, secretBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]harvesterv1.SecretBackup, 0, len(plain))
+	for _, secretBackup := range plain {
+		data := make(map[string][]byte, len(secretBackup.Data))
+		for key, value := range secretBackup.Data {
+			if data[key], err = keys.seal(value); err != nil {
+				return nil, err
+			}
+		}
+		encrypted = append(encrypted, harvesterv1.SecretBackup{
+			Name:            secretBackup.Name,
+			Data:            data,
+			EncryptionKeyID: keys.activeID,
+		})
+	}
+	return encrypted, nil
+}
+
+// decryptSecretBackups returns the secret backups with the plain data
+func decryptSecretBackups(keys *encryptionKeys, secretBackups []harvesterv1.SecretBackup) ([]harvesterv1.SecretBackup, error) {
+	decrypted := make([]harvesterv1.SecretBackup, 0, len(secretBackups))
+	for _, secretBackup := range secretBackups {
+		if secretBackup.EncryptionKeyID == "" {
+			decrypted = append(decrypted, secretBackup)
+			continue
+		}
+		if keys == nil {
+			return nil, fmt.Errorf("%w: secret backup %s is encrypted with key %q, but the backup target isn't encrypted",
+				errEncryptionKeyMissing, secretBackup.Name, secretBackup.EncryptionKeyID)
+		}
+
+		data := make(map[string][]byte, len(secretBackup.Data))
+		for key, value := range secretBackup.Data {
+			plaintext, err := keys.open(secretBackup.EncryptionKeyID, value)
+			if err != nil {
+				return nil, err
+			}
+			data[key] = plaintext
+		}
+		decrypted = append(decrypted, harvesterv1.SecretBackup{Name: secretBackup.Name, Data: data})
+	}
+	return decrypted, nil
+}
+
+// encryptBackupMetadata returns the metadata file content encrypted with the active key
+func encryptBackupMetadata(keys *encryptionKeys, metadata *VirtualMachineBackupMetadata) (*VirtualMachineBackupMetadata, error) {
+	plaintext, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := keys.seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &VirtualMachineBackupMetadata{
+		Name:            metadata.Name,
+		Namespace:       metadata.Namespace,
+		EncryptionKeyID: keys.activeID,
+		EncryptedData:   sealed,
+	}, nil
+}
+
+// decryptBackupMetadata returns the plain metadata, the metadata which isn't encrypted is returned as it is
+func decryptBackupMetadata(keys *encryptionKeys, metadata *VirtualMachineBackupMetadata) (*VirtualMachineBackupMetadata, error) {
+	if metadata.EncryptionKeyID == "" {
+		return metadata, nil
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("%w: the backup is encrypted with key %q, but the backup target isn't encrypted", errEncryptionKeyMissing, metadata.EncryptionKeyID)
+	}
+
+	plaintext, err := keys.open(metadata.EncryptionKeyID, metadata.EncryptedData)
+	if err != nil {
+		return nil, err
+	}
+	decrypted := &VirtualMachineBackupMetadata{}
+	if err := json.Unmarshal(plaintext, decrypted); err != nil {
+		return nil, err
+	}
+	return decrypted, nil
+}
+
+// isEncryptionKeyMissing returns true if the backup is synced from a target without the key to decrypt it
+func isEncryptionKeyMissing(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	if vmBackup.Status == nil {
+		return false
+	}
+	for _, c := range vmBackup.Status.Conditions {
+		if c.Type == harvesterv1.BackupConditionReady && c.Reason == backupReasonEncryptionKeyMissing {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVolumeEncryption checks the volumes of the VM are longhorn encrypted volumes if the backup target is encrypted,
+// the keys of the target don't encrypt the volume data.
+func (h *Handler) checkVolumeEncryption(vm *kubevirtv1.VirtualMachine, target *harvesterv1.BackupTarget) error {
+	if target.Spec.Encryption == nil {
+		return nil
+	}
+
+	for _, pvcName := range volumeToPVCMappings(vm.Spec.Template.Spec.Volumes) {
+		pvc, err := h.getBackupPVC(vm.Namespace, pvcName)
+		if err != nil {
+			return err
+		}
+		volume, err := h.volumeCache.Get(util.LonghornSystemNamespaceName, pvc.Spec.VolumeName)
+		if err != nil {
+			return fmt.Errorf("can't get volume of PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+		}
+		if !volume.Spec.Encrypted {
+			return fmt.Errorf("volume of PVC %s/%s is not a longhorn encrypted volume, the keys of backup target %s only encrypt "+
+				"the backup metadata and secrets, so the encrypted target only allows encrypted volumes", pvc.Namespace, pvc.Name, target.Name)
+		}
+	}
+	return nil
+}
+
+// encryptSecretBackups encrypts the secret backups of the VM backup with the active key of the backup target
+func (h *Handler) encryptSecretBackups(vmBackup *harvesterv1.VirtualMachineBackup, target *harvesterv1.BackupTarget) error {
+	keys, err := getEncryptionKeys(h.secretCache, target)
+	if err != nil || keys == nil {
+		return err
+	}
+
+	if vmBackup.Status.SecretBackups, err = encryptSecretBackups(keys, vmBackup.Status.SecretBackups); err != nil {
+		return err
+	}
+	vmBackup.Status.EncryptionKeyID = keys.activeID
+	return nil
+}
+
+// rotateEncryptionKey encrypts the secret backups again if the active key of the backup target is changed,
+// it returns true if the VM backup is updated.
+func (h *Handler) rotateEncryptionKey(vmBackup *harvesterv1.VirtualMachineBackup) (bool, error) {
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if target.Spec.Encryption == nil || target.Spec.Encryption.ActiveKeyID == vmBackup.Status.EncryptionKeyID ||
+		!IsBackupTargetSame(vmBackup.Status.BackupTarget, target) {
+		return false, nil
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	if err := h.encryptSecretBackups(vmBackupCpy, target); err != nil {
+		return false, err
+	}
+	logrus.Infof("rotate encryption key of vm backup %s/%s from %q to %q", vmBackup.Namespace, vmBackup.Name,
+		vmBackup.Status.EncryptionKeyID, vmBackupCpy.Status.EncryptionKeyID)
+	if _, err := h.vmBackups.Update(vmBackupCpy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckBackupDecryptable checks the keys encrypting the VM backup are in the key secret of its backup target
+func CheckBackupDecryptable(secretCache ctlcorev1.SecretCache, target *harvesterv1.BackupTarget, vmBackup *harvesterv1.VirtualMachineBackup) error {
+	if isEncryptionKeyMissing(vmBackup) {
+		return fmt.Errorf("%w: VM backup %s/%s can't be decrypted with the keys of backup target %s",
+			errEncryptionKeyMissing, vmBackup.Namespace, vmBackup.Name, target.Name)
+	}
+	if vmBackup.Status == nil || vmBackup.Status.EncryptionKeyID == "" {
+		return nil
+	}
+
+	keys, err := getEncryptionKeys(secretCache, target)
+	if err != nil {
+		return err
+	}
+	keyIDs := []string{vmBackup.Status.EncryptionKeyID}
+	for _, secretBackup := range vmBackup.Status.SecretBackups {
+		if secretBackup.EncryptionKeyID != "" {
+			keyIDs = append(keyIDs, secretBackup.EncryptionKeyID)
+		}
+	}
+	for _, keyID := range keyIDs {
+		if keys == nil {
+			return fmt.Errorf("%w: VM backup %s/%s is encrypted, but backup target %s isn't encrypted",
+				errEncryptionKeyMissing, vmBackup.Namespace, vmBackup.Name, target.Name)
+		}
+		if _, err := keys.getAEAD(keyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func newTestEncryptionKeys(activeID string, ids ...string) *encryptionKeys {
+	keys := &encryptionKeys{
+		targetName: "target",
+		secretName: "default/keys",
+		activeID:   activeID,
+		keys:       map[string][]byte{},
+	}
+	for i, id := range ids {
+		keys.keys[id] = bytes.Repeat([]byte{byte(i + 1)}, encryptionKeySize)
+	}
+	return keys
+}
+
+func Test_ValidateEncryptionKeys(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		keys        map[string][]byte
+		activeKeyID string
+		expectError bool
+	}{
+		{
+			name:        "valid keys",
+			keys:        map[string][]byte{"k1": make([]byte, 32), "k2": make([]byte, 32)},
+			activeKeyID: "k2",
+		},
+		{
+			name:        "active key isn't in the secret",
+			keys:        map[string][]byte{"k1": make([]byte, 32)},
+			activeKeyID: "k2",
+			expectError: true,
+		},
+		{
+			name:        "key isn't 256 bits",
+			keys:        map[string][]byte{"k1": make([]byte, 32), "k2": make([]byte, 16)},
+			activeKeyID: "k1",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateEncryptionKeys(tc.keys, tc.activeKeyID)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+	}
+}
+
+func Test_encryptBackupMetadata(t *testing.T) {
+	keys := newTestEncryptionKeys("k1", "k1")
+	metadata := &VirtualMachineBackupMetadata{
+		Name:             "backup",
+		Namespace:        "default",
+		ParentBackupName: "parent",
+		SecretBackups:    []harvesterv1.SecretBackup{{Name: "secret", Data: map[string][]byte{"key": []byte("value")}}},
+	}
+
+	encrypted, err := encryptBackupMetadata(keys, metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, "backup", encrypted.Name)
+	assert.Equal(t, "default", encrypted.Namespace)
+	assert.Equal(t, "k1", encrypted.EncryptionKeyID)
+	assert.Empty(t, encrypted.ParentBackupName)
+	assert.Empty(t, encrypted.SecretBackups)
+
+	decrypted, err := decryptBackupMetadata(keys, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, metadata, decrypted)
+
+	// the metadata can't be opened with another key
+	_, err = decryptBackupMetadata(newTestEncryptionKeys("k2", "k2"), encrypted)
+	assert.True(t, errors.Is(err, errEncryptionKeyMissing))
+	_, err = decryptBackupMetadata(nil, encrypted)
+	assert.True(t, errors.Is(err, errEncryptionKeyMissing))
+
+	// the key ID is authenticated, a key with the same ID but different bytes can't open it
+	_, err = decryptBackupMetadata(newTestEncryptionKeys("k1", "k0", "k1"), encrypted)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, errEncryptionKeyMissing))
+}
+
+func Test_encryptSecretBackups(t *testing.T) {
+	plain := []harvesterv1.SecretBackup{{Name: "secret", Data: map[string][]byte{"userdata": []byte("#cloud-config")}}}
+	oldKeys := newTestEncryptionKeys("k1", "k1", "k2")
+	newKeys := newTestEncryptionKeys("k2", "k1", "k2")
+
+	encrypted, err := encryptSecretBackups(oldKeys, plain)
+	assert.Nil(t, err)
+	assert.Equal(t, "k1", encrypted[0].EncryptionKeyID)
+	assert.NotEqual(t, plain[0].Data["userdata"], encrypted[0].Data["userdata"])
+
+	// the secret backups are encrypted again with the rotated key
+	rotated, err := encryptSecretBackups(newKeys, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "k2", rotated[0].EncryptionKeyID)
+
+	decrypted, err := decryptSecretBackups(newKeys, rotated)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	// the secret backups which aren't encrypted are kept as they are
+	decrypted, err = decryptSecretBackups(nil, plain)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	_, err = decryptSecretBackups(nil, rotated)
+	assert.True(t, errors.Is(err, errEncryptionKeyMissing))
+}
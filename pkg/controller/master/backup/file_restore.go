@@ -0,0 +1,429 @@
+package backup
+
+// A file restore restores a volume backup to a PVC and mounts it read-only in a helper pod, the files are browsed
+// and downloaded by the VM backup API running find, cat and tar in the pod. The helper pods and PVCs are created
+// in the harvester system namespace, so the users who can exec into the pods of their own namespaces can't access
+// the privileged helper pods. The API requests a file restore by adding the volume to the file restore request
+// annotation of the VM backup, and the controller restores the volume backup once longhorn points at the backup
+// target of the VM backup. The file restores are removed after an idle timeout, or with the VM backup.
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/pkg/name"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	fileRestoreControllerName = "harvester-file-restore-controller"
+
+	fileRestoreIdleTimeout = 30 * time.Minute
+
+	// FileRestoreContainerName is the container of the helper pod running the commands of the API
+	FileRestoreContainerName = "file-restore"
+	// FileRestoreRoot is the directory the partitions of the volume backup are mounted to in the helper pod
+	FileRestoreRoot = "/mnt/files"
+
+	fileRestoreDevicePath = "/dev/backup"
+	fileRestoreReadyFile  = "/tmp/ready"
+
+	// fileRestoreBackupTargetAnnotation is the BackupTarget the helper pod restores the volume backup from
+	fileRestoreBackupTargetAnnotation = "harvesterhci.io/fileRestoreBackupTarget"
+)
+
+// fileRestoreScript mounts the partitions of the volume read-only, the journals aren't replayed to keep
+// the data as it's backed up.
+var fileRestoreScript = fmt.Sprintf(`
+root=%[1]s
+device=%[2]s
+mount_ro() {
+	mkdir -p "$3"
+	for opts in ro,noload ro,norecovery ro; do
+		mount -o "$opts$1" "$2" "$3" 2>/dev/null && return 0
+	done
+	rmdir "$3"
+	return 1
+}
+if [ -b "$device" ]; then
+	n=0
+	for start in $(sfdisk -d "$device" 2>/dev/null | sed -n 's/.*start= *\([0-9]*\).*/\1/p'); do
+		n=$((n+1))
+		mount_ro ",loop,offset=$((start*512))" "$device" "$root/partition$n" || echo "failed to mount partition $n"
+	done
+	if [ "$n" -eq 0 ]; then
+		mount_ro "" "$device" "$root/disk" || echo "failed to mount the disk"
+	fi
+fi
+touch %[3]s
+exec sleep infinity
+`, FileRestoreRoot, fileRestoreDevicePath, fileRestoreReadyFile)
+
+// RegisterFileRestore register the controller restoring the volume backups requested by the VM backup API for
+// browsing, and cleaning up the idle file restores.
+func RegisterFileRestore(ctx context.Context, management *config.Management, opts config.Options) error {
+	pods := management.CoreFactory.Core().V1().Pod()
+	pvcs := management.CoreFactory.Core().V1().PersistentVolumeClaim()
+	vmBackups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+	snapshots := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshot()
+	snapshotContents := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshotContent()
+	lhbackups := management.LonghornFactory.Longhorn().V1beta1().Backup()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
+
+	handler := &FileRestoreHandler{
+		namespace:          opts.Namespace,
+		pods:               pods,
+		podCache:           pods.Cache(),
+		podController:      pods,
+		pvcs:               pvcs,
+		vmBackups:          vmBackups,
+		vmBackupCache:      vmBackups.Cache(),
+		vmBackupController: vmBackups,
+		snapshots:          snapshots,
+		snapshotContents:   snapshotContents,
+		backupTargetCache:  backupTargets.Cache(),
+		activator:          getTargetActivator(management),
+		snapshotRestorer: &volumeSnapshotRestorer{
+			snapshots:            snapshots,
+			snapshotCache:        snapshots.Cache(),
+			snapshotContents:     snapshotContents,
+			snapshotContentCache: snapshotContents.Cache(),
+			lhbackupCache:        lhbackups.Cache(),
+		},
+	}
+
+	vmBackups.OnChange(ctx, fileRestoreControllerName, handler.OnBackupChange)
+	vmBackups.OnRemove(ctx, fileRestoreControllerName, handler.OnBackupRemove)
+	pods.OnChange(ctx, fileRestoreControllerName, handler.OnPodChange)
+	return nil
+}
+
+type FileRestoreHandler struct {
+	namespace          string
+	pods               ctlcorev1.PodClient
+	podCache           ctlcorev1.PodCache
+	podController      ctlcorev1.PodController
+	pvcs               ctlcorev1.PersistentVolumeClaimClient
+	vmBackups          ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache      ctlharvesterv1.VirtualMachineBackupCache
+	vmBackupController ctlharvesterv1.VirtualMachineBackupController
+	snapshots          ctlsnapshotv1.VolumeSnapshotClient
+	snapshotContents   ctlsnapshotv1.VolumeSnapshotContentClient
+	backupTargetCache  ctlharvesterv1.BackupTargetCache
+	activator          *targetActivator
+	snapshotRestorer   *volumeSnapshotRestorer
+}
+
+// GetFileRestoreName returns the name of the helper pod, the PVC and the VolumeSnapshot of a file restore
+func GetFileRestoreName(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) string {
+	return name.SafeConcatName("file-restore", vmBackup.Namespace, vmBackup.Name, volumeName)
+}
+
+// GetFileRestoreRequest returns the volumes of the VM backup requested to restore for browsing
+func GetFileRestoreRequest(vmBackup *harvesterv1.VirtualMachineBackup) ([]string, error) {
+	value, ok := vmBackup.Annotations[util.AnnotationFileRestoreRequest]
+	if !ok {
+		return nil, nil
+	}
+	var volumeNames []string
+	if err := json.Unmarshal([]byte(value), &volumeNames); err != nil {
+		return nil, fmt.Errorf("failed to decode the file restore request of vm backup %s/%s: %w", vmBackup.Namespace, vmBackup.Name, err)
+	}
+	return volumeNames, nil
+}
+
+// SetFileRestoreRequest sets the volumes requested to restore for browsing, the caller updates the backup
+func SetFileRestoreRequest(vmBackup *harvesterv1.VirtualMachineBackup, volumeNames []string) error {
+	if len(volumeNames) == 0 {
+		delete(vmBackup.Annotations, util.AnnotationFileRestoreRequest)
+		return nil
+	}
+	value, err := json.Marshal(volumeNames)
+	if err != nil {
+		return err
+	}
+	if vmBackup.Annotations == nil {
+		vmBackup.Annotations = map[string]string{}
+	}
+	vmBackup.Annotations[util.AnnotationFileRestoreRequest] = string(value)
+	return nil
+}
+
+// OnBackupChange creates the helper pods of the requested volumes
+func (h *FileRestoreHandler) OnBackupChange(key string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	if vmBackup == nil || vmBackup.DeletionTimestamp != nil || vmBackup.Status == nil {
+		return nil, nil
+	}
+
+	volumeNames, err := GetFileRestoreRequest(vmBackup)
+	if err != nil || len(volumeNames) == 0 {
+		return nil, err
+	}
+	for _, volumeName := range volumeNames {
+		if err := h.restore(vmBackup, volumeName); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// OnBackupRemove removes the file restores of the VM backup, they can't be owned by the VM backup in another namespace
+func (h *FileRestoreHandler) OnBackupRemove(key string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	if vmBackup == nil {
+		return nil, nil
+	}
+	pods, err := h.podCache.List(h.namespace, labels.SelectorFromSet(labels.Set{
+		util.LabelFileRestore:          vmBackup.Name,
+		util.LabelFileRestoreNamespace: vmBackup.Namespace,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if err := h.cleanup(pod); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// OnPodChange removes a file restore once it's idle for the timeout, a failed helper pod is removed immediately
+// so that the next request recreates it.
+func (h *FileRestoreHandler) OnPodChange(key string, pod *corev1.Pod) (*corev1.Pod, error) {
+	if pod == nil || pod.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if _, ok := pod.Labels[util.LabelFileRestore]; !ok {
+		return nil, nil
+	}
+
+	if pod.Status.Phase != corev1.PodFailed && pod.Status.Phase != corev1.PodSucceeded {
+		if idle := getFileRestoreIdleTime(pod, time.Now()); idle < fileRestoreIdleTimeout {
+			h.podController.EnqueueAfter(pod.Namespace, pod.Name, fileRestoreIdleTimeout-idle)
+			return nil, nil
+		}
+	}
+
+	if err := h.removeRequest(pod); err != nil {
+		return nil, err
+	}
+	return nil, h.cleanup(pod)
+}
+
+func getFileRestoreIdleTime(pod *corev1.Pod, now time.Time) time.Duration {
+	accessed := pod.CreationTimestamp.Time
+	if t, err := time.Parse(time.RFC3339, pod.Annotations[util.AnnotationFileRestoreAccessed]); err == nil {
+		accessed = t
+	}
+	return now.Sub(accessed)
+}
+
+// isPodReady returns whether the helper pod has mounted the volume backup
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func getVolumeBackup(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) *harvesterv1.VolumeBackup {
+	for i, vb := range vmBackup.Status.VolumeBackups {
+		if vb.VolumeName == volumeName && vb.Name != nil && vb.ReadyToUse != nil && *vb.ReadyToUse {
+			return &vmBackup.Status.VolumeBackups[i]
+		}
+	}
+	return nil
+}
+
+// restore creates the VolumeSnapshot, the PVC and the helper pod of the volume backup, the longhorn backup target
+// is claimed while they're created so that it isn't switched to another target before the helper pod is found by
+// the activator.
+func (h *FileRestoreHandler) restore(vmBackup *harvesterv1.VirtualMachineBackup, volumeName string) error {
+	restoreName := GetFileRestoreName(vmBackup, volumeName)
+	if _, err := h.podCache.Get(h.namespace, restoreName); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+	volumeBackup := getVolumeBackup(vmBackup, volumeName)
+	if volumeBackup == nil {
+		logrus.Warnf("backup of volume %s in VM backup %s/%s is not ready for file restore", volumeName, vmBackup.Namespace, vmBackup.Name)
+		return nil
+	}
+
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(vmBackup))
+	if err != nil {
+		return fmt.Errorf("can't get backup target %s: %w", GetBackupTargetName(vmBackup), err)
+	}
+
+	activated, err := h.activator.activate(target)
+	if err != nil {
+		return err
+	}
+	if !activated {
+		logrus.Infof("file restore of VM backup %s/%s waits for longhorn to switch to backup target %s", vmBackup.Namespace, vmBackup.Name, target.Name)
+		h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, waitBackupTargetInterval)
+		return nil
+	}
+
+	meta := metav1.ObjectMeta{
+		Name:      restoreName,
+		Namespace: h.namespace,
+		Labels: map[string]string{
+			util.LabelFileRestore:          vmBackup.Name,
+			util.LabelFileRestoreNamespace: vmBackup.Namespace,
+		},
+		Annotations: map[string]string{
+			util.AnnotationFileRestoreVolume:  volumeName,
+			fileRestoreBackupTargetAnnotation: target.Name,
+		},
+	}
+	volumeSnapshot, err := h.snapshotRestorer.getOrCreateVolumeSnapshot(metav1.ObjectMeta{
+		Name:      restoreName,
+		Namespace: h.namespace,
+		Labels:    meta.Labels,
+	}, restoreName, *volumeBackup)
+	if err != nil {
+		return err
+	}
+	if err := h.createPVC(meta, volumeBackup, volumeSnapshot.Name); err != nil {
+		return err
+	}
+	return h.createPod(meta, volumeBackup)
+}
+
+func (h *FileRestoreHandler) createPVC(meta metav1.ObjectMeta, volumeBackup *harvesterv1.VolumeBackup, volumeSnapshotName string) error {
+	spec := *volumeBackup.PersistentVolumeClaim.Spec.DeepCopy()
+	spec.VolumeName = ""
+	spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: pointer.StringPtr(snapshotv1.SchemeGroupVersion.Group),
+		Kind:     volumeSnapshotKindName,
+		Name:     volumeSnapshotName,
+	}
+	_, err := h.pvcs.Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: meta,
+		Spec:       spec,
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (h *FileRestoreHandler) createPod(meta metav1.ObjectMeta, volumeBackup *harvesterv1.VolumeBackup) error {
+	image, pullPolicy, err := settings.GetFileRestoreImage()
+	if err != nil {
+		return err
+	}
+
+	container := corev1.Container{
+		Name:            FileRestoreContainerName,
+		Image:           image,
+		ImagePullPolicy: pullPolicy,
+		Command:         []string{"/bin/sh", "-c", fileRestoreScript},
+		SecurityContext: &corev1.SecurityContext{
+			// mounting the partitions requires the loop devices
+			Privileged: pointer.BoolPtr(true),
+		},
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{Command: []string{"test", "-f", fileRestoreReadyFile}},
+			},
+			PeriodSeconds: 2,
+		},
+	}
+	volumeMode := volumeBackup.PersistentVolumeClaim.Spec.VolumeMode
+	if volumeMode != nil && *volumeMode == corev1.PersistentVolumeBlock {
+		container.VolumeDevices = []corev1.VolumeDevice{{Name: "backup", DevicePath: fileRestoreDevicePath}}
+	} else {
+		container.VolumeMounts = []corev1.VolumeMount{{Name: "backup", MountPath: FileRestoreRoot + "/volume", ReadOnly: true}}
+	}
+
+	podMeta := *meta.DeepCopy()
+	podMeta.Annotations[util.AnnotationFileRestoreAccessed] = time.Now().UTC().Format(time.RFC3339)
+	_, err = h.pods.Create(&corev1.Pod{
+		ObjectMeta: podMeta,
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: pointer.BoolPtr(false),
+			RestartPolicy:                corev1.RestartPolicyNever,
+			Containers:                   []corev1.Container{container},
+			Volumes: []corev1.Volume{{
+				Name: "backup",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: meta.Name, ReadOnly: true},
+				},
+			}},
+		},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// removeRequest removes the volume of the helper pod from the file restore request of the VM backup,
+// so that the file restore isn't recreated until it's requested again.
+func (h *FileRestoreHandler) removeRequest(pod *corev1.Pod) error {
+	vmBackup, err := h.vmBackupCache.Get(pod.Labels[util.LabelFileRestoreNamespace], pod.Labels[util.LabelFileRestore])
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	volumeNames, err := GetFileRestoreRequest(vmBackup)
+	if err != nil {
+		return err
+	}
+
+	var remaining []string
+	for _, volumeName := range volumeNames {
+		if volumeName != pod.Annotations[util.AnnotationFileRestoreVolume] {
+			remaining = append(remaining, volumeName)
+		}
+	}
+	if len(remaining) == len(volumeNames) {
+		return nil
+	}
+	vmBackupCpy := vmBackup.DeepCopy()
+	if err := SetFileRestoreRequest(vmBackupCpy, remaining); err != nil {
+		return err
+	}
+	_, err = h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// cleanup removes the helper pod, the PVC, the VolumeSnapshot and the retained VolumeSnapshotContent of a file restore,
+// they have the same name.
+func (h *FileRestoreHandler) cleanup(pod *corev1.Pod) error {
+	logrus.Infof("clean up file restore %s/%s", pod.Namespace, pod.Name)
+	if err := h.pods.Delete(pod.Namespace, pod.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := h.pvcs.Delete(pod.Namespace, pod.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := h.snapshots.Delete(pod.Namespace, pod.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := h.snapshotContents.Delete(pod.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
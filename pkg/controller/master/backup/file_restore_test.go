@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_fileRestoreRequest(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		annotations map[string]string
+		volumeNames []string
+		expected    map[string]string
+	}{
+		{
+			name:        "add the first volume",
+			volumeNames: []string{"disk-0"},
+			expected:    map[string]string{util.AnnotationFileRestoreRequest: `["disk-0"]`},
+		},
+		{
+			name:        "add another volume",
+			annotations: map[string]string{util.AnnotationFileRestoreRequest: `["disk-0"]`},
+			volumeNames: []string{"disk-0", "disk-1"},
+			expected:    map[string]string{util.AnnotationFileRestoreRequest: `["disk-0","disk-1"]`},
+		},
+		{
+			name:        "remove the last volume",
+			annotations: map[string]string{util.AnnotationFileRestoreRequest: `["disk-0"]`, "foo": "bar"},
+			expected:    map[string]string{"foo": "bar"},
+		},
+	}
+
+	for _, tc := range testCases {
+		vmBackup := &harvesterv1.VirtualMachineBackup{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		assert.Nil(t, SetFileRestoreRequest(vmBackup, tc.volumeNames), tc.name)
+		assert.Equal(t, tc.expected, vmBackup.Annotations, tc.name)
+
+		volumeNames, err := GetFileRestoreRequest(vmBackup)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.volumeNames, volumeNames, tc.name)
+	}
+
+	_, err := GetFileRestoreRequest(&harvesterv1.VirtualMachineBackup{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{util.AnnotationFileRestoreRequest: "disk-0"},
+	}})
+	assert.NotNil(t, err)
+}
+
+func Test_getFileRestoreIdleTime(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-time.Hour))
+
+	var testCases = []struct {
+		name     string
+		accessed string
+		expected time.Duration
+	}{
+		{
+			name:     "accessed recently",
+			accessed: now.Add(-5 * time.Minute).Format(time.RFC3339),
+			expected: 5 * time.Minute,
+		},
+		{
+			name:     "never accessed",
+			expected: time.Hour,
+		},
+		{
+			name:     "invalid access time",
+			accessed: "yesterday",
+			expected: time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: created,
+			Annotations:       map[string]string{},
+		}}
+		if tc.accessed != "" {
+			pod.Annotations[util.AnnotationFileRestoreAccessed] = tc.accessed
+		}
+		assert.Equal(t, tc.expected, getFileRestoreIdleTime(pod, now), tc.name)
+	}
+}
+
+func Test_getFileRestoreName(t *testing.T) {
+	vmBackup := func(namespace, name string) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	// the helper pods of the backups in different namespaces are in the same system namespace
+	assert.NotEqual(t, GetFileRestoreName(vmBackup("ns1", "backup"), "disk-0"), GetFileRestoreName(vmBackup("ns2", "backup"), "disk-0"))
+	assert.Equal(t, "file-restore-default-backup-disk-0", GetFileRestoreName(vmBackup("default", "backup"), "disk-0"))
+}
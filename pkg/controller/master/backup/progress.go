@@ -0,0 +1,238 @@
+package backup
+
+// The volume data of VM backups and restores are transferred by longhorn, which only reports a percentage:
+// the longhorn backup CR has the progress of uploading a volume, and the engine of a volume has the progress
+// of restoring it from every replica. The percentages are turned into bytes with the volume sizes,
+// the throughput is averaged since the transfer started and the completion time is extrapolated from it.
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	lhv1beta1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	lhtypes "github.com/longhorn/longhorn-manager/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// newTransferProgress returns the progress of transferring percentage of total bytes,
+// the start time is kept from the last progress of the same transfer.
+func newTransferProgress(last *harvesterv1.TransferProgress, percentage int, totalBytes int64, now *metav1.Time) *harvesterv1.TransferProgress {
+	if percentage < 0 {
+		percentage = 0
+	} else if percentage > 100 {
+		percentage = 100
+	}
+
+	progress := &harvesterv1.TransferProgress{
+		Percentage:       percentage,
+		BytesTransferred: totalBytes * int64(percentage) / 100,
+		TotalBytes:       totalBytes,
+		StartTime:        now,
+	}
+	if last != nil && last.StartTime != nil {
+		progress.StartTime = last.StartTime
+	}
+	if last != nil && last.Percentage == percentage && last.TotalBytes == totalBytes {
+		// keep the throughput and estimation until longhorn reports the next percentage
+		progress.Throughput = last.Throughput
+		progress.EstimatedCompletionTime = last.EstimatedCompletionTime
+		return progress
+	}
+	setThroughput(progress, now)
+	return progress
+}
+
+func setThroughput(progress *harvesterv1.TransferProgress, now *metav1.Time) {
+	progress.Throughput, progress.EstimatedCompletionTime = 0, nil
+	elapsed := now.Sub(progress.StartTime.Time).Seconds()
+	if elapsed <= 0 || progress.BytesTransferred == 0 {
+		return
+	}
+
+	progress.Throughput = int64(float64(progress.BytesTransferred) / elapsed)
+	if progress.Throughput == 0 {
+		return
+	}
+	remaining := time.Duration(math.Ceil(float64(progress.TotalBytes-progress.BytesTransferred)/float64(progress.Throughput))) * time.Second
+	progress.EstimatedCompletionTime = &metav1.Time{Time: now.Add(remaining)}
+}
+
+// aggregateProgress returns the overall progress of the volumes, the volumes are transferred in parallel
+func aggregateProgress(progresses []*harvesterv1.TransferProgress, now *metav1.Time) *harvesterv1.TransferProgress {
+	var aggregated *harvesterv1.TransferProgress
+	for _, progress := range progresses {
+		if progress == nil {
+			continue
+		}
+		if aggregated == nil {
+			aggregated = &harvesterv1.TransferProgress{StartTime: progress.StartTime}
+		}
+		aggregated.BytesTransferred += progress.BytesTransferred
+		aggregated.TotalBytes += progress.TotalBytes
+		if progress.StartTime != nil && (aggregated.StartTime == nil || progress.StartTime.Before(aggregated.StartTime)) {
+			aggregated.StartTime = progress.StartTime
+		}
+	}
+	if aggregated == nil {
+		return nil
+	}
+
+	if aggregated.TotalBytes > 0 {
+		aggregated.Percentage = int(aggregated.BytesTransferred * 100 / aggregated.TotalBytes)
+	}
+	if aggregated.StartTime != nil {
+		setThroughput(aggregated, now)
+	}
+	return aggregated
+}
+
+// getBackupProgress returns the progress of uploading a volume from its longhorn backup
+func getBackupProgress(last *harvesterv1.TransferProgress, lhBackup *lhv1beta1.Backup, now *metav1.Time) *harvesterv1.TransferProgress {
+	volumeSize, _ := strconv.ParseInt(lhBackup.Status.VolumeSize, 10, 64)
+	percentage := lhBackup.Status.Progress
+	if lhBackup.Status.State == lhv1beta1.BackupStateCompleted {
+		percentage = 100
+	}
+	return newTransferProgress(last, percentage, volumeSize, now)
+}
+
+// updateBackupProgress sets the progress of the volume backups and the overall progress of the VM backup
+func updateBackupProgress(vmBackup *harvesterv1.VirtualMachineBackup, volumeBackupName string, lhBackup *lhv1beta1.Backup) {
+	now := currentTime()
+	changed := false
+	var progresses []*harvesterv1.TransferProgress
+	for i, vb := range vmBackup.Status.VolumeBackups {
+		if vb.Name != nil && *vb.Name == volumeBackupName {
+			progress := getBackupProgress(vb.Progress, lhBackup, now)
+			if !reflect.DeepEqual(vb.Progress, progress) {
+				vmBackup.Status.VolumeBackups[i].Progress = progress
+				changed = true
+			}
+		}
+		progresses = append(progresses, vmBackup.Status.VolumeBackups[i].Progress)
+	}
+	// longhorn resyncs the backups periodically, don't update the VM backup if nothing is transferred
+	if changed {
+		vmBackup.Status.Progress = aggregateProgress(progresses, now)
+	}
+}
+
+// getRestorePercentage returns the restore progress of the slowest replica, the volume is restored
+// once all the replicas are restored.
+func getRestorePercentage(engine *lhv1beta1.Engine) (int, bool) {
+	percentage, restoring := 100, false
+	for _, status := range engine.Status.RestoreStatus {
+		if status == nil {
+			continue
+		}
+		restoring = true
+		if status.Progress < percentage {
+			percentage = status.Progress
+		}
+	}
+	return percentage, restoring
+}
+
+// syncRestoreProgress sets the progress of the volume restores and the overall progress of the VM restore
+func (h *RestoreHandler) syncRestoreProgress(vmRestore *harvesterv1.VirtualMachineRestore) error {
+	now := currentTime()
+	changed := false
+	var progresses []*harvesterv1.TransferProgress
+	for i, vr := range vmRestore.Status.VolumeRestores {
+		progress, err := h.getRestoreProgress(vmRestore.Namespace, vr, now)
+		if err != nil {
+			return err
+		}
+		if progress != nil && !reflect.DeepEqual(vr.Progress, progress) {
+			vmRestore.Status.VolumeRestores[i].Progress = progress
+			changed = true
+		}
+		progresses = append(progresses, vmRestore.Status.VolumeRestores[i].Progress)
+	}
+	if changed {
+		vmRestore.Status.Progress = aggregateProgress(progresses, now)
+	}
+	return nil
+}
+
+// getRestoreProgress returns the progress of restoring a volume, it's nil if the restore doesn't start
+func (h *RestoreHandler) getRestoreProgress(namespace string, volumeRestore harvesterv1.VolumeRestore, now *metav1.Time) (*harvesterv1.TransferProgress, error) {
+	last := volumeRestore.Progress
+	if last != nil && last.Percentage == 100 {
+		return last, nil
+	}
+
+	pvc, err := h.pvcCache.Get(namespace, volumeRestore.PersistentVolumeClaim.ObjectMeta.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil, nil
+	}
+
+	volume, err := h.volumeCache.Get(util.LonghornSystemNamespaceName, pvc.Spec.VolumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !volume.Status.RestoreRequired {
+		if !volume.Status.RestoreInitiated {
+			return nil, nil
+		}
+		return newTransferProgress(last, 100, volume.Spec.Size, now), nil
+	}
+
+	engines, err := h.engineCache.List(util.LonghornSystemNamespaceName, labels.SelectorFromSet(lhtypes.GetVolumeLabels(volume.Name)))
+	if err != nil {
+		return nil, err
+	}
+	for _, engine := range engines {
+		if percentage, restoring := getRestorePercentage(engine); restoring {
+			return newTransferProgress(last, percentage, volume.Spec.Size, now), nil
+		}
+	}
+	return newTransferProgress(last, 0, volume.Spec.Size, now), nil
+}
+
+// EngineOnChange enqueues the VM restore of the volume being restored by the engine
+func (h *RestoreHandler) EngineOnChange(key string, engine *lhv1beta1.Engine) (*lhv1beta1.Engine, error) {
+	if engine == nil || engine.DeletionTimestamp != nil || len(engine.Status.RestoreStatus) == 0 {
+		return nil, nil
+	}
+
+	volume, err := h.volumeCache.Get(util.LonghornSystemNamespaceName, engine.Spec.VolumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	kubeStatus := volume.Status.KubernetesStatus
+	if kubeStatus.Namespace == "" || kubeStatus.PVCName == "" {
+		return nil, nil
+	}
+
+	pvc, err := h.pvcCache.Get(kubeStatus.Namespace, kubeStatus.PVCName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if restoreName, ok := pvc.Annotations[restoreNameAnnotation]; ok {
+		h.restoreController.Enqueue(pvc.Namespace, restoreName)
+	}
+	return nil, nil
+}
@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	lhv1beta1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_newTransferProgress(t *testing.T) {
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(start.Add(100 * time.Second))
+	const gb = int64(1000 * 1000 * 1000)
+
+	var testCases = []struct {
+		name       string
+		last       *harvesterv1.TransferProgress
+		percentage int
+		expected   *harvesterv1.TransferProgress
+	}{
+		{
+			name:       "transfer starts",
+			percentage: 0,
+			expected:   &harvesterv1.TransferProgress{TotalBytes: 10 * gb, StartTime: &now},
+		},
+		{
+			name:       "transfer in progress",
+			last:       &harvesterv1.TransferProgress{TotalBytes: 10 * gb, StartTime: &start},
+			percentage: 20,
+			expected: &harvesterv1.TransferProgress{
+				Percentage:              20,
+				BytesTransferred:        2 * gb,
+				TotalBytes:              10 * gb,
+				Throughput:              2 * gb / 100,
+				StartTime:               &start,
+				EstimatedCompletionTime: &metav1.Time{Time: now.Add(400 * time.Second)},
+			},
+		},
+		{
+			name: "keep the estimation until the next percentage",
+			last: &harvesterv1.TransferProgress{
+				Percentage:              20,
+				BytesTransferred:        2 * gb,
+				TotalBytes:              10 * gb,
+				Throughput:              gb,
+				StartTime:               &start,
+				EstimatedCompletionTime: &start,
+			},
+			percentage: 20,
+			expected: &harvesterv1.TransferProgress{
+				Percentage:              20,
+				BytesTransferred:        2 * gb,
+				TotalBytes:              10 * gb,
+				Throughput:              gb,
+				StartTime:               &start,
+				EstimatedCompletionTime: &start,
+			},
+		},
+		{
+			name:       "transfer completes",
+			last:       &harvesterv1.TransferProgress{TotalBytes: 10 * gb, StartTime: &start},
+			percentage: 100,
+			expected: &harvesterv1.TransferProgress{
+				Percentage:              100,
+				BytesTransferred:        10 * gb,
+				TotalBytes:              10 * gb,
+				Throughput:              10 * gb / 100,
+				StartTime:               &start,
+				EstimatedCompletionTime: &now,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, newTransferProgress(tc.last, tc.percentage, 10*gb, &now), tc.name)
+	}
+}
+
+func Test_aggregateProgress(t *testing.T) {
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(start.Add(50 * time.Second))
+	now := metav1.NewTime(start.Add(100 * time.Second))
+
+	assert.Nil(t, aggregateProgress([]*harvesterv1.TransferProgress{nil, nil}, &now))
+
+	aggregated := aggregateProgress([]*harvesterv1.TransferProgress{
+		{Percentage: 50, BytesTransferred: 500, TotalBytes: 1000, StartTime: &later},
+		nil,
+		{Percentage: 100, BytesTransferred: 3000, TotalBytes: 3000, StartTime: &start},
+	}, &now)
+	assert.Equal(t, &harvesterv1.TransferProgress{
+		Percentage:              87,
+		BytesTransferred:        3500,
+		TotalBytes:              4000,
+		Throughput:              35,
+		StartTime:               &start,
+		EstimatedCompletionTime: &metav1.Time{Time: now.Add(15 * time.Second)},
+	}, aggregated)
+}
+
+func Test_getRestorePercentage(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		restoreStatus map[string]*lhv1beta1.RestoreStatus
+		percentage    int
+		restoring     bool
+	}{
+		{
+			name:       "not restoring",
+			percentage: 100,
+		},
+		{
+			name: "slowest replica",
+			restoreStatus: map[string]*lhv1beta1.RestoreStatus{
+				"tcp://10.52.0.10:10000": {IsRestoring: true, Progress: 60},
+				"tcp://10.52.1.10:10000": {IsRestoring: true, Progress: 40},
+			},
+			percentage: 40,
+			restoring:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		engine := &lhv1beta1.Engine{Status: lhv1beta1.EngineStatus{RestoreStatus: tc.restoreStatus}}
+		percentage, restoring := getRestorePercentage(engine)
+		assert.Equal(t, tc.percentage, percentage, tc.name)
+		assert.Equal(t, tc.restoring, restoring, tc.name)
+	}
+}
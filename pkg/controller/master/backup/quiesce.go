@@ -0,0 +1,426 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+)
+
+const (
+	defaultFreezeTimeout = 5 * time.Minute
+	defaultHookTimeout   = time.Minute
+
+	quiescedReasonFrozen        = "Frozen"
+	quiescedReasonThawed        = "Thawed"
+	quiescedReasonNotRunning    = "VMNotRunning"
+	quiescedReasonAgentMissing  = "AgentNotConnected"
+	quiescedReasonFreezeFailed  = "FreezeFailed"
+	quiescedReasonFreezeTimeout = "FreezeTimeout"
+	quiescedReasonPreHookFailed = "PreHookFailed"
+
+	preHookName  = "preHook"
+	postHookName = "postHook"
+
+	virtLauncherComputeContainer = "compute"
+	guestExecPollInterval        = time.Second
+)
+
+var kubevirtSubresourceGroupVersion = k8sschema.GroupVersion{Group: "subresources.kubevirt.io", Version: "v1"}
+
+// guestQuiescer freezes and thaws the guest filesystems through the KubeVirt freeze/unfreeze subresources,
+// and runs hook commands in the guest through the qemu guest agent of the virt-launcher pod.
+type guestQuiescer struct {
+	restConfig            *rest.Config
+	clientSet             kubernetes.Interface
+	virtSubresourceClient rest.Interface
+	podCache              ctlcorev1.PodCache
+}
+
+func newGuestQuiescer(restConfig *rest.Config, clientSet kubernetes.Interface, podCache ctlcorev1.PodCache) (*guestQuiescer, error) {
+	copyConfig := rest.CopyConfig(restConfig)
+	copyConfig.GroupVersion = &kubevirtSubresourceGroupVersion
+	copyConfig.APIPath = "/apis"
+	copyConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	virtSubresourceClient, err := rest.RESTClientFor(copyConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &guestQuiescer{
+		restConfig:            restConfig,
+		clientSet:             clientSet,
+		virtSubresourceClient: virtSubresourceClient,
+		podCache:              podCache,
+	}, nil
+}
+
+func (q *guestQuiescer) freeze(vmi *kubevirtv1.VirtualMachineInstance, timeout time.Duration) error {
+	body, err := json.Marshal(&kubevirtv1.FreezeUnfreezeTimeout{
+		UnfreezeTimeout: &metav1.Duration{Duration: timeout},
+	})
+	if err != nil {
+		return err
+	}
+	return q.virtSubresourceClient.Put().Namespace(vmi.Namespace).Resource("virtualmachineinstances").
+		SubResource("freeze").Name(vmi.Name).Body(body).Do(context.TODO()).Error()
+}
+
+func (q *guestQuiescer) unfreeze(namespace, name string) error {
+	return q.virtSubresourceClient.Put().Namespace(namespace).Resource("virtualmachineinstances").
+		SubResource("unfreeze").Name(name).Do(context.TODO()).Error()
+}
+
+// guestExecStatus is the result of the guest-exec-status guest agent command
+type guestExecStatus struct {
+	Exited   bool   `json:"exited"`
+	ExitCode int    `json:"exitcode"`
+	OutData  string `json:"out-data,omitempty"`
+	ErrData  string `json:"err-data,omitempty"`
+}
+
+// startHook starts the hook command in the guest and returns its PID
+func (q *guestQuiescer) startHook(vmi *kubevirtv1.VirtualMachineInstance, hook *harvesterv1.BackupHook) (int, error) {
+	if len(hook.Command) == 0 {
+		return 0, fmt.Errorf("hook command is empty")
+	}
+	pod, err := q.getVirtLauncherPod(vmi)
+	if err != nil {
+		return 0, err
+	}
+
+	var started struct {
+		Return struct {
+			PID int `json:"pid"`
+		} `json:"return"`
+	}
+	if err := q.agentCommand(pod, vmi, map[string]interface{}{
+		"execute": "guest-exec",
+		"arguments": map[string]interface{}{
+			"path":           hook.Command[0],
+			"arg":            hook.Command[1:],
+			"capture-output": true,
+		},
+	}, &started); err != nil {
+		return 0, err
+	}
+	return started.Return.PID, nil
+}
+
+// getHookStatus returns the status of the hook command started in the guest
+func (q *guestQuiescer) getHookStatus(vmi *kubevirtv1.VirtualMachineInstance, pid int) (*guestExecStatus, error) {
+	pod, err := q.getVirtLauncherPod(vmi)
+	if err != nil {
+		return nil, err
+	}
+	var status struct {
+		Return guestExecStatus `json:"return"`
+	}
+	if err := q.agentCommand(pod, vmi, map[string]interface{}{
+		"execute":   "guest-exec-status",
+		"arguments": map[string]interface{}{"pid": pid},
+	}, &status); err != nil {
+		return nil, err
+	}
+	return &status.Return, nil
+}
+
+// getHookError returns the error of the exited hook command, it's nil if the command succeeded
+func getHookError(hook *harvesterv1.BackupHook, status *guestExecStatus) error {
+	if status.ExitCode == 0 {
+		return nil
+	}
+	errData, _ := base64.StdEncoding.DecodeString(status.ErrData)
+	return fmt.Errorf("command %q exited with code %d: %s", strings.Join(hook.Command, " "), status.ExitCode, strings.TrimSpace(string(errData)))
+}
+
+func getHookTimeout(hook *harvesterv1.BackupHook) time.Duration {
+	if hook.Timeout != nil {
+		return hook.Timeout.Duration
+	}
+	return defaultHookTimeout
+}
+
+func (q *guestQuiescer) getVirtLauncherPod(vmi *kubevirtv1.VirtualMachineInstance) (*corev1.Pod, error) {
+	pods, err := q.podCache.List(vmi.Namespace, labels.SelectorFromSet(labels.Set{
+		kubevirtv1.AppLabel:       "virt-launcher",
+		kubevirtv1.CreatedByLabel: string(vmi.UID),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find the running virt-launcher pod of VMI %s/%s", vmi.Namespace, vmi.Name)
+}
+
+// agentCommand sends the command to the qemu guest agent through virsh in the virt-launcher pod
+func (q *guestQuiescer) agentCommand(pod *corev1.Pod, vmi *kubevirtv1.VirtualMachineInstance, command interface{}, result interface{}) error {
+	commandJSON, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	domain := fmt.Sprintf("%s_%s", vmi.Namespace, vmi.Name)
+
+	req := q.clientSet.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).
+		SubResource("exec").VersionedParams(&corev1.PodExecOptions{
+		Container: virtLauncherComputeContainer,
+		Command:   []string{"virsh", "qemu-agent-command", domain, string(commandJSON)},
+		Stdout:    true,
+		Stderr:    true,
+	}, k8sscheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(q.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("guest agent command failed: %v, %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return json.Unmarshal(stdout.Bytes(), result)
+}
+
+func newQuiescedCondition(status corev1.ConditionStatus, reason string, message string) harvesterv1.Condition {
+	return harvesterv1.Condition{
+		Type:               harvesterv1.BackupConditionQuiesced,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: currentTime().Format(time.RFC3339),
+	}
+}
+
+func getQuiescedCondition(vmBackup *harvesterv1.VirtualMachineBackup) *harvesterv1.Condition {
+	if vmBackup.Status == nil {
+		return nil
+	}
+	for i := range vmBackup.Status.Conditions {
+		if vmBackup.Status.Conditions[i].Type == harvesterv1.BackupConditionQuiesced {
+			return &vmBackup.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// needsQuiesce returns true if the guest should be quiesced and it hasn't been tried yet
+func needsQuiesce(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	return vmBackup.Spec.Quiesce != nil && getQuiescedCondition(vmBackup) == nil
+}
+
+func isGuestFrozen(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	c := getQuiescedCondition(vmBackup)
+	return c != nil && c.Reason == quiescedReasonFrozen
+}
+
+// isVolumeSnapshotsTaken returns true if all the volumes are snapshotted or failed, so that the guest can be thawed
+func isVolumeSnapshotsTaken(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		if vb.CreationTime == nil && vb.Error == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func getFreezeTimeout(vmBackup *harvesterv1.VirtualMachineBackup) time.Duration {
+	if vmBackup.Spec.Quiesce.Timeout != nil {
+		return vmBackup.Spec.Quiesce.Timeout.Duration
+	}
+	return defaultFreezeTimeout
+}
+
+// quiesceGuest starts the pre hook, or freezes the guest filesystems before the volume snapshots are created if there
+// is no pre hook. If the guest can't be frozen, the backup continues as a crash-consistent one and the reason is
+// recorded in the Quiesced condition.
+func (h *Handler) quiesceGuest(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	vmBackupCpy := vmBackup.DeepCopy()
+
+	vmi, err := h.vmiCache.Get(vmBackup.Namespace, vmBackup.Spec.Source.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	switch {
+	case vmi == nil || vmi.Status.Phase != kubevirtv1.Running:
+		updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionTrue, quiescedReasonNotRunning, "VM is not running, no need to freeze the guest"))
+	case !isAgentConnected(vmi):
+		updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionFalse, quiescedReasonAgentMissing, "guest agent is not connected, the backup is crash-consistent"))
+	case vmBackup.Spec.Quiesce.PreHook != nil:
+		pid, err := h.quiescer.startHook(vmi, vmBackup.Spec.Quiesce.PreHook)
+		if err != nil {
+			return h.onPreHookDone(vmBackupCpy, vmi, fmt.Errorf("failed to start the pre hook: %w", err))
+		}
+		h.setRunningHook(vmBackupCpy, preHookName, pid)
+	default:
+		h.freezeGuest(vmBackupCpy, vmi)
+	}
+
+	_, err = h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// freezeGuest freezes the guest filesystems, the post hook is started if the guest can't be frozen
+func (h *Handler) freezeGuest(vmBackupCpy *harvesterv1.VirtualMachineBackup, vmi *kubevirtv1.VirtualMachineInstance) {
+	if err := h.quiescer.freeze(vmi, getFreezeTimeout(vmBackupCpy)); err != nil {
+		updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionFalse, quiescedReasonFreezeFailed,
+			fmt.Sprintf("failed to freeze the guest filesystems, the backup is crash-consistent: %v", err)))
+		h.startPostHook(vmBackupCpy, vmi)
+		return
+	}
+	updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionTrue, quiescedReasonFrozen, "guest filesystems are frozen"))
+}
+
+// onPreHookDone fails the backup if the pre hook failed and its error isn't ignored, or freezes the guest filesystems
+func (h *Handler) onPreHookDone(vmBackupCpy *harvesterv1.VirtualMachineBackup, vmi *kubevirtv1.VirtualMachineInstance, hookErr error) error {
+	if hookErr != nil {
+		if vmBackupCpy.Spec.Quiesce.PreHook.OnError != harvesterv1.BackupHookContinue {
+			updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionFalse, quiescedReasonPreHookFailed, hookErr.Error()))
+			return h.setStatusError(vmBackupCpy, fmt.Errorf("pre hook failed: %w", hookErr))
+		}
+		logrus.Warnf("pre hook of vm backup %s/%s failed: %v", vmBackupCpy.Namespace, vmBackupCpy.Name, hookErr)
+	}
+	h.freezeGuest(vmBackupCpy, vmi)
+	_, err := h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// thawGuest thaws the guest filesystems and starts the post hook, it's called once the volume snapshots are taken or
+// the backup fails
+func (h *Handler) thawGuest(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	vmBackupCpy := vmBackup.DeepCopy()
+	if err := h.quiescer.unfreeze(vmBackup.Namespace, vmBackup.Spec.Source.Name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	condition := newQuiescedCondition(corev1.ConditionTrue, quiescedReasonThawed, "guest filesystems were frozen while the volumes were snapshotted")
+	if frozenAt, err := time.Parse(time.RFC3339, getQuiescedCondition(vmBackup).LastTransitionTime); err == nil &&
+		currentTime().Sub(frozenAt) > getFreezeTimeout(vmBackup) {
+		condition = newQuiescedCondition(corev1.ConditionFalse, quiescedReasonFreezeTimeout,
+			fmt.Sprintf("guest filesystems were thawed after the %s timeout before the volumes were snapshotted, the backup is crash-consistent", getFreezeTimeout(vmBackup)))
+	}
+	updateBackupCondition(vmBackupCpy, condition)
+
+	vmi, err := h.vmiCache.Get(vmBackup.Namespace, vmBackup.Spec.Source.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if vmi != nil {
+		h.startPostHook(vmBackupCpy, vmi)
+	}
+
+	_, err = h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// startPostHook starts the post hook, a failed post hook is recorded in the Quiesced condition
+func (h *Handler) startPostHook(vmBackupCpy *harvesterv1.VirtualMachineBackup, vmi *kubevirtv1.VirtualMachineInstance) {
+	if vmBackupCpy.Spec.Quiesce.PostHook == nil {
+		return
+	}
+	pid, err := h.quiescer.startHook(vmi, vmBackupCpy.Spec.Quiesce.PostHook)
+	if err != nil {
+		h.onPostHookDone(vmBackupCpy, fmt.Errorf("failed to start the post hook: %w", err))
+		return
+	}
+	h.setRunningHook(vmBackupCpy, postHookName, pid)
+}
+
+func (h *Handler) onPostHookDone(vmBackupCpy *harvesterv1.VirtualMachineBackup, hookErr error) {
+	if hookErr == nil {
+		return
+	}
+	logrus.Warnf("post hook of vm backup %s/%s failed: %v", vmBackupCpy.Namespace, vmBackupCpy.Name, hookErr)
+	if condition := getQuiescedCondition(vmBackupCpy); condition != nil {
+		condition.Message = fmt.Sprintf("%s, post hook failed: %v", condition.Message, hookErr)
+	}
+}
+
+// setRunningHook records the hook started in the guest, the backup is requeued to check whether it has exited
+func (h *Handler) setRunningHook(vmBackupCpy *harvesterv1.VirtualMachineBackup, name string, pid int) {
+	vmBackupCpy.Status.RunningHook = &harvesterv1.BackupHookStatus{
+		Name:      name,
+		PID:       pid,
+		StartTime: *currentTime(),
+	}
+	h.vmBackupController.EnqueueAfter(vmBackupCpy.Namespace, vmBackupCpy.Name, guestExecPollInterval)
+}
+
+// checkRunningHook checks whether the hook running in the guest has exited, the backup continues once it exits or
+// times out
+func (h *Handler) checkRunningHook(vmBackup *harvesterv1.VirtualMachineBackup) error {
+	running := vmBackup.Status.RunningHook
+	hook := vmBackup.Spec.Quiesce.PreHook
+	if running.Name == postHookName {
+		hook = vmBackup.Spec.Quiesce.PostHook
+	}
+
+	vmi, err := h.vmiCache.Get(vmBackup.Namespace, vmBackup.Spec.Source.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	var hookErr error
+	switch {
+	case vmi == nil || vmi.Status.Phase != kubevirtv1.Running:
+		hookErr = fmt.Errorf("VM stopped before the command %q exited", strings.Join(hook.Command, " "))
+	default:
+		status, err := h.quiescer.getHookStatus(vmi, running.PID)
+		if err != nil {
+			return err
+		}
+		if status.Exited {
+			hookErr = getHookError(hook, status)
+		} else if timeout := getHookTimeout(hook); currentTime().Sub(running.StartTime.Time) > timeout {
+			hookErr = fmt.Errorf("command %q didn't finish in %s", strings.Join(hook.Command, " "), timeout)
+		} else {
+			h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, guestExecPollInterval)
+			return nil
+		}
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	vmBackupCpy.Status.RunningHook = nil
+	if running.Name == preHookName {
+		if vmi == nil || vmi.Status.Phase != kubevirtv1.Running {
+			updateBackupCondition(vmBackupCpy, newQuiescedCondition(corev1.ConditionTrue, quiescedReasonNotRunning, "VM is not running, no need to freeze the guest"))
+			_, err := h.vmBackups.Update(vmBackupCpy)
+			return err
+		}
+		return h.onPreHookDone(vmBackupCpy, vmi, hookErr)
+	}
+	h.onPostHookDone(vmBackupCpy, hookErr)
+	_, err = h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+func isAgentConnected(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	for _, c := range vmi.Status.Conditions {
+		if c.Type == kubevirtv1.VirtualMachineInstanceAgentConnected {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_quiesceState(t *testing.T) {
+	now := metav1.Now()
+	quiesceBackup := func(conditions []harvesterv1.Condition, volumeBackups ...harvesterv1.VolumeBackup) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{
+			Spec: harvesterv1.VirtualMachineBackupSpec{
+				Quiesce: &harvesterv1.BackupQuiesce{},
+			},
+			Status: &harvesterv1.VirtualMachineBackupStatus{
+				Conditions:    conditions,
+				VolumeBackups: volumeBackups,
+			},
+		}
+	}
+	frozen := []harvesterv1.Condition{newQuiescedCondition(corev1.ConditionTrue, quiescedReasonFrozen, "")}
+	thawed := []harvesterv1.Condition{newQuiescedCondition(corev1.ConditionTrue, quiescedReasonThawed, "")}
+
+	var testCases = []struct {
+		name          string
+		backup        *harvesterv1.VirtualMachineBackup
+		needsQuiesce  bool
+		frozen        bool
+		snapshotTaken bool
+	}{
+		{
+			name: "quiesce is not enabled",
+			backup: &harvesterv1.VirtualMachineBackup{
+				Status: &harvesterv1.VirtualMachineBackupStatus{},
+			},
+			snapshotTaken: true,
+		},
+		{
+			name:         "quiesce is not tried yet",
+			backup:       quiesceBackup(nil, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol")}),
+			needsQuiesce: true,
+		},
+		{
+			name:   "frozen and volume snapshots are not taken",
+			backup: quiesceBackup(frozen, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol")}, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol2"), CreationTime: &now}),
+			frozen: true,
+		},
+		{
+			name:          "frozen and volume snapshots are taken or failed",
+			backup:        quiesceBackup(frozen, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol"), CreationTime: &now}, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol2"), Error: &harvesterv1.Error{}}),
+			frozen:        true,
+			snapshotTaken: true,
+		},
+		{
+			name:          "thawed",
+			backup:        quiesceBackup(thawed, harvesterv1.VolumeBackup{Name: pointer.StringPtr("vol"), CreationTime: &now}),
+			snapshotTaken: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.needsQuiesce, needsQuiesce(tc.backup), tc.name)
+		assert.Equal(t, tc.frozen, isGuestFrozen(tc.backup), tc.name)
+		assert.Equal(t, tc.snapshotTaken, isVolumeSnapshotsTaken(tc.backup), tc.name)
+	}
+}
+
+func Test_getHookError(t *testing.T) {
+	hook := &harvesterv1.BackupHook{Command: []string{"fsfreeze-hook", "pre"}}
+	assert.Nil(t, getHookError(hook, &guestExecStatus{Exited: true}))
+
+	err := getHookError(hook, &guestExecStatus{
+		Exited:   true,
+		ExitCode: 1,
+		ErrData:  base64.StdEncoding.EncodeToString([]byte("database is busy\n")),
+	})
+	assert.EqualError(t, err, `command "fsfreeze-hook pre" exited with code 1: database is busy`)
+}
+
+func Test_getHookTimeout(t *testing.T) {
+	assert.Equal(t, defaultHookTimeout, getHookTimeout(&harvesterv1.BackupHook{}))
+	assert.Equal(t, 5*time.Minute, getHookTimeout(&harvesterv1.BackupHook{Timeout: &metav1.Duration{Duration: 5 * time.Minute}}))
+}
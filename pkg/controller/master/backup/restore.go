@@ -80,6 +80,10 @@ type RestoreHandler struct {
 	snapshotContents     ctlsnapshotv1.VolumeSnapshotContentClient
 	snapshotContentCache ctlsnapshotv1.VolumeSnapshotContentCache
 	lhbackupCache        ctllonghornv1.BackupCache
+	volumeCache          ctllonghornv1.VolumeCache
+	engineCache          ctllonghornv1.EngineCache
+	backupTargetCache    ctlharvesterv1.BackupTargetCache
+	activator            *targetActivator
 
 	recorder   record.EventRecorder
 	restClient *rest.RESTClient
@@ -95,6 +99,9 @@ func RegisterRestore(ctx context.Context, management *config.Management, opts co
 	snapshots := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshot()
 	snapshotContents := management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshotContent()
 	lhbackups := management.LonghornFactory.Longhorn().V1beta1().Backup()
+	volumes := management.LonghornFactory.Longhorn().V1beta1().Volume()
+	engines := management.LonghornFactory.Longhorn().V1beta1().Engine()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 
 	copyConfig := rest.CopyConfig(management.RestConfig)
 	copyConfig.GroupVersion = &k8sschema.GroupVersion{Group: kubevirtv1.SubresourceGroupName, Version: kubevirtv1.ApiLatestVersion}
@@ -123,6 +130,10 @@ func RegisterRestore(ctx context.Context, management *config.Management, opts co
 		snapshotContents:     snapshotContents,
 		snapshotContentCache: snapshotContents.Cache(),
 		lhbackupCache:        lhbackups.Cache(),
+		volumeCache:          volumes.Cache(),
+		engineCache:          engines.Cache(),
+		backupTargetCache:    backupTargets.Cache(),
+		activator:            getTargetActivator(management),
 		recorder:             management.NewRecorder(restoreControllerName, "", ""),
 		restClient:           restClient,
 	}
@@ -131,6 +142,7 @@ func RegisterRestore(ctx context.Context, management *config.Management, opts co
 	restores.OnRemove(ctx, restoreControllerName, handler.RestoreOnRemove)
 	pvcs.OnChange(ctx, restoreControllerName, handler.PersistentVolumeClaimOnChange)
 	vms.OnChange(ctx, restoreControllerName, handler.VMOnChange)
+	engines.OnChange(ctx, restoreControllerName, handler.EngineOnChange)
 	return nil
 }
 
@@ -155,7 +167,10 @@ func (h *RestoreHandler) RestoreOnChanged(key string, restore *harvesterv1.Virtu
 	}
 
 	if isVMRestoreMissingVolumes(restore) {
-		return nil, h.initVolumesStatus(restore, backup)
+		if isVMSnapshot(backup) {
+			return nil, h.initVolumesStatus(restore, backup)
+		}
+		return nil, h.claimBackupTarget(restore, backup)
 	}
 
 	vm, isVolumesReady, err := h.reconcileResources(restore, backup)
@@ -245,6 +260,34 @@ func (h *RestoreHandler) initStatus(restore *harvesterv1.VirtualMachineRestore)
 	return nil
 }
 
+// claimBackupTarget points the longhorn backup target at the target of the backup before the volumes are restored,
+// the restore waits if longhorn is transferring the data of another target.
+func (h *RestoreHandler) claimBackupTarget(vmRestore *harvesterv1.VirtualMachineRestore, backup *harvesterv1.VirtualMachineBackup) error {
+	target, err := h.backupTargetCache.Get(GetBackupTargetName(backup))
+	if err != nil {
+		return h.updateStatusError(vmRestore, fmt.Errorf("can't get backup target %s: %w", GetBackupTargetName(backup), err), true)
+	}
+
+	activated, err := h.activator.activate(target)
+	if err != nil {
+		return err
+	}
+	if activated {
+		return h.initVolumesStatus(vmRestore, backup)
+	}
+
+	restoreCpy := vmRestore.DeepCopy()
+	updateRestoreCondition(restoreCpy, newProgressingCondition(corev1.ConditionFalse, backupTargetBusyReason,
+		fmt.Sprintf("Waiting for longhorn to finish the backups or restores of other backup targets before switching to %s", target.Name)))
+	h.restoreController.EnqueueAfter(vmRestore.Namespace, vmRestore.Name, waitBackupTargetInterval)
+	if !reflect.DeepEqual(vmRestore.Status, restoreCpy.Status) {
+		if _, err := h.restores.Update(restoreCpy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *RestoreHandler) initVolumesStatus(vmRestore *harvesterv1.VirtualMachineRestore, backup *harvesterv1.VirtualMachineBackup) error {
 	restoreCpy := vmRestore.DeepCopy()
 
@@ -313,10 +356,11 @@ func getVolumeRestores(vmRestore *harvesterv1.VirtualMachineRestore, backup *har
 						Name:      getRestorePVCName(vmRestore, vb.VolumeName),
 						Namespace: vmRestore.Namespace,
 					},
-					Spec: vb.PersistentVolumeClaim.Spec,
+					Spec: *vb.PersistentVolumeClaim.Spec.DeepCopy(),
 				},
 				VolumeBackupName: *vb.Name,
 			}
+			vr.PersistentVolumeClaim.Spec.StorageClassName = MapStorageClassName(GetRestoreMapping(vmRestore), vb.PersistentVolumeClaim)
 			restores = append(restores, vr)
 		}
 	}
@@ -404,8 +448,9 @@ func (h *RestoreHandler) reconcileVM(
 	}
 
 	vmCpy := vm.DeepCopy()
-	vmCpy.Spec = backup.Status.SourceSpec.Spec
+	vmCpy.Spec = *backup.Status.SourceSpec.Spec.DeepCopy()
 	vmCpy.Spec.Template.Spec.Volumes = newVolumes
+	mapVMNetworks(GetRestoreMapping(vmRestore), backup.Namespace, &vmCpy.Spec.Template.Spec)
 	if vmCpy.Annotations == nil {
 		vmCpy.Annotations = make(map[string]string)
 	}
@@ -425,9 +470,14 @@ func (h *RestoreHandler) reconcileSecretBackups(
 	backup *harvesterv1.VirtualMachineBackup,
 	vm *kubevirtv1.VirtualMachine,
 ) error {
+	secretBackups, err := h.getDecryptedSecretBackups(backup)
+	if err != nil {
+		return err
+	}
+
 	ownerRefs := configVMOwner(vm)
 	if !vmRestore.Spec.NewVM {
-		for _, secretBackup := range backup.Status.SecretBackups {
+		for _, secretBackup := range secretBackups {
 			if err := h.createOrUpdateSecret(vmRestore.Namespace, secretBackup.Name, secretBackup.Data, ownerRefs); err != nil {
 				return err
 			}
@@ -436,7 +486,7 @@ func (h *RestoreHandler) reconcileSecretBackups(
 	}
 
 	// Create new secret for new VM
-	for _, secretBackup := range backup.Status.SecretBackups {
+	for _, secretBackup := range secretBackups {
 		newSecretName := getSecretRefName(vmRestore.Spec.Target.Name, secretBackup.Name)
 		if err := h.createOrUpdateSecret(vmRestore.Namespace, newSecretName, secretBackup.Data, ownerRefs); err != nil {
 			return err
@@ -445,6 +495,21 @@ func (h *RestoreHandler) reconcileSecretBackups(
 	return nil
 }
 
+// getDecryptedSecretBackups returns the secret backups decrypted with the keys of the backup target
+func (h *RestoreHandler) getDecryptedSecretBackups(backup *harvesterv1.VirtualMachineBackup) ([]harvesterv1.SecretBackup, error) {
+	var keys *encryptionKeys
+	if backup.Status.EncryptionKeyID != "" {
+		target, err := h.backupTargetCache.Get(GetBackupTargetName(backup))
+		if err != nil {
+			return nil, err
+		}
+		if keys, err = getEncryptionKeys(h.secretCache, target); err != nil {
+			return nil, err
+		}
+	}
+	return decryptSecretBackups(keys, backup.Status.SecretBackups)
+}
+
 func (h *RestoreHandler) createOrUpdateSecret(namespace, name string, data map[string][]byte, ownerRefs []metav1.OwnerReference) error {
 	secret, err := h.secretCache.Get(namespace, name)
 	if err != nil {
@@ -540,12 +605,17 @@ func (h *RestoreHandler) createNewVM(restore *harvesterv1.VirtualMachineRestore,
 		return nil, err
 	}
 	vm.Spec.Template.Spec.Volumes = newVolumes
+	mapVMNetworks(GetRestoreMapping(restore), backup.Namespace, &vm.Spec.Template.Spec)
 
 	for i := range vm.Spec.Template.Spec.Domain.Devices.Interfaces {
 		// remove the copied mac address of the new VM
 		vm.Spec.Template.Spec.Domain.Devices.Interfaces[i].MacAddress = ""
 	}
 
+	if err := prepareVerificationVM(restore, vm); err != nil {
+		return nil, err
+	}
+
 	newVM, err := h.vms.Create(vm)
 	if err != nil {
 		return nil, err
@@ -606,10 +676,12 @@ func (h *RestoreHandler) createRestoredPVC(
 		}
 	}
 	annotations[restoreNameAnnotation] = vmRestore.Name
+	mapVolumeClaimAnnotations(GetRestoreMapping(vmRestore), annotations)
 
 	sourcePVC := volumeBackup.PersistentVolumeClaim
 	spec := *sourcePVC.Spec.DeepCopy()
 	spec.VolumeName = ""
+	spec.StorageClassName = MapStorageClassName(GetRestoreMapping(vmRestore), sourcePVC)
 	spec.DataSource = &corev1.TypedLocalObjectReference{
 		APIGroup: pointer.StringPtr(snapshotv1.SchemeGroupVersion.Group),
 		Kind:     volumeSnapshotKindName,
@@ -638,12 +710,50 @@ func (h *RestoreHandler) createRestoredPVC(
 	return err
 }
 
-func (h *RestoreHandler) getOrCreateVolumeSnapshotContent(
+func (h *RestoreHandler) getOrCreateVolumeSnapshot(
 	vmRestore *harvesterv1.VirtualMachineRestore,
 	volumeBackup harvesterv1.VolumeBackup,
+) (*snapshotv1.VolumeSnapshot, error) {
+	restorer := &volumeSnapshotRestorer{
+		snapshots:            h.snapshots,
+		snapshotCache:        h.snapshotCache,
+		snapshotContents:     h.snapshotContents,
+		snapshotContentCache: h.snapshotContentCache,
+		lhbackupCache:        h.lhbackupCache,
+	}
+	return restorer.getOrCreateVolumeSnapshot(metav1.ObjectMeta{
+		Name:      h.constructVolumeSnapshotName(vmRestore.Name, *volumeBackup.Name),
+		Namespace: vmRestore.Namespace,
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: harvesterv1.SchemeGroupVersion.String(),
+				Kind:       vmRestoreKindName,
+				Name:       vmRestore.Name,
+				UID:        vmRestore.UID,
+			},
+		},
+	}, h.constructVolumeSnapshotContentName(vmRestore.Namespace, vmRestore.Name, *volumeBackup.Name), volumeBackup)
+}
+
+// volumeSnapshotRestorer creates the VolumeSnapshots to restore PVCs from the longhorn backups of the volume backups,
+// since a VolumeSnapshot can't be used in another namespace, and the VolumeSnapshots of the backups synced from
+// a backup target don't exist.
+type volumeSnapshotRestorer struct {
+	snapshots            ctlsnapshotv1.VolumeSnapshotClient
+	snapshotCache        ctlsnapshotv1.VolumeSnapshotCache
+	snapshotContents     ctlsnapshotv1.VolumeSnapshotContentClient
+	snapshotContentCache ctlsnapshotv1.VolumeSnapshotContentCache
+	lhbackupCache        ctllonghornv1.BackupCache
+}
+
+// getOrCreateVolumeSnapshotContent creates the VolumeSnapshotContent of the longhorn backup, it has the labels and
+// the owners of the VolumeSnapshot.
+func (r *volumeSnapshotRestorer) getOrCreateVolumeSnapshotContent(
+	snapshotMeta metav1.ObjectMeta,
+	volumeSnapshotContentName string,
+	volumeBackup harvesterv1.VolumeBackup,
 ) (*snapshotv1.VolumeSnapshotContent, error) {
-	volumeSnapshotContentName := h.constructVolumeSnapshotContentName(vmRestore.Namespace, vmRestore.Name, *volumeBackup.Name)
-	if volumeSnapshotContent, err := h.snapshotContentCache.Get(volumeSnapshotContentName); err != nil {
+	if volumeSnapshotContent, err := r.snapshotContentCache.Get(volumeSnapshotContentName); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return nil, err
 		}
@@ -651,7 +761,10 @@ func (h *RestoreHandler) getOrCreateVolumeSnapshotContent(
 		return volumeSnapshotContent, nil
 	}
 
-	lhBackup, err := h.lhbackupCache.Get(util.LonghornSystemNamespaceName, *volumeBackup.LonghornBackupName)
+	if volumeBackup.LonghornBackupName == nil {
+		return nil, fmt.Errorf("volume backup %s has no longhorn backup", *volumeBackup.Name)
+	}
+	lhBackup, err := r.lhbackupCache.Get(util.LonghornSystemNamespaceName, *volumeBackup.LonghornBackupName)
 	if err != nil {
 		return nil, err
 	}
@@ -659,18 +772,11 @@ func (h *RestoreHandler) getOrCreateVolumeSnapshotContent(
 	snapshotHandle := fmt.Sprintf("bs://%s/%s", volumeBackup.PersistentVolumeClaim.ObjectMeta.Name, lhBackup.Name)
 
 	logrus.Debugf("create VolumeSnapshotContent %s ...", volumeSnapshotContentName)
-	return h.snapshotContents.Create(&snapshotv1.VolumeSnapshotContent{
+	return r.snapshotContents.Create(&snapshotv1.VolumeSnapshotContent{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      volumeSnapshotContentName,
-			Namespace: vmRestore.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion: harvesterv1.SchemeGroupVersion.String(),
-					Kind:       vmRestoreKindName,
-					Name:       vmRestore.Name,
-					UID:        vmRestore.UID,
-				},
-			},
+			Name:            volumeSnapshotContentName,
+			Labels:          snapshotMeta.Labels,
+			OwnerReferences: snapshotMeta.OwnerReferences,
 		},
 		Spec: snapshotv1.VolumeSnapshotContentSpec{
 			Driver: "driver.longhorn.io",
@@ -681,19 +787,21 @@ func (h *RestoreHandler) getOrCreateVolumeSnapshotContent(
 			},
 			VolumeSnapshotClassName: pointer.StringPtr(settings.VolumeSnapshotClass.Get()),
 			VolumeSnapshotRef: corev1.ObjectReference{
-				Name:      h.constructVolumeSnapshotName(vmRestore.Name, *volumeBackup.Name),
-				Namespace: vmRestore.Namespace,
+				Name:      snapshotMeta.Name,
+				Namespace: snapshotMeta.Namespace,
 			},
 		},
 	})
 }
 
-func (h *RestoreHandler) getOrCreateVolumeSnapshot(
-	vmRestore *harvesterv1.VirtualMachineRestore,
+// getOrCreateVolumeSnapshot creates the VolumeSnapshot of the longhorn backup with the metadata,
+// the owners of the VolumeSnapshot block their deletion.
+func (r *volumeSnapshotRestorer) getOrCreateVolumeSnapshot(
+	snapshotMeta metav1.ObjectMeta,
+	volumeSnapshotContentName string,
 	volumeBackup harvesterv1.VolumeBackup,
 ) (*snapshotv1.VolumeSnapshot, error) {
-	volumeSnapshotName := h.constructVolumeSnapshotName(vmRestore.Name, *volumeBackup.Name)
-	if volumeSnapshot, err := h.snapshotCache.Get(vmRestore.Namespace, volumeSnapshotName); err != nil {
+	if volumeSnapshot, err := r.snapshotCache.Get(snapshotMeta.Namespace, snapshotMeta.Name); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return nil, err
 		}
@@ -701,26 +809,20 @@ func (h *RestoreHandler) getOrCreateVolumeSnapshot(
 		return volumeSnapshot, nil
 	}
 
-	volumeSnapshotContent, err := h.getOrCreateVolumeSnapshotContent(vmRestore, volumeBackup)
+	volumeSnapshotContent, err := r.getOrCreateVolumeSnapshotContent(snapshotMeta, volumeSnapshotContentName, volumeBackup)
 	if err != nil {
 		return nil, err
 	}
 
-	logrus.Debugf("create VolumeSnapshot %s/%s", vmRestore.Namespace, volumeSnapshotName)
-	return h.snapshots.Create(&snapshotv1.VolumeSnapshot{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      volumeSnapshotName,
-			Namespace: vmRestore.Namespace,
-			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion:         harvesterv1.SchemeGroupVersion.String(),
-					Kind:               vmRestoreKindName,
-					Name:               vmRestore.Name,
-					UID:                vmRestore.UID,
-					BlockOwnerDeletion: pointer.BoolPtr(true),
-				},
-			},
-		},
+	owners := snapshotMeta.OwnerReferences
+	snapshotMeta.OwnerReferences = nil
+	for _, owner := range owners {
+		owner.BlockOwnerDeletion = pointer.BoolPtr(true)
+		snapshotMeta.OwnerReferences = append(snapshotMeta.OwnerReferences, owner)
+	}
+	logrus.Debugf("create VolumeSnapshot %s/%s", snapshotMeta.Namespace, snapshotMeta.Name)
+	return r.snapshots.Create(&snapshotv1.VolumeSnapshot{
+		ObjectMeta: snapshotMeta,
 		Spec: snapshotv1.VolumeSnapshotSpec{
 			Source: snapshotv1.VolumeSnapshotSource{
 				VolumeSnapshotContentName: pointer.StringPtr(volumeSnapshotContent.Name),
@@ -784,6 +886,10 @@ func (h *RestoreHandler) updateStatus(
 	isVolumesReady bool,
 ) error {
 	restoreCpy := vmRestore.DeepCopy()
+	if err := h.syncRestoreProgress(restoreCpy); err != nil {
+		return err
+	}
+
 	if !isVolumesReady {
 		updateRestoreCondition(restoreCpy, newProgressingCondition(corev1.ConditionTrue, "", "Creating new PVCs"))
 		updateRestoreCondition(restoreCpy, newReadyCondition(corev1.ConditionFalse, "", "Waiting for new PVCs"))
@@ -0,0 +1,102 @@
+package backup
+
+import (
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/builder"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// GetRestoreMapping returns the mapping table of a cross-cluster restore, it's nil for the other restores
+func GetRestoreMapping(vmRestore *harvesterv1.VirtualMachineRestore) *harvesterv1.RestoreMapping {
+	if vmRestore.Spec.Mode != harvesterv1.RestoreModeCrossCluster {
+		return nil
+	}
+	if vmRestore.Spec.Mapping == nil {
+		return &harvesterv1.RestoreMapping{}
+	}
+	return vmRestore.Spec.Mapping
+}
+
+// MapNamespace maps a namespace of the source cluster to the namespace in this cluster
+func MapNamespace(mapping *harvesterv1.RestoreMapping, namespace string) string {
+	if mapping == nil {
+		return namespace
+	}
+	if mapped, ok := mapping.Namespaces[namespace]; ok {
+		return mapped
+	}
+	return namespace
+}
+
+// MapImageID maps the <namespace>/<name> of an image, the namespace is mapped if the image isn't in the table
+func MapImageID(mapping *harvesterv1.RestoreMapping, imageID string) string {
+	if mapping == nil || imageID == "" {
+		return imageID
+	}
+	if mapped, ok := mapping.Images[imageID]; ok {
+		return mapped
+	}
+	namespace, name := ref.Parse(imageID)
+	return ref.Construct(MapNamespace(mapping, namespace), name)
+}
+
+// MapStorageClassName returns the storage class of the restored volume,
+// a volume created from an image uses the backing storage class of the mapped image.
+func MapStorageClassName(mapping *harvesterv1.RestoreMapping, pvc harvesterv1.PersistentVolumeClaimSourceSpec) *string {
+	if mapping == nil {
+		return pvc.Spec.StorageClassName
+	}
+
+	if imageID := pvc.ObjectMeta.Annotations[util.AnnotationImageID]; imageID != "" {
+		_, name := ref.Parse(MapImageID(mapping, imageID))
+		scName := builder.BuildImageStorageClassName("", name)
+		return &scName
+	}
+
+	if pvc.Spec.StorageClassName == nil {
+		return nil
+	}
+	if mapped, ok := mapping.StorageClasses[*pvc.Spec.StorageClassName]; ok {
+		return &mapped
+	}
+	return pvc.Spec.StorageClassName
+}
+
+// MapNetworkName maps a Multus network name of the VM, the network is in the namespace of the VM if it's not namespaced
+func MapNetworkName(mapping *harvesterv1.RestoreMapping, vmNamespace, networkName string) string {
+	if mapping == nil {
+		return networkName
+	}
+
+	namespace, name := ref.Parse(networkName)
+	if namespace == "" {
+		namespace = vmNamespace
+	}
+	fullName := ref.Construct(namespace, name)
+	if mapped, ok := mapping.Networks[fullName]; ok {
+		return mapped
+	}
+	if mappedNamespace := MapNamespace(mapping, namespace); mappedNamespace != namespace {
+		return ref.Construct(mappedNamespace, name)
+	}
+	return networkName
+}
+
+// mapVolumeClaimAnnotations points the image annotation of a restored volume at the mapped image
+func mapVolumeClaimAnnotations(mapping *harvesterv1.RestoreMapping, annotations map[string]string) {
+	if imageID, ok := annotations[util.AnnotationImageID]; ok {
+		annotations[util.AnnotationImageID] = MapImageID(mapping, imageID)
+	}
+}
+
+func mapVMNetworks(mapping *harvesterv1.RestoreMapping, vmNamespace string, spec *kubevirtv1.VirtualMachineInstanceSpec) {
+	for i, network := range spec.Networks {
+		if network.Multus == nil {
+			continue
+		}
+		spec.Networks[i].Multus.NetworkName = MapNetworkName(mapping, vmNamespace, network.Multus.NetworkName)
+	}
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_MapStorageClassName(t *testing.T) {
+	mapping := &harvesterv1.RestoreMapping{
+		Namespaces:     map[string]string{"prod": "dr"},
+		StorageClasses: map[string]string{"longhorn-ssd": "longhorn"},
+		Images:         map[string]string{"prod/ubuntu": "images/ubuntu-20.04"},
+	}
+	newPVC := func(storageClassName, imageID string) harvesterv1.PersistentVolumeClaimSourceSpec {
+		pvc := harvesterv1.PersistentVolumeClaimSourceSpec{
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr(storageClassName)},
+		}
+		if imageID != "" {
+			pvc.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{util.AnnotationImageID: imageID}}
+		}
+		return pvc
+	}
+
+	var testCases = []struct {
+		name     string
+		mapping  *harvesterv1.RestoreMapping
+		pvc      harvesterv1.PersistentVolumeClaimSourceSpec
+		expected string
+	}{
+		{
+			name:     "not a cross-cluster restore",
+			pvc:      newPVC("longhorn-ssd", ""),
+			expected: "longhorn-ssd",
+		},
+		{
+			name:     "mapped storage class",
+			mapping:  mapping,
+			pvc:      newPVC("longhorn-ssd", ""),
+			expected: "longhorn",
+		},
+		{
+			name:     "storage class not in the table",
+			mapping:  mapping,
+			pvc:      newPVC("longhorn-hdd", ""),
+			expected: "longhorn-hdd",
+		},
+		{
+			name:     "mapped image",
+			mapping:  mapping,
+			pvc:      newPVC("longhorn-ubuntu", "prod/ubuntu"),
+			expected: "longhorn-ubuntu-20.04",
+		},
+		{
+			name:     "image in a mapped namespace",
+			mapping:  mapping,
+			pvc:      newPVC("longhorn-centos", "prod/centos"),
+			expected: "longhorn-centos",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, *MapStorageClassName(tc.mapping, tc.pvc), tc.name)
+	}
+
+	assert.Equal(t, "dr/centos", MapImageID(mapping, "prod/centos"))
+}
+
+func Test_MapNetworkName(t *testing.T) {
+	mapping := &harvesterv1.RestoreMapping{
+		Namespaces: map[string]string{"prod": "dr"},
+		Networks:   map[string]string{"default/vlan100": "default/vlan200"},
+	}
+
+	var testCases = []struct {
+		name        string
+		mapping     *harvesterv1.RestoreMapping
+		vmNamespace string
+		network     string
+		expected    string
+	}{
+		{
+			name:        "not a cross-cluster restore",
+			vmNamespace: "prod",
+			network:     "default/vlan100",
+			expected:    "default/vlan100",
+		},
+		{
+			name:        "mapped network",
+			mapping:     mapping,
+			vmNamespace: "prod",
+			network:     "default/vlan100",
+			expected:    "default/vlan200",
+		},
+		{
+			name:        "network in the namespace of the VM",
+			mapping:     mapping,
+			vmNamespace: "default",
+			network:     "vlan100",
+			expected:    "default/vlan200",
+		},
+		{
+			name:        "network in a mapped namespace",
+			mapping:     mapping,
+			vmNamespace: "prod",
+			network:     "vlan300",
+			expected:    "dr/vlan300",
+		},
+		{
+			name:        "network not in the table",
+			mapping:     mapping,
+			vmNamespace: "default",
+			network:     "vlan300",
+			expected:    "vlan300",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, MapNetworkName(tc.mapping, tc.vmNamespace, tc.network), tc.name)
+	}
+}
@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/longhorn/backupstore"
+	bshttp "github.com/longhorn/backupstore/http"
+
+	"github.com/harvester/harvester/pkg/settings"
+)
+
+const s3DriverKind = "s3"
+
+// s3Driver is a backupstore driver of a S3 backup target. Unlike the longhorn S3 driver which reads the credentials
+// and the endpoint from the process environment on every call, it holds its own client so that the drivers of
+// different targets can be used at the same time.
+type s3Driver struct {
+	client *s3.S3
+	bucket string
+	url    string
+}
+
+var _ backupstore.BackupStoreDriver = &s3Driver{}
+
+func newS3Driver(target *settings.BackupTarget) (*s3Driver, error) {
+	if target.BucketName == "" {
+		return nil, fmt.Errorf("bucket name of the S3 backup target is required")
+	}
+
+	config := &aws.Config{
+		Region:     aws.String(target.BucketRegion),
+		MaxRetries: aws.Int(3),
+	}
+	if target.AccessKeyID != "" || target.SecretAccessKey != "" {
+		config.Credentials = credentials.NewStaticCredentials(target.AccessKeyID, target.SecretAccessKey, "")
+	}
+	config.S3ForcePathStyle = aws.Bool(!target.VirtualHostedStyle)
+	if target.Endpoint != "" {
+		config.Endpoint = aws.String(target.Endpoint)
+	}
+
+	certs := target.Cert
+	if additionalCA := settings.AdditionalCA.Get(); additionalCA != "" {
+		certs = additionalCA
+	}
+	if certs != "" {
+		client, err := bshttp.GetClientWithCustomCerts([]byte(certs))
+		if err != nil {
+			return nil, err
+		}
+		config.HTTPClient = client
+	}
+
+	ses, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	driver := &s3Driver{
+		client: s3.New(ses),
+		bucket: target.BucketName,
+		url:    ConstructEndpoint(target),
+	}
+
+	// test the connection like the longhorn driver
+	if _, err := driver.List(""); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+func (d *s3Driver) Kind() string {
+	return s3DriverKind
+}
+
+func (d *s3Driver) GetURL() string {
+	return d.url
+}
+
+func (d *s3Driver) key(path string) string {
+	return strings.TrimLeft(filepath.Join("/", path), "/")
+}
+
+func (d *s3Driver) listObjects(prefix, delimiter string) ([]*s3.Object, []*s3.CommonPrefix, error) {
+	var (
+		objects  []*s3.Object
+		prefixes []*s3.CommonPrefix
+	)
+	err := d.client.ListObjectsPages(&s3.ListObjectsInput{
+		Bucket:    aws.String(d.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String(delimiter),
+	}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+		objects = append(objects, page.Contents...)
+		prefixes = append(prefixes, page.CommonPrefixes...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list objects with prefix %s in bucket %s: %w", prefix, d.bucket, err)
+	}
+	return objects, prefixes, nil
+}
+
+func (d *s3Driver) List(listPath string) ([]string, error) {
+	// directories must end with "/" in S3, otherwise the prefix may match other directories
+	prefix := d.key(listPath)
+	if prefix != "" {
+		prefix += "/"
+	}
+	objects, prefixes, err := d.listObjects(prefix, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, object := range objects {
+		if name := strings.TrimPrefix(aws.StringValue(object.Key), prefix); name != "" {
+			result = append(result, name)
+		}
+	}
+	for _, p := range prefixes {
+		if name := strings.TrimSuffix(strings.TrimPrefix(aws.StringValue(p.Prefix), prefix), "/"); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+func (d *s3Driver) headObject(filePath string) (*s3.HeadObjectOutput, error) {
+	return d.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.key(filePath)),
+	})
+}
+
+func (d *s3Driver) FileExists(filePath string) bool {
+	return d.FileSize(filePath) >= 0
+}
+
+func (d *s3Driver) FileSize(filePath string) int64 {
+	head, err := d.headObject(filePath)
+	if err != nil || head.ContentLength == nil {
+		return -1
+	}
+	return *head.ContentLength
+}
+
+func (d *s3Driver) FileTime(filePath string) time.Time {
+	head, err := d.headObject(filePath)
+	if err != nil || head.ContentLength == nil {
+		return time.Time{}
+	}
+	return aws.TimeValue(head.LastModified).UTC()
+}
+
+func (d *s3Driver) Remove(path string) error {
+	objects, _, err := d.listObjects(d.key(path), "")
+	if err != nil {
+		return err
+	}
+	var failures []string
+	for _, object := range objects {
+		if _, err := d.client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(d.bucket),
+			Key:    object.Key,
+		}); err != nil {
+			failures = append(failures, aws.StringValue(object.Key))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to delete objects %v", failures)
+	}
+	return nil
+}
+
+func (d *s3Driver) Read(src string) (io.ReadCloser, error) {
+	resp, err := d.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.key(src)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", src, err)
+	}
+	return resp.Body, nil
+}
+
+func (d *s3Driver) Write(dst string, rs io.ReadSeeker) error {
+	if _, err := d.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.key(dst)),
+		Body:   rs,
+	}); err != nil {
+		return fmt.Errorf("failed to put object %s: %w", dst, err)
+	}
+	return nil
+}
+
+func (d *s3Driver) Upload(src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return d.Write(dst, file)
+}
+
+func (d *s3Driver) Download(src, dst string) error {
+	rc, err := d.Read(src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, rc)
+	return err
+}
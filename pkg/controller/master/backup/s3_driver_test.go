@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/harvester/harvester/pkg/settings"
+)
+
+func Test_newS3Driver(t *testing.T) {
+	// each fake S3 server records the access keys of the requests it receives
+	newServer := func(keys *[]string, lock *sync.Mutex) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			*keys = append(*keys, strings.Split(strings.TrimPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="), "/")[0])
+			lock.Unlock()
+			assert.True(t, strings.HasPrefix(req.URL.Path, "/bucket"), "path style request")
+			_, _ = rw.Write([]byte(`<ListBucketResult><Name>bucket</Name></ListBucketResult>`))
+		}))
+	}
+
+	var lock sync.Mutex
+	var keys1, keys2 []string
+	server1 := newServer(&keys1, &lock)
+	defer server1.Close()
+	server2 := newServer(&keys2, &lock)
+	defer server2.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		for _, target := range []*settings.BackupTarget{
+			{Type: settings.S3BackupType, Endpoint: server1.URL, BucketName: "bucket", BucketRegion: "us-east-1", AccessKeyID: "key1", SecretAccessKey: "secret1"},
+			{Type: settings.S3BackupType, Endpoint: server2.URL, BucketName: "bucket", BucketRegion: "us-east-1", AccessKeyID: "key2", SecretAccessKey: "secret2"},
+		} {
+			wg.Add(1)
+			go func(target *settings.BackupTarget) {
+				defer wg.Done()
+				driver, err := newS3Driver(target)
+				assert.Nil(t, err)
+				_, err = driver.List("harvester/vmbackups")
+				assert.Nil(t, err)
+			}(target)
+		}
+	}
+	wg.Wait()
+
+	assert.Len(t, keys1, 10)
+	assert.Len(t, keys2, 10)
+	for _, key := range keys1 {
+		assert.Equal(t, "key1", key)
+	}
+	for _, key := range keys2 {
+		assert.Equal(t, "key2", key)
+	}
+}
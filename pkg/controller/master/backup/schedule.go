@@ -0,0 +1,522 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	wranglername "github.com/rancher/wrangler/pkg/name"
+	"github.com/robfig/cron"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+)
+
+const (
+	backupScheduleControllerName         = "harvester-vm-backup-schedule-controller"
+	backupScheduleVMBackupControllerName = "harvester-vm-backup-schedule-vm-backup-controller"
+
+	// BackupScheduleLabel is set on the VirtualMachineBackups created by a VirtualMachineBackupSchedule
+	BackupScheduleLabel = "harvesterhci.io/vm-backup-schedule"
+
+	scheduledBackupTimeFormat = "20060102-150405"
+
+	lastRunReasonInProgress = "InProgress"
+)
+
+// RegisterBackupSchedule register the vm backup schedule controller
+func RegisterBackupSchedule(ctx context.Context, management *config.Management, opts config.Options) error {
+	schedules := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackupSchedule()
+	vmBackups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
+
+	scheduleHandler := &ScheduleHandler{
+		schedules:          schedules,
+		scheduleController: schedules,
+		vmBackups:          vmBackups,
+		vmBackupCache:      vmBackups.Cache(),
+		vmCache:            vms.Cache(),
+		backupTargetCache:  backupTargets.Cache(),
+	}
+
+	schedules.OnChange(ctx, backupScheduleControllerName, scheduleHandler.OnScheduleChange)
+	vmBackups.OnChange(ctx, backupScheduleVMBackupControllerName, scheduleHandler.OnVMBackupChange)
+	return nil
+}
+
+type ScheduleHandler struct {
+	schedules          ctlharvesterv1.VirtualMachineBackupScheduleClient
+	scheduleController ctlharvesterv1.VirtualMachineBackupScheduleController
+	vmBackups          ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupCache      ctlharvesterv1.VirtualMachineBackupCache
+	vmCache            ctlkubevirtv1.VirtualMachineCache
+	backupTargetCache  ctlharvesterv1.BackupTargetCache
+}
+
+// OnScheduleChange creates the VM backups when the schedule is due, prunes the old ones
+// according to the retention policy and requeues the schedule for the next run.
+func (h *ScheduleHandler) OnScheduleChange(key string, schedule *harvesterv1.VirtualMachineBackupSchedule) (*harvesterv1.VirtualMachineBackupSchedule, error) {
+	if schedule == nil || schedule.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	toUpdate := schedule.DeepCopy()
+	cronSchedule, err := cron.ParseStandard(schedule.Spec.Schedule)
+	if err != nil {
+		harvesterv1.BackupScheduleConditionReady.SetError(toUpdate, "InvalidSchedule", fmt.Errorf("failed to parse schedule %q: %w", schedule.Spec.Schedule, err))
+		return h.updateStatus(schedule, toUpdate)
+	}
+	harvesterv1.BackupScheduleConditionReady.SetError(toUpdate, "", nil)
+
+	if err := h.updateLastRun(toUpdate); err != nil {
+		return nil, err
+	}
+
+	if schedule.Spec.Suspend {
+		return h.updateStatus(schedule, toUpdate)
+	}
+
+	now := currentTime()
+	if err := h.reconcileVerification(schedule, toUpdate, now); err != nil {
+		return nil, err
+	}
+	lastRun := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		lastRun = schedule.Status.LastScheduleTime.Time
+	}
+
+	next := cronSchedule.Next(lastRun)
+	if now.Time.Before(next) {
+		h.scheduleController.EnqueueAfter(schedule.Namespace, schedule.Name, next.Sub(now.Time))
+		if err := h.pruneBackups(schedule); err != nil {
+			return nil, err
+		}
+		return h.updateStatus(schedule, toUpdate)
+	}
+
+	logrus.Debugf("OnScheduleChange: run backup schedule %s/%s", schedule.Namespace, schedule.Name)
+	results, err := h.runSchedule(schedule, next)
+	if err != nil {
+		return nil, err
+	}
+
+	toUpdate.Status.LastScheduleTime = now
+	toUpdate.Status.LastRunBackups = results
+	if err := h.updateLastRun(toUpdate); err != nil {
+		return nil, err
+	}
+
+	h.scheduleController.EnqueueAfter(schedule.Namespace, schedule.Name, cronSchedule.Next(now.Time).Sub(now.Time))
+	if err := h.pruneBackups(schedule); err != nil {
+		return nil, err
+	}
+	return h.updateStatus(schedule, toUpdate)
+}
+
+// OnVMBackupChange enqueues the schedule of a scheduled backup, so that the result of the latest run follows the
+// backups as they become ready or fail.
+func (h *ScheduleHandler) OnVMBackupChange(key string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	if vmBackup == nil {
+		return nil, nil
+	}
+	if scheduleName := vmBackup.Labels[BackupScheduleLabel]; scheduleName != "" {
+		h.scheduleController.Enqueue(vmBackup.Namespace, scheduleName)
+	}
+	return vmBackup, nil
+}
+
+// updateLastRun updates the LastRun condition with the backups of the latest run, the run is in progress until
+// all of them are ready, and it fails if a backup can't be created or fails.
+func (h *ScheduleHandler) updateLastRun(toUpdate *harvesterv1.VirtualMachineBackupSchedule) error {
+	if toUpdate.Status.LastScheduleTime == nil {
+		return nil
+	}
+
+	backups := make(map[string]*harvesterv1.VirtualMachineBackup, len(toUpdate.Status.LastRunBackups))
+	for _, result := range toUpdate.Status.LastRunBackups {
+		if result.BackupName == "" {
+			continue
+		}
+		backup, err := h.getVMBackup(toUpdate.Namespace, result.BackupName)
+		if err != nil {
+			return err
+		}
+		if backup != nil {
+			backups[result.BackupName] = backup
+		}
+	}
+
+	succeeded := toUpdate.Status.LastSuccessfulTime != nil && toUpdate.Status.LastSuccessfulTime.Equal(toUpdate.Status.LastScheduleTime)
+	failed, pending := reconcileLastRunBackups(toUpdate.Status.LastRunBackups, backups, succeeded)
+	switch {
+	case len(failed) > 0:
+		harvesterv1.BackupScheduleConditionLastRun.SetError(toUpdate, "", fmt.Errorf("failed to back up VMs: %s", strings.Join(failed, ", ")))
+	case pending:
+		harvesterv1.BackupScheduleConditionLastRun.Unknown(toUpdate)
+		harvesterv1.BackupScheduleConditionLastRun.Reason(toUpdate, lastRunReasonInProgress)
+		harvesterv1.BackupScheduleConditionLastRun.Message(toUpdate, "waiting for the VM backups to be ready")
+	default:
+		toUpdate.Status.LastSuccessfulTime = toUpdate.Status.LastScheduleTime
+		harvesterv1.BackupScheduleConditionLastRun.SetError(toUpdate, "", nil)
+	}
+	return nil
+}
+
+// getVMBackup returns nil if the backup is deleted, the API server is checked since a backup created by the latest
+// run may not be in the cache yet.
+func (h *ScheduleHandler) getVMBackup(namespace, name string) (*harvesterv1.VirtualMachineBackup, error) {
+	backup, err := h.vmBackupCache.Get(namespace, name)
+	if err == nil {
+		return backup, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	backup, err = h.vmBackups.Get(namespace, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return backup, err
+}
+
+// reconcileLastRunBackups sets the messages of the results to the errors of their backups, and returns the VMs failed to
+// be backed up and whether a backup is in progress. A backup missing in backups is deleted, it fails the run
+// unless the run has succeeded.
+func reconcileLastRunBackups(results []harvesterv1.ScheduledBackup, backups map[string]*harvesterv1.VirtualMachineBackup, succeeded bool) ([]string, bool) {
+	var failed []string
+	pending := false
+	for i := range results {
+		result := &results[i]
+		if result.Skipped {
+			continue
+		}
+		if result.BackupName == "" {
+			failed = append(failed, result.VMName)
+			continue
+		}
+
+		backup, ok := backups[result.BackupName]
+		switch {
+		case !ok:
+			if succeeded {
+				continue
+			}
+			if result.Message == "" {
+				result.Message = fmt.Sprintf("VM backup %s is deleted before it's ready", result.BackupName)
+			}
+			failed = append(failed, result.VMName)
+		case isBackupReady(backup):
+			result.Message = ""
+		case GetVMBackupError(backup) != nil:
+			if backupErr := GetVMBackupError(backup); backupErr.Message != nil {
+				result.Message = *backupErr.Message
+			}
+			failed = append(failed, result.VMName)
+		default:
+			pending = true
+		}
+	}
+	return failed, pending
+}
+
+// reconcileVerification requests to verify the latest ready backup of each VM when the verification schedule is due
+func (h *ScheduleHandler) reconcileVerification(schedule, toUpdate *harvesterv1.VirtualMachineBackupSchedule, now *metav1.Time) error {
+	verification := schedule.Spec.Verification
+	if verification == nil {
+		return nil
+	}
+
+	cronSchedule, err := cron.ParseStandard(verification.Schedule)
+	if err != nil {
+		harvesterv1.BackupScheduleConditionReady.SetError(toUpdate, "InvalidVerificationSchedule", fmt.Errorf("failed to parse verification schedule %q: %w", verification.Schedule, err))
+		return nil
+	}
+
+	lastRun := schedule.CreationTimestamp.Time
+	if schedule.Status.LastVerificationTime != nil {
+		lastRun = schedule.Status.LastVerificationTime.Time
+	}
+	if next := cronSchedule.Next(lastRun); now.Time.Before(next) {
+		h.scheduleController.EnqueueAfter(schedule.Namespace, schedule.Name, next.Sub(now.Time))
+		return nil
+	}
+
+	backups, err := h.vmBackupCache.List(schedule.Namespace, labels.SelectorFromSet(labels.Set{
+		BackupScheduleLabel: schedule.Name,
+	}))
+	if err != nil {
+		return err
+	}
+
+	latest := map[string]*harvesterv1.VirtualMachineBackup{}
+	for _, backup := range backups {
+		if backup.DeletionTimestamp != nil || !isBackupReady(backup) {
+			continue
+		}
+		if l, ok := latest[backup.Spec.Source.Name]; !ok || l.CreationTimestamp.Before(&backup.CreationTimestamp) {
+			latest[backup.Spec.Source.Name] = backup
+		}
+	}
+
+	for _, backup := range latest {
+		if IsBackupVerifying(backup) {
+			continue
+		}
+		logrus.Debugf("request to verify VM backup %s/%s of schedule %s", backup.Namespace, backup.Name, schedule.Name)
+		backupCpy := backup.DeepCopy()
+		if err := SetVerifyRequest(backupCpy, verification.BackupVerification); err != nil {
+			return err
+		}
+		if _, err := h.vmBackups.Update(backupCpy); err != nil {
+			return err
+		}
+	}
+
+	toUpdate.Status.LastVerificationTime = now
+	h.scheduleController.EnqueueAfter(schedule.Namespace, schedule.Name, cronSchedule.Next(now.Time).Sub(now.Time))
+	return nil
+}
+
+func (h *ScheduleHandler) updateStatus(schedule, toUpdate *harvesterv1.VirtualMachineBackupSchedule) (*harvesterv1.VirtualMachineBackupSchedule, error) {
+	if reflect.DeepEqual(schedule.Status, toUpdate.Status) {
+		return schedule, nil
+	}
+	return h.schedules.Update(toUpdate)
+}
+
+// runSchedule creates a backup for each of the selected VMs, a VM is skipped if it still has a backup in progress.
+// The backups are named after the scheduled time instead of the current time, so that a run retried because the
+// schedule status failed to be updated finds the backups it has created instead of creating them again.
+func (h *ScheduleHandler) runSchedule(schedule *harvesterv1.VirtualMachineBackupSchedule, scheduledTime time.Time) ([]harvesterv1.ScheduledBackup, error) {
+	vms, err := h.selectVMs(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	targetName := schedule.Spec.BackupTargetName
+	if targetName == "" {
+		targetName = harvesterv1.DefaultBackupTargetName
+	}
+	_, err = h.backupTargetCache.Get(targetName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	targetMissing := apierrors.IsNotFound(err)
+
+	results := make([]harvesterv1.ScheduledBackup, 0, len(vms))
+	for _, vm := range vms {
+		result := harvesterv1.ScheduledBackup{VMName: vm.Name}
+		if targetMissing {
+			result.Message = fmt.Sprintf("backup target %s is not configured", targetName)
+			results = append(results, result)
+			continue
+		}
+
+		backupName := wranglername.SafeConcatName(schedule.Name, vm.Name, scheduledTime.UTC().Format(scheduledBackupTimeFormat))
+		backup, err := h.getVMBackup(schedule.Namespace, backupName)
+		if err != nil {
+			return nil, err
+		}
+		if backup != nil {
+			result.BackupName = backupName
+			results = append(results, result)
+			continue
+		}
+
+		progressing, err := h.isVMBackupProgressing(vm)
+		if err != nil {
+			return nil, err
+		}
+		if progressing {
+			result.Skipped = true
+			result.Message = "the previous backup of the VM is still in progress"
+			results = append(results, result)
+			continue
+		}
+
+		if err := h.createVMBackup(schedule, vm, backupName); err != nil {
+			result.Message = err.Error()
+		} else {
+			result.BackupName = backupName
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (h *ScheduleHandler) selectVMs(schedule *harvesterv1.VirtualMachineBackupSchedule) ([]*kubevirtv1.VirtualMachine, error) {
+	selector := labels.Nothing()
+	if schedule.Spec.VMSelector.LabelSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(schedule.Spec.VMSelector.LabelSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool, len(schedule.Spec.VMSelector.Names))
+	for _, name := range schedule.Spec.VMSelector.Names {
+		names[name] = true
+	}
+
+	vms, err := h.vmCache.List(schedule.Namespace, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*kubevirtv1.VirtualMachine
+	for _, vm := range vms {
+		if vm.DeletionTimestamp != nil {
+			continue
+		}
+		if names[vm.Name] || selector.Matches(labels.Set(vm.Labels)) {
+			selected = append(selected, vm)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+	return selected, nil
+}
+
+func (h *ScheduleHandler) isVMBackupProgressing(vm *kubevirtv1.VirtualMachine) (bool, error) {
+	backups, err := h.vmBackupCache.List(vm.Namespace, labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, backup := range backups {
+		if backup.Spec.Source.Name == vm.Name && backup.DeletionTimestamp == nil && IsBackupProgressing(backup) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (h *ScheduleHandler) createVMBackup(schedule *harvesterv1.VirtualMachineBackupSchedule, vm *kubevirtv1.VirtualMachine, name string) error {
+	apiGroup := kubevirtv1.SchemeGroupVersion.Group
+	// backups don't refer to the schedule as owner, so that they are kept after the schedule is deleted
+	backup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: schedule.Namespace,
+			Labels: map[string]string{
+				BackupScheduleLabel: schedule.Name,
+			},
+		},
+		Spec: harvesterv1.VirtualMachineBackupSpec{
+			Source: corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+				Name:     vm.Name,
+			},
+			BackupTargetName: schedule.Spec.BackupTargetName,
+		},
+	}
+	if _, err := h.vmBackups.Create(backup); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create VM backup %s/%s: %w", schedule.Namespace, name, err)
+	}
+	return nil
+}
+
+// pruneBackups deletes the scheduled backups of each VM which are not kept by the retention policy.
+func (h *ScheduleHandler) pruneBackups(schedule *harvesterv1.VirtualMachineBackupSchedule) error {
+	backups, err := h.vmBackupCache.List(schedule.Namespace, labels.SelectorFromSet(labels.Set{
+		BackupScheduleLabel: schedule.Name,
+	}))
+	if err != nil {
+		return err
+	}
+
+	backupsByVM := map[string][]*harvesterv1.VirtualMachineBackup{}
+	for _, backup := range backups {
+		if backup.DeletionTimestamp != nil {
+			continue
+		}
+		backupsByVM[backup.Spec.Source.Name] = append(backupsByVM[backup.Spec.Source.Name], backup)
+	}
+
+	for _, vmBackups := range backupsByVM {
+		for _, backup := range getBackupsToPrune(vmBackups, schedule.Spec.Retention) {
+			logrus.Debugf("pruning VM backup %s/%s of schedule %s", backup.Namespace, backup.Name, schedule.Name)
+			if err := h.vmBackups.Delete(backup.Namespace, backup.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getBackupsToPrune returns the backups which are not kept by the retention policy.
+// Backups in progress are never pruned, failed backups are pruned once there is a newer ready backup.
+func getBackupsToPrune(backups []*harvesterv1.VirtualMachineBackup, retention harvesterv1.BackupRetentionPolicy) []*harvesterv1.VirtualMachineBackup {
+	var ready, failed []*harvesterv1.VirtualMachineBackup
+	for _, backup := range backups {
+		if isBackupReady(backup) {
+			ready = append(ready, backup)
+		} else if GetVMBackupError(backup) != nil {
+			failed = append(failed, backup)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[j].CreationTimestamp.Before(&ready[i].CreationTimestamp)
+	})
+
+	var toPrune []*harvesterv1.VirtualMachineBackup
+	if len(ready) > 0 {
+		for _, backup := range failed {
+			if backup.CreationTimestamp.Before(&ready[0].CreationTimestamp) {
+				toPrune = append(toPrune, backup)
+			}
+		}
+	}
+
+	if retention.KeepLast == 0 && retention.KeepDaily == 0 && retention.KeepWeekly == 0 {
+		return toPrune
+	}
+
+	keep := make(map[string]bool, len(ready))
+	for i := 0; i < retention.KeepLast && i < len(ready); i++ {
+		keep[ready[i].Name] = true
+	}
+	keepLatestPerPeriod(ready, retention.KeepDaily, keep, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatestPerPeriod(ready, retention.KeepWeekly, keep, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+
+	for _, backup := range ready {
+		if !keep[backup.Name] {
+			toPrune = append(toPrune, backup)
+		}
+	}
+	return toPrune
+}
+
+// keepLatestPerPeriod marks the latest backup of each of the latest n periods as kept,
+// backups must be sorted from the newest to the oldest.
+func keepLatestPerPeriod(backups []*harvesterv1.VirtualMachineBackup, n int, keep map[string]bool, period func(time.Time) string) {
+	seen := map[string]bool{}
+	for _, backup := range backups {
+		if len(seen) >= n {
+			return
+		}
+		p := period(backup.CreationTimestamp.Time)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		keep[backup.Name] = true
+	}
+}
@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func newScheduledBackup(name string, created time.Time, ready bool, failed bool) *harvesterv1.VirtualMachineBackup {
+	backup := &harvesterv1.VirtualMachineBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: &harvesterv1.VirtualMachineBackupStatus{
+			ReadyToUse: pointer.BoolPtr(ready),
+		},
+	}
+	if failed {
+		backup.Status.Error = &harvesterv1.Error{Message: pointer.StringPtr("failed")}
+	}
+	return backup
+}
+
+func Test_getBackupsToPrune(t *testing.T) {
+	// Monday
+	base := time.Date(2022, 3, 7, 2, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	backups := []*harvesterv1.VirtualMachineBackup{
+		newScheduledBackup("d0-a", base, true, false),
+		newScheduledBackup("d0-b", base.Add(time.Hour), true, false),
+		newScheduledBackup("d1", base.Add(day), true, false),
+		newScheduledBackup("d2-failed", base.Add(2*day), false, true),
+		newScheduledBackup("d3", base.Add(3*day), true, false),
+		newScheduledBackup("d7", base.Add(7*day), true, false),
+		newScheduledBackup("d8", base.Add(8*day), true, false),
+		newScheduledBackup("d9-progressing", base.Add(9*day), false, false),
+	}
+
+	var testCases = []struct {
+		name      string
+		retention harvesterv1.BackupRetentionPolicy
+		expected  []string
+	}{
+		{
+			name:      "no retention rules keep all ready backups",
+			retention: harvesterv1.BackupRetentionPolicy{},
+			expected:  []string{"d2-failed"},
+		},
+		{
+			name:      "keep last",
+			retention: harvesterv1.BackupRetentionPolicy{KeepLast: 2},
+			expected:  []string{"d0-a", "d0-b", "d1", "d2-failed", "d3"},
+		},
+		{
+			name:      "keep daily",
+			retention: harvesterv1.BackupRetentionPolicy{KeepDaily: 5},
+			expected:  []string{"d0-a", "d2-failed"},
+		},
+		{
+			name:      "keep weekly",
+			retention: harvesterv1.BackupRetentionPolicy{KeepWeekly: 2},
+			expected:  []string{"d0-a", "d0-b", "d1", "d2-failed", "d7"},
+		},
+		{
+			name:      "combined rules",
+			retention: harvesterv1.BackupRetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 2},
+			expected:  []string{"d0-a", "d0-b", "d1", "d2-failed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		var pruned []string
+		for _, backup := range getBackupsToPrune(backups, tc.retention) {
+			pruned = append(pruned, backup.Name)
+		}
+		sort.Strings(pruned)
+		assert.Equal(t, tc.expected, pruned, tc.name)
+	}
+}
+
+func Test_reconcileLastRunBackups(t *testing.T) {
+	now := time.Now()
+	newResults := func() []harvesterv1.ScheduledBackup {
+		return []harvesterv1.ScheduledBackup{
+			{VMName: "vm1", BackupName: "b1"},
+			{VMName: "vm2", BackupName: "b2"},
+			{VMName: "vm3", Skipped: true},
+			{VMName: "vm4", Message: "failed to create VM backup"},
+		}
+	}
+	tests := []struct {
+		name            string
+		backups         map[string]*harvesterv1.VirtualMachineBackup
+		succeeded       bool
+		expectedFailed  []string
+		expectedPending bool
+		expectedMessage string
+	}{
+		{
+			name: "backups in progress",
+			backups: map[string]*harvesterv1.VirtualMachineBackup{
+				"b1": newScheduledBackup("b1", now, true, false),
+				"b2": newScheduledBackup("b2", now, false, false),
+			},
+			expectedFailed:  []string{"vm4"},
+			expectedPending: true,
+		},
+		{
+			name: "backup failed after it's created",
+			backups: map[string]*harvesterv1.VirtualMachineBackup{
+				"b1": newScheduledBackup("b1", now, true, false),
+				"b2": newScheduledBackup("b2", now, false, true),
+			},
+			expectedFailed:  []string{"vm2", "vm4"},
+			expectedMessage: "failed",
+		},
+		{
+			name: "backup deleted before it's ready",
+			backups: map[string]*harvesterv1.VirtualMachineBackup{
+				"b1": newScheduledBackup("b1", now, true, false),
+			},
+			expectedFailed:  []string{"vm2", "vm4"},
+			expectedMessage: "VM backup b2 is deleted before it's ready",
+		},
+		{
+			name: "backup deleted after the run succeeded",
+			backups: map[string]*harvesterv1.VirtualMachineBackup{
+				"b1": newScheduledBackup("b1", now, true, false),
+			},
+			succeeded:      true,
+			expectedFailed: []string{"vm4"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			results := newResults()
+			failed, pending := reconcileLastRunBackups(results, tc.backups, tc.succeeded)
+			assert.Equal(t, tc.expectedFailed, failed)
+			assert.Equal(t, tc.expectedPending, pending)
+			assert.Equal(t, tc.expectedMessage, results[1].Message)
+		})
+	}
+}
+
+// fakeScheduleClient fails the first status updates of the schedule and records the updated schedules
+type fakeScheduleClient struct {
+	ctlharvesterv1.VirtualMachineBackupScheduleClient
+	failures int
+	updated  []*harvesterv1.VirtualMachineBackupSchedule
+}
+
+func (c *fakeScheduleClient) Update(schedule *harvesterv1.VirtualMachineBackupSchedule) (*harvesterv1.VirtualMachineBackupSchedule, error) {
+	c.updated = append(c.updated, schedule)
+	if c.failures > 0 {
+		c.failures--
+		return nil, apierrors.NewConflict(harvesterv1.Resource(harvesterv1.VirtualMachineBackupScheduleResourceName), schedule.Name, errors.New("conflict"))
+	}
+	return schedule, nil
+}
+
+type fakeScheduleController struct {
+	ctlharvesterv1.VirtualMachineBackupScheduleController
+}
+
+func (c fakeScheduleController) EnqueueAfter(namespace, name string, duration time.Duration) {}
+
+func Test_OnScheduleChange_retryAfterStatusUpdateFailed(t *testing.T) {
+	schedule := &harvesterv1.VirtualMachineBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "daily",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+		},
+		Spec: harvesterv1.VirtualMachineBackupScheduleSpec{
+			Schedule:   "0 0 * * *",
+			VMSelector: harvesterv1.VirtualMachineSelector{Names: []string{"vm1"}},
+		},
+	}
+	clientset := fake.NewSimpleClientset(
+		&kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm1"}},
+		&harvesterv1.BackupTarget{ObjectMeta: metav1.ObjectMeta{Name: harvesterv1.DefaultBackupTargetName}},
+	)
+	schedules := &fakeScheduleClient{failures: 1}
+	h := &ScheduleHandler{
+		schedules:          schedules,
+		scheduleController: fakeScheduleController{},
+		vmBackups:          fakeclients.VirtualMachineBackupClient(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmBackupCache:      fakeclients.VirtualMachineBackupCache(clientset.HarvesterhciV1beta1().VirtualMachineBackups),
+		vmCache:            fakeclients.VirtualMachineCache(clientset.KubevirtV1().VirtualMachines),
+		backupTargetCache:  fakeclients.BackupTargetCache(clientset.HarvesterhciV1beta1().BackupTargets),
+	}
+
+	now := time.Now()
+	defer func(f func() *metav1.Time) { currentTime = f }(currentTime)
+	currentTime = func() *metav1.Time { return &metav1.Time{Time: now} }
+
+	// the schedule is retried a minute later with the same status since the status update failed
+	_, err := h.OnScheduleChange("", schedule)
+	assert.NotNil(t, err)
+	now = now.Add(time.Minute)
+	_, err = h.OnScheduleChange("", schedule)
+	assert.Nil(t, err)
+
+	backups, err := clientset.HarvesterhciV1beta1().VirtualMachineBackups("default").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	if assert.Len(t, backups.Items, 1) && assert.Len(t, schedules.updated, 2) {
+		for _, updated := range schedules.updated {
+			assert.Equal(t, []harvesterv1.ScheduledBackup{{VMName: "vm1", BackupName: backups.Items[0].Name}}, updated.Status.LastRunBackups)
+		}
+	}
+}
@@ -32,11 +32,54 @@ func IsBackupProgressing(backup *harvesterv1.VirtualMachineBackup) bool {
 }
 
 func isBackupMissingStatus(backup *harvesterv1.VirtualMachineBackup) bool {
-	return backup.Status == nil || backup.Status.SourceSpec == nil || backup.Status.VolumeBackups == nil || backup.Status.BackupTarget == nil
+	return backup.Status == nil || backup.Status.SourceSpec == nil || backup.Status.VolumeBackups == nil ||
+		(!isVMSnapshot(backup) && backup.Status.BackupTarget == nil)
 }
 
-func IsBackupTargetSame(vmBackupTarget *harvesterv1.BackupTarget, target *settings.BackupTarget) bool {
-	return vmBackupTarget.Endpoint == target.Endpoint && vmBackupTarget.BucketName == target.BucketName && vmBackupTarget.BucketRegion == target.BucketRegion
+// isVMSnapshot returns true if the volume data of the backup is kept in the cluster instead of the backup target
+func isVMSnapshot(backup *harvesterv1.VirtualMachineBackup) bool {
+	return backup.Spec.Type == harvesterv1.Snapshot
+}
+
+func getVolumeSnapshotClassName(backup *harvesterv1.VirtualMachineBackup) string {
+	if isVMSnapshot(backup) {
+		return settings.LocalVolumeSnapshotClass.Get()
+	}
+	return settings.VolumeSnapshotClass.Get()
+}
+
+// GetBackupTargetName returns the name of the BackupTarget the backup is stored in
+func GetBackupTargetName(backup *harvesterv1.VirtualMachineBackup) string {
+	if backup.Spec.BackupTargetName == "" {
+		return harvesterv1.DefaultBackupTargetName
+	}
+	return backup.Spec.BackupTargetName
+}
+
+func IsBackupTargetSame(vmBackupTarget *harvesterv1.BackupTargetInfo, target *harvesterv1.BackupTarget) bool {
+	return vmBackupTarget.Endpoint == target.Spec.Endpoint && vmBackupTarget.BucketName == target.Spec.BucketName && vmBackupTarget.BucketRegion == target.Spec.BucketRegion
+}
+
+func newBackupTargetInfo(target *harvesterv1.BackupTarget) *harvesterv1.BackupTargetInfo {
+	return &harvesterv1.BackupTargetInfo{
+		Name:         target.Name,
+		Endpoint:     target.Spec.Endpoint,
+		BucketName:   target.Spec.BucketName,
+		BucketRegion: target.Spec.BucketRegion,
+	}
+}
+
+// isBackupTargetClaimed checks whether the backup has got the longhorn backup target to transfer its data
+func isBackupTargetClaimed(backup *harvesterv1.VirtualMachineBackup) bool {
+	if backup.Status == nil {
+		return false
+	}
+	for _, c := range backup.Status.Conditions {
+		if c.Type == harvesterv1.BackupConditionProgressing && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
 }
 
 func isBackupTargetOnAnnotation(backup *harvesterv1.VirtualMachineBackup) bool {
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+)
+
+func Test_isBackupMissingStatus(t *testing.T) {
+	newStatus := func(target *harvesterv1.BackupTargetInfo) *harvesterv1.VirtualMachineBackupStatus {
+		return &harvesterv1.VirtualMachineBackupStatus{
+			SourceSpec:    &harvesterv1.VirtualMachineSourceSpec{},
+			VolumeBackups: []harvesterv1.VolumeBackup{},
+			BackupTarget:  target,
+		}
+	}
+
+	var testCases = []struct {
+		name       string
+		backupType harvesterv1.BackupType
+		status     *harvesterv1.VirtualMachineBackupStatus
+		expected   bool
+	}{
+		{
+			name:       "backup without status",
+			backupType: harvesterv1.Backup,
+			expected:   true,
+		},
+		{
+			name:       "backup without target",
+			backupType: harvesterv1.Backup,
+			status:     newStatus(nil),
+			expected:   true,
+		},
+		{
+			name:       "backup with target",
+			backupType: harvesterv1.Backup,
+			status:     newStatus(&harvesterv1.BackupTargetInfo{Endpoint: "nfs://backup"}),
+		},
+		{
+			name:       "snapshot without target",
+			backupType: harvesterv1.Snapshot,
+			status:     newStatus(nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		vmBackup := &harvesterv1.VirtualMachineBackup{
+			Spec:   harvesterv1.VirtualMachineBackupSpec{Type: tc.backupType},
+			Status: tc.status,
+		}
+		assert.Equal(t, tc.expected, isBackupMissingStatus(vmBackup), tc.name)
+	}
+}
+
+func Test_getVolumeSnapshotClassName(t *testing.T) {
+	backup := &harvesterv1.VirtualMachineBackup{Spec: harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Backup}}
+	snapshot := &harvesterv1.VirtualMachineBackup{Spec: harvesterv1.VirtualMachineBackupSpec{Type: harvesterv1.Snapshot}}
+
+	assert.Equal(t, settings.VolumeSnapshotClass.Get(), getVolumeSnapshotClassName(backup))
+	assert.Equal(t, settings.LocalVolumeSnapshotClass.Get(), getVolumeSnapshotClassName(snapshot))
+	assert.NotEqual(t, getVolumeSnapshotClassName(backup), getVolumeSnapshotClassName(snapshot))
+}
@@ -0,0 +1,378 @@
+package backup
+
+// A VM backup is verified by restoring it as a new VM in a throwaway namespace with the networks detached.
+// The VM is verified once it boots and either its readiness probe passes or the guest agent is connected,
+// the throwaway namespace is removed with everything restored in it when the verification completes.
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	wranglername "github.com/rancher/wrangler/pkg/name"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/config"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	backupVerifyControllerName = "harvester-vm-backup-verify-controller"
+
+	// backupVerificationLabel is set on the throwaway namespaces, its value is the UID of the verified backup
+	backupVerificationLabel = "harvesterhci.io/backup-verification"
+
+	defaultVerificationTimeout = 15 * time.Minute
+	verificationCheckInterval  = 10 * time.Second
+
+	verifyReasonVerifying = "Verifying"
+	verifyReasonSucceeded = "Succeeded"
+	verifyReasonFailed    = "Failed"
+)
+
+// RegisterBackupVerification register the vm backup verification controller
+func RegisterBackupVerification(ctx context.Context, management *config.Management, opts config.Options) error {
+	vmBackups := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup()
+	restores := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineRestore()
+	namespaces := management.CoreFactory.Core().V1().Namespace()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+
+	handler := &VerifyHandler{
+		vmBackups:          vmBackups,
+		vmBackupController: vmBackups,
+		restores:           restores,
+		restoreCache:       restores.Cache(),
+		namespaces:         namespaces,
+		namespaceCache:     namespaces.Cache(),
+		vmiCache:           vmis.Cache(),
+	}
+
+	vmBackups.OnChange(ctx, backupVerifyControllerName, handler.OnBackupChange)
+	vmBackups.OnRemove(ctx, backupVerifyControllerName, handler.OnBackupRemove)
+	restores.OnChange(ctx, backupVerifyControllerName, handler.OnRestoreChange)
+	return nil
+}
+
+type VerifyHandler struct {
+	vmBackups          ctlharvesterv1.VirtualMachineBackupClient
+	vmBackupController ctlharvesterv1.VirtualMachineBackupController
+	restores           ctlharvesterv1.VirtualMachineRestoreClient
+	restoreCache       ctlharvesterv1.VirtualMachineRestoreCache
+	namespaces         ctlcorev1.NamespaceClient
+	namespaceCache     ctlcorev1.NamespaceCache
+	vmiCache           ctlkubevirtv1.VirtualMachineInstanceCache
+}
+
+// OnBackupChange handles the verify request of the backup
+func (h *VerifyHandler) OnBackupChange(key string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	if vmBackup == nil || vmBackup.DeletionTimestamp != nil || vmBackup.Status == nil {
+		return nil, nil
+	}
+
+	request, err := GetVerifyRequest(vmBackup)
+	if err != nil || request == nil {
+		return nil, err
+	}
+
+	verification := vmBackup.Status.Verification
+	if verification == nil || verification.RequestID != request.ID {
+		if !isBackupReady(vmBackup) {
+			if GetVMBackupError(vmBackup) != nil {
+				return nil, h.startVerification(vmBackup, request, fmt.Errorf("the backup is in error state"))
+			}
+			// the backup is enqueued again once it's ready
+			return nil, nil
+		}
+		return nil, h.startVerification(vmBackup, request, nil)
+	}
+
+	if verification.CompletionTime != nil {
+		return nil, nil
+	}
+	return nil, h.checkVerification(vmBackup, request)
+}
+
+// OnBackupRemove removes the throwaway namespace of an ongoing verification
+func (h *VerifyHandler) OnBackupRemove(key string, vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	if vmBackup == nil || vmBackup.Status == nil || vmBackup.Status.Verification == nil {
+		return nil, nil
+	}
+	return nil, h.deleteNamespace(vmBackup.Status.Verification.Namespace)
+}
+
+// OnRestoreChange enqueues the backup verified by the restore
+func (h *VerifyHandler) OnRestoreChange(key string, restore *harvesterv1.VirtualMachineRestore) (*harvesterv1.VirtualMachineRestore, error) {
+	if restore == nil {
+		return nil, nil
+	}
+	if _, ok := restore.Annotations[util.AnnotationBackupVerification]; ok {
+		h.vmBackupController.Enqueue(restore.Spec.VirtualMachineBackupNamespace, restore.Spec.VirtualMachineBackupName)
+	}
+	return nil, nil
+}
+
+// GetVerifyRequest returns the verify request of the backup, it's nil if the backup is never requested to verify
+func GetVerifyRequest(vmBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.BackupVerifyRequest, error) {
+	value, ok := vmBackup.Annotations[util.AnnotationBackupVerifyRequest]
+	if !ok {
+		return nil, nil
+	}
+	request := &harvesterv1.BackupVerifyRequest{}
+	if err := json.Unmarshal([]byte(value), request); err != nil {
+		return nil, fmt.Errorf("failed to decode the verify request of vm backup %s/%s: %w", vmBackup.Namespace, vmBackup.Name, err)
+	}
+	return request, nil
+}
+
+// SetVerifyRequest requests to verify the backup, the caller updates the backup
+func SetVerifyRequest(vmBackup *harvesterv1.VirtualMachineBackup, verification harvesterv1.BackupVerification) error {
+	request, err := json.Marshal(harvesterv1.BackupVerifyRequest{
+		ID:                 currentTime().UTC().Format(time.RFC3339Nano),
+		BackupVerification: verification,
+	})
+	if err != nil {
+		return err
+	}
+	if vmBackup.Annotations == nil {
+		vmBackup.Annotations = map[string]string{}
+	}
+	vmBackup.Annotations[util.AnnotationBackupVerifyRequest] = string(request)
+	return nil
+}
+
+// IsBackupVerifying returns true if the backup is being verified
+func IsBackupVerifying(vmBackup *harvesterv1.VirtualMachineBackup) bool {
+	return vmBackup.Status != nil && vmBackup.Status.Verification != nil && vmBackup.Status.Verification.CompletionTime == nil
+}
+
+func getVerificationNamespace(vmBackup *harvesterv1.VirtualMachineBackup, request *harvesterv1.BackupVerifyRequest) string {
+	return wranglername.SafeConcatName("verify", vmBackup.Name, wranglername.Hex(request.ID, 5))
+}
+
+func getVerificationTimeout(verification harvesterv1.BackupVerification) time.Duration {
+	if verification.Timeout == nil || verification.Timeout.Duration <= 0 {
+		return defaultVerificationTimeout
+	}
+	return verification.Timeout.Duration
+}
+
+func newVerifiedCondition(status corev1.ConditionStatus, reason string, message string) harvesterv1.Condition {
+	return harvesterv1.Condition{
+		Type:               harvesterv1.BackupConditionVerified,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: currentTime().Format(time.RFC3339),
+	}
+}
+
+// startVerification creates the throwaway namespace of a new verify request,
+// the verification fails at once if the backup can't be verified.
+func (h *VerifyHandler) startVerification(vmBackup *harvesterv1.VirtualMachineBackup, request *harvesterv1.BackupVerifyRequest, verifyErr error) error {
+	// a new request replaces the ongoing verification
+	if IsBackupVerifying(vmBackup) {
+		if err := h.deleteNamespace(vmBackup.Status.Verification.Namespace); err != nil {
+			return err
+		}
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	vmBackupCpy.Status.Verification = &harvesterv1.BackupVerificationStatus{
+		RequestID: request.ID,
+		StartTime: currentTime(),
+	}
+
+	if verifyErr == nil && isVMSnapshot(vmBackup) {
+		verifyErr = fmt.Errorf("VM snapshots are kept in the namespace of the VM, only backups can be verified")
+	}
+	if verifyErr != nil {
+		vmBackupCpy.Status.Verification.CompletionTime = currentTime()
+		updateBackupCondition(vmBackupCpy, newVerifiedCondition(corev1.ConditionFalse, verifyReasonFailed, verifyErr.Error()))
+		_, err := h.vmBackups.Update(vmBackupCpy)
+		return err
+	}
+
+	namespace := getVerificationNamespace(vmBackup, request)
+	logrus.Infof("verify vm backup %s/%s in namespace %s", vmBackup.Namespace, vmBackup.Name, namespace)
+	if _, err := h.namespaces.Create(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+			Labels: map[string]string{
+				backupVerificationLabel: string(vmBackup.UID),
+			},
+		},
+	}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	vmBackupCpy.Status.Verification.Namespace = namespace
+	updateBackupCondition(vmBackupCpy, newVerifiedCondition(corev1.ConditionUnknown, verifyReasonVerifying,
+		fmt.Sprintf("Restoring the backup in namespace %s", namespace)))
+	_, err := h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+// checkVerification restores the backup in the throwaway namespace and waits for the restored VM to be ready
+func (h *VerifyHandler) checkVerification(vmBackup *harvesterv1.VirtualMachineBackup, request *harvesterv1.BackupVerifyRequest) error {
+	verification := vmBackup.Status.Verification
+	if verification.StartTime != nil && currentTime().Sub(verification.StartTime.Time) > getVerificationTimeout(request.BackupVerification) {
+		return h.completeVerification(vmBackup, fmt.Errorf("the restored VM isn't ready after %s", getVerificationTimeout(request.BackupVerification)))
+	}
+
+	restore, err := h.restoreCache.Get(verification.Namespace, vmBackup.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if restore, err = h.createVerificationRestore(vmBackup, request); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return nil
+			}
+			return h.completeVerification(vmBackup, fmt.Errorf("failed to restore the backup: %w", err))
+		}
+	}
+
+	if restoreErr := getRestoreError(restore); restoreErr != "" {
+		return h.completeVerification(vmBackup, fmt.Errorf("failed to restore the backup: %s", restoreErr))
+	}
+
+	if restore.Status == nil || restore.Status.Complete == nil || !*restore.Status.Complete {
+		return h.updateVerifyingMessage(vmBackup, "Waiting for the restored VM to be ready")
+	}
+
+	// the restore is complete once the VM is ready, which means the readiness probe has passed
+	if request.ReadinessProbe == nil {
+		vmi, err := h.vmiCache.Get(verification.Namespace, vmBackup.Spec.Source.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if vmi == nil || !isAgentConnected(vmi) {
+			return h.updateVerifyingMessage(vmBackup, "Waiting for the guest agent of the restored VM to be connected")
+		}
+	}
+	return h.completeVerification(vmBackup, nil)
+}
+
+func (h *VerifyHandler) updateVerifyingMessage(vmBackup *harvesterv1.VirtualMachineBackup, message string) error {
+	h.vmBackupController.EnqueueAfter(vmBackup.Namespace, vmBackup.Name, verificationCheckInterval)
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	updateBackupCondition(vmBackupCpy, newVerifiedCondition(corev1.ConditionUnknown, verifyReasonVerifying, message))
+	if !reflect.DeepEqual(vmBackup.Status, vmBackupCpy.Status) {
+		if _, err := h.vmBackups.Update(vmBackupCpy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *VerifyHandler) createVerificationRestore(vmBackup *harvesterv1.VirtualMachineBackup, request *harvesterv1.BackupVerifyRequest) (*harvesterv1.VirtualMachineRestore, error) {
+	verification, err := json.Marshal(request.BackupVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	apiGroup := kubevirtv1.SchemeGroupVersion.Group
+	return h.restores.Create(&harvesterv1.VirtualMachineRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vmBackup.Name,
+			Namespace: vmBackup.Status.Verification.Namespace,
+			Annotations: map[string]string{
+				util.AnnotationBackupVerification: string(verification),
+			},
+		},
+		Spec: harvesterv1.VirtualMachineRestoreSpec{
+			Target: corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+				Name:     vmBackup.Spec.Source.Name,
+			},
+			VirtualMachineBackupName:      vmBackup.Name,
+			VirtualMachineBackupNamespace: vmBackup.Namespace,
+			NewVM:                         true,
+		},
+	})
+}
+
+// completeVerification records the result and removes the throwaway namespace
+func (h *VerifyHandler) completeVerification(vmBackup *harvesterv1.VirtualMachineBackup, verifyErr error) error {
+	if err := h.deleteNamespace(vmBackup.Status.Verification.Namespace); err != nil {
+		return err
+	}
+
+	vmBackupCpy := vmBackup.DeepCopy()
+	vmBackupCpy.Status.Verification.CompletionTime = currentTime()
+	if verifyErr != nil {
+		logrus.Infof("failed to verify vm backup %s/%s: %v", vmBackup.Namespace, vmBackup.Name, verifyErr)
+		updateBackupCondition(vmBackupCpy, newVerifiedCondition(corev1.ConditionFalse, verifyReasonFailed, verifyErr.Error()))
+	} else {
+		updateBackupCondition(vmBackupCpy, newVerifiedCondition(corev1.ConditionTrue, verifyReasonSucceeded, "The restored VM is ready"))
+	}
+	_, err := h.vmBackups.Update(vmBackupCpy)
+	return err
+}
+
+func (h *VerifyHandler) deleteNamespace(name string) error {
+	if name == "" {
+		return nil
+	}
+	namespace, err := h.namespaceCache.Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// only the throwaway namespaces are removed
+	if _, ok := namespace.Labels[backupVerificationLabel]; !ok || namespace.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := h.namespaces.Delete(name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func getRestoreError(restore *harvesterv1.VirtualMachineRestore) string {
+	if restore.Status == nil {
+		return ""
+	}
+	for _, c := range restore.Status.Conditions {
+		if c.Type == harvesterv1.BackupConditionReady && c.Status == corev1.ConditionFalse && c.Reason == "Error" {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// prepareVerificationVM detaches the networks of the VM restored for verification, so that it doesn't
+// conflict with the source VM, and sets the readiness probe of the verification.
+func prepareVerificationVM(restore *harvesterv1.VirtualMachineRestore, vm *kubevirtv1.VirtualMachine) error {
+	value, ok := restore.Annotations[util.AnnotationBackupVerification]
+	if !ok {
+		return nil
+	}
+	verification := harvesterv1.BackupVerification{}
+	if err := json.Unmarshal([]byte(value), &verification); err != nil {
+		return err
+	}
+
+	spec := &vm.Spec.Template.Spec
+	spec.Networks = nil
+	spec.Domain.Devices.Interfaces = nil
+	spec.Domain.Devices.AutoattachPodInterface = pointer.BoolPtr(false)
+	if verification.ReadinessProbe != nil {
+		spec.ReadinessProbe = verification.ReadinessProbe
+	}
+	return nil
+}
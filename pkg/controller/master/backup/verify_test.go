@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func Test_prepareVerificationVM(t *testing.T) {
+	newVM := func() *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Domain: kubevirtv1.DomainSpec{
+							Devices: kubevirtv1.Devices{
+								Interfaces: []kubevirtv1.Interface{{Name: "default"}},
+							},
+						},
+						Networks: []kubevirtv1.Network{{Name: "default", NetworkSource: kubevirtv1.NetworkSource{Pod: &kubevirtv1.PodNetwork{}}}},
+					},
+				},
+			},
+		}
+	}
+	newRestore := func(annotations map[string]string) *harvesterv1.VirtualMachineRestore {
+		return &harvesterv1.VirtualMachineRestore{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+
+	var testCases = []struct {
+		name           string
+		restore        *harvesterv1.VirtualMachineRestore
+		detached       bool
+		readinessProbe bool
+		expectError    bool
+	}{
+		{
+			name:    "not a verification restore",
+			restore: newRestore(nil),
+		},
+		{
+			name:     "wait for the guest agent",
+			restore:  newRestore(map[string]string{util.AnnotationBackupVerification: `{}`}),
+			detached: true,
+		},
+		{
+			name:           "readiness probe",
+			restore:        newRestore(map[string]string{util.AnnotationBackupVerification: `{"readinessProbe":{"tcpSocket":{"port":22}}}`}),
+			detached:       true,
+			readinessProbe: true,
+		},
+		{
+			name:        "invalid verification",
+			restore:     newRestore(map[string]string{util.AnnotationBackupVerification: `{`}),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		vm := newVM()
+		err := prepareVerificationVM(tc.restore, vm)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		spec := vm.Spec.Template.Spec
+		assert.Equal(t, tc.detached, len(spec.Networks) == 0 && len(spec.Domain.Devices.Interfaces) == 0, tc.name)
+		assert.Equal(t, tc.readinessProbe, spec.ReadinessProbe != nil, tc.name)
+	}
+}
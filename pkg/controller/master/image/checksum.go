@@ -0,0 +1,36 @@
+package image
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util/checksum"
+)
+
+// resolveChecksum returns the expected checksum of the image, the checksum url of an uploaded image is resolved
+// on upload since the name of the file to look up is unknown until then
+func (h *vmImageHandler) resolveChecksum(image *harvesterv1.VirtualMachineImage) (*checksum.Checksum, error) {
+	if image.Spec.Checksum != "" {
+		return checksum.Parse(image.Spec.Checksum)
+	}
+	if image.Spec.ChecksumURL == "" || image.Spec.SourceType != harvesterv1.VirtualMachineImageSourceTypeDownload {
+		return nil, nil
+	}
+
+	u, err := url.Parse(image.Spec.URL)
+	if err != nil {
+		return nil, err
+	}
+	return checksum.FindInURL(context.Background(), &h.httpClient, image.Spec.ChecksumURL, path.Base(u.Path))
+}
+
+// getLonghornChecksum returns the checksum longhorn verifies, longhorn only supports sha512
+func getLonghornChecksum(image *harvesterv1.VirtualMachineImage) string {
+	expected, err := checksum.Parse(image.Status.Checksum)
+	if err != nil || expected.Algorithm != checksum.SHA512 {
+		return ""
+	}
+	return expected.Digest
+}
@@ -0,0 +1,444 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	ctlbatchv1 "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	wranglername "github.com/rancher/wrangler/pkg/name"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/checksum"
+	"github.com/harvester/harvester/pkg/util/imageformat"
+)
+
+const (
+	downloadWorkDir   = "/data"
+	downloadComponent = "image-download"
+
+	// downloadBackoffLimit is how many times a failed download is retried, the job controller
+	// backs off exponentially between the retries
+	downloadBackoffLimit = 6
+	// downloadScratchOverhead is added to the scratch volume for the files of the conversion
+	downloadScratchOverhead = 1 << 30
+
+	downloadFailedReason   = "DownloadFailed"
+	conversionFailedReason = "ConversionFailed"
+	convertingReason       = "Converting"
+	retryingReason         = "Retrying"
+	checksumMismatchReason = "ChecksumMismatch"
+
+	downloadFailedPrefix = "failed to download the image"
+)
+
+// downloadScript downloads the image from the urls tried in order, verifies its checksum, converts it to qcow2
+// if the format is set and uploads it to the backing image. The partial download is resumed when the job retries
+// since the work directory is a volume. The virtual size of the disk is written to the termination message on
+// success, the reason on failure.
+const downloadScript = `
+set -f
+fail() { echo "$1" > /dev/termination-log; exit 1; }
+cd ` + downloadWorkDir + `
+
+if [ ! -f downloaded ]; then
+  rm -f errors
+  for url in $SOURCE_URLS; do
+    curl -fsSL -C - $CURL_OPTIONS -o source "$url" 2>err && touch downloaded && break
+    echo "$url: $(cat err)" >> errors
+  done
+  [ -f downloaded ] || fail "` + downloadFailedPrefix + `, $(tr '\n' ' ' < errors)"
+
+  if [ -n "$CHECKSUM" ]; then
+    actual=$(${CHECKSUM_ALGORITHM}sum source | cut -d ' ' -f 1)
+    if [ "$actual" != "$CHECKSUM" ]; then
+      rm -f source downloaded
+      fail "checksum mismatch: expected $CHECKSUM_ALGORITHM $CHECKSUM, got $actual"
+    fi
+  fi
+fi
+
+if [ ! -f converted ]; then
+  if [ "$SOURCE_FORMAT" = "ova" ]; then
+    disk=$(tar -tf source | grep -i '\.vmdk$' | head -n 1)
+    [ -n "$disk" ] || fail "there is no VMDK disk in the OVA"
+    tar -xOf source "$disk" > extracted 2>err || fail "failed to extract $disk from the OVA: $(cat err)"
+    SOURCE_FORMAT=vmdk
+  else
+    ln -f source extracted
+  fi
+
+  if [ -n "$SOURCE_FORMAT" ]; then
+    qemu-img convert -f "$SOURCE_FORMAT" -O qcow2 extracted disk 2>err || fail "failed to convert the image: $(cat err)"
+  else
+    ln -f extracted disk
+  fi
+  rm -f extracted
+  touch converted
+fi
+size=$(stat -c %s disk)
+
+# longhorn accepts the upload once the backing image data source is started
+for i in $(seq 1 30); do
+  curl -fsS -F "chunk=@disk" "$UPLOAD_URL?action=upload&size=$size" >/dev/null 2>err && uploaded=true && break
+  sleep 10
+done
+[ "$uploaded" = "true" ] || fail "failed to upload the image: $(cat err)"
+
+qemu-img info --output=json disk | grep -o '"virtual-size": *[0-9]*' | grep -o '[0-9]*$' > /dev/termination-log
+`
+
+// qemuImgFormats are the names of the formats in qemu-img
+var qemuImgFormats = map[imageformat.Format]string{
+	imageformat.VMDK: "vmdk",
+	imageformat.VHD:  "vpc",
+	imageformat.VHDX: "vhdx",
+	imageformat.OVA:  "ova",
+}
+
+// probe returns the first reachable url of the image with the size of the image
+func (h *vmImageHandler) probe(image *harvesterv1.VirtualMachineImage) (string, int64, error) {
+	var errs []string
+	for _, url := range getDownloadURLs(image) {
+		resp, err := h.httpClient.Head(url)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			errs = append(errs, fmt.Sprintf("got %d status code from %s", resp.StatusCode, url))
+			continue
+		}
+		return url, resp.ContentLength, nil
+	}
+	return "", 0, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// detectFormat reads the header of the image at the URL to detect its format
+func (h *vmImageHandler) detectFormat(url string, size int64) (*imageformat.Info, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the server may ignore the range and return the whole image, only the header is read anyway
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", imageformat.HeaderSize-1))
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("got %d status code from %s", resp.StatusCode, url)
+	}
+	header, err := ioutil.ReadAll(io.LimitReader(resp.Body, imageformat.HeaderSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the image header: %w", err)
+	}
+	return imageformat.Detect(header, size)
+}
+
+// getDownloadURLs returns the url and the mirrors of the image in the order they're tried
+func getDownloadURLs(image *harvesterv1.VirtualMachineImage) []string {
+	return append([]string{image.Spec.URL}, image.Spec.Mirrors...)
+}
+
+// isDownloadedByJob returns true if the image is downloaded by a job which uploads it to the backing image, it's
+// only used when longhorn can't download the image itself: longhorn can't try the mirrors, limit the bandwidth,
+// convert the image or verify a checksum other than sha512. The format and the checksum are resolved in the status
+// when the image is initialized.
+func isDownloadedByJob(image *harvesterv1.VirtualMachineImage) bool {
+	if image.Spec.SourceType != harvesterv1.VirtualMachineImageSourceTypeDownload {
+		return false
+	}
+	if len(image.Spec.Mirrors) > 0 {
+		return true
+	}
+	// an invalid limit fails the job instead of downloading the image without the limit
+	if limit, err := getBandwidthLimit(image); err != nil || limit > 0 {
+		return true
+	}
+	if imageformat.Format(image.Status.Format).NeedsConversion() {
+		return true
+	}
+	return image.Status.Checksum != "" && getLonghornChecksum(image) == ""
+}
+
+func getDownloadJobName(image *harvesterv1.VirtualMachineImage) string {
+	return wranglername.SafeConcatName("download-image", image.Name)
+}
+
+// getBandwidthLimit returns the bandwidth limit of the image in bytes per second, the one of the image
+// overrides the setting. It's 0 if the bandwidth isn't limited.
+func getBandwidthLimit(image *harvesterv1.VirtualMachineImage) (int64, error) {
+	if image.Spec.BandwidthLimit != "" {
+		return util.ParseBandwidthLimit(image.Spec.BandwidthLimit)
+	}
+	return util.ParseBandwidthLimit(settings.ImageDownloadBandwidthLimit.Get())
+}
+
+// getScratchVolume returns the volume the image is downloaded to. The download is resumed from a PVC when the
+// job retries. An emptyDir is used if the server doesn't return the size of the image, the download is resumed
+// when the container restarts but starts over if the pod is recreated, e.g. after the node restarts.
+func getScratchVolume(image *harvesterv1.VirtualMachineImage) (*corev1.PersistentVolumeClaim, corev1.Volume) {
+	volume := corev1.Volume{Name: "data"}
+	if image.Status.Size <= 0 {
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		return nil, volume
+	}
+
+	// the downloaded file, the disk extracted from an OVA and the converted disk
+	size := image.Status.Size
+	if imageformat.Format(image.Status.Format).NeedsConversion() {
+		size = 3 * size
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getDownloadJobName(image),
+			Namespace: image.Namespace,
+			Labels: labels.Set{
+				util.LabelImageDownload: image.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{getImageOwnerReference(image)},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: *resource.NewQuantity(size+downloadScratchOverhead, resource.BinarySI),
+				},
+			},
+		},
+	}
+	volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}
+	return pvc, volume
+}
+
+func getImageOwnerReference(image *harvesterv1.VirtualMachineImage) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       "VirtualMachineImage",
+		Name:       image.Name,
+		UID:        image.UID,
+	}
+}
+
+func (h *vmImageHandler) createDownloadJob(image *harvesterv1.VirtualMachineImage) error {
+	downloaderImage, pullPolicy, err := settings.GetImageDownloaderImage()
+	if err != nil {
+		return err
+	}
+	var checksumAlgorithm, digest string
+	if image.Status.Checksum != "" {
+		expected, err := checksum.Parse(image.Status.Checksum)
+		if err != nil {
+			return err
+		}
+		checksumAlgorithm, digest = string(expected.Algorithm), expected.Digest
+	}
+	bandwidthLimit, err := getBandwidthLimit(image)
+	if err != nil {
+		return err
+	}
+	// the transient errors like timeouts are retried by curl before the next url is tried
+	curlOptions := "--retry 3 --retry-delay 10"
+	if bandwidthLimit > 0 {
+		curlOptions = fmt.Sprintf("%s --limit-rate %d", curlOptions, bandwidthLimit)
+	}
+
+	pvc, volume := getScratchVolume(image)
+	if pvc != nil {
+		if _, err := h.pvcs.Create(pvc); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	podLabels := labels.Set{
+		"app.kubernetes.io/name":      "harvester",
+		"app.kubernetes.io/component": downloadComponent,
+		util.LabelImageDownload:       image.Name,
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getDownloadJobName(image),
+			Namespace: image.Namespace,
+			Labels: labels.Set{
+				util.LabelImageDownload: image.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{getImageOwnerReference(image)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(downloadBackoffLimit),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:            "download",
+							Image:           downloaderImage,
+							ImagePullPolicy: pullPolicy,
+							Command:         []string{"/bin/sh", "-c", downloadScript},
+							Env: []corev1.EnvVar{
+								{Name: "SOURCE_URLS", Value: strings.Join(getDownloadURLs(image), " ")},
+								{Name: "SOURCE_FORMAT", Value: qemuImgFormats[imageformat.Format(image.Status.Format)]},
+								{Name: "CURL_OPTIONS", Value: curlOptions},
+								{Name: "CHECKSUM_ALGORITHM", Value: checksumAlgorithm},
+								{Name: "CHECKSUM", Value: digest},
+								{Name: "UPLOAD_URL", Value: fmt.Sprintf(backingImageUploadURL, getBackingImageName(image))},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: volume.Name, MountPath: downloadWorkDir},
+							},
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+					Volumes: []corev1.Volume{volume},
+				},
+			},
+		},
+	}
+
+	_, err = h.jobs.Create(job)
+	return err
+}
+
+// deleteDownloadJob deletes the job and the scratch volume of the image
+func deleteDownloadJob(jobs ctlbatchv1.JobClient, pvcs ctlcorev1.PersistentVolumeClaimClient, image *harvesterv1.VirtualMachineImage) error {
+	propagation := metav1.DeletePropagationBackground
+	if err := jobs.Delete(image.Namespace, getDownloadJobName(image), &metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := pvcs.Delete(image.Namespace, getDownloadJobName(image), &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// downloadJobHandler syncs the progress and the result of the download jobs to the vm images
+type downloadJobHandler struct {
+	images     ctlharvesterv1.VirtualMachineImageClient
+	imageCache ctlharvesterv1.VirtualMachineImageCache
+	podCache   ctlcorev1.PodCache
+	jobs       ctlbatchv1.JobClient
+	pvcs       ctlcorev1.PersistentVolumeClaimClient
+}
+
+func (h *downloadJobHandler) OnChanged(_ string, job *batchv1.Job) (*batchv1.Job, error) {
+	if job == nil || job.DeletionTimestamp != nil || job.Labels[util.LabelImageDownload] == "" {
+		return job, nil
+	}
+	image, err := h.imageCache.Get(job.Namespace, job.Labels[util.LabelImageDownload])
+	if errors.IsNotFound(err) {
+		return job, nil
+	} else if err != nil {
+		return job, err
+	}
+	if !harvesterv1.ImageImported.IsUnknown(image) {
+		return job, nil
+	}
+
+	message, err := h.getLastTerminationMessage(job)
+	if err != nil {
+		return job, err
+	}
+
+	toUpdate := image.DeepCopy()
+	switch {
+	case isJobConditionTrue(job, batchv1.JobComplete):
+		// the backing image handler marks the image imported once longhorn has the image
+		if virtualSize, err := strconv.ParseInt(message, 10, 64); err == nil {
+			toUpdate.Status.VirtualSize = virtualSize
+		}
+		if err := h.pvcs.Delete(job.Namespace, job.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return job, err
+		}
+	case isJobConditionTrue(job, batchv1.JobFailed) || strings.HasPrefix(message, checksum.ErrMismatch.Error()):
+		// a checksum mismatch isn't retried since the file on the server won't change
+		if message == "" {
+			message = downloadFailedPrefix
+		}
+		harvesterv1.ImageImported.False(toUpdate)
+		harvesterv1.ImageImported.Reason(toUpdate, getDownloadFailedReason(message))
+		harvesterv1.ImageImported.Message(toUpdate, message)
+		if err := deleteDownloadJob(h.jobs, h.pvcs, image); err != nil {
+			return job, err
+		}
+	case job.Status.Failed > 0 && toUpdate.Status.Progress == 0:
+		harvesterv1.ImageImported.Reason(toUpdate, retryingReason)
+		harvesterv1.ImageImported.Message(toUpdate, fmt.Sprintf("attempt %d of %d failed, retrying: %s",
+			job.Status.Failed, downloadBackoffLimit+1, message))
+	case toUpdate.Status.Progress == 0 && imageformat.Format(image.Status.Format).NeedsConversion():
+		harvesterv1.ImageImported.Reason(toUpdate, convertingReason)
+		harvesterv1.ImageImported.Message(toUpdate, fmt.Sprintf("downloading and converting the %s image to qcow2", image.Status.Format))
+	}
+
+	if !reflect.DeepEqual(image, toUpdate) {
+		if _, err := h.images.Update(toUpdate); err != nil {
+			return job, err
+		}
+	}
+	return job, nil
+}
+
+func getDownloadFailedReason(message string) string {
+	switch {
+	case strings.HasPrefix(message, checksum.ErrMismatch.Error()):
+		return checksumMismatchReason
+	case strings.HasPrefix(message, downloadFailedPrefix):
+		return downloadFailedReason
+	}
+	return conversionFailedReason
+}
+
+// getLastTerminationMessage returns the termination message of the last terminated pod of the job
+func (h *downloadJobHandler) getLastTerminationMessage(job *batchv1.Job) (string, error) {
+	pods, err := h.podCache.List(job.Namespace, labels.SelectorFromSet(labels.Set{"job-name": job.Name}))
+	if err != nil {
+		return "", err
+	}
+	var terminated []*corev1.ContainerStateTerminated
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				terminated = append(terminated, status.State.Terminated)
+			}
+		}
+	}
+	if len(terminated) == 0 {
+		return "", nil
+	}
+	sort.Slice(terminated, func(i, j int) bool {
+		return terminated[i].FinishedAt.Before(&terminated[j].FinishedAt)
+	})
+	return strings.TrimSpace(terminated[len(terminated)-1].Message), nil
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
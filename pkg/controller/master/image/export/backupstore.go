@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/longhorn/backupstore"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util/checksum"
+)
+
+// An image in the backup target is a directory of the parts of the exported file and a manifest. The file is split
+// since the backup store drivers write from a seekable reader, the manifest is written last so that an incomplete
+// export isn't imported.
+const (
+	folderPath   = "harvester/vmimages"
+	manifestFile = "manifest.json"
+	partsFolder  = "parts"
+
+	// DefaultPartSize is the size of the parts, a part is buffered in memory while it's written
+	DefaultPartSize = 32 << 20
+)
+
+// Manifest describes an image exported to the backup target
+type Manifest struct {
+	DisplayName string `json:"displayName"`
+	Format      string `json:"format"`
+	Size        int64  `json:"size"`
+	VirtualSize int64  `json:"virtualSize,omitempty"`
+	// Checksum is the sha512 checksum of the file in the <algorithm>:<digest> form
+	Checksum string `json:"checksum"`
+	PartSize int64  `json:"partSize"`
+	Parts    int    `json:"parts"`
+}
+
+// GetBackupStorePath returns the directory of the image exported in the format in the backup target
+func GetBackupStorePath(image *harvesterv1.VirtualMachineImage, format string) string {
+	return path.Join(folderPath, image.Namespace, GetFileName(image, format))
+}
+
+func getPartPath(dir string, i int) string {
+	return path.Join(dir, partsFolder, fmt.Sprintf("part-%05d", i))
+}
+
+// WriteToBackupStore writes the file read from r to the directory in the backup target, the previous export in the
+// directory is replaced. The size, checksum and parts of the manifest are filled in from the file.
+func WriteToBackupStore(driver backupstore.BackupStoreDriver, dir string, manifest Manifest, partSize int64, r io.Reader) (*Manifest, error) {
+	if driver.FileExists(dir) {
+		if err := driver.Remove(dir); err != nil {
+			return nil, fmt.Errorf("failed to remove the previous export %s: %w", dir, err)
+		}
+	}
+
+	hash := sha512.New()
+	r = io.TeeReader(r, hash)
+	buf := make([]byte, partSize)
+	manifest.Size, manifest.PartSize, manifest.Parts = 0, partSize, 0
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if writeErr := driver.Write(getPartPath(dir, manifest.Parts), bytes.NewReader(buf[:n])); writeErr != nil {
+				return nil, fmt.Errorf("failed to write part %d of %s: %w", manifest.Parts, dir, writeErr)
+			}
+			manifest.Parts++
+			manifest.Size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	manifest.Checksum = (&checksum.Checksum{Algorithm: checksum.SHA512, Digest: hex.EncodeToString(hash.Sum(nil))}).String()
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := driver.Write(path.Join(dir, manifestFile), bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to write the manifest of %s: %w", dir, err)
+	}
+	return &manifest, nil
+}
+
+// ReadManifest reads the manifest of the image in the directory of the backup target
+func ReadManifest(driver backupstore.BackupStoreDriver, dir string) (*Manifest, error) {
+	manifestPath := path.Join(dir, manifestFile)
+	if !driver.FileExists(manifestPath) {
+		return nil, fmt.Errorf("there is no exported image in %s", dir)
+	}
+	rc, err := driver.Read(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(rc).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// partsReader reads the parts of an exported file in order
+type partsReader struct {
+	driver  backupstore.BackupStoreDriver
+	dir     string
+	parts   int
+	next    int
+	current io.ReadCloser
+}
+
+// OpenFromBackupStore returns the reader of the file exported to the directory of the backup target
+func OpenFromBackupStore(driver backupstore.BackupStoreDriver, dir string, manifest *Manifest) io.ReadCloser {
+	return &partsReader{driver: driver, dir: dir, parts: manifest.Parts}
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next >= r.parts {
+				return 0, io.EOF
+			}
+			rc, err := r.driver.Read(getPartPath(r.dir, r.next))
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part %d of %s: %w", r.next, r.dir, err)
+			}
+			r.current = rc
+			r.next++
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			_ = r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver keeps the files in memory
+type fakeDriver struct {
+	files map[string][]byte
+}
+
+func newFakeDriver() *fakeDriver {
+	return &fakeDriver{files: map[string][]byte{}}
+}
+
+func (d *fakeDriver) Kind() string   { return "fake" }
+func (d *fakeDriver) GetURL() string { return "fake://" }
+
+func (d *fakeDriver) FileExists(filePath string) bool {
+	for name := range d.files {
+		if name == filePath || strings.HasPrefix(name, filePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *fakeDriver) FileSize(filePath string) int64     { return int64(len(d.files[filePath])) }
+func (d *fakeDriver) FileTime(filePath string) time.Time { return time.Time{} }
+
+func (d *fakeDriver) Remove(path string) error {
+	for name := range d.files {
+		if name == path || strings.HasPrefix(name, path+"/") {
+			delete(d.files, name)
+		}
+	}
+	return nil
+}
+
+func (d *fakeDriver) Read(src string) (io.ReadCloser, error) {
+	data, ok := d.files[src]
+	if !ok {
+		return nil, fmt.Errorf("%s is not found", src)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (d *fakeDriver) Write(dst string, rs io.ReadSeeker) error {
+	data, err := ioutil.ReadAll(rs)
+	if err != nil {
+		return err
+	}
+	d.files[dst] = data
+	return nil
+}
+
+func (d *fakeDriver) List(path string) ([]string, error) {
+	var names []string
+	for name := range d.files {
+		if strings.HasPrefix(name, path+"/") {
+			names = append(names, strings.TrimPrefix(name, path+"/"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *fakeDriver) Upload(src, dst string) error   { return fmt.Errorf("not implemented") }
+func (d *fakeDriver) Download(src, dst string) error { return fmt.Errorf("not implemented") }
+
+func Test_BackupStoreRoundTrip(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		size          int
+		partSize      int64
+		expectedParts int
+	}{
+		{
+			name:          "empty file",
+			size:          0,
+			partSize:      4,
+			expectedParts: 0,
+		},
+		{
+			name:          "file of whole parts",
+			size:          12,
+			partSize:      4,
+			expectedParts: 3,
+		},
+		{
+			name:          "file with a partial part",
+			size:          10,
+			partSize:      4,
+			expectedParts: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		driver := newFakeDriver()
+		// a stale part of a previous export is removed
+		driver.files["harvester/vmimages/default/image.qcow2/parts/part-00009"] = []byte("stale")
+
+		data := bytes.Repeat([]byte("a"), tc.size)
+		digest := sha512.Sum512(data)
+		dir := "harvester/vmimages/default/image.qcow2"
+		manifest, err := WriteToBackupStore(driver, dir, Manifest{DisplayName: "image", Format: "qcow2"}, tc.partSize, bytes.NewReader(data))
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, &Manifest{
+			DisplayName: "image",
+			Format:      "qcow2",
+			Size:        int64(tc.size),
+			Checksum:    "sha512:" + hex.EncodeToString(digest[:]),
+			PartSize:    tc.partSize,
+			Parts:       tc.expectedParts,
+		}, manifest, tc.name)
+
+		read, err := ReadManifest(driver, dir)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, manifest, read, tc.name)
+
+		parts, _ := driver.List(dir + "/parts")
+		assert.Equal(t, tc.expectedParts, len(parts), tc.name)
+
+		rc := OpenFromBackupStore(driver, dir, read)
+		imported, err := ioutil.ReadAll(rc)
+		assert.Nil(t, err, tc.name)
+		assert.Nil(t, rc.Close(), tc.name)
+		assert.Equal(t, data, imported, tc.name)
+	}
+}
+
+func Test_ReadManifest_NotExported(t *testing.T) {
+	_, err := ReadManifest(newFakeDriver(), "harvester/vmimages/default/image.raw")
+	assert.NotNil(t, err)
+}
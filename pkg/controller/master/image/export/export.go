@@ -0,0 +1,448 @@
+package export
+
+// An image is exported by a helper pod with a read-only volume created from the backing image of the image.
+// The disk is streamed from the block device of the volume by running head in the pod. qemu-img can't write a qcow2
+// file to stdout, so the disk is converted to a compressed qcow2 file in a scratch volume sized for the disk first,
+// and the file is streamed once the conversion is done. The conversion runs in the main process of the pod on the
+// first qcow2 request, the requests are refused with ErrNotReady until it's done. The helper pod and the volumes are
+// created on the first download or export of the image, and are removed by the image controller once they're idle,
+// or by the garbage collector with the image.
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	containerName = "image-export"
+	devicePath    = "/dev/image"
+	scratchDir    = "/export"
+
+	qcow2File          = scratchDir + "/image.qcow2"
+	convertRequestFile = scratchDir + "/convert.requested"
+	convertLogFile     = scratchDir + "/convert.log"
+	convertFailedFile  = scratchDir + "/convert.failed"
+
+	convertStateReady      = "ready"
+	convertStateFailed     = "failed"
+	convertStateConverting = "converting"
+
+	// IdleTimeout is how long the helper pod of an image is kept after the last download or export
+	IdleTimeout = 30 * time.Minute
+
+	// the access time is recorded at most once in the interval to avoid updating the pod for every request
+	accessRecordInterval = time.Minute
+
+	vmImageKind = "VirtualMachineImage"
+)
+
+// ErrNotReady is returned when the helper pod of the image isn't ready to export the image
+var ErrNotReady = errors.New("the image is being prepared for export")
+
+// helperScript is the main process of the helper pod, it converts the disk to a compressed qcow2 file once the
+// conversion is requested. The error of a failed conversion is kept in the scratch volume until the pod is recreated.
+var helperScript = fmt.Sprintf(`rm -f %[1]s.tmp %[3]s %[4]s
+until [ -f %[1]s ] || [ -f %[2]s ]; do sleep 1; done
+if [ ! -f %[1]s ]; then
+  if qemu-img convert -c -f raw -O qcow2 %[5]s %[1]s.tmp 2>%[3]s; then mv %[1]s.tmp %[1]s; else rm -f %[1]s.tmp; mv %[3]s %[4]s; fi
+fi
+exec sleep infinity`, qcow2File, convertRequestFile, convertLogFile, convertFailedFile, devicePath)
+
+// convertStateScript requests the conversion and prints its state, the size of the qcow2 file is printed once it's ready
+var convertStateScript = fmt.Sprintf(`touch %[2]s
+if [ -f %[1]s ]; then echo %[4]s $(stat -c %%s %[1]s)
+elif [ -f %[3]s ]; then echo %[5]s $(cat %[3]s)
+else echo %[6]s; fi`, qcow2File, convertRequestFile, convertFailedFile, convertStateReady, convertStateFailed, convertStateConverting)
+
+type Exporter struct {
+	restConfig *rest.Config
+	clientSet  kubernetes.Interface
+	pods       ctlcorev1.PodClient
+	podCache   ctlcorev1.PodCache
+	pvcs       ctlcorev1.PersistentVolumeClaimClient
+	pvcCache   ctlcorev1.PersistentVolumeClaimCache
+}
+
+func NewExporter(restConfig *rest.Config, clientSet kubernetes.Interface, pods ctlcorev1.PodController,
+	pvcs ctlcorev1.PersistentVolumeClaimController) *Exporter {
+	return &Exporter{
+		restConfig: restConfig,
+		clientSet:  clientSet,
+		pods:       pods,
+		podCache:   pods.Cache(),
+		pvcs:       pvcs,
+		pvcCache:   pvcs.Cache(),
+	}
+}
+
+// GetName returns the name of the helper pod and the volume of the image
+func GetName(image *harvesterv1.VirtualMachineImage) string {
+	return name.SafeConcatName("image-export", image.Name)
+}
+
+// GetScratchName returns the name of the scratch volume of the helper pod
+func GetScratchName(exportName string) string {
+	return name.SafeConcatName(exportName, "scratch")
+}
+
+// GetFileName returns the name of the file the image is exported to
+func GetFileName(image *harvesterv1.VirtualMachineImage, format string) string {
+	return fmt.Sprintf("%s.%s", image.Name, format)
+}
+
+// GetRawSize returns the size of the raw disk, it's 0 if it's unknown
+func GetRawSize(image *harvesterv1.VirtualMachineImage) int64 {
+	if image.Status.VirtualSize > 0 {
+		return image.Status.VirtualSize
+	}
+	if image.Status.Format == harvesterv1.ImageExportFormatRaw {
+		return image.Status.Size
+	}
+	return 0
+}
+
+// GetPod returns the ready helper pod of the image, the pod is created if it doesn't exist.
+// ErrNotReady is returned until the pod is ready.
+func (e *Exporter) GetPod(image *harvesterv1.VirtualMachineImage) (*corev1.Pod, error) {
+	if !harvesterv1.ImageImported.IsTrue(image) {
+		return nil, fmt.Errorf("image %s/%s is not imported", image.Namespace, image.Name)
+	}
+
+	exportName := GetName(image)
+	pod, err := e.podCache.Get(image.Namespace, exportName)
+	if apierrors.IsNotFound(err) {
+		if err := e.createPVC(image, exportName); err != nil {
+			return nil, err
+		}
+		if err := e.createScratchPVC(image, exportName); err != nil {
+			return nil, err
+		}
+		if pod, err = e.createPod(image, exportName); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || !isPodReady(pod) {
+		return nil, ErrNotReady
+	}
+	return pod, e.RecordAccess(pod)
+}
+
+// Prepare returns the size of the disk in the format, it's 0 if it's unknown. The conversion to qcow2 is started
+// on the first call, ErrNotReady is returned until it's done. The helper pod is removed if the conversion fails,
+// so that the next request starts over.
+func (e *Exporter) Prepare(pod *corev1.Pod, image *harvesterv1.VirtualMachineImage, format string) (int64, error) {
+	switch format {
+	case harvesterv1.ImageExportFormatRaw:
+		return GetRawSize(image), nil
+	case harvesterv1.ImageExportFormatQCOW2:
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	var stdout bytes.Buffer
+	if err := e.exec(pod, []string{"/bin/sh", "-c", convertStateScript}, &stdout); err != nil {
+		return 0, err
+	}
+	state := strings.Fields(stdout.String())
+	if len(state) == 0 {
+		return 0, fmt.Errorf("unknown state of the qcow2 conversion of image %s/%s", image.Namespace, image.Name)
+	}
+	switch state[0] {
+	case convertStateReady:
+		if len(state) != 2 {
+			return 0, fmt.Errorf("unknown size of the qcow2 file of image %s/%s", image.Namespace, image.Name)
+		}
+		return strconv.ParseInt(state[1], 10, 64)
+	case convertStateConverting:
+		return 0, ErrNotReady
+	case convertStateFailed:
+		if err := e.pods.Delete(pod.Namespace, pod.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to convert image %s/%s to qcow2: %s", image.Namespace, image.Name, strings.Join(state[1:], " "))
+	default:
+		return 0, fmt.Errorf("unknown state %q of the qcow2 conversion of image %s/%s", state[0], image.Namespace, image.Name)
+	}
+}
+
+// WaitForExport waits for the helper pod of the image to be ready and the disk to be prepared in the format,
+// it returns the pod and the size of the disk.
+func (e *Exporter) WaitForExport(ctx context.Context, image *harvesterv1.VirtualMachineImage, format string, interval time.Duration) (*corev1.Pod, int64, error) {
+	for {
+		pod, err := e.GetPod(image)
+		if err == nil {
+			size, err := e.Prepare(pod, image, format)
+			if !errors.Is(err, ErrNotReady) {
+				return pod, size, err
+			}
+		} else if !errors.Is(err, ErrNotReady) {
+			return nil, 0, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func newOwnerReference(image *harvesterv1.VirtualMachineImage) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       vmImageKind,
+		Name:       image.Name,
+		UID:        image.UID,
+	}
+}
+
+func (e *Exporter) createPVC(image *harvesterv1.VirtualMachineImage, exportName string) error {
+	if _, err := e.pvcCache.Get(image.Namespace, exportName); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	size := GetRawSize(image)
+	if size == 0 {
+		size = image.Status.Size
+	}
+	volumeMode := corev1.PersistentVolumeBlock
+	_, err := e.pvcs.Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            exportName,
+			Namespace:       image.Namespace,
+			Labels:          map[string]string{util.LabelImageExport: image.Name},
+			Annotations:     map[string]string{util.AnnotationImageID: fmt.Sprintf("%s/%s", image.Namespace, image.Name)},
+			OwnerReferences: []metav1.OwnerReference{newOwnerReference(image)},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: pointer.StringPtr(image.Status.StorageClassName),
+			VolumeMode:       &volumeMode,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: *resource.NewQuantity(size, resource.BinarySI),
+				},
+			},
+		},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// createScratchPVC creates the volume keeping the qcow2 file, a compressed qcow2 file is at most as large as the disk
+// with the metadata of qcow2, the 10% extra space is for the metadata and the filesystem.
+// The volume is created from the default storage class, the one of the image is bound to the backing image.
+func (e *Exporter) createScratchPVC(image *harvesterv1.VirtualMachineImage, exportName string) error {
+	scratchName := GetScratchName(exportName)
+	if _, err := e.pvcCache.Get(image.Namespace, scratchName); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	size := GetRawSize(image)
+	if size == 0 {
+		size = image.Status.Size
+	}
+	size += size / 10
+	volumeMode := corev1.PersistentVolumeFilesystem
+	_, err := e.pvcs.Create(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            scratchName,
+			Namespace:       image.Namespace,
+			Labels:          map[string]string{util.LabelImageExport: image.Name},
+			OwnerReferences: []metav1.OwnerReference{newOwnerReference(image)},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			VolumeMode:  &volumeMode,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: *resource.NewQuantity(size, resource.BinarySI),
+				},
+			},
+		},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (e *Exporter) createPod(image *harvesterv1.VirtualMachineImage, exportName string) (*corev1.Pod, error) {
+	exportImage, pullPolicy, err := settings.GetImageDownloaderImage()
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := e.pods.Create(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            exportName,
+			Namespace:       image.Namespace,
+			Labels:          map[string]string{util.LabelImageExport: image.Name},
+			Annotations:     map[string]string{util.AnnotationImageExportAccessed: time.Now().UTC().Format(time.RFC3339)},
+			OwnerReferences: []metav1.OwnerReference{newOwnerReference(image)},
+		},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: pointer.BoolPtr(false),
+			RestartPolicy:                corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:            containerName,
+				Image:           exportImage,
+				ImagePullPolicy: pullPolicy,
+				Command:         []string{"/bin/sh", "-c", helperScript},
+				VolumeDevices:   []corev1.VolumeDevice{{Name: "image", DevicePath: devicePath}},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "scratch", MountPath: scratchDir}},
+			}},
+			Volumes: []corev1.Volume{
+				{
+					Name: "image",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: exportName, ReadOnly: true},
+					},
+				},
+				{
+					Name: "scratch",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: GetScratchName(exportName)},
+					},
+				},
+			},
+		},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		return e.pods.Get(image.Namespace, exportName, metav1.GetOptions{})
+	}
+	return pod, err
+}
+
+// RecordAccess postpones the idle cleanup of the helper pod
+func (e *Exporter) RecordAccess(pod *corev1.Pod) error {
+	pod, err := e.podCache.Get(pod.Namespace, pod.Name)
+	if err != nil {
+		return err
+	}
+	if accessed, err := time.Parse(time.RFC3339, pod.Annotations[util.AnnotationImageExportAccessed]); err == nil && time.Since(accessed) < accessRecordInterval {
+		return nil
+	}
+	podCpy := pod.DeepCopy()
+	if podCpy.Annotations == nil {
+		podCpy.Annotations = map[string]string{}
+	}
+	podCpy.Annotations[util.AnnotationImageExportAccessed] = time.Now().UTC().Format(time.RFC3339)
+	if _, err := e.pods.Update(podCpy); err != nil && !apierrors.IsConflict(err) {
+		return err
+	}
+	return nil
+}
+
+// GetIdleTime returns how long the helper pod isn't accessed
+func GetIdleTime(pod *corev1.Pod, now time.Time) time.Duration {
+	accessed := pod.CreationTimestamp.Time
+	if t, err := time.Parse(time.RFC3339, pod.Annotations[util.AnnotationImageExportAccessed]); err == nil {
+		accessed = t
+	}
+	return now.Sub(accessed)
+}
+
+// getCommand returns the command writing the disk in the format to stdout, the qcow2 file is written once it's prepared
+func getCommand(image *harvesterv1.VirtualMachineImage, format string) ([]string, error) {
+	switch format {
+	case harvesterv1.ImageExportFormatRaw:
+		// the volume may be larger than the disk
+		if size := GetRawSize(image); size > 0 {
+			return []string{"head", "-c", strconv.FormatInt(size, 10), devicePath}, nil
+		}
+		return []string{"cat", devicePath}, nil
+	case harvesterv1.ImageExportFormatQCOW2:
+		return []string{"cat", qcow2File}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// Stream writes the disk of the image in the format to w, the disk must be prepared in the format first.
+// The access time of the helper pod is recorded while it's streaming, so that a long export isn't cleaned up as an idle one.
+func (e *Exporter) Stream(ctx context.Context, pod *corev1.Pod, image *harvesterv1.VirtualMachineImage, format string, w io.Writer) error {
+	command, err := getCommand(image, format)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(accessRecordInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = e.RecordAccess(pod)
+			}
+		}
+	}()
+
+	return e.exec(pod, command, w)
+}
+
+// exec runs the command in the helper pod and writes its stdout to w
+func (e *Exporter) exec(pod *corev1.Pod, command []string, w io.Writer) error {
+	req := e.clientSet.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).
+		SubResource("exec").VersionedParams(&corev1.PodExecOptions{
+		Container: containerName,
+		Command:   command,
+		Stdout:    true,
+		Stderr:    true,
+	}, k8sscheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(e.restConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: w, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
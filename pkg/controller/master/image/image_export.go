@@ -0,0 +1,170 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	exportPodWaitInterval = 5 * time.Second
+	// the timeout covers the conversion of a large disk to qcow2
+	exportPrepareTimeout = 2 * time.Hour
+)
+
+// imageExportHandler exports the images to the backup targets as requested by the exportToBackupTarget action,
+// and cleans up the idle helper pods of the exports and downloads
+type imageExportHandler struct {
+	ctx               context.Context
+	images            ctlharvesterv1.VirtualMachineImageClient
+	imageCache        ctlharvesterv1.VirtualMachineImageCache
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	secretCache       ctlcorev1.SecretCache
+	pods              ctlcorev1.PodController
+	pvcs              ctlcorev1.PersistentVolumeClaimClient
+	exporter          *export.Exporter
+
+	// exporting are the exports in progress, keyed by the image UID, the backup target and the format
+	exporting sync.Map
+}
+
+// OnChanged starts the pending exports, the exports interrupted by a restart of the controller are started over
+func (h *imageExportHandler) OnChanged(_ string, image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if image == nil || image.DeletionTimestamp != nil {
+		return image, nil
+	}
+	for _, e := range image.Status.Exports {
+		if e.State == harvesterv1.ImageExportStatePending || e.State == harvesterv1.ImageExportStateExporting {
+			h.start(image, e)
+		}
+	}
+	return image, nil
+}
+
+func (h *imageExportHandler) start(image *harvesterv1.VirtualMachineImage, e harvesterv1.VirtualMachineImageExport) {
+	key := fmt.Sprintf("%s/%s/%s", image.UID, e.BackupTarget, e.Format)
+	if _, loaded := h.exporting.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer h.exporting.Delete(key)
+
+		logrus.Infof("export image %s/%s to backup target %s in %s", image.Namespace, image.Name, e.BackupTarget, e.Format)
+		now := metav1.Now()
+		if err := h.updateExport(image, e, func(toUpdate *harvesterv1.VirtualMachineImageExport) {
+			toUpdate.State = harvesterv1.ImageExportStateExporting
+			toUpdate.StartTime = &now
+			toUpdate.CompletionTime = nil
+			toUpdate.Message = ""
+		}); err != nil {
+			logrus.Errorf("failed to update image %s/%s: %v", image.Namespace, image.Name, err)
+			return
+		}
+
+		manifest, exportErr := h.export(image, e)
+		completed := metav1.Now()
+		if err := h.updateExport(image, e, func(toUpdate *harvesterv1.VirtualMachineImageExport) {
+			toUpdate.CompletionTime = &completed
+			if exportErr != nil {
+				toUpdate.State = harvesterv1.ImageExportStateFailed
+				toUpdate.Message = exportErr.Error()
+				return
+			}
+			toUpdate.State = harvesterv1.ImageExportStateCompleted
+			toUpdate.Size = manifest.Size
+		}); err != nil {
+			logrus.Errorf("failed to update image %s/%s: %v", image.Namespace, image.Name, err)
+		}
+		if exportErr != nil {
+			logrus.Errorf("failed to export image %s/%s to backup target %s: %v", image.Namespace, image.Name, e.BackupTarget, exportErr)
+		}
+	}()
+}
+
+// export streams the image from the helper pod to the backup target
+func (h *imageExportHandler) export(image *harvesterv1.VirtualMachineImage, e harvesterv1.VirtualMachineImageExport) (*export.Manifest, error) {
+	target, err := h.backupTargetCache.Get(e.BackupTarget)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := ctlbackup.GetBackupStoreDriver(h.secretCache, target)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, exportPrepareTimeout)
+	pod, _, err := h.exporter.WaitForExport(ctx, image, e.Format, exportPodWaitInterval)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare the image for export: %w", err)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(h.exporter.Stream(h.ctx, pod, image, e.Format, w))
+	}()
+	manifest, err := export.WriteToBackupStore(driver, e.Path, export.Manifest{
+		DisplayName: image.Spec.DisplayName,
+		Format:      e.Format,
+		VirtualSize: export.GetRawSize(image),
+	}, export.DefaultPartSize, r)
+	// stop the streaming if the backup target fails
+	_ = r.CloseWithError(err)
+	return manifest, err
+}
+
+func (h *imageExportHandler) updateExport(image *harvesterv1.VirtualMachineImage, e harvesterv1.VirtualMachineImageExport,
+	mutate func(toUpdate *harvesterv1.VirtualMachineImageExport)) error {
+	return updateImage(h.images, h.imageCache, image.Namespace, image.Name, func(toUpdate *harvesterv1.VirtualMachineImage) {
+		for i := range toUpdate.Status.Exports {
+			if toUpdate.Status.Exports[i].BackupTarget == e.BackupTarget && toUpdate.Status.Exports[i].Format == e.Format {
+				mutate(&toUpdate.Status.Exports[i])
+			}
+		}
+	})
+}
+
+// OnPodChanged removes the helper pod and the volumes of an image export once it's idle for the timeout,
+// a failed helper pod is removed immediately so that the next request recreates it.
+func (h *imageExportHandler) OnPodChanged(_ string, pod *corev1.Pod) (*corev1.Pod, error) {
+	if pod == nil || pod.DeletionTimestamp != nil {
+		return pod, nil
+	}
+	if _, ok := pod.Labels[util.LabelImageExport]; !ok {
+		return pod, nil
+	}
+
+	if pod.Status.Phase != corev1.PodFailed && pod.Status.Phase != corev1.PodSucceeded {
+		if idle := export.GetIdleTime(pod, time.Now()); idle < export.IdleTimeout {
+			h.pods.EnqueueAfter(pod.Namespace, pod.Name, export.IdleTimeout-idle)
+			return pod, nil
+		}
+	}
+
+	logrus.Infof("clean up image export %s/%s", pod.Namespace, pod.Name)
+	if err := h.pods.Delete(pod.Namespace, pod.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return pod, err
+	}
+	// the helper PVC has the same name as the pod
+	for _, pvcName := range []string{pod.Name, export.GetScratchName(pod.Name)} {
+		if err := h.pvcs.Delete(pod.Namespace, pvcName, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return pod, err
+		}
+	}
+	return pod, nil
+}
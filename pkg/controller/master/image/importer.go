@@ -0,0 +1,266 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/longhorn/backupstore"
+	lhv1beta1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctllhv1beta1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/imageformat"
+	"github.com/harvester/harvester/pkg/util/oci"
+)
+
+const (
+	backingImageUploadURL = "http://longhorn-backend.longhorn-system:9500/v1/backingimages/%s"
+
+	importWaitInterval = 2 * time.Second
+	importWaitTimeout  = 2 * time.Minute
+)
+
+// importer pulls the disk of an OCI image, or reads an image exported to a backup target, and uploads it to
+// the backing image of the VM image. Longhorn reports the progress of the upload as it does for the images
+// uploaded by users.
+type importer struct {
+	ctx                         context.Context
+	httpClient                  http.Client
+	images                      ctlharvesterv1.VirtualMachineImageClient
+	imageCache                  ctlharvesterv1.VirtualMachineImageCache
+	secretCache                 ctlcorev1.SecretCache
+	backupTargetCache           ctlharvesterv1.BackupTargetCache
+	backingImageDataSourceCache ctllhv1beta1.BackingImageDataSourceCache
+
+	// importing are the UIDs of the images being imported
+	importing sync.Map
+}
+
+// start imports the image in the background if it's not being imported
+func (i *importer) start(image *harvesterv1.VirtualMachineImage) {
+	if _, loaded := i.importing.LoadOrStore(image.UID, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer i.importing.Delete(image.UID)
+
+		var err error
+		if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeBackupTarget {
+			logrus.Infof("import image %s/%s from backup target %s", image.Namespace, image.Name, image.Spec.BackupTarget.Name)
+			err = i.importFromBackupTarget(image)
+		} else {
+			logrus.Infof("import image %s/%s from %s", image.Namespace, image.Name, image.Spec.OCI.Reference)
+			err = i.importFromOCI(image)
+		}
+		if err != nil {
+			logrus.Errorf("failed to import image %s/%s: %v", image.Namespace, image.Name, err)
+			if updateErr := i.setImportFailed(image, err); updateErr != nil {
+				logrus.Errorf("failed to update image %s/%s: %v", image.Namespace, image.Name, updateErr)
+			}
+		}
+	}()
+}
+
+func (i *importer) importFromOCI(image *harvesterv1.VirtualMachineImage) error {
+	source := image.Spec.OCI
+	ref, err := oci.ParseReference(source.Reference, source.Digest)
+	if err != nil {
+		return err
+	}
+	creds, err := i.getCredentials(image.Namespace, source.PullSecretName)
+	if err != nil {
+		return err
+	}
+
+	// longhorn accepts the upload once the data source is started
+	if err := i.waitForDataSource(getBackingImageName(image)); err != nil {
+		return err
+	}
+
+	disk, err := oci.Open(i.ctx, ref, creds)
+	if err != nil {
+		return err
+	}
+	defer disk.Close()
+
+	info, r, err := imageformat.Peek(disk, disk.Size)
+	if err != nil {
+		return err
+	}
+	if info.Format.NeedsConversion() {
+		return fmt.Errorf("the %s disk in the image must be converted to qcow2 or raw", info.Format)
+	}
+
+	if err := i.updateImage(image.Namespace, image.Name, func(toUpdate *harvesterv1.VirtualMachineImage) {
+		toUpdate.Status.OCIDigest = disk.Digest
+		toUpdate.Status.Size = disk.Size
+		toUpdate.Status.Format = string(info.Format)
+		toUpdate.Status.VirtualSize = info.VirtualSize
+	}); err != nil {
+		return err
+	}
+	return i.upload(getBackingImageName(image), r, disk.Size)
+}
+
+// importFromBackupTarget uploads the parts of the image exported to the backup target, the checksum in the manifest
+// is verified by longhorn
+func (i *importer) importFromBackupTarget(image *harvesterv1.VirtualMachineImage) error {
+	driver, err := getBackupStoreDriver(i.backupTargetCache, i.secretCache, image.Spec.BackupTarget.Name)
+	if err != nil {
+		return err
+	}
+	manifest, err := export.ReadManifest(driver, image.Spec.BackupTarget.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := i.waitForDataSource(getBackingImageName(image)); err != nil {
+		return err
+	}
+
+	rc := export.OpenFromBackupStore(driver, image.Spec.BackupTarget.Path, manifest)
+	defer rc.Close()
+	return i.upload(getBackingImageName(image), rc, manifest.Size)
+}
+
+// getBackupStoreDriver returns the driver of the backup target
+func getBackupStoreDriver(backupTargetCache ctlharvesterv1.BackupTargetCache, secretCache ctlcorev1.SecretCache,
+	backupTargetName string) (backupstore.BackupStoreDriver, error) {
+	target, err := backupTargetCache.Get(backupTargetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup target %s: %w", backupTargetName, err)
+	}
+	return ctlbackup.GetBackupStoreDriver(secretCache, target)
+}
+
+// getCredentials returns the credentials in the pull secret
+func (i *importer) getCredentials(namespace, secretName string) (oci.Credentials, error) {
+	if secretName == "" {
+		return nil, nil
+	}
+	secret, err := i.secretCache.Get(namespace, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull secret %s/%s: %w", namespace, secretName, err)
+	}
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, fmt.Errorf("pull secret %s/%s is not a %s secret", namespace, secretName, corev1.SecretTypeDockerConfigJson)
+	}
+	return oci.CredentialsFromDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+}
+
+func (i *importer) waitForDataSource(name string) error {
+	for waited := time.Duration(0); waited < importWaitTimeout; waited += importWaitInterval {
+		ds, err := i.backingImageDataSourceCache.Get(util.LonghornSystemNamespaceName, name)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			switch ds.Status.CurrentState {
+			case lhv1beta1.BackingImageStateStarting:
+				return nil
+			case lhv1beta1.BackingImageStateFailed:
+				return fmt.Errorf("backing image data source failed: %s", ds.Status.Message)
+			}
+		}
+
+		select {
+		case <-i.ctx.Done():
+			return i.ctx.Err()
+		case <-time.After(importWaitInterval):
+		}
+	}
+	return fmt.Errorf("timeout waiting for backing image data source %s to be ready", name)
+}
+
+// upload sends the disk to longhorn the same way as the upload action of the images
+func (i *importer) upload(backingImageName string, disk io.Reader, size int64) error {
+	r, w := io.Pipe()
+	m := multipart.NewWriter(w)
+	go func() {
+		part, err := m.CreateFormFile("chunk", "blob")
+		if err == nil {
+			_, err = io.Copy(part, disk)
+		}
+		if err == nil {
+			err = m.Close()
+		}
+		w.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(i.ctx, http.MethodPost, fmt.Sprintf(backingImageUploadURL, backingImageName), r)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("action", "upload")
+	q.Add("size", strconv.FormatInt(size, 10))
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Content-Type", m.FormDataContentType())
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to backing image %s: %w", backingImageName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("upload failed: %s", string(body))
+	}
+	return nil
+}
+
+func (i *importer) setImportFailed(image *harvesterv1.VirtualMachineImage, importErr error) error {
+	return i.updateImage(image.Namespace, image.Name, func(toUpdate *harvesterv1.VirtualMachineImage) {
+		harvesterv1.ImageImported.False(toUpdate)
+		harvesterv1.ImageImported.Reason(toUpdate, "ImportFailed")
+		harvesterv1.ImageImported.Message(toUpdate, importErr.Error())
+	})
+}
+
+func (i *importer) updateImage(namespace, name string, mutate func(image *harvesterv1.VirtualMachineImage)) error {
+	return updateImage(i.images, i.imageCache, namespace, name, mutate)
+}
+
+// updateImage applies the mutation to the image in the cache and retries on conflicts
+func updateImage(images ctlharvesterv1.VirtualMachineImageClient, imageCache ctlharvesterv1.VirtualMachineImageCache,
+	namespace, name string, mutate func(image *harvesterv1.VirtualMachineImage)) error {
+	retry := 3
+	for j := 0; j < retry; j++ {
+		current, err := imageCache.Get(namespace, name)
+		if err != nil {
+			return err
+		}
+		if current.DeletionTimestamp != nil {
+			return nil
+		}
+		toUpdate := current.DeepCopy()
+		mutate(toUpdate)
+		if reflect.DeepEqual(current, toUpdate) {
+			return nil
+		}
+		_, err = images.Update(toUpdate)
+		if err == nil || !errors.IsConflict(err) {
+			return err
+		}
+		time.Sleep(importWaitInterval)
+	}
+	return fmt.Errorf("failed to update image %s/%s, max retries exceeded", namespace, name)
+}
@@ -6,11 +6,15 @@ import (
 	"time"
 
 	"github.com/harvester/harvester/pkg/config"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
 )
 
 const (
 	vmImageControllerName      = "vm-image-controller"
 	backingImageControllerName = "backing-image-controller"
+	downloadJobControllerName  = "image-download-job-controller"
+	imageUsageControllerName   = "vm-image-usage-controller"
+	imageExportControllerName  = "vm-image-export-controller"
 )
 
 func Register(ctx context.Context, management *config.Management, options config.Options) error {
@@ -18,14 +22,38 @@ func Register(ctx context.Context, management *config.Management, options config
 	images := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
 	storageClasses := management.StorageFactory.Storage().V1().StorageClass()
 	pvcs := management.CoreFactory.Core().V1().PersistentVolumeClaim()
+	settings := management.HarvesterFactory.Harvesterhci().V1beta1().Setting()
+	secrets := management.CoreFactory.Core().V1().Secret()
+	backingImageDataSources := management.LonghornFactory.Longhorn().V1beta1().BackingImageDataSource()
+	jobs := management.BatchFactory.Batch().V1().Job()
+	pods := management.CoreFactory.Core().V1().Pod()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+	templateVersions := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion()
+	lhNodes := management.LonghornFactory.Longhorn().V1beta1().Node()
+	backupTargets := management.HarvesterFactory.Harvesterhci().V1beta1().BackupTarget()
 	vmImageHandler := &vmImageHandler{
-		backingImages:  backingImages,
-		storageClasses: storageClasses,
-		images:         images,
+		backingImages:     backingImages,
+		storageClasses:    storageClasses,
+		storageClassCache: storageClasses.Cache(),
+		images:            images,
+		imageCache:        images.Cache(),
 		httpClient: http.Client{
 			Timeout: 15 * time.Second,
 		},
-		pvcCache: pvcs.Cache(),
+		pvcs:              pvcs,
+		pvcCache:          pvcs.Cache(),
+		settingCache:      settings.Cache(),
+		backupTargetCache: backupTargets.Cache(),
+		secretCache:       secrets.Cache(),
+		jobs:              jobs,
+		importer: &importer{
+			ctx:                         ctx,
+			images:                      images,
+			imageCache:                  images.Cache(),
+			secretCache:                 secrets.Cache(),
+			backupTargetCache:           backupTargets.Cache(),
+			backingImageDataSourceCache: backingImageDataSources.Cache(),
+		},
 	}
 	backingImageHandler := &backingImageHandler{
 		vmImages:          images,
@@ -33,9 +61,48 @@ func Register(ctx context.Context, management *config.Management, options config
 		backingImages:     backingImages,
 		backingImageCache: backingImages.Cache(),
 	}
+	downloadJobHandler := &downloadJobHandler{
+		images:     images,
+		imageCache: images.Cache(),
+		podCache:   pods.Cache(),
+		jobs:       jobs,
+		pvcs:       pvcs,
+	}
+	imageUsageHandler := &imageUsageHandler{
+		images:               images,
+		imageCache:           images.Cache(),
+		pvcCache:             pvcs.Cache(),
+		vmCache:              vms.Cache(),
+		templateVersionCache: templateVersions.Cache(),
+		backingImageCache:    backingImages.Cache(),
+		nodeCache:            lhNodes.Cache(),
+		settingCache:         settings.Cache(),
+	}
+	imageExportHandler := &imageExportHandler{
+		ctx:               ctx,
+		images:            images,
+		imageCache:        images.Cache(),
+		backupTargetCache: backupTargets.Cache(),
+		secretCache:       secrets.Cache(),
+		pods:              pods,
+		pvcs:              pvcs,
+		exporter:          export.NewExporter(management.RestConfig, management.ClientSet, pods, pvcs),
+	}
 	images.OnChange(ctx, vmImageControllerName, vmImageHandler.OnChanged)
 	images.OnRemove(ctx, vmImageControllerName, vmImageHandler.OnRemove)
+	settings.OnChange(ctx, vmImageControllerName, vmImageHandler.OnSettingChanged)
 
 	backingImages.OnChange(ctx, backingImageControllerName, backingImageHandler.OnChanged)
+	jobs.OnChange(ctx, downloadJobControllerName, downloadJobHandler.OnChanged)
+
+	images.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnChanged)
+	pvcs.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnPVCChanged)
+	vms.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnVMChanged)
+	templateVersions.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnTemplateVersionChanged)
+	backingImages.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnBackingImageChanged)
+	settings.OnChange(ctx, imageUsageControllerName, imageUsageHandler.OnSettingChanged)
+
+	images.OnChange(ctx, imageExportControllerName, imageExportHandler.OnChanged)
+	pods.OnChange(ctx, imageExportControllerName, imageExportHandler.OnPodChanged)
 	return nil
 }
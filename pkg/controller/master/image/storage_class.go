@@ -0,0 +1,127 @@
+package image
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	optionDataLocality = "dataLocality"
+)
+
+// getDefaultStorageClassParameters returns the parameters of the images which don't set them
+func (h *vmImageHandler) getDefaultStorageClassParameters() (*harvesterv1.ImageStorageClassParameters, error) {
+	setting, err := h.settingCache.Get(settings.DefaultVMImageStorageClassParametersSettingName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return util.DecodeImageStorageClassParameters(settings.DefaultVMImageStorageClassParameters.Default)
+		}
+		return nil, err
+	}
+	value := setting.Value
+	if value == "" {
+		value = setting.Default
+	}
+	return util.DecodeImageStorageClassParameters(value)
+}
+
+// getStorageClassParameters returns the storage class parameters of the image merged with the default ones
+func getStorageClassParameters(image *harvesterv1.VirtualMachineImage, defaults *harvesterv1.ImageStorageClassParameters) map[string]string {
+	merged := *defaults
+	if params := image.Spec.StorageClassParameters; params != nil {
+		if params.NumberOfReplicas != 0 {
+			merged.NumberOfReplicas = params.NumberOfReplicas
+		}
+		if params.StaleReplicaTimeout != 0 {
+			merged.StaleReplicaTimeout = params.StaleReplicaTimeout
+		}
+		if len(params.NodeSelector) != 0 {
+			merged.NodeSelector = params.NodeSelector
+		}
+		if len(params.DiskSelector) != 0 {
+			merged.DiskSelector = params.DiskSelector
+		}
+		if params.DataLocality != "" {
+			merged.DataLocality = params.DataLocality
+		}
+	}
+
+	parameters := map[string]string{
+		optionMigratable:       "true",
+		optionBackingImageName: getBackingImageName(image),
+	}
+	if merged.NumberOfReplicas != 0 {
+		parameters[types.OptionNumberOfReplicas] = strconv.Itoa(merged.NumberOfReplicas)
+	}
+	if merged.StaleReplicaTimeout != 0 {
+		parameters[types.OptionStaleReplicaTimeout] = strconv.Itoa(merged.StaleReplicaTimeout)
+	}
+	if len(merged.NodeSelector) != 0 {
+		parameters[types.OptionNodeSelector] = strings.Join(merged.NodeSelector, ",")
+	}
+	if len(merged.DiskSelector) != 0 {
+		parameters[types.OptionDiskSelector] = strings.Join(merged.DiskSelector, ",")
+	}
+	if merged.DataLocality != "" {
+		parameters[optionDataLocality] = merged.DataLocality
+	}
+	return parameters
+}
+
+// syncStorageClass recreates the storage class of the image if its parameters are changed,
+// the parameters of a storage class are immutable and the existing volumes keep theirs.
+func (h *vmImageHandler) syncStorageClass(image *harvesterv1.VirtualMachineImage) error {
+	defaults, err := h.getDefaultStorageClassParameters()
+	if err != nil {
+		return err
+	}
+	parameters := getStorageClassParameters(image, defaults)
+
+	scName := getImageStorageClassName(image.Name)
+	sc, err := h.storageClassCache.Get(scName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return h.createStorageClass(image, parameters)
+		}
+		return err
+	}
+	if reflect.DeepEqual(sc.Parameters, parameters) {
+		return nil
+	}
+
+	logrus.Infof("storage class parameters of image %s/%s are changed, recreate storage class %s", image.Namespace, image.Name, scName)
+	if err := h.storageClasses.Delete(scName, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := h.createStorageClass(image, parameters); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// OnSettingChanged resyncs the storage classes of the images when the default parameters are changed
+func (h *vmImageHandler) OnSettingChanged(_ string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
+	if setting == nil || setting.DeletionTimestamp != nil || setting.Name != settings.DefaultVMImageStorageClassParametersSettingName {
+		return setting, nil
+	}
+
+	images, err := h.imageCache.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return setting, err
+	}
+	for _, image := range images {
+		h.images.Enqueue(image.Namespace, image.Name)
+	}
+	return setting, nil
+}
@@ -0,0 +1,310 @@
+package image
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	lhv1beta1 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	ctllhv1beta1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// imageUsageHandler reports what references the images and deletes the unused ones by the vm-image-gc-policy setting
+type imageUsageHandler struct {
+	images               ctlharvesterv1.VirtualMachineImageController
+	imageCache           ctlharvesterv1.VirtualMachineImageCache
+	pvcCache             ctlcorev1.PersistentVolumeClaimCache
+	vmCache              ctlkubevirtv1.VirtualMachineCache
+	templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache
+	backingImageCache    ctllhv1beta1.BackingImageCache
+	nodeCache            ctllhv1beta1.NodeCache
+	settingCache         ctlharvesterv1.SettingCache
+}
+
+func (h *imageUsageHandler) OnChanged(_ string, image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if image == nil || image.DeletionTimestamp != nil {
+		return image, nil
+	}
+
+	usage, err := h.getUsage(image)
+	if err != nil {
+		return image, err
+	}
+	if !reflect.DeepEqual(image.Status.Usage, usage) {
+		toUpdate := image.DeepCopy()
+		toUpdate.Status.Usage = usage
+		return h.images.Update(toUpdate)
+	}
+
+	policy, err := h.getGCPolicy()
+	if err != nil {
+		return image, err
+	}
+	collectTime, ok := policy.GetCollectTime(image)
+	if !ok {
+		return image, nil
+	}
+	if wait := time.Until(collectTime); wait > 0 {
+		h.images.EnqueueAfter(image.Namespace, image.Name, wait)
+		return image, nil
+	}
+	logrus.Infof("Deleting image %s/%s unused since %s", image.Namespace, image.Name, usage.UnusedSince)
+	if err := h.images.Delete(image.Namespace, image.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return image, err
+	}
+	return image, nil
+}
+
+func (h *imageUsageHandler) getUsage(image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImageUsage, error) {
+	imageID := ref.Construct(image.Namespace, image.Name)
+	vms, volumes, templateVersions := sets.NewString(), sets.NewString(), sets.NewString()
+
+	if image.Status.StorageClassName != "" {
+		pvcs, err := h.pvcCache.GetByIndex(indexeres.PVCByStorageClassIndex, image.Status.StorageClassName)
+		if err != nil {
+			return nil, err
+		}
+		for _, pvc := range pvcs {
+			volumes.Insert(ref.Construct(pvc.Namespace, pvc.Name))
+			owners, err := ref.GetSchemaOwnersFromAnnotation(pvc)
+			if err != nil {
+				return nil, err
+			}
+			vms.Insert(owners.List(kubevirtv1.VirtualMachineGroupVersionKind.GroupKind())...)
+		}
+	}
+
+	// the VMs whose volumes aren't created yet
+	vmObjs, err := h.vmCache.GetByIndex(indexeres.VMByImageIndex, imageID)
+	if err != nil {
+		return nil, err
+	}
+	for _, vm := range vmObjs {
+		vms.Insert(ref.Construct(vm.Namespace, vm.Name))
+	}
+
+	templateVersionObjs, err := h.templateVersionCache.GetByIndex(indexeres.TemplateVersionByImageIndex, imageID)
+	if err != nil {
+		return nil, err
+	}
+	// the template versions referencing the family create the VMs from any of its versions that is not deprecated
+	if image.Spec.Family != "" && !image.Spec.Deprecated && !harvesterv1.ImageImported.IsFalse(image) {
+		familyTemplateVersionObjs, err := h.templateVersionCache.GetByIndex(indexeres.TemplateVersionByImageFamilyIndex,
+			ref.Construct(image.Namespace, image.Spec.Family))
+		if err != nil {
+			return nil, err
+		}
+		templateVersionObjs = append(templateVersionObjs, familyTemplateVersionObjs...)
+	}
+	for _, templateVersion := range templateVersionObjs {
+		templateVersions.Insert(ref.Construct(templateVersion.Namespace, templateVersion.Name))
+	}
+
+	nodes, err := h.getNodeUsage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &harvesterv1.VirtualMachineImageUsage{
+		VirtualMachines:  listOrNil(vms),
+		Volumes:          listOrNil(volumes),
+		TemplateVersions: listOrNil(templateVersions),
+		Nodes:            nodes,
+	}
+	if vms.Len() == 0 && volumes.Len() == 0 && templateVersions.Len() == 0 {
+		if image.Status.Usage != nil && image.Status.Usage.UnusedSince != nil {
+			usage.UnusedSince = image.Status.Usage.UnusedSince
+		} else {
+			now := metav1.NewTime(time.Now().Truncate(time.Second))
+			usage.UnusedSince = &now
+		}
+	}
+	return usage, nil
+}
+
+// getNodeUsage returns the space consumed by the ready backing image files on each node
+func (h *imageUsageHandler) getNodeUsage(image *harvesterv1.VirtualMachineImage) ([]harvesterv1.ImageNodeUsage, error) {
+	backingImage, err := h.backingImageCache.Get(util.LonghornSystemNamespaceName, getBackingImageName(image))
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	lhNodes, err := h.nodeCache.List(util.LonghornSystemNamespaceName, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	diskNodes := map[string]string{}
+	for _, node := range lhNodes {
+		for _, disk := range node.Status.DiskStatus {
+			if disk != nil && disk.DiskUUID != "" {
+				diskNodes[disk.DiskUUID] = node.Name
+			}
+		}
+	}
+
+	sizes := map[string]int64{}
+	for diskUUID, status := range backingImage.Status.DiskFileStatusMap {
+		if status == nil || (status.State != lhv1beta1.BackingImageStateReady && status.State != lhv1beta1.BackingImageStateReadyForTransfer) {
+			continue
+		}
+		if nodeName, ok := diskNodes[diskUUID]; ok {
+			sizes[nodeName] += backingImage.Status.Size
+		}
+	}
+
+	var nodes []harvesterv1.ImageNodeUsage
+	for nodeName, size := range sizes {
+		nodes = append(nodes, harvesterv1.ImageNodeUsage{NodeName: nodeName, Size: size})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].NodeName < nodes[j].NodeName
+	})
+	return nodes, nil
+}
+
+func (h *imageUsageHandler) getGCPolicy() (*util.ImageGCPolicy, error) {
+	setting, err := h.settingCache.Get(settings.VMImageGCPolicySettingName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return util.DecodeImageGCPolicy(settings.VMImageGCPolicy.Default)
+		}
+		return nil, err
+	}
+	value := setting.Value
+	if value == "" {
+		value = setting.Default
+	}
+	return util.DecodeImageGCPolicy(value)
+}
+
+// enqueueImages enqueues the images in the usage of an object and the images referenced by it
+func (h *imageUsageHandler) enqueueImages(kind, key string, imageIDs ...string) error {
+	images, err := h.imageCache.GetByIndex(indexeres.ImageByUsageIndex, indexeres.ImageUsageKey(kind, key))
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		h.images.Enqueue(image.Namespace, image.Name)
+	}
+	for _, imageID := range imageIDs {
+		namespace, name := ref.Parse(imageID)
+		h.images.Enqueue(namespace, name)
+	}
+	return nil
+}
+
+func (h *imageUsageHandler) OnPVCChanged(key string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	var imageIDs []string
+	if pvc != nil && pvc.Spec.StorageClassName != nil {
+		images, err := h.imageCache.GetByIndex(indexeres.ImageByStorageClassIndex, *pvc.Spec.StorageClassName)
+		if err != nil {
+			return pvc, err
+		}
+		for _, image := range images {
+			imageIDs = append(imageIDs, ref.Construct(image.Namespace, image.Name))
+		}
+	}
+	return pvc, h.enqueueImages(indexeres.ImageUsageKindVolume, key, imageIDs...)
+}
+
+func (h *imageUsageHandler) OnVMChanged(key string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	var imageIDs []string
+	if vm != nil {
+		var err error
+		if imageIDs, err = util.GetImageIDsFromVolumeClaimTemplates(vm.Annotations); err != nil {
+			// the annotation is validated by the webhook, the VM can't reference images by it if it's invalid
+			logrus.Warnf("failed to get the images of VM %s: %v", key, err)
+		}
+	}
+	return vm, h.enqueueImages(indexeres.ImageUsageKindVM, key, imageIDs...)
+}
+
+func (h *imageUsageHandler) OnTemplateVersionChanged(key string, templateVersion *harvesterv1.VirtualMachineTemplateVersion) (*harvesterv1.VirtualMachineTemplateVersion, error) {
+	var imageIDs []string
+	if templateVersion != nil {
+		var err error
+		if imageIDs, err = indexeres.TemplateVersionByImage(templateVersion); err != nil {
+			logrus.Warnf("failed to get the images of template version %s: %v", key, err)
+		}
+		familyImageIDs, err := h.getFamilyImageIDs(templateVersion)
+		if err != nil {
+			return templateVersion, err
+		}
+		imageIDs = append(imageIDs, familyImageIDs...)
+	}
+	return templateVersion, h.enqueueImages(indexeres.ImageUsageKindTemplateVersion, key, imageIDs...)
+}
+
+// getFamilyImageIDs returns the <namespace>/<name> of the versions of the image families referenced by the template version
+func (h *imageUsageHandler) getFamilyImageIDs(templateVersion *harvesterv1.VirtualMachineTemplateVersion) ([]string, error) {
+	families, err := indexeres.TemplateVersionByImageFamily(templateVersion)
+	if err != nil {
+		logrus.Warnf("failed to get the image families of template version %s/%s: %v", templateVersion.Namespace, templateVersion.Name, err)
+		return nil, nil
+	}
+	var imageIDs []string
+	for _, family := range families {
+		namespace, name := ref.Parse(family)
+		images, err := h.imageCache.List(namespace, labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			if image.Spec.Family == name {
+				imageIDs = append(imageIDs, ref.Construct(image.Namespace, image.Name))
+			}
+		}
+	}
+	return imageIDs, nil
+}
+
+// OnBackingImageChanged refreshes the node usage of the image when its backing image files change
+func (h *imageUsageHandler) OnBackingImageChanged(_ string, backingImage *lhv1beta1.BackingImage) (*lhv1beta1.BackingImage, error) {
+	if backingImage == nil || backingImage.Annotations[util.AnnotationImageID] == "" {
+		return backingImage, nil
+	}
+	namespace, name := ref.Parse(backingImage.Annotations[util.AnnotationImageID])
+	h.images.Enqueue(namespace, name)
+	return backingImage, nil
+}
+
+// OnSettingChanged applies the changed vm-image-gc-policy setting to the images
+func (h *imageUsageHandler) OnSettingChanged(_ string, setting *harvesterv1.Setting) (*harvesterv1.Setting, error) {
+	if setting == nil || setting.DeletionTimestamp != nil || setting.Name != settings.VMImageGCPolicySettingName {
+		return setting, nil
+	}
+
+	images, err := h.imageCache.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return setting, err
+	}
+	for _, image := range images {
+		h.images.Enqueue(image.Namespace, image.Name)
+	}
+	return setting, nil
+}
+
+func listOrNil(s sets.String) []string {
+	if s.Len() == 0 {
+		return nil
+	}
+	return s.List()
+}
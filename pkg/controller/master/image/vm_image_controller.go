@@ -8,6 +8,7 @@ import (
 	"github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
 	lhmanager "github.com/longhorn/longhorn-manager/manager"
 	"github.com/longhorn/longhorn-manager/types"
+	ctlbatchv1 "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
 	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	v1 "github.com/rancher/wrangler/pkg/generated/controllers/storage/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +18,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/image/export"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	lhv1beta1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
 	"github.com/harvester/harvester/pkg/ref"
@@ -30,11 +32,19 @@ const (
 
 // vmImageHandler syncs status on vm image changes, and manage a storageclass & a backingimage per vm image
 type vmImageHandler struct {
-	httpClient     http.Client
-	storageClasses v1.StorageClassClient
-	images         ctlharvesterv1.VirtualMachineImageClient
-	backingImages  lhv1beta1.BackingImageClient
-	pvcCache       ctlcorev1.PersistentVolumeClaimCache
+	httpClient        http.Client
+	storageClasses    v1.StorageClassClient
+	storageClassCache v1.StorageClassCache
+	images            ctlharvesterv1.VirtualMachineImageController
+	imageCache        ctlharvesterv1.VirtualMachineImageCache
+	backingImages     lhv1beta1.BackingImageClient
+	pvcs              ctlcorev1.PersistentVolumeClaimClient
+	pvcCache          ctlcorev1.PersistentVolumeClaimCache
+	settingCache      ctlharvesterv1.SettingCache
+	backupTargetCache ctlharvesterv1.BackupTargetCache
+	secretCache       ctlcorev1.SecretCache
+	jobs              ctlbatchv1.JobClient
+	importer          *importer
 }
 
 func (h *vmImageHandler) OnChanged(_ string, image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
@@ -51,9 +61,20 @@ func (h *vmImageHandler) OnChanged(_ string, image *harvesterv1.VirtualMachineIm
 		if err := h.storageClasses.Delete(getImageStorageClassName(image.Name), &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 			return image, err
 		}
+		if err := deleteDownloadJob(h.jobs, h.pvcs, image); err != nil {
+			return image, err
+		}
 		return h.initialize(image)
+	} else if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeOCI && harvesterv1.ImageInitialized.IsTrue(image) &&
+		harvesterv1.ImageImported.IsUnknown(image) && image.Status.OCIDigest == "" {
+		// the import isn't started, e.g. the controller is restarted before pulling the image
+		h.importer.start(image)
+	} else if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeBackupTarget && harvesterv1.ImageInitialized.IsTrue(image) &&
+		harvesterv1.ImageImported.IsUnknown(image) && image.Status.Progress == 0 {
+		// the upload isn't started, the importer ignores the image if it's being imported
+		h.importer.start(image)
 	}
-	return image, nil
+	return image, h.syncStorageClass(image)
 }
 
 func (h *vmImageHandler) OnRemove(_ string, image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
@@ -72,37 +93,73 @@ func (h *vmImageHandler) OnRemove(_ string, image *harvesterv1.VirtualMachineIma
 }
 
 func (h *vmImageHandler) initialize(image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
-	if err := h.createBackingImage(image); err != nil && !errors.IsAlreadyExists(err) {
-		return nil, err
-	}
-	if err := h.createStorageClass(image); err != nil && !errors.IsAlreadyExists(err) {
-		return nil, err
-	}
-
 	toUpdate := image.DeepCopy()
 	toUpdate.Status.AppliedURL = toUpdate.Spec.URL
 	toUpdate.Status.StorageClassName = getImageStorageClassName(image.Name)
+	toUpdate.Status.Format = ""
+	toUpdate.Status.VirtualSize = 0
+	toUpdate.Status.Checksum = ""
+
+	expected, err := h.resolveChecksum(image)
+	if err != nil {
+		harvesterv1.ImageInitialized.False(toUpdate)
+		harvesterv1.ImageInitialized.Reason(toUpdate, "InvalidChecksum")
+		harvesterv1.ImageInitialized.Message(toUpdate, err.Error())
+		return h.images.Update(toUpdate)
+	}
+	if expected != nil {
+		toUpdate.Status.Checksum = expected.String()
+	}
 
 	if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload {
-		resp, err := h.httpClient.Head(image.Spec.URL)
+		url, size, err := h.probe(image)
 		if err != nil {
 			harvesterv1.ImageInitialized.False(toUpdate)
 			harvesterv1.ImageInitialized.Message(toUpdate, err.Error())
 			return h.images.Update(toUpdate)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		if size > 0 {
+			toUpdate.Status.Size = size
+		}
+
+		info, err := h.detectFormat(url, size)
+		if err != nil {
 			harvesterv1.ImageInitialized.False(toUpdate)
-			harvesterv1.ImageInitialized.Message(toUpdate, fmt.Sprintf("got %d status code from %s", resp.StatusCode, image.Spec.URL))
+			harvesterv1.ImageInitialized.Reason(toUpdate, "UnsupportedFormat")
+			harvesterv1.ImageInitialized.Message(toUpdate, err.Error())
 			return h.images.Update(toUpdate)
 		}
+		toUpdate.Status.Format = string(info.Format)
+		toUpdate.Status.VirtualSize = info.VirtualSize
+	}
 
-		if resp.ContentLength > 0 {
-			toUpdate.Status.Size = resp.ContentLength
+	if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeBackupTarget {
+		manifest, err := h.readExportManifest(image)
+		if err != nil {
+			harvesterv1.ImageInitialized.False(toUpdate)
+			harvesterv1.ImageInitialized.Reason(toUpdate, "InvalidBackupTarget")
+			harvesterv1.ImageInitialized.Message(toUpdate, err.Error())
+			return h.images.Update(toUpdate)
+		}
+		toUpdate.Status.Size = manifest.Size
+		toUpdate.Status.Format = manifest.Format
+		toUpdate.Status.VirtualSize = manifest.VirtualSize
+		if expected == nil {
+			toUpdate.Status.Checksum = manifest.Checksum
 		}
-	} else {
-		toUpdate.Status.Progress = 0
+	}
+	toUpdate.Status.Progress = 0
+
+	if err := h.createBackingImage(toUpdate); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	defaults, err := h.getDefaultStorageClassParameters()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.createStorageClass(image, getStorageClassParameters(image, defaults)); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
 	}
 
 	harvesterv1.ImageImported.Unknown(toUpdate)
@@ -110,7 +167,22 @@ func (h *vmImageHandler) initialize(image *harvesterv1.VirtualMachineImage) (*ha
 	harvesterv1.ImageInitialized.True(toUpdate)
 	harvesterv1.ImageInitialized.Reason(toUpdate, "Initialized")
 
-	return h.images.Update(toUpdate)
+	updated, err := h.images.Update(toUpdate)
+	if err != nil {
+		return nil, err
+	}
+	// the disk in the registry or the backup target is uploaded to the backing image by harvester
+	if updated.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeOCI ||
+		updated.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeBackupTarget {
+		h.importer.start(updated)
+	}
+	// the image is downloaded by a job and uploaded to the backing image
+	if isDownloadedByJob(updated) {
+		if err := h.createDownloadJob(updated); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+	}
+	return updated, nil
 }
 
 func (h *vmImageHandler) createBackingImage(image *harvesterv1.VirtualMachineImage) error {
@@ -125,10 +197,19 @@ func (h *vmImageHandler) createBackingImage(image *harvesterv1.VirtualMachineIma
 		Spec: v1beta1.BackingImageSpec{
 			SourceType:       v1beta1.BackingImageDataSourceType(image.Spec.SourceType),
 			SourceParameters: map[string]string{},
-			Checksum:         image.Spec.Checksum,
+			Checksum:         getLonghornChecksum(image),
 		},
 	}
-	if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload {
+	if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeOCI ||
+		image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeBackupTarget {
+		bi.Spec.SourceType = v1beta1.BackingImageDataSourceTypeUpload
+	}
+
+	if isDownloadedByJob(image) {
+		// the checksum is of the downloaded file and is verified by the job, the uploaded one may be converted
+		bi.Spec.SourceType = v1beta1.BackingImageDataSourceTypeUpload
+		bi.Spec.Checksum = ""
+	} else if image.Spec.SourceType == harvesterv1.VirtualMachineImageSourceTypeDownload {
 		bi.Spec.SourceParameters[v1beta1.DataSourceTypeDownloadParameterURL] = image.Spec.URL
 	}
 
@@ -146,7 +227,16 @@ func (h *vmImageHandler) createBackingImage(image *harvesterv1.VirtualMachineIma
 	return err
 }
 
-func (h *vmImageHandler) createStorageClass(image *harvesterv1.VirtualMachineImage) error {
+// readExportManifest reads the manifest of the image exported to the backup target
+func (h *vmImageHandler) readExportManifest(image *harvesterv1.VirtualMachineImage) (*export.Manifest, error) {
+	driver, err := getBackupStoreDriver(h.backupTargetCache, h.secretCache, image.Spec.BackupTarget.Name)
+	if err != nil {
+		return nil, err
+	}
+	return export.ReadManifest(driver, image.Spec.BackupTarget.Path)
+}
+
+func (h *vmImageHandler) createStorageClass(image *harvesterv1.VirtualMachineImage, parameters map[string]string) error {
 	recliamPolicy := corev1.PersistentVolumeReclaimDelete
 	volumeBindingMode := storagev1.VolumeBindingImmediate
 	sc := &storagev1.StorageClass{
@@ -157,12 +247,7 @@ func (h *vmImageHandler) createStorageClass(image *harvesterv1.VirtualMachineIma
 		ReclaimPolicy:        &recliamPolicy,
 		AllowVolumeExpansion: pointer.BoolPtr(true),
 		VolumeBindingMode:    &volumeBindingMode,
-		Parameters: map[string]string{
-			types.OptionNumberOfReplicas:    "3",
-			types.OptionStaleReplicaTimeout: "30",
-			optionMigratable:                "true",
-			optionBackingImageName:          getBackingImageName(image),
-		},
+		Parameters:           parameters,
 	}
 
 	_, err := h.storageClasses.Create(sc)
@@ -0,0 +1,235 @@
+package imagebuild
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	defaultTimeout = time.Hour
+
+	// baseImageWaitInterval is how often a build waiting for its base image to be imported is checked
+	baseImageWaitInterval = 30 * time.Second
+)
+
+type Handler struct {
+	builds     ctlharvesterv1.VirtualMachineImageBuildController
+	images     ctlharvesterv1.VirtualMachineImageClient
+	imageCache ctlharvesterv1.VirtualMachineImageCache
+	vms        ctlkubevirtv1.VirtualMachineClient
+	vmCache    ctlkubevirtv1.VirtualMachineCache
+	vmiCache   ctlkubevirtv1.VirtualMachineInstanceCache
+	secrets    ctlcorev1.SecretClient
+}
+
+// OnChanged moves the build through its phases, the temporary VM is created when the build starts, the root volume
+// is exported once the VM powers off, and the VM is removed when the build completes.
+func (h *Handler) OnChanged(_ string, build *harvesterv1.VirtualMachineImageBuild) (*harvesterv1.VirtualMachineImageBuild, error) {
+	if build == nil || build.DeletionTimestamp != nil {
+		return build, nil
+	}
+
+	switch build.Status.Phase {
+	case "":
+		return h.start(build)
+	case harvesterv1.ImageBuildPhaseBuilding:
+		return h.checkVM(build)
+	case harvesterv1.ImageBuildPhaseExporting:
+		return h.checkImage(build)
+	default:
+		return build, h.cleanup(build)
+	}
+}
+
+func (h *Handler) start(build *harvesterv1.VirtualMachineImageBuild) (*harvesterv1.VirtualMachineImageBuild, error) {
+	namespace, name := GetBaseImage(build)
+	baseImage, err := h.imageCache.Get(namespace, name)
+	if apierrors.IsNotFound(err) {
+		return h.fail(build, fmt.Errorf("base image %s/%s is not found", namespace, name))
+	} else if err != nil {
+		return build, err
+	}
+	if harvesterv1.ImageImported.IsFalse(baseImage) {
+		return h.fail(build, fmt.Errorf("base image %s/%s failed to import", namespace, name))
+	}
+	if !harvesterv1.ImageImported.IsTrue(baseImage) {
+		h.builds.EnqueueAfter(build.Namespace, build.Name, baseImageWaitInterval)
+		return build, nil
+	}
+
+	if _, err := h.secrets.Create(newCloudInitSecret(build)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return build, err
+	}
+	vm, err := newVM(build, baseImage)
+	if err != nil {
+		return build, err
+	}
+	if _, err := h.vms.Create(vm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return build, err
+	}
+
+	logrus.Infof("image build %s/%s started VM %s", build.Namespace, build.Name, vm.Name)
+	now := metav1.Now()
+	toUpdate := build.DeepCopy()
+	toUpdate.Status.Phase = harvesterv1.ImageBuildPhaseBuilding
+	toUpdate.Status.VirtualMachineName = vm.Name
+	toUpdate.Status.Version = getVersion(build)
+	toUpdate.Status.StartTime = &now
+	return h.builds.Update(toUpdate)
+}
+
+// checkVM exports the root volume once the VM powers off, the build fails if the VM doesn't power off in time
+func (h *Handler) checkVM(build *harvesterv1.VirtualMachineImageBuild) (*harvesterv1.VirtualMachineImageBuild, error) {
+	if _, err := h.vmCache.Get(build.Namespace, build.Status.VirtualMachineName); apierrors.IsNotFound(err) {
+		return h.fail(build, fmt.Errorf("VM %s is removed", build.Status.VirtualMachineName))
+	} else if err != nil {
+		return build, err
+	}
+
+	vmi, err := h.vmiCache.Get(build.Namespace, build.Status.VirtualMachineName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return build, err
+	}
+	if err == nil {
+		switch vmi.Status.Phase {
+		case kubevirtv1.Succeeded:
+			return h.export(build)
+		case kubevirtv1.Failed:
+			return h.fail(build, fmt.Errorf("VM %s failed", vmi.Name))
+		}
+	}
+
+	timeout := defaultTimeout
+	if build.Spec.Timeout != nil {
+		timeout = build.Spec.Timeout.Duration
+	}
+	remaining := time.Until(build.Status.StartTime.Add(timeout))
+	if remaining <= 0 {
+		return h.fail(build, fmt.Errorf("VM %s didn't power off in %s", build.Status.VirtualMachineName, timeout))
+	}
+	h.builds.EnqueueAfter(build.Namespace, build.Name, remaining)
+	return build, nil
+}
+
+func (h *Handler) export(build *harvesterv1.VirtualMachineImageBuild) (*harvesterv1.VirtualMachineImageBuild, error) {
+	image := newImage(build)
+	if _, err := h.images.Create(image); apierrors.IsAlreadyExists(err) {
+		existing, err := h.imageCache.Get(image.Namespace, image.Name)
+		if err != nil {
+			return build, err
+		}
+		if existing.Labels[util.LabelImageBuild] != build.Name {
+			return h.fail(build, fmt.Errorf("image %s/%s already exists", image.Namespace, image.Name))
+		}
+	} else if err != nil {
+		return h.fail(build, fmt.Errorf("failed to create image %s/%s: %w", image.Namespace, image.Name, err))
+	}
+
+	logrus.Infof("image build %s/%s exports the root volume to image %s", build.Namespace, build.Name, image.Name)
+	toUpdate := build.DeepCopy()
+	toUpdate.Status.Phase = harvesterv1.ImageBuildPhaseExporting
+	toUpdate.Status.ImageName = image.Name
+	return h.builds.Update(toUpdate)
+}
+
+// checkImage completes the build once the image is imported
+func (h *Handler) checkImage(build *harvesterv1.VirtualMachineImageBuild) (*harvesterv1.VirtualMachineImageBuild, error) {
+	image, err := h.imageCache.Get(build.Namespace, build.Status.ImageName)
+	if apierrors.IsNotFound(err) {
+		return h.fail(build, fmt.Errorf("image %s is removed", build.Status.ImageName))
+	} else if err != nil {
+		return build, err
+	}
+
+	if harvesterv1.ImageImported.IsFalse(image) {
+		return h.fail(build, fmt.Errorf("image %s failed to import: %s", image.Name, harvesterv1.ImageImported.GetMessage(image)))
+	}
+	if !harvesterv1.ImageImported.IsTrue(image) {
+		return build, nil
+	}
+
+	now := metav1.Now()
+	toUpdate := build.DeepCopy()
+	toUpdate.Status.Phase = harvesterv1.ImageBuildPhaseSucceeded
+	toUpdate.Status.CompletionTime = &now
+	toUpdate.Status.Message = ""
+	return h.builds.Update(toUpdate)
+}
+
+func (h *Handler) fail(build *harvesterv1.VirtualMachineImageBuild, buildErr error) (*harvesterv1.VirtualMachineImageBuild, error) {
+	logrus.Errorf("image build %s/%s failed: %v", build.Namespace, build.Name, buildErr)
+	now := metav1.Now()
+	toUpdate := build.DeepCopy()
+	toUpdate.Status.Phase = harvesterv1.ImageBuildPhaseFailed
+	toUpdate.Status.Message = buildErr.Error()
+	toUpdate.Status.CompletionTime = &now
+	if reflect.DeepEqual(build.Status, toUpdate.Status) {
+		return build, nil
+	}
+	return h.builds.Update(toUpdate)
+}
+
+// cleanup removes the temporary VM and its cloud-init secret, the root volume is removed along with the VM.
+// The image of a failed build is removed unless it's imported.
+func (h *Handler) cleanup(build *harvesterv1.VirtualMachineImageBuild) error {
+	vmName := getVMName(build)
+	if vm, err := h.vmCache.Get(build.Namespace, vmName); err == nil {
+		if vm.DeletionTimestamp == nil && vm.Labels[util.LabelImageBuild] == build.Name {
+			if err := h.vms.Delete(build.Namespace, vmName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := h.secrets.Delete(build.Namespace, vmName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if build.Status.Phase != harvesterv1.ImageBuildPhaseFailed || build.Status.ImageName == "" {
+		return nil
+	}
+	image, err := h.imageCache.Get(build.Namespace, build.Status.ImageName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if image.DeletionTimestamp != nil || image.Labels[util.LabelImageBuild] != build.Name || harvesterv1.ImageImported.IsTrue(image) {
+		return nil
+	}
+	if err := h.images.Delete(image.Namespace, image.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// OnVMIChanged enqueues the build of the temporary VM when the VM powers off
+func (h *Handler) OnVMIChanged(_ string, vmi *kubevirtv1.VirtualMachineInstance) (*kubevirtv1.VirtualMachineInstance, error) {
+	if vmi == nil || vmi.Labels[util.LabelImageBuild] == "" {
+		return vmi, nil
+	}
+	h.builds.Enqueue(vmi.Namespace, vmi.Labels[util.LabelImageBuild])
+	return vmi, nil
+}
+
+// OnImageChanged enqueues the build of the built image when the image is imported
+func (h *Handler) OnImageChanged(_ string, image *harvesterv1.VirtualMachineImage) (*harvesterv1.VirtualMachineImage, error) {
+	if image == nil || image.Labels[util.LabelImageBuild] == "" {
+		return image, nil
+	}
+	h.builds.Enqueue(image.Namespace, image.Labels[util.LabelImageBuild])
+	return image, nil
+}
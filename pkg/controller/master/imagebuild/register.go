@@ -0,0 +1,34 @@
+package imagebuild
+
+import (
+	"context"
+
+	"github.com/harvester/harvester/pkg/config"
+)
+
+const (
+	imageBuildControllerName = "vm-image-build-controller"
+)
+
+func Register(ctx context.Context, management *config.Management, options config.Options) error {
+	builds := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImageBuild()
+	images := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage()
+	vms := management.VirtFactory.Kubevirt().V1().VirtualMachine()
+	vmis := management.VirtFactory.Kubevirt().V1().VirtualMachineInstance()
+	secrets := management.CoreFactory.Core().V1().Secret()
+
+	handler := &Handler{
+		builds:     builds,
+		images:     images,
+		imageCache: images.Cache(),
+		vms:        vms,
+		vmCache:    vms.Cache(),
+		vmiCache:   vmis.Cache(),
+		secrets:    secrets,
+	}
+
+	builds.OnChange(ctx, imageBuildControllerName, handler.OnChanged)
+	vmis.OnChange(ctx, imageBuildControllerName, handler.OnVMIChanged)
+	images.OnChange(ctx, imageBuildControllerName, handler.OnImageChanged)
+	return nil
+}
@@ -0,0 +1,176 @@
+package imagebuild
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/wrangler/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/builder"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+const (
+	vmCreator       = "harvester-image-build"
+	rootDiskName    = "rootdisk"
+	networkName     = "default"
+	defaultCPU      = 2
+	defaultMemory   = "2Gi"
+	versionFormat   = "20060102-150405"
+	imageBuildKind  = "VirtualMachineImageBuild"
+	userDataKey     = "userdata"
+	networkDataKey  = "networkdata"
+	imageNamePrefix = "image"
+)
+
+// GetBaseImage returns the namespace and the name of the base image of the build
+func GetBaseImage(build *harvesterv1.VirtualMachineImageBuild) (string, string) {
+	if strings.Contains(build.Spec.BaseImage, "/") {
+		return ref.Parse(build.Spec.BaseImage)
+	}
+	return build.Namespace, build.Spec.BaseImage
+}
+
+// getVersion returns the version of the built image, it's the creation time of the build by default
+func getVersion(build *harvesterv1.VirtualMachineImageBuild) string {
+	if build.Spec.Image.Version != "" {
+		return build.Spec.Image.Version
+	}
+	return build.CreationTimestamp.UTC().Format(versionFormat)
+}
+
+// getVMName returns the name of the temporary VM, its cloud-init secret and the prefix of its root volume
+func getVMName(build *harvesterv1.VirtualMachineImageBuild) string {
+	return name.SafeConcatName("image-build", build.Name)
+}
+
+func getRootDiskPVCName(build *harvesterv1.VirtualMachineImageBuild) string {
+	return name.SafeConcatName(getVMName(build), rootDiskName)
+}
+
+func getImageName(build *harvesterv1.VirtualMachineImageBuild) string {
+	return name.SafeConcatName(imageNamePrefix, build.Name)
+}
+
+func newOwnerReference(build *harvesterv1.VirtualMachineImageBuild) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: harvesterv1.SchemeGroupVersion.String(),
+		Kind:       imageBuildKind,
+		Name:       build.Name,
+		UID:        build.UID,
+	}
+}
+
+// getDiskSize returns the size of the root disk, it must be large enough for the disk in the base image
+func getDiskSize(build *harvesterv1.VirtualMachineImageBuild, baseImage *harvesterv1.VirtualMachineImage) string {
+	if build.Spec.DiskSize != "" {
+		return build.Spec.DiskSize
+	}
+	if baseImage.Status.VirtualSize > 0 {
+		return resource.NewQuantity(baseImage.Status.VirtualSize, resource.BinarySI).String()
+	}
+	return builder.DefaultDiskSize
+}
+
+// newCloudInitSecret returns the secret of the cloud-init data, the user data may exceed the size limit of
+// the inline user data of the VMs
+func newCloudInitSecret(build *harvesterv1.VirtualMachineImageBuild) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            getVMName(build),
+			Namespace:       build.Namespace,
+			Labels:          map[string]string{util.LabelImageBuild: build.Name},
+			OwnerReferences: []metav1.OwnerReference{newOwnerReference(build)},
+		},
+		StringData: map[string]string{
+			userDataKey: build.Spec.UserData,
+		},
+	}
+	if build.Spec.NetworkData != "" {
+		secret.StringData[networkDataKey] = build.Spec.NetworkData
+	}
+	return secret
+}
+
+// newVM returns the temporary VM booting from a volume of the base image. The VM isn't restarted once it powers
+// itself off, and its root volume is removed along with it.
+func newVM(build *harvesterv1.VirtualMachineImageBuild, baseImage *harvesterv1.VirtualMachineImage) (*kubevirtv1.VirtualMachine, error) {
+	vmName := getVMName(build)
+	pvcName := getRootDiskPVCName(build)
+	cpu := build.Spec.CPU
+	if cpu == 0 {
+		cpu = defaultCPU
+	}
+	memory := build.Spec.Memory
+	if memory == "" {
+		memory = defaultMemory
+	}
+	cloudInitSource := builder.CloudInitSource{
+		CloudInitType:      builder.CloudInitTypeNoCloud,
+		UserDataSecretName: vmName,
+	}
+	if build.Spec.NetworkData != "" {
+		cloudInitSource.NetworkDataSecretName = vmName
+	}
+	interfaceType := builder.NetworkInterfaceTypeMasquerade
+	if build.Spec.NetworkName != "" {
+		interfaceType = builder.NetworkInterfaceTypeBridge
+	}
+
+	vmBuilder := builder.NewVMBuilder(vmCreator).
+		Name(vmName).
+		Namespace(build.Namespace).
+		Description(fmt.Sprintf("temporary VM of image build %s", build.Name)).
+		Labels(map[string]string{util.LabelImageBuild: build.Name}).
+		Annotations(map[string]string{util.RemovedPVCsAnnotationKey: pvcName}).
+		CPU(cpu).
+		Memory(memory).
+		PVCDisk(rootDiskName, builder.DiskBusVirtio, false, false, 1, getDiskSize(build, baseImage), pvcName, &builder.PersistentVolumeClaimOption{
+			ImageID:          ref.Construct(baseImage.Namespace, baseImage.Name),
+			VolumeMode:       corev1.PersistentVolumeBlock,
+			AccessMode:       corev1.ReadWriteMany,
+			StorageClassName: pointer.StringPtr(baseImage.Status.StorageClassName),
+		}).
+		CloudInitDisk(builder.CloudInitDiskName, builder.DiskBusVirtio, false, 0, cloudInitSource).
+		NetworkInterface(networkName, "virtio", "", interfaceType, build.Spec.NetworkName).
+		RunStrategy(kubevirtv1.RunStrategyRerunOnFailure)
+	vmBuilder.VirtualMachine.Spec.Template.ObjectMeta.Labels[util.LabelImageBuild] = build.Name
+
+	vm, err := vmBuilder.VM()
+	if err != nil {
+		return nil, err
+	}
+	vm.OwnerReferences = []metav1.OwnerReference{newOwnerReference(build)}
+	return vm, nil
+}
+
+// newImage returns the image exported from the root volume of the temporary VM, it's a new version of the family
+// of the build if the family is set
+func newImage(build *harvesterv1.VirtualMachineImageBuild) *harvesterv1.VirtualMachineImage {
+	image := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getImageName(build),
+			Namespace: build.Namespace,
+			Labels:    map[string]string{util.LabelImageBuild: build.Name},
+		},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			DisplayName:  fmt.Sprintf("%s-%s", build.Spec.Image.DisplayName, build.Status.Version),
+			Description:  build.Spec.Image.Description,
+			SourceType:   harvesterv1.VirtualMachineImageSourceTypeExportVolume,
+			PVCName:      getRootDiskPVCName(build),
+			PVCNamespace: build.Namespace,
+			Family:       build.Spec.Image.Family,
+		},
+	}
+	if image.Spec.Family != "" {
+		image.Spec.Version = build.Status.Version
+	}
+	return image
+}
@@ -0,0 +1,150 @@
+package imagebuild
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func newBuild(spec harvesterv1.VirtualMachineImageBuildSpec) *harvesterv1.VirtualMachineImageBuild {
+	return &harvesterv1.VirtualMachineImageBuild{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "golden",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Date(2022, 3, 1, 2, 0, 0, 0, time.UTC)),
+		},
+		Spec: spec,
+	}
+}
+
+func Test_GetBaseImage(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		baseImage         string
+		expectedNamespace string
+		expectedName      string
+	}{
+		{
+			name:              "image in the namespace of the build",
+			baseImage:         "ubuntu",
+			expectedNamespace: "default",
+			expectedName:      "ubuntu",
+		},
+		{
+			name:              "image shared from another namespace",
+			baseImage:         "harvester-public/ubuntu",
+			expectedNamespace: "harvester-public",
+			expectedName:      "ubuntu",
+		},
+	}
+	for _, tc := range testCases {
+		namespace, name := GetBaseImage(newBuild(harvesterv1.VirtualMachineImageBuildSpec{BaseImage: tc.baseImage}))
+		assert.Equal(t, tc.expectedNamespace, namespace, tc.name)
+		assert.Equal(t, tc.expectedName, name, tc.name)
+	}
+}
+
+func Test_getVersion(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{
+			name:     "specified version",
+			version:  "v1",
+			expected: "v1",
+		},
+		{
+			name:     "creation time by default",
+			expected: "20220301-020000",
+		},
+	}
+	for _, tc := range testCases {
+		build := newBuild(harvesterv1.VirtualMachineImageBuildSpec{
+			Image: harvesterv1.VirtualMachineImageBuildOutput{DisplayName: "ubuntu-golden", Version: tc.version},
+		})
+		assert.Equal(t, tc.expected, getVersion(build), tc.name)
+	}
+}
+
+func Test_newVM(t *testing.T) {
+	baseImage := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "default"},
+		Status: harvesterv1.VirtualMachineImageStatus{
+			StorageClassName: "longhorn-ubuntu",
+			VirtualSize:      10 * 1024 * 1024 * 1024,
+		},
+	}
+	var testCases = []struct {
+		name             string
+		spec             harvesterv1.VirtualMachineImageBuildSpec
+		expectedCPU      uint32
+		expectedDiskSize string
+		expectedNetwork  bool
+	}{
+		{
+			name:             "defaults",
+			spec:             harvesterv1.VirtualMachineImageBuildSpec{BaseImage: "ubuntu", UserData: "#cloud-config"},
+			expectedCPU:      defaultCPU,
+			expectedDiskSize: "10Gi",
+		},
+		{
+			name: "customized",
+			spec: harvesterv1.VirtualMachineImageBuildSpec{
+				BaseImage:   "ubuntu",
+				UserData:    "#cloud-config",
+				NetworkData: "version: 2",
+				NetworkName: "default/vlan1",
+				CPU:         4,
+				DiskSize:    "20Gi",
+			},
+			expectedCPU:      4,
+			expectedDiskSize: "20Gi",
+			expectedNetwork:  true,
+		},
+	}
+	for _, tc := range testCases {
+		build := newBuild(tc.spec)
+		vm, err := newVM(build, baseImage)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, "image-build-golden", vm.Name, tc.name)
+		assert.Equal(t, build.Name, vm.Labels[util.LabelImageBuild], tc.name)
+		assert.Equal(t, build.Name, vm.Spec.Template.ObjectMeta.Labels[util.LabelImageBuild], tc.name)
+		assert.Equal(t, "image-build-golden-rootdisk", vm.Annotations[util.RemovedPVCsAnnotationKey], tc.name)
+		assert.Contains(t, vm.Annotations[util.AnnotationVolumeClaimTemplates], `"storageClassName":"longhorn-ubuntu"`, tc.name)
+		assert.Contains(t, vm.Annotations[util.AnnotationVolumeClaimTemplates], `"storage":"`+tc.expectedDiskSize+`"`, tc.name)
+		assert.Equal(t, kubevirtv1.RunStrategyRerunOnFailure, *vm.Spec.RunStrategy, tc.name)
+		assert.Equal(t, tc.expectedCPU, vm.Spec.Template.Spec.Domain.CPU.Cores, tc.name)
+		assert.Equal(t, tc.expectedNetwork, vm.Spec.Template.Spec.Networks[0].Multus != nil, tc.name)
+		assert.Len(t, vm.OwnerReferences, 1, tc.name)
+
+		secret := newCloudInitSecret(build)
+		_, ok := secret.StringData[networkDataKey]
+		assert.Equal(t, tc.spec.NetworkData != "", ok, tc.name)
+	}
+}
+
+func Test_newImage(t *testing.T) {
+	build := newBuild(harvesterv1.VirtualMachineImageBuildSpec{
+		Image: harvesterv1.VirtualMachineImageBuildOutput{DisplayName: "ubuntu-golden"},
+	})
+	build.Status.Version = "20220301-020000"
+	image := newImage(build)
+	assert.Equal(t, "image-golden", image.Name)
+	assert.Equal(t, "ubuntu-golden-20220301-020000", image.Spec.DisplayName)
+	assert.Equal(t, "image-build-golden-rootdisk", image.Spec.PVCName)
+	assert.Empty(t, image.Spec.Family)
+	assert.Empty(t, image.Spec.Version)
+
+	build.Spec.Image.Family = "ubuntu-golden"
+	image = newImage(build)
+	assert.Equal(t, "ubuntu-golden", image.Spec.Family)
+	assert.Equal(t, "20220301-020000", image.Spec.Version)
+}
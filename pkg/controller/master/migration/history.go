@@ -0,0 +1,112 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// recordMigration adds the completed migration in the migration state of the VMI to the history of the VM
+func (h *Handler) recordMigration(vmi *kubevirtv1.VirtualMachineInstance) error {
+	state := vmi.Status.MigrationState
+	record := util.MigrationRecord{
+		UID:        string(state.MigrationUID),
+		SourceNode: state.SourceNode,
+		TargetNode: state.TargetNode,
+		StartTime:  state.StartTimestamp,
+		EndTime:    state.EndTimestamp,
+		Mode:       string(state.Mode),
+		Result:     util.MigrationResultSucceeded,
+	}
+	if state.MigrationPolicyName != nil {
+		record.Policy = *state.MigrationPolicyName
+	}
+	switch {
+	case state.AbortStatus == kubevirtv1.MigrationAbortSucceeded:
+		record.Result = util.MigrationResultAborted
+	case state.Failed:
+		record.Result = util.MigrationResultFailed
+	}
+	return h.addMigrationRecord(vmi, record)
+}
+
+// recordFailedMigration adds the failed migration which isn't reported in the migration state of the VMI to the
+// history of the VM, https://github.com/kubevirt/kubevirt/issues/5503
+func (h *Handler) recordFailedMigration(vmi *kubevirtv1.VirtualMachineInstance, vmim *kubevirtv1.VirtualMachineInstanceMigration) error {
+	now := metav1.Now()
+	return h.addMigrationRecord(vmi, util.MigrationRecord{
+		UID:        string(vmim.UID),
+		SourceNode: vmi.Status.NodeName,
+		TargetNode: vmi.Annotations[util.AnnotationMigrationTarget],
+		StartTime:  vmim.CreationTimestamp.DeepCopy(),
+		EndTime:    &now,
+		Result:     util.MigrationResultFailed,
+	})
+}
+
+func (h *Handler) addMigrationRecord(vmi *kubevirtv1.VirtualMachineInstance, record util.MigrationRecord) error {
+	vm, err := h.vmCache.Get(vmi.Namespace, vmi.Name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if util.HasMigrationRecord(vm, record.UID) {
+		return nil
+	}
+
+	if record.Result == util.MigrationResultFailed {
+		if record.Reason, err = h.getFailureReason(vmi, types.UID(record.UID), record.StartTime); err != nil {
+			return err
+		}
+	}
+	toUpdate := vm.DeepCopy()
+	if _, err := util.AddMigrationRecord(toUpdate, record); err != nil {
+		return err
+	}
+	_, err = h.vms.Update(toUpdate)
+	return err
+}
+
+// getFailureReason returns the message of the latest warning event of the migration, or of the VMI since the
+// migration started
+func (h *Handler) getFailureReason(vmi *kubevirtv1.VirtualMachineInstance, migrationUID types.UID, startTime *metav1.Time) (string, error) {
+	for _, uid := range []types.UID{migrationUID, vmi.UID} {
+		events, err := h.events.List(vmi.Namespace, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("involvedObject.uid=%s,type=%s", uid, corev1.EventTypeWarning),
+		})
+		if err != nil {
+			return "", err
+		}
+		var latest *corev1.Event
+		for i, event := range events.Items {
+			if startTime != nil && eventTime(&event).Before(startTime.Time) {
+				continue
+			}
+			if latest == nil || eventTime(latest).Before(eventTime(&event)) {
+				latest = &events.Items[i]
+			}
+		}
+		if latest != nil {
+			return fmt.Sprintf("%s: %s", latest.Reason, latest.Message), nil
+		}
+	}
+	return "", nil
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,104 @@
+package migration
+
+import (
+	"errors"
+
+	"github.com/rancher/wrangler/pkg/apply"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kubevirt.io/api/migrations"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+)
+
+const (
+	kubevirtMigrationPolicyKind = "MigrationPolicy"
+
+	policyNotSupportedReason  = "NotSupported"
+	policyNotSupportedMessage = "migration policies are not supported by KubeVirt, enable the MigrationPolicies feature gate of KubeVirt"
+	policyApplyFailedReason   = "ApplyFailed"
+)
+
+// PolicyHandler applies the migration policies to KubeVirt. The bandwidth, timeout, auto-converge and post-copy of a
+// policy are applied by a KubeVirt migration policy with the same selectors, the parallel limits are enforced by the
+// webhook of the migrations. The Applied condition of a policy reports whether its KubeVirt policy is in effect.
+type PolicyHandler struct {
+	apply    apply.Apply
+	policies ctlharvesterv1.MigrationPolicyClient
+}
+
+func (h *PolicyHandler) OnPolicyChanged(_ string, policy *harvesterv1.MigrationPolicy) (*harvesterv1.MigrationPolicy, error) {
+	if policy == nil || policy.DeletionTimestamp != nil {
+		return policy, nil
+	}
+
+	// the KubeVirt policy is removed along with its owner
+	err := h.apply.
+		WithDynamicLookup().
+		WithSetID("harvester-migration-policy").
+		WithOwner(policy).
+		WithSetOwnerReference(true, false).
+		ApplyObjects(newKubeVirtMigrationPolicy(policy))
+	reason, condErr := "", err
+	if meta.IsNoMatchError(err) {
+		// retrying doesn't help until KubeVirt is reconfigured, the policy is resynced when it's updated
+		reason, condErr, err = policyNotSupportedReason, errors.New(policyNotSupportedMessage), nil
+	} else if err != nil {
+		reason = policyApplyFailedReason
+	}
+	if harvesterv1.MigrationPolicyApplied.MatchesError(policy, reason, condErr) {
+		return policy, err
+	}
+
+	toUpdate := policy.DeepCopy()
+	harvesterv1.MigrationPolicyApplied.SetError(toUpdate, reason, condErr)
+	updated, updateErr := h.policies.Update(toUpdate)
+	if updateErr != nil {
+		return policy, updateErr
+	}
+	return updated, err
+}
+
+func newKubeVirtMigrationPolicy(policy *harvesterv1.MigrationPolicy) *unstructured.Unstructured {
+	selectors := map[string]interface{}{}
+	if len(policy.Spec.Selectors.NamespaceSelector) > 0 {
+		selectors["namespaceSelector"] = toInterfaceMap(policy.Spec.Selectors.NamespaceSelector)
+	}
+	if len(policy.Spec.Selectors.VirtualMachineSelector) > 0 {
+		selectors["virtualMachineInstanceSelector"] = toInterfaceMap(policy.Spec.Selectors.VirtualMachineSelector)
+	}
+	spec := map[string]interface{}{
+		"selectors": selectors,
+	}
+	if policy.Spec.BandwidthPerMigration != nil {
+		spec["bandwidthPerMigration"] = policy.Spec.BandwidthPerMigration.String()
+	}
+	if policy.Spec.CompletionTimeoutPerGiB != nil {
+		spec["completionTimeoutPerGiB"] = *policy.Spec.CompletionTimeoutPerGiB
+	}
+	if policy.Spec.AllowAutoConverge != nil {
+		spec["allowAutoConverge"] = *policy.Spec.AllowAutoConverge
+	}
+	if policy.Spec.AllowPostCopy != nil {
+		spec["allowPostCopy"] = *policy.Spec.AllowPostCopy
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	obj.SetAPIVersion(migrations.GroupName + "/" + migrations.Version)
+	obj.SetKind(kubevirtMigrationPolicyKind)
+	obj.SetName(policy.Name)
+	return obj
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	vmiControllerName  = "migrationTargetController"
-	vmimControllerName = "migrationAnnotationController"
+	vmiControllerName    = "migrationTargetController"
+	vmimControllerName   = "migrationAnnotationController"
+	policyControllerName = "migrationPolicyController"
 )
 
 func Register(ctx context.Context, management *config.Management, options config.Options) error {
@@ -31,10 +32,18 @@ func Register(ctx context.Context, management *config.Management, options config
 		vmCache:    vms.Cache(),
 		pods:       pods,
 		podCache:   pods.Cache(),
+		events:     management.CoreFactory.Core().V1().Event(),
 		restClient: virtv1Client.RESTClient(),
 	}
 
 	vmis.OnChange(ctx, vmiControllerName, handler.OnVmiChanged)
 	vmims.OnChange(ctx, vmimControllerName, handler.OnVmimChanged)
+
+	policies := management.HarvesterFactory.Harvesterhci().V1beta1().MigrationPolicy()
+	policyHandler := &PolicyHandler{
+		apply:    management.Apply,
+		policies: policies,
+	}
+	policies.OnChange(ctx, policyControllerName, policyHandler.OnPolicyChanged)
 	return nil
 }
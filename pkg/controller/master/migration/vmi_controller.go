@@ -15,7 +15,8 @@ import (
 	"github.com/harvester/harvester/pkg/util"
 )
 
-// Handler resets vmi annotations and nodeSelector when a migration completes
+// Handler resets vmi annotations and nodeSelector when a migration completes, and keeps the completed migrations in
+// the migration history of the VM
 type Handler struct {
 	namespace  string
 	vmiCache   ctlv1.VirtualMachineInstanceCache
@@ -23,6 +24,7 @@ type Handler struct {
 	vmCache    ctlv1.VirtualMachineCache
 	podCache   ctlcorev1.PodCache
 	pods       ctlcorev1.PodClient
+	events     ctlcorev1.EventClient
 	restClient rest.Interface
 }
 
@@ -32,6 +34,12 @@ func (h *Handler) OnVmiChanged(_ string, vmi *kubevirtv1.VirtualMachineInstance)
 		return vmi, nil
 	}
 
+	if vmi.Status.MigrationState.Completed {
+		if err := h.recordMigration(vmi); err != nil {
+			return vmi, err
+		}
+	}
+
 	if vmi.Annotations[util.AnnotationMigrationUID] == string(vmi.Status.MigrationState.MigrationUID) &&
 		vmi.Status.MigrationState.Completed {
 		if err := h.resetHarvesterMigrationStateInVMI(vmi); err != nil {
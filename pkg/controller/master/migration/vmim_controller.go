@@ -44,6 +44,9 @@ func (h *Handler) OnVmimChanged(_ string, vmim *kubevirtv1.VirtualMachineInstanc
 	} else if vmi.Annotations[util.AnnotationMigrationUID] == string(vmim.UID) && vmim.Status.Phase == kubevirtv1.MigrationFailed {
 		// There are cases when VMIM failed but the status is not reported in VMI.status.migrationState
 		// https://github.com/kubevirt/kubevirt/issues/5503
+		if err := h.recordFailedMigration(vmi, vmim); err != nil {
+			return vmim, err
+		}
 		if err := h.resetHarvesterMigrationStateInVMI(vmi); err != nil {
 			return vmim, err
 		}
@@ -9,6 +9,7 @@ import (
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/controller/master/backup"
 	"github.com/harvester/harvester/pkg/controller/master/image"
+	"github.com/harvester/harvester/pkg/controller/master/imagebuild"
 	"github.com/harvester/harvester/pkg/controller/master/keypair"
 	"github.com/harvester/harvester/pkg/controller/master/migration"
 	"github.com/harvester/harvester/pkg/controller/master/node"
@@ -25,6 +26,7 @@ type registerFunc func(context.Context, *config.Management, config.Options) erro
 
 var registerFuncs = []registerFunc{
 	image.Register,
+	imagebuild.Register,
 	keypair.Register,
 	migration.Register,
 	node.PromoteRegister,
@@ -37,6 +39,9 @@ var registerFuncs = []registerFunc{
 	backup.RegisterRestore,
 	backup.RegisterBackupTarget,
 	backup.RegisterBackupMetadata,
+	backup.RegisterBackupSchedule,
+	backup.RegisterBackupVerification,
+	backup.RegisterFileRestore,
 	supportbundle.Register,
 	rancher.Register,
 	upgrade.Register,
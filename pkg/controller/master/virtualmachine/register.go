@@ -12,6 +12,9 @@ const (
 	vmControllerUnsetOwnerOfPVCsControllerName         = "VMController.UnsetOwnerOfPVCs"
 	vmiControllerUnsetOwnerOfPVCsControllerName        = "VMIController.UnsetOwnerOfPVCs"
 	vmControllerSetDefaultManagementNetworkMac         = "VMController.SetDefaultManagementNetworkMacAddress"
+	vmiResizeControllerClearRestartRequired            = "VMIResizeController.ClearRestartRequired"
+	vmCloneControllerBindSnapshotsControllerName       = "VMCloneController.BindSnapshots"
+	vmCloneControllerRemoveSnapshotsControllerName     = "VMCloneController.RemoveSnapshots"
 )
 
 func Register(ctx context.Context, management *config.Management, options config.Options) error {
@@ -51,5 +54,27 @@ func Register(ctx context.Context, management *config.Management, options config
 	}
 	virtualMachineInstanceClient.OnChange(ctx, vmControllerSetDefaultManagementNetworkMac, vmNetworkCtl.SetDefaultNetworkMacAddress)
 
+	// registers the vmi resize controller
+	var vmiResizeCtrl = &VMIResizeController{
+		vmCache:  vmCache,
+		vmClient: vmClient,
+	}
+	virtualMachineInstanceClient.OnChange(ctx, vmiResizeControllerClearRestartRequired, vmiResizeCtrl.ClearRestartRequired)
+
+	// registers the vm clone controller
+	var (
+		snapshotClient        = management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshot()
+		snapshotContentClient = management.SnapshotFactory.Snapshot().V1beta1().VolumeSnapshotContent()
+	)
+	var vmCloneCtrl = &VMCloneController{
+		snapshots:            snapshotClient,
+		snapshotCache:        snapshotClient.Cache(),
+		snapshotContents:     snapshotContentClient,
+		snapshotContentCache: snapshotContentClient.Cache(),
+		vmCache:              vmCache,
+	}
+	snapshotClient.OnChange(ctx, vmCloneControllerBindSnapshotsControllerName, vmCloneCtrl.OnVolumeSnapshotChanged)
+	virtualMachineClient.OnRemove(ctx, vmCloneControllerRemoveSnapshotsControllerName, vmCloneCtrl.OnVMRemoved)
+
 	return nil
 }
@@ -0,0 +1,147 @@
+package virtualmachine
+
+import (
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	wranglername "github.com/rancher/wrangler/pkg/name"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	ctlsnapshotv1 "github.com/harvester/harvester/pkg/generated/controllers/snapshot.storage.k8s.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+// VMCloneController binds the volume snapshots of linked clones to the namespaces of the cloned VMs, and removes
+// the snapshots once the cloned VMs are removed.
+type VMCloneController struct {
+	snapshots            ctlsnapshotv1.VolumeSnapshotClient
+	snapshotCache        ctlsnapshotv1.VolumeSnapshotCache
+	snapshotContents     ctlsnapshotv1.VolumeSnapshotContentClient
+	snapshotContentCache ctlsnapshotv1.VolumeSnapshotContentCache
+	vmCache              ctlkubevirtv1.VirtualMachineCache
+}
+
+// OnVolumeSnapshotChanged creates a pre-provisioned snapshot in the namespace of the cloned VM from the ready snapshot
+// of a VM cloned to another namespace, the volumes of the cloned VM are created from it.
+func (h *VMCloneController) OnVolumeSnapshotChanged(key string, snapshot *snapshotv1.VolumeSnapshot) (*snapshotv1.VolumeSnapshot, error) {
+	if snapshot == nil || snapshot.DeletionTimestamp != nil {
+		return snapshot, nil
+	}
+	targetNamespace, targetName := snapshot.Labels[util.LabelVMCloneNamespace], snapshot.Labels[util.LabelVMCloneName]
+	if targetNamespace == "" || targetName == "" || targetNamespace == snapshot.Namespace {
+		return snapshot, nil
+	}
+	if snapshot.Status == nil || snapshot.Status.ReadyToUse == nil || !*snapshot.Status.ReadyToUse ||
+		snapshot.Status.BoundVolumeSnapshotContentName == nil {
+		return snapshot, nil
+	}
+	if _, err := h.snapshotCache.Get(targetNamespace, snapshot.Name); err == nil {
+		return snapshot, nil
+	} else if !apierrors.IsNotFound(err) {
+		return snapshot, err
+	}
+
+	vm, err := h.vmCache.Get(targetNamespace, targetName)
+	if err != nil {
+		// the cloned VM is created after its snapshots
+		return snapshot, err
+	}
+	content, err := h.snapshotContentCache.Get(*snapshot.Status.BoundVolumeSnapshotContentName)
+	if err != nil {
+		return snapshot, err
+	}
+	if content.Status == nil || content.Status.SnapshotHandle == nil {
+		return snapshot, nil
+	}
+
+	targetContent := newCloneSnapshotContent(snapshot, content, targetNamespace)
+	if _, err := h.snapshotContents.Create(targetContent); err != nil && !apierrors.IsAlreadyExists(err) {
+		return snapshot, err
+	}
+	if _, err := h.snapshots.Create(newCloneSnapshot(snapshot, targetContent, vm)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return snapshot, err
+	}
+	logrus.Infof("snapshot %s/%s is bound to namespace %s for VM %s/%s", snapshot.Namespace, snapshot.Name, targetNamespace, targetNamespace, targetName)
+	return snapshot, nil
+}
+
+// OnVMRemoved removes the snapshots and the snapshot contents the VM is cloned from
+func (h *VMCloneController) OnVMRemoved(key string, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	if vm == nil {
+		return vm, nil
+	}
+	selector := labels.SelectorFromSet(labels.Set{
+		util.LabelVMCloneNamespace: vm.Namespace,
+		util.LabelVMCloneName:      vm.Name,
+	})
+	snapshots, err := h.snapshotCache.List(metav1.NamespaceAll, selector)
+	if err != nil {
+		return vm, err
+	}
+	for _, snapshot := range snapshots {
+		if err := h.snapshots.Delete(snapshot.Namespace, snapshot.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return vm, err
+		}
+	}
+	contents, err := h.snapshotContentCache.List(selector)
+	if err != nil {
+		return vm, err
+	}
+	for _, content := range contents {
+		if err := h.snapshotContents.Delete(content.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return vm, err
+		}
+	}
+	return vm, nil
+}
+
+// newCloneSnapshotContent returns a snapshot content sharing the snapshot handle of the content of the source snapshot.
+// It retains the snapshot so that the snapshot is only deleted along with the source snapshot.
+func newCloneSnapshotContent(snapshot *snapshotv1.VolumeSnapshot, content *snapshotv1.VolumeSnapshotContent, targetNamespace string) *snapshotv1.VolumeSnapshotContent {
+	return &snapshotv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   wranglername.SafeConcatName("vmclone", string(snapshot.UID)),
+			Labels: snapshot.Labels,
+		},
+		Spec: snapshotv1.VolumeSnapshotContentSpec{
+			Driver:         content.Spec.Driver,
+			DeletionPolicy: snapshotv1.VolumeSnapshotContentRetain,
+			Source: snapshotv1.VolumeSnapshotContentSource{
+				SnapshotHandle: content.Status.SnapshotHandle,
+			},
+			VolumeSnapshotClassName: content.Spec.VolumeSnapshotClassName,
+			VolumeSnapshotRef: corev1.ObjectReference{
+				Name:      snapshot.Name,
+				Namespace: targetNamespace,
+			},
+		},
+	}
+}
+
+func newCloneSnapshot(snapshot *snapshotv1.VolumeSnapshot, content *snapshotv1.VolumeSnapshotContent, vm *kubevirtv1.VirtualMachine) *snapshotv1.VolumeSnapshot {
+	return &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshot.Name,
+			Namespace: vm.Namespace,
+			Labels:    snapshot.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: kubevirtv1.SchemeGroupVersion.String(),
+					Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+					Name:       vm.Name,
+					UID:        vm.UID,
+				},
+			},
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				VolumeSnapshotContentName: &content.Name,
+			},
+			VolumeSnapshotClassName: snapshot.Spec.VolumeSnapshotClassName,
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package virtualmachine
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	vmv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+type VMIResizeController struct {
+	vmCache  vmv1.VirtualMachineCache
+	vmClient vmv1.VirtualMachineClient
+}
+
+// ClearRestartRequired removes the restart required annotation of a resized VM once the VMI runs with the new shape.
+func (h *VMIResizeController) ClearRestartRequired(id string, vmi *kubevirtv1.VirtualMachineInstance) (*kubevirtv1.VirtualMachineInstance, error) {
+	if id == "" || vmi == nil || vmi.DeletionTimestamp != nil {
+		return vmi, nil
+	}
+
+	if vmi.Status.Phase != kubevirtv1.Running {
+		return vmi, nil
+	}
+
+	vm, err := h.vmCache.Get(vmi.Namespace, vmi.Name)
+	if apierrors.IsNotFound(err) {
+		return vmi, nil
+	} else if err != nil {
+		return vmi, err
+	}
+
+	if !util.IsRestartRequired(vm) || !util.HasSameShape(vm, vmi) {
+		return vmi, nil
+	}
+
+	vmCopy := vm.DeepCopy()
+	delete(vmCopy.Annotations, util.AnnotationRestartRequired)
+	if _, err := h.vmClient.Update(vmCopy); err != nil {
+		return vmi, err
+	}
+
+	return vmi, nil
+}
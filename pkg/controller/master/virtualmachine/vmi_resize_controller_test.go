@@ -0,0 +1,93 @@
+package virtualmachine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
+)
+
+func TestVMIResizeController_ClearRestartRequired(t *testing.T) {
+	domain := func(cores uint32, memory string) kubevirtv1.DomainSpec {
+		return kubevirtv1.DomainSpec{
+			CPU: &kubevirtv1.CPU{Cores: cores},
+			Resources: kubevirtv1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(int64(cores), resource.DecimalSI),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	newVM := func(cores uint32, memory string) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "test",
+				Annotations: map[string]string{util.AnnotationRestartRequired: "true"},
+			},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{Domain: domain(cores, memory)},
+				},
+			},
+		}
+	}
+	newVMI := func(cores uint32, memory string, phase kubevirtv1.VirtualMachineInstancePhase) *kubevirtv1.VirtualMachineInstance {
+		return &kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			Spec:       kubevirtv1.VirtualMachineInstanceSpec{Domain: domain(cores, memory)},
+			Status:     kubevirtv1.VirtualMachineInstanceStatus{Phase: phase},
+		}
+	}
+
+	var testCases = []struct {
+		name            string
+		vm              *kubevirtv1.VirtualMachine
+		vmi             *kubevirtv1.VirtualMachineInstance
+		restartRequired bool
+	}{
+		{
+			name:            "running with the old shape",
+			vm:              newVM(4, "8Gi"),
+			vmi:             newVMI(2, "4Gi", kubevirtv1.Running),
+			restartRequired: true,
+		},
+		{
+			name:            "starting with the new shape",
+			vm:              newVM(4, "8Gi"),
+			vmi:             newVMI(4, "8Gi", kubevirtv1.Scheduling),
+			restartRequired: true,
+		},
+		{
+			name:            "running with the new shape",
+			vm:              newVM(4, "8Gi"),
+			vmi:             newVMI(4, "8Gi", kubevirtv1.Running),
+			restartRequired: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tc.vm)
+			ctrl := &VMIResizeController{
+				vmCache:  fakeVMCache(clientset.KubevirtV1().VirtualMachines),
+				vmClient: fakeVMClient(clientset.KubevirtV1().VirtualMachines),
+			}
+
+			_, err := ctrl.ClearRestartRequired("default/test", tc.vmi)
+			assert.Nil(t, err)
+
+			vm, err := clientset.KubevirtV1().VirtualMachines("default").Get(context.TODO(), "test", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.restartRequired, util.IsRestartRequired(vm))
+		})
+	}
+}
@@ -28,10 +28,18 @@ type FakeHarvesterhciV1beta1 struct {
 	*testing.Fake
 }
 
+func (c *FakeHarvesterhciV1beta1) BackupTargets() v1beta1.BackupTargetInterface {
+	return &FakeBackupTargets{c}
+}
+
 func (c *FakeHarvesterhciV1beta1) KeyPairs(namespace string) v1beta1.KeyPairInterface {
 	return &FakeKeyPairs{c, namespace}
 }
 
+func (c *FakeHarvesterhciV1beta1) MigrationPolicies() v1beta1.MigrationPolicyInterface {
+	return &FakeMigrationPolicies{c}
+}
+
 func (c *FakeHarvesterhciV1beta1) Preferences(namespace string) v1beta1.PreferenceInterface {
 	return &FakePreferences{c, namespace}
 }
@@ -56,10 +64,18 @@ func (c *FakeHarvesterhciV1beta1) VirtualMachineBackups(namespace string) v1beta
 	return &FakeVirtualMachineBackups{c, namespace}
 }
 
+func (c *FakeHarvesterhciV1beta1) VirtualMachineBackupSchedules(namespace string) v1beta1.VirtualMachineBackupScheduleInterface {
+	return &FakeVirtualMachineBackupSchedules{c, namespace}
+}
+
 func (c *FakeHarvesterhciV1beta1) VirtualMachineImages(namespace string) v1beta1.VirtualMachineImageInterface {
 	return &FakeVirtualMachineImages{c, namespace}
 }
 
+func (c *FakeHarvesterhciV1beta1) VirtualMachineImageBuilds(namespace string) v1beta1.VirtualMachineImageBuildInterface {
+	return &FakeVirtualMachineImageBuilds{c, namespace}
+}
+
 func (c *FakeHarvesterhciV1beta1) VirtualMachineRestores(namespace string) v1beta1.VirtualMachineRestoreInterface {
 	return &FakeVirtualMachineRestores{c, namespace}
 }
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVirtualMachineBackupSchedules implements VirtualMachineBackupScheduleInterface
+type FakeVirtualMachineBackupSchedules struct {
+	Fake *FakeHarvesterhciV1beta1
+	ns   string
+}
+
+var virtualmachinebackupschedulesResource = schema.GroupVersionResource{Group: "harvesterhci.io", Version: "v1beta1", Resource: "virtualmachinebackupschedules"}
+
+var virtualmachinebackupschedulesKind = schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBackupSchedule"}
+
+// Get takes name of the virtualMachineBackupSchedule, and returns the corresponding virtualMachineBackupSchedule object, and an error if there is any.
+func (c *FakeVirtualMachineBackupSchedules) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(virtualmachinebackupschedulesResource, c.ns, name), &v1beta1.VirtualMachineBackupSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), err
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineBackupSchedules that match those selectors.
+func (c *FakeVirtualMachineBackupSchedules) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.VirtualMachineBackupScheduleList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(virtualmachinebackupschedulesResource, virtualmachinebackupschedulesKind, c.ns, opts), &v1beta1.VirtualMachineBackupScheduleList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.VirtualMachineBackupScheduleList{ListMeta: obj.(*v1beta1.VirtualMachineBackupScheduleList).ListMeta}
+	for _, item := range obj.(*v1beta1.VirtualMachineBackupScheduleList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineBackupSchedules.
+func (c *FakeVirtualMachineBackupSchedules) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(virtualmachinebackupschedulesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a virtualMachineBackupSchedule and creates it.  Returns the server's representation of the virtualMachineBackupSchedule, and an error, if there is any.
+func (c *FakeVirtualMachineBackupSchedules) Create(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.CreateOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(virtualmachinebackupschedulesResource, c.ns, virtualMachineBackupSchedule), &v1beta1.VirtualMachineBackupSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), err
+}
+
+// Update takes the representation of a virtualMachineBackupSchedule and updates it. Returns the server's representation of the virtualMachineBackupSchedule, and an error, if there is any.
+func (c *FakeVirtualMachineBackupSchedules) Update(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(virtualmachinebackupschedulesResource, c.ns, virtualMachineBackupSchedule), &v1beta1.VirtualMachineBackupSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVirtualMachineBackupSchedules) UpdateStatus(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(virtualmachinebackupschedulesResource, "status", c.ns, virtualMachineBackupSchedule), &v1beta1.VirtualMachineBackupSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), err
+}
+
+// Delete takes name of the virtualMachineBackupSchedule and deletes it. Returns an error if one occurs.
+func (c *FakeVirtualMachineBackupSchedules) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(virtualmachinebackupschedulesResource, c.ns, name), &v1beta1.VirtualMachineBackupSchedule{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVirtualMachineBackupSchedules) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(virtualmachinebackupschedulesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.VirtualMachineBackupScheduleList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched virtualMachineBackupSchedule.
+func (c *FakeVirtualMachineBackupSchedules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(virtualmachinebackupschedulesResource, c.ns, name, pt, data, subresources...), &v1beta1.VirtualMachineBackupSchedule{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), err
+}
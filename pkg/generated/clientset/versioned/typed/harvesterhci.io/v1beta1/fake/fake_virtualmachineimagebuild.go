@@ -0,0 +1,142 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVirtualMachineImageBuilds implements VirtualMachineImageBuildInterface
+type FakeVirtualMachineImageBuilds struct {
+	Fake *FakeHarvesterhciV1beta1
+	ns   string
+}
+
+var virtualmachineimagebuildsResource = schema.GroupVersionResource{Group: "harvesterhci.io", Version: "v1beta1", Resource: "virtualmachineimagebuilds"}
+
+var virtualmachineimagebuildsKind = schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineImageBuild"}
+
+// Get takes name of the virtualMachineImageBuild, and returns the corresponding virtualMachineImageBuild object, and an error if there is any.
+func (c *FakeVirtualMachineImageBuilds) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(virtualmachineimagebuildsResource, c.ns, name), &v1beta1.VirtualMachineImageBuild{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), err
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineImageBuilds that match those selectors.
+func (c *FakeVirtualMachineImageBuilds) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.VirtualMachineImageBuildList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(virtualmachineimagebuildsResource, virtualmachineimagebuildsKind, c.ns, opts), &v1beta1.VirtualMachineImageBuildList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.VirtualMachineImageBuildList{ListMeta: obj.(*v1beta1.VirtualMachineImageBuildList).ListMeta}
+	for _, item := range obj.(*v1beta1.VirtualMachineImageBuildList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineImageBuilds.
+func (c *FakeVirtualMachineImageBuilds) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(virtualmachineimagebuildsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a virtualMachineImageBuild and creates it.  Returns the server's representation of the virtualMachineImageBuild, and an error, if there is any.
+func (c *FakeVirtualMachineImageBuilds) Create(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.CreateOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(virtualmachineimagebuildsResource, c.ns, virtualMachineImageBuild), &v1beta1.VirtualMachineImageBuild{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), err
+}
+
+// Update takes the representation of a virtualMachineImageBuild and updates it. Returns the server's representation of the virtualMachineImageBuild, and an error, if there is any.
+func (c *FakeVirtualMachineImageBuilds) Update(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(virtualmachineimagebuildsResource, c.ns, virtualMachineImageBuild), &v1beta1.VirtualMachineImageBuild{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVirtualMachineImageBuilds) UpdateStatus(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (*v1beta1.VirtualMachineImageBuild, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(virtualmachineimagebuildsResource, "status", c.ns, virtualMachineImageBuild), &v1beta1.VirtualMachineImageBuild{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), err
+}
+
+// Delete takes name of the virtualMachineImageBuild and deletes it. Returns an error if one occurs.
+func (c *FakeVirtualMachineImageBuilds) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(virtualmachineimagebuildsResource, c.ns, name), &v1beta1.VirtualMachineImageBuild{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVirtualMachineImageBuilds) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(virtualmachineimagebuildsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.VirtualMachineImageBuildList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched virtualMachineImageBuild.
+func (c *FakeVirtualMachineImageBuilds) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(virtualmachineimagebuildsResource, c.ns, name, pt, data, subresources...), &v1beta1.VirtualMachineImageBuild{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), err
+}
@@ -18,8 +18,12 @@ limitations under the License.
 
 package v1beta1
 
+type BackupTargetExpansion interface{}
+
 type KeyPairExpansion interface{}
 
+type MigrationPolicyExpansion interface{}
+
 type PreferenceExpansion interface{}
 
 type SettingExpansion interface{}
@@ -32,8 +36,12 @@ type VersionExpansion interface{}
 
 type VirtualMachineBackupExpansion interface{}
 
+type VirtualMachineBackupScheduleExpansion interface{}
+
 type VirtualMachineImageExpansion interface{}
 
+type VirtualMachineImageBuildExpansion interface{}
+
 type VirtualMachineRestoreExpansion interface{}
 
 type VirtualMachineTemplateExpansion interface{}
@@ -26,14 +26,18 @@ import (
 
 type HarvesterhciV1beta1Interface interface {
 	RESTClient() rest.Interface
+	BackupTargetsGetter
 	KeyPairsGetter
+	MigrationPoliciesGetter
 	PreferencesGetter
 	SettingsGetter
 	SupportBundlesGetter
 	UpgradesGetter
 	VersionsGetter
 	VirtualMachineBackupsGetter
+	VirtualMachineBackupSchedulesGetter
 	VirtualMachineImagesGetter
+	VirtualMachineImageBuildsGetter
 	VirtualMachineRestoresGetter
 	VirtualMachineTemplatesGetter
 	VirtualMachineTemplateVersionsGetter
@@ -44,10 +48,18 @@ type HarvesterhciV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *HarvesterhciV1beta1Client) BackupTargets() BackupTargetInterface {
+	return newBackupTargets(c)
+}
+
 func (c *HarvesterhciV1beta1Client) KeyPairs(namespace string) KeyPairInterface {
 	return newKeyPairs(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) MigrationPolicies() MigrationPolicyInterface {
+	return newMigrationPolicies(c)
+}
+
 func (c *HarvesterhciV1beta1Client) Preferences(namespace string) PreferenceInterface {
 	return newPreferences(c, namespace)
 }
@@ -72,10 +84,18 @@ func (c *HarvesterhciV1beta1Client) VirtualMachineBackups(namespace string) Virt
 	return newVirtualMachineBackups(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) VirtualMachineBackupSchedules(namespace string) VirtualMachineBackupScheduleInterface {
+	return newVirtualMachineBackupSchedules(c, namespace)
+}
+
 func (c *HarvesterhciV1beta1Client) VirtualMachineImages(namespace string) VirtualMachineImageInterface {
 	return newVirtualMachineImages(c, namespace)
 }
 
+func (c *HarvesterhciV1beta1Client) VirtualMachineImageBuilds(namespace string) VirtualMachineImageBuildInterface {
+	return newVirtualMachineImageBuilds(c, namespace)
+}
+
 func (c *HarvesterhciV1beta1Client) VirtualMachineRestores(namespace string) VirtualMachineRestoreInterface {
 	return newVirtualMachineRestores(c, namespace)
 }
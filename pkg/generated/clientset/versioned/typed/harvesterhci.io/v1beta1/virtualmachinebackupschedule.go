@@ -0,0 +1,195 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VirtualMachineBackupSchedulesGetter has a method to return a VirtualMachineBackupScheduleInterface.
+// A group's client should implement this interface.
+type VirtualMachineBackupSchedulesGetter interface {
+	VirtualMachineBackupSchedules(namespace string) VirtualMachineBackupScheduleInterface
+}
+
+// VirtualMachineBackupScheduleInterface has methods to work with VirtualMachineBackupSchedule resources.
+type VirtualMachineBackupScheduleInterface interface {
+	Create(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.CreateOptions) (*v1beta1.VirtualMachineBackupSchedule, error)
+	Update(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (*v1beta1.VirtualMachineBackupSchedule, error)
+	UpdateStatus(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (*v1beta1.VirtualMachineBackupSchedule, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.VirtualMachineBackupSchedule, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.VirtualMachineBackupScheduleList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineBackupSchedule, err error)
+	VirtualMachineBackupScheduleExpansion
+}
+
+// virtualMachineBackupSchedules implements VirtualMachineBackupScheduleInterface
+type virtualMachineBackupSchedules struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVirtualMachineBackupSchedules returns a VirtualMachineBackupSchedules
+func newVirtualMachineBackupSchedules(c *HarvesterhciV1beta1Client, namespace string) *virtualMachineBackupSchedules {
+	return &virtualMachineBackupSchedules{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the virtualMachineBackupSchedule, and returns the corresponding virtualMachineBackupSchedule object, and an error if there is any.
+func (c *virtualMachineBackupSchedules) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	result = &v1beta1.VirtualMachineBackupSchedule{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineBackupSchedules that match those selectors.
+func (c *virtualMachineBackupSchedules) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.VirtualMachineBackupScheduleList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.VirtualMachineBackupScheduleList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineBackupSchedules.
+func (c *virtualMachineBackupSchedules) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a virtualMachineBackupSchedule and creates it.  Returns the server's representation of the virtualMachineBackupSchedule, and an error, if there is any.
+func (c *virtualMachineBackupSchedules) Create(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.CreateOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	result = &v1beta1.VirtualMachineBackupSchedule{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineBackupSchedule).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a virtualMachineBackupSchedule and updates it. Returns the server's representation of the virtualMachineBackupSchedule, and an error, if there is any.
+func (c *virtualMachineBackupSchedules) Update(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	result = &v1beta1.VirtualMachineBackupSchedule{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		Name(virtualMachineBackupSchedule.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineBackupSchedule).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *virtualMachineBackupSchedules) UpdateStatus(ctx context.Context, virtualMachineBackupSchedule *v1beta1.VirtualMachineBackupSchedule, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	result = &v1beta1.VirtualMachineBackupSchedule{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		Name(virtualMachineBackupSchedule.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineBackupSchedule).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the virtualMachineBackupSchedule and deletes it. Returns an error if one occurs.
+func (c *virtualMachineBackupSchedules) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *virtualMachineBackupSchedules) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched virtualMachineBackupSchedule.
+func (c *virtualMachineBackupSchedules) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineBackupSchedule, err error) {
+	result = &v1beta1.VirtualMachineBackupSchedule{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("virtualmachinebackupschedules").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	scheme "github.com/harvester/harvester/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VirtualMachineImageBuildsGetter has a method to return a VirtualMachineImageBuildInterface.
+// A group's client should implement this interface.
+type VirtualMachineImageBuildsGetter interface {
+	VirtualMachineImageBuilds(namespace string) VirtualMachineImageBuildInterface
+}
+
+// VirtualMachineImageBuildInterface has methods to work with VirtualMachineImageBuild resources.
+type VirtualMachineImageBuildInterface interface {
+	Create(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.CreateOptions) (*v1beta1.VirtualMachineImageBuild, error)
+	Update(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (*v1beta1.VirtualMachineImageBuild, error)
+	UpdateStatus(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (*v1beta1.VirtualMachineImageBuild, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.VirtualMachineImageBuild, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.VirtualMachineImageBuildList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineImageBuild, err error)
+	VirtualMachineImageBuildExpansion
+}
+
+// virtualMachineImageBuilds implements VirtualMachineImageBuildInterface
+type virtualMachineImageBuilds struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVirtualMachineImageBuilds returns a VirtualMachineImageBuilds
+func newVirtualMachineImageBuilds(c *HarvesterhciV1beta1Client, namespace string) *virtualMachineImageBuilds {
+	return &virtualMachineImageBuilds{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the virtualMachineImageBuild, and returns the corresponding virtualMachineImageBuild object, and an error if there is any.
+func (c *virtualMachineImageBuilds) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	result = &v1beta1.VirtualMachineImageBuild{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineImageBuilds that match those selectors.
+func (c *virtualMachineImageBuilds) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.VirtualMachineImageBuildList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.VirtualMachineImageBuildList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineImageBuilds.
+func (c *virtualMachineImageBuilds) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a virtualMachineImageBuild and creates it.  Returns the server's representation of the virtualMachineImageBuild, and an error, if there is any.
+func (c *virtualMachineImageBuilds) Create(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.CreateOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	result = &v1beta1.VirtualMachineImageBuild{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineImageBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a virtualMachineImageBuild and updates it. Returns the server's representation of the virtualMachineImageBuild, and an error, if there is any.
+func (c *virtualMachineImageBuilds) Update(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	result = &v1beta1.VirtualMachineImageBuild{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		Name(virtualMachineImageBuild.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineImageBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *virtualMachineImageBuilds) UpdateStatus(ctx context.Context, virtualMachineImageBuild *v1beta1.VirtualMachineImageBuild, opts v1.UpdateOptions) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	result = &v1beta1.VirtualMachineImageBuild{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		Name(virtualMachineImageBuild.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineImageBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the virtualMachineImageBuild and deletes it. Returns an error if one occurs.
+func (c *virtualMachineImageBuilds) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *virtualMachineImageBuilds) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched virtualMachineImageBuild.
+func (c *virtualMachineImageBuilds) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.VirtualMachineImageBuild, err error) {
+	result = &v1beta1.VirtualMachineImageBuild{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("virtualmachineimagebuilds").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
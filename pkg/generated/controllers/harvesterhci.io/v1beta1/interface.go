@@ -30,14 +30,18 @@ func init() {
 }
 
 type Interface interface {
+	BackupTarget() BackupTargetController
 	KeyPair() KeyPairController
+	MigrationPolicy() MigrationPolicyController
 	Preference() PreferenceController
 	Setting() SettingController
 	SupportBundle() SupportBundleController
 	Upgrade() UpgradeController
 	Version() VersionController
 	VirtualMachineBackup() VirtualMachineBackupController
+	VirtualMachineBackupSchedule() VirtualMachineBackupScheduleController
 	VirtualMachineImage() VirtualMachineImageController
+	VirtualMachineImageBuild() VirtualMachineImageBuildController
 	VirtualMachineRestore() VirtualMachineRestoreController
 	VirtualMachineTemplate() VirtualMachineTemplateController
 	VirtualMachineTemplateVersion() VirtualMachineTemplateVersionController
@@ -53,9 +57,15 @@ type version struct {
 	controllerFactory controller.SharedControllerFactory
 }
 
+func (c *version) BackupTarget() BackupTargetController {
+	return NewBackupTargetController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "BackupTarget"}, "backuptargets", false, c.controllerFactory)
+}
 func (c *version) KeyPair() KeyPairController {
 	return NewKeyPairController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "KeyPair"}, "keypairs", true, c.controllerFactory)
 }
+func (c *version) MigrationPolicy() MigrationPolicyController {
+	return NewMigrationPolicyController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "MigrationPolicy"}, "migrationpolicies", false, c.controllerFactory)
+}
 func (c *version) Preference() PreferenceController {
 	return NewPreferenceController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "Preference"}, "preferences", true, c.controllerFactory)
 }
@@ -74,9 +84,15 @@ func (c *version) Version() VersionController {
 func (c *version) VirtualMachineBackup() VirtualMachineBackupController {
 	return NewVirtualMachineBackupController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBackup"}, "virtualmachinebackups", true, c.controllerFactory)
 }
+func (c *version) VirtualMachineBackupSchedule() VirtualMachineBackupScheduleController {
+	return NewVirtualMachineBackupScheduleController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineBackupSchedule"}, "virtualmachinebackupschedules", true, c.controllerFactory)
+}
 func (c *version) VirtualMachineImage() VirtualMachineImageController {
 	return NewVirtualMachineImageController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineImage"}, "virtualmachineimages", true, c.controllerFactory)
 }
+func (c *version) VirtualMachineImageBuild() VirtualMachineImageBuildController {
+	return NewVirtualMachineImageBuildController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineImageBuild"}, "virtualmachineimagebuilds", true, c.controllerFactory)
+}
 func (c *version) VirtualMachineRestore() VirtualMachineRestoreController {
 	return NewVirtualMachineRestoreController(schema.GroupVersionKind{Group: "harvesterhci.io", Version: "v1beta1", Kind: "VirtualMachineRestore"}, "virtualmachinerestores", true, c.controllerFactory)
 }
@@ -0,0 +1,376 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/rancher/lasso/pkg/client"
+	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/rancher/wrangler/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+type VirtualMachineBackupScheduleHandler func(string, *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error)
+
+type VirtualMachineBackupScheduleController interface {
+	generic.ControllerMeta
+	VirtualMachineBackupScheduleClient
+
+	OnChange(ctx context.Context, name string, sync VirtualMachineBackupScheduleHandler)
+	OnRemove(ctx context.Context, name string, sync VirtualMachineBackupScheduleHandler)
+	Enqueue(namespace, name string)
+	EnqueueAfter(namespace, name string, duration time.Duration)
+
+	Cache() VirtualMachineBackupScheduleCache
+}
+
+type VirtualMachineBackupScheduleClient interface {
+	Create(*v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error)
+	Update(*v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error)
+	UpdateStatus(*v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error)
+	Delete(namespace, name string, options *metav1.DeleteOptions) error
+	Get(namespace, name string, options metav1.GetOptions) (*v1beta1.VirtualMachineBackupSchedule, error)
+	List(namespace string, opts metav1.ListOptions) (*v1beta1.VirtualMachineBackupScheduleList, error)
+	Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.VirtualMachineBackupSchedule, err error)
+}
+
+type VirtualMachineBackupScheduleCache interface {
+	Get(namespace, name string) (*v1beta1.VirtualMachineBackupSchedule, error)
+	List(namespace string, selector labels.Selector) ([]*v1beta1.VirtualMachineBackupSchedule, error)
+
+	AddIndexer(indexName string, indexer VirtualMachineBackupScheduleIndexer)
+	GetByIndex(indexName, key string) ([]*v1beta1.VirtualMachineBackupSchedule, error)
+}
+
+type VirtualMachineBackupScheduleIndexer func(obj *v1beta1.VirtualMachineBackupSchedule) ([]string, error)
+
+type virtualMachineBackupScheduleController struct {
+	controller    controller.SharedController
+	client        *client.Client
+	gvk           schema.GroupVersionKind
+	groupResource schema.GroupResource
+}
+
+func NewVirtualMachineBackupScheduleController(gvk schema.GroupVersionKind, resource string, namespaced bool, controller controller.SharedControllerFactory) VirtualMachineBackupScheduleController {
+	c := controller.ForResourceKind(gvk.GroupVersion().WithResource(resource), gvk.Kind, namespaced)
+	return &virtualMachineBackupScheduleController{
+		controller: c,
+		client:     c.Client(),
+		gvk:        gvk,
+		groupResource: schema.GroupResource{
+			Group:    gvk.Group,
+			Resource: resource,
+		},
+	}
+}
+
+func FromVirtualMachineBackupScheduleHandlerToHandler(sync VirtualMachineBackupScheduleHandler) generic.Handler {
+	return func(key string, obj runtime.Object) (ret runtime.Object, err error) {
+		var v *v1beta1.VirtualMachineBackupSchedule
+		if obj == nil {
+			v, err = sync(key, nil)
+		} else {
+			v, err = sync(key, obj.(*v1beta1.VirtualMachineBackupSchedule))
+		}
+		if v == nil {
+			return nil, err
+		}
+		return v, err
+	}
+}
+
+func (c *virtualMachineBackupScheduleController) Updater() generic.Updater {
+	return func(obj runtime.Object) (runtime.Object, error) {
+		newObj, err := c.Update(obj.(*v1beta1.VirtualMachineBackupSchedule))
+		if newObj == nil {
+			return nil, err
+		}
+		return newObj, err
+	}
+}
+
+func UpdateVirtualMachineBackupScheduleDeepCopyOnChange(client VirtualMachineBackupScheduleClient, obj *v1beta1.VirtualMachineBackupSchedule, handler func(obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error)) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	copyObj := obj.DeepCopy()
+	newObj, err := handler(copyObj)
+	if newObj != nil {
+		copyObj = newObj
+	}
+	if obj.ResourceVersion == copyObj.ResourceVersion && !equality.Semantic.DeepEqual(obj, copyObj) {
+		return client.Update(copyObj)
+	}
+
+	return copyObj, err
+}
+
+func (c *virtualMachineBackupScheduleController) AddGenericHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.controller.RegisterHandler(ctx, name, controller.SharedControllerHandlerFunc(handler))
+}
+
+func (c *virtualMachineBackupScheduleController) AddGenericRemoveHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), handler))
+}
+
+func (c *virtualMachineBackupScheduleController) OnChange(ctx context.Context, name string, sync VirtualMachineBackupScheduleHandler) {
+	c.AddGenericHandler(ctx, name, FromVirtualMachineBackupScheduleHandlerToHandler(sync))
+}
+
+func (c *virtualMachineBackupScheduleController) OnRemove(ctx context.Context, name string, sync VirtualMachineBackupScheduleHandler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), FromVirtualMachineBackupScheduleHandlerToHandler(sync)))
+}
+
+func (c *virtualMachineBackupScheduleController) Enqueue(namespace, name string) {
+	c.controller.Enqueue(namespace, name)
+}
+
+func (c *virtualMachineBackupScheduleController) EnqueueAfter(namespace, name string, duration time.Duration) {
+	c.controller.EnqueueAfter(namespace, name, duration)
+}
+
+func (c *virtualMachineBackupScheduleController) Informer() cache.SharedIndexInformer {
+	return c.controller.Informer()
+}
+
+func (c *virtualMachineBackupScheduleController) GroupVersionKind() schema.GroupVersionKind {
+	return c.gvk
+}
+
+func (c *virtualMachineBackupScheduleController) Cache() VirtualMachineBackupScheduleCache {
+	return &virtualMachineBackupScheduleCache{
+		indexer:  c.Informer().GetIndexer(),
+		resource: c.groupResource,
+	}
+}
+
+func (c *virtualMachineBackupScheduleController) Create(obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	result := &v1beta1.VirtualMachineBackupSchedule{}
+	return result, c.client.Create(context.TODO(), obj.Namespace, obj, result, metav1.CreateOptions{})
+}
+
+func (c *virtualMachineBackupScheduleController) Update(obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	result := &v1beta1.VirtualMachineBackupSchedule{}
+	return result, c.client.Update(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
+}
+
+func (c *virtualMachineBackupScheduleController) UpdateStatus(obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	result := &v1beta1.VirtualMachineBackupSchedule{}
+	return result, c.client.UpdateStatus(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
+}
+
+func (c *virtualMachineBackupScheduleController) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+	return c.client.Delete(context.TODO(), namespace, name, *options)
+}
+
+func (c *virtualMachineBackupScheduleController) Get(namespace, name string, options metav1.GetOptions) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	result := &v1beta1.VirtualMachineBackupSchedule{}
+	return result, c.client.Get(context.TODO(), namespace, name, result, options)
+}
+
+func (c *virtualMachineBackupScheduleController) List(namespace string, opts metav1.ListOptions) (*v1beta1.VirtualMachineBackupScheduleList, error) {
+	result := &v1beta1.VirtualMachineBackupScheduleList{}
+	return result, c.client.List(context.TODO(), namespace, result, opts)
+}
+
+func (c *virtualMachineBackupScheduleController) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.client.Watch(context.TODO(), namespace, opts)
+}
+
+func (c *virtualMachineBackupScheduleController) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	result := &v1beta1.VirtualMachineBackupSchedule{}
+	return result, c.client.Patch(context.TODO(), namespace, name, pt, data, result, metav1.PatchOptions{}, subresources...)
+}
+
+type virtualMachineBackupScheduleCache struct {
+	indexer  cache.Indexer
+	resource schema.GroupResource
+}
+
+func (c *virtualMachineBackupScheduleCache) Get(namespace, name string) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	obj, exists, err := c.indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(c.resource, name)
+	}
+	return obj.(*v1beta1.VirtualMachineBackupSchedule), nil
+}
+
+func (c *virtualMachineBackupScheduleCache) List(namespace string, selector labels.Selector) (ret []*v1beta1.VirtualMachineBackupSchedule, err error) {
+
+	err = cache.ListAllByNamespace(c.indexer, namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.VirtualMachineBackupSchedule))
+	})
+
+	return ret, err
+}
+
+func (c *virtualMachineBackupScheduleCache) AddIndexer(indexName string, indexer VirtualMachineBackupScheduleIndexer) {
+	utilruntime.Must(c.indexer.AddIndexers(map[string]cache.IndexFunc{
+		indexName: func(obj interface{}) (strings []string, e error) {
+			return indexer(obj.(*v1beta1.VirtualMachineBackupSchedule))
+		},
+	}))
+}
+
+func (c *virtualMachineBackupScheduleCache) GetByIndex(indexName, key string) (result []*v1beta1.VirtualMachineBackupSchedule, err error) {
+	objs, err := c.indexer.ByIndex(indexName, key)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]*v1beta1.VirtualMachineBackupSchedule, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, obj.(*v1beta1.VirtualMachineBackupSchedule))
+	}
+	return result, nil
+}
+
+type VirtualMachineBackupScheduleStatusHandler func(obj *v1beta1.VirtualMachineBackupSchedule, status v1beta1.VirtualMachineBackupScheduleStatus) (v1beta1.VirtualMachineBackupScheduleStatus, error)
+
+type VirtualMachineBackupScheduleGeneratingHandler func(obj *v1beta1.VirtualMachineBackupSchedule, status v1beta1.VirtualMachineBackupScheduleStatus) ([]runtime.Object, v1beta1.VirtualMachineBackupScheduleStatus, error)
+
+func RegisterVirtualMachineBackupScheduleStatusHandler(ctx context.Context, controller VirtualMachineBackupScheduleController, condition condition.Cond, name string, handler VirtualMachineBackupScheduleStatusHandler) {
+	statusHandler := &virtualMachineBackupScheduleStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, FromVirtualMachineBackupScheduleHandlerToHandler(statusHandler.sync))
+}
+
+func RegisterVirtualMachineBackupScheduleGeneratingHandler(ctx context.Context, controller VirtualMachineBackupScheduleController, apply apply.Apply,
+	condition condition.Cond, name string, handler VirtualMachineBackupScheduleGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &virtualMachineBackupScheduleGeneratingHandler{
+		VirtualMachineBackupScheduleGeneratingHandler: handler,
+		apply: apply,
+		name:  name,
+		gvk:   controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterVirtualMachineBackupScheduleStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type virtualMachineBackupScheduleStatusHandler struct {
+	client    VirtualMachineBackupScheduleClient
+	condition condition.Cond
+	handler   VirtualMachineBackupScheduleStatusHandler
+}
+
+func (a *virtualMachineBackupScheduleStatusHandler) sync(key string, obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type virtualMachineBackupScheduleGeneratingHandler struct {
+	VirtualMachineBackupScheduleGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+}
+
+func (a *virtualMachineBackupScheduleGeneratingHandler) Remove(key string, obj *v1beta1.VirtualMachineBackupSchedule) (*v1beta1.VirtualMachineBackupSchedule, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1beta1.VirtualMachineBackupSchedule{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+func (a *virtualMachineBackupScheduleGeneratingHandler) Handle(obj *v1beta1.VirtualMachineBackupSchedule, status v1beta1.VirtualMachineBackupScheduleStatus) (v1beta1.VirtualMachineBackupScheduleStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.VirtualMachineBackupScheduleGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+
+	return newStatus, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+}
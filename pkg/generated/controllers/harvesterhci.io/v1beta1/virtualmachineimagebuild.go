@@ -0,0 +1,376 @@
+/*
+Copyright 2022 Rancher Labs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/rancher/lasso/pkg/client"
+	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/rancher/wrangler/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+type VirtualMachineImageBuildHandler func(string, *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error)
+
+type VirtualMachineImageBuildController interface {
+	generic.ControllerMeta
+	VirtualMachineImageBuildClient
+
+	OnChange(ctx context.Context, name string, sync VirtualMachineImageBuildHandler)
+	OnRemove(ctx context.Context, name string, sync VirtualMachineImageBuildHandler)
+	Enqueue(namespace, name string)
+	EnqueueAfter(namespace, name string, duration time.Duration)
+
+	Cache() VirtualMachineImageBuildCache
+}
+
+type VirtualMachineImageBuildClient interface {
+	Create(*v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error)
+	Update(*v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error)
+	UpdateStatus(*v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error)
+	Delete(namespace, name string, options *metav1.DeleteOptions) error
+	Get(namespace, name string, options metav1.GetOptions) (*v1beta1.VirtualMachineImageBuild, error)
+	List(namespace string, opts metav1.ListOptions) (*v1beta1.VirtualMachineImageBuildList, error)
+	Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.VirtualMachineImageBuild, err error)
+}
+
+type VirtualMachineImageBuildCache interface {
+	Get(namespace, name string) (*v1beta1.VirtualMachineImageBuild, error)
+	List(namespace string, selector labels.Selector) ([]*v1beta1.VirtualMachineImageBuild, error)
+
+	AddIndexer(indexName string, indexer VirtualMachineImageBuildIndexer)
+	GetByIndex(indexName, key string) ([]*v1beta1.VirtualMachineImageBuild, error)
+}
+
+type VirtualMachineImageBuildIndexer func(obj *v1beta1.VirtualMachineImageBuild) ([]string, error)
+
+type virtualMachineImageBuildController struct {
+	controller    controller.SharedController
+	client        *client.Client
+	gvk           schema.GroupVersionKind
+	groupResource schema.GroupResource
+}
+
+func NewVirtualMachineImageBuildController(gvk schema.GroupVersionKind, resource string, namespaced bool, controller controller.SharedControllerFactory) VirtualMachineImageBuildController {
+	c := controller.ForResourceKind(gvk.GroupVersion().WithResource(resource), gvk.Kind, namespaced)
+	return &virtualMachineImageBuildController{
+		controller: c,
+		client:     c.Client(),
+		gvk:        gvk,
+		groupResource: schema.GroupResource{
+			Group:    gvk.Group,
+			Resource: resource,
+		},
+	}
+}
+
+func FromVirtualMachineImageBuildHandlerToHandler(sync VirtualMachineImageBuildHandler) generic.Handler {
+	return func(key string, obj runtime.Object) (ret runtime.Object, err error) {
+		var v *v1beta1.VirtualMachineImageBuild
+		if obj == nil {
+			v, err = sync(key, nil)
+		} else {
+			v, err = sync(key, obj.(*v1beta1.VirtualMachineImageBuild))
+		}
+		if v == nil {
+			return nil, err
+		}
+		return v, err
+	}
+}
+
+func (c *virtualMachineImageBuildController) Updater() generic.Updater {
+	return func(obj runtime.Object) (runtime.Object, error) {
+		newObj, err := c.Update(obj.(*v1beta1.VirtualMachineImageBuild))
+		if newObj == nil {
+			return nil, err
+		}
+		return newObj, err
+	}
+}
+
+func UpdateVirtualMachineImageBuildDeepCopyOnChange(client VirtualMachineImageBuildClient, obj *v1beta1.VirtualMachineImageBuild, handler func(obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error)) (*v1beta1.VirtualMachineImageBuild, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	copyObj := obj.DeepCopy()
+	newObj, err := handler(copyObj)
+	if newObj != nil {
+		copyObj = newObj
+	}
+	if obj.ResourceVersion == copyObj.ResourceVersion && !equality.Semantic.DeepEqual(obj, copyObj) {
+		return client.Update(copyObj)
+	}
+
+	return copyObj, err
+}
+
+func (c *virtualMachineImageBuildController) AddGenericHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.controller.RegisterHandler(ctx, name, controller.SharedControllerHandlerFunc(handler))
+}
+
+func (c *virtualMachineImageBuildController) AddGenericRemoveHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), handler))
+}
+
+func (c *virtualMachineImageBuildController) OnChange(ctx context.Context, name string, sync VirtualMachineImageBuildHandler) {
+	c.AddGenericHandler(ctx, name, FromVirtualMachineImageBuildHandlerToHandler(sync))
+}
+
+func (c *virtualMachineImageBuildController) OnRemove(ctx context.Context, name string, sync VirtualMachineImageBuildHandler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), FromVirtualMachineImageBuildHandlerToHandler(sync)))
+}
+
+func (c *virtualMachineImageBuildController) Enqueue(namespace, name string) {
+	c.controller.Enqueue(namespace, name)
+}
+
+func (c *virtualMachineImageBuildController) EnqueueAfter(namespace, name string, duration time.Duration) {
+	c.controller.EnqueueAfter(namespace, name, duration)
+}
+
+func (c *virtualMachineImageBuildController) Informer() cache.SharedIndexInformer {
+	return c.controller.Informer()
+}
+
+func (c *virtualMachineImageBuildController) GroupVersionKind() schema.GroupVersionKind {
+	return c.gvk
+}
+
+func (c *virtualMachineImageBuildController) Cache() VirtualMachineImageBuildCache {
+	return &virtualMachineImageBuildCache{
+		indexer:  c.Informer().GetIndexer(),
+		resource: c.groupResource,
+	}
+}
+
+func (c *virtualMachineImageBuildController) Create(obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error) {
+	result := &v1beta1.VirtualMachineImageBuild{}
+	return result, c.client.Create(context.TODO(), obj.Namespace, obj, result, metav1.CreateOptions{})
+}
+
+func (c *virtualMachineImageBuildController) Update(obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error) {
+	result := &v1beta1.VirtualMachineImageBuild{}
+	return result, c.client.Update(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
+}
+
+func (c *virtualMachineImageBuildController) UpdateStatus(obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error) {
+	result := &v1beta1.VirtualMachineImageBuild{}
+	return result, c.client.UpdateStatus(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
+}
+
+func (c *virtualMachineImageBuildController) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+	return c.client.Delete(context.TODO(), namespace, name, *options)
+}
+
+func (c *virtualMachineImageBuildController) Get(namespace, name string, options metav1.GetOptions) (*v1beta1.VirtualMachineImageBuild, error) {
+	result := &v1beta1.VirtualMachineImageBuild{}
+	return result, c.client.Get(context.TODO(), namespace, name, result, options)
+}
+
+func (c *virtualMachineImageBuildController) List(namespace string, opts metav1.ListOptions) (*v1beta1.VirtualMachineImageBuildList, error) {
+	result := &v1beta1.VirtualMachineImageBuildList{}
+	return result, c.client.List(context.TODO(), namespace, result, opts)
+}
+
+func (c *virtualMachineImageBuildController) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.client.Watch(context.TODO(), namespace, opts)
+}
+
+func (c *virtualMachineImageBuildController) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (*v1beta1.VirtualMachineImageBuild, error) {
+	result := &v1beta1.VirtualMachineImageBuild{}
+	return result, c.client.Patch(context.TODO(), namespace, name, pt, data, result, metav1.PatchOptions{}, subresources...)
+}
+
+type virtualMachineImageBuildCache struct {
+	indexer  cache.Indexer
+	resource schema.GroupResource
+}
+
+func (c *virtualMachineImageBuildCache) Get(namespace, name string) (*v1beta1.VirtualMachineImageBuild, error) {
+	obj, exists, err := c.indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(c.resource, name)
+	}
+	return obj.(*v1beta1.VirtualMachineImageBuild), nil
+}
+
+func (c *virtualMachineImageBuildCache) List(namespace string, selector labels.Selector) (ret []*v1beta1.VirtualMachineImageBuild, err error) {
+
+	err = cache.ListAllByNamespace(c.indexer, namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.VirtualMachineImageBuild))
+	})
+
+	return ret, err
+}
+
+func (c *virtualMachineImageBuildCache) AddIndexer(indexName string, indexer VirtualMachineImageBuildIndexer) {
+	utilruntime.Must(c.indexer.AddIndexers(map[string]cache.IndexFunc{
+		indexName: func(obj interface{}) (strings []string, e error) {
+			return indexer(obj.(*v1beta1.VirtualMachineImageBuild))
+		},
+	}))
+}
+
+func (c *virtualMachineImageBuildCache) GetByIndex(indexName, key string) (result []*v1beta1.VirtualMachineImageBuild, err error) {
+	objs, err := c.indexer.ByIndex(indexName, key)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]*v1beta1.VirtualMachineImageBuild, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, obj.(*v1beta1.VirtualMachineImageBuild))
+	}
+	return result, nil
+}
+
+type VirtualMachineImageBuildStatusHandler func(obj *v1beta1.VirtualMachineImageBuild, status v1beta1.VirtualMachineImageBuildStatus) (v1beta1.VirtualMachineImageBuildStatus, error)
+
+type VirtualMachineImageBuildGeneratingHandler func(obj *v1beta1.VirtualMachineImageBuild, status v1beta1.VirtualMachineImageBuildStatus) ([]runtime.Object, v1beta1.VirtualMachineImageBuildStatus, error)
+
+func RegisterVirtualMachineImageBuildStatusHandler(ctx context.Context, controller VirtualMachineImageBuildController, condition condition.Cond, name string, handler VirtualMachineImageBuildStatusHandler) {
+	statusHandler := &virtualMachineImageBuildStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, FromVirtualMachineImageBuildHandlerToHandler(statusHandler.sync))
+}
+
+func RegisterVirtualMachineImageBuildGeneratingHandler(ctx context.Context, controller VirtualMachineImageBuildController, apply apply.Apply,
+	condition condition.Cond, name string, handler VirtualMachineImageBuildGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &virtualMachineImageBuildGeneratingHandler{
+		VirtualMachineImageBuildGeneratingHandler: handler,
+		apply: apply,
+		name:  name,
+		gvk:   controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterVirtualMachineImageBuildStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type virtualMachineImageBuildStatusHandler struct {
+	client    VirtualMachineImageBuildClient
+	condition condition.Cond
+	handler   VirtualMachineImageBuildStatusHandler
+}
+
+func (a *virtualMachineImageBuildStatusHandler) sync(key string, obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type virtualMachineImageBuildGeneratingHandler struct {
+	VirtualMachineImageBuildGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+}
+
+func (a *virtualMachineImageBuildGeneratingHandler) Remove(key string, obj *v1beta1.VirtualMachineImageBuild) (*v1beta1.VirtualMachineImageBuild, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1beta1.VirtualMachineImageBuild{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+func (a *virtualMachineImageBuildGeneratingHandler) Handle(obj *v1beta1.VirtualMachineImageBuild, status v1beta1.VirtualMachineImageBuildStatus) (v1beta1.VirtualMachineImageBuildStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.VirtualMachineImageBuildGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+
+	return newStatus, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+}
@@ -33,6 +33,8 @@ type Interface interface {
 	BackingImage() BackingImageController
 	BackingImageDataSource() BackingImageDataSourceController
 	Backup() BackupController
+	Engine() EngineController
+	Node() NodeController
 	Setting() SettingController
 	Volume() VolumeController
 }
@@ -56,6 +58,12 @@ func (c *version) BackingImageDataSource() BackingImageDataSourceController {
 func (c *version) Backup() BackupController {
 	return NewBackupController(schema.GroupVersionKind{Group: "longhorn.io", Version: "v1beta1", Kind: "Backup"}, "backups", true, c.controllerFactory)
 }
+func (c *version) Engine() EngineController {
+	return NewEngineController(schema.GroupVersionKind{Group: "longhorn.io", Version: "v1beta1", Kind: "Engine"}, "engines", true, c.controllerFactory)
+}
+func (c *version) Node() NodeController {
+	return NewNodeController(schema.GroupVersionKind{Group: "longhorn.io", Version: "v1beta1", Kind: "Node"}, "nodes", true, c.controllerFactory)
+}
 func (c *version) Setting() SettingController {
 	return NewSettingController(schema.GroupVersionKind{Group: "longhorn.io", Version: "v1beta1", Kind: "Setting"}, "settings", true, c.controllerFactory)
 }
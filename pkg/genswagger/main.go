@@ -30,7 +30,11 @@ var kindToTagMappings = map[string]string{
 	"VirtualMachineTemplateVersion":   "Virtual Machine Templates",
 	"PersistentVolumeClaim":           "Volumes",
 	"VirtualMachineImage":             "Images",
+	"VirtualMachineImageBuild":        "Images",
+	"MigrationPolicy":                 "Virtual Machines",
 	"VirtualMachineBackup":            "Backups",
+	"VirtualMachineBackupSchedule":    "Backups",
+	"BackupTarget":                    "Backups",
 	"VirtualMachineRestore":           "Restores",
 	"VirtualMachineInstanceMigration": "Migrations",
 	"KeyPair":                         "SSH Keys",
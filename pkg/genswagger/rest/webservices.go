@@ -25,8 +25,12 @@ var defaultActions = []string{http.MethodGet, http.MethodPost, http.MethodPut, h
 func AggregatedWebServices() []*restful.WebService {
 	harvesterv1beta1API := NewGroupVersionWebService(v1beta1.SchemeGroupVersion)
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachinebackups", &v1beta1.VirtualMachineBackup{}, "VirtualMachineBackup", &v1beta1.VirtualMachineBackupList{})
+	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachinebackupschedules", &v1beta1.VirtualMachineBackupSchedule{}, "VirtualMachineBackupSchedule", &v1beta1.VirtualMachineBackupScheduleList{})
+	AddGenericNonNamespacedResourceRoutes(harvesterv1beta1API, "backuptargets", &v1beta1.BackupTarget{}, "BackupTarget", &v1beta1.BackupTargetList{})
+	AddGenericNonNamespacedResourceRoutes(harvesterv1beta1API, "migrationpolicies", &v1beta1.MigrationPolicy{}, "MigrationPolicy", &v1beta1.MigrationPolicyList{})
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachinerestores", &v1beta1.VirtualMachineRestore{}, "VirtualMachineRestore", &v1beta1.VirtualMachineRestoreList{})
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachineimages", &v1beta1.VirtualMachineImage{}, "VirtualMachineImage", &v1beta1.VirtualMachineImageList{})
+	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachineimagebuilds", &v1beta1.VirtualMachineImageBuild{}, "VirtualMachineImageBuild", &v1beta1.VirtualMachineImageBuildList{})
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachinetemplates", &v1beta1.VirtualMachineTemplate{}, "VirtualMachineTemplate", &v1beta1.VirtualMachineTemplateList{})
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "virtualmachinetemplateversions", &v1beta1.VirtualMachineTemplateVersion{}, "VirtualMachineTemplateVersion", &v1beta1.VirtualMachineTemplateVersionList{})
 	AddGenericNamespacedResourceRoutes(harvesterv1beta1API, "keypairs", &v1beta1.KeyPair{}, "KeyPair", &v1beta1.KeyPairList{})
@@ -7,8 +7,10 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/config"
 	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
 )
 
 const (
@@ -16,6 +18,18 @@ const (
 	RbByRoleAndSubjectIndex = "auth.harvesterhci.io/crb-by-role-and-subject"
 	PVCByVMIndex            = "harvesterhci.io/pvc-by-vm-index"
 	VMByNetworkIndex        = "vm.harvesterhci.io/vm-by-network"
+
+	PVCByStorageClassIndex      = "harvesterhci.io/pvc-by-storage-class"
+	VMByImageIndex              = "harvesterhci.io/vm-by-image"
+	TemplateVersionByImageIndex = "harvesterhci.io/templateversion-by-image"
+	ImageByUsageIndex           = "harvesterhci.io/image-by-usage"
+	ImageByStorageClassIndex    = "harvesterhci.io/image-by-storage-class"
+
+	TemplateVersionByImageFamilyIndex = "harvesterhci.io/templateversion-by-image-family"
+
+	ImageUsageKindVM              = "VirtualMachine"
+	ImageUsageKindVolume          = "PersistentVolumeClaim"
+	ImageUsageKindTemplateVersion = "VirtualMachineTemplateVersion"
 )
 
 func RegisterScaledIndexers(scaled *config.Scaled) {
@@ -28,6 +42,15 @@ func RegisterManagementIndexers(management *config.Management) {
 	crbInformer.AddIndexer(RbByRoleAndSubjectIndex, rbByRoleAndSubject)
 	pvcInformer := management.CoreFactory.Core().V1().PersistentVolumeClaim().Cache()
 	pvcInformer.AddIndexer(PVCByVMIndex, pvcByVM)
+	pvcInformer.AddIndexer(PVCByStorageClassIndex, PVCByStorageClass)
+	vmInformer := management.VirtFactory.Kubevirt().V1().VirtualMachine().Cache()
+	vmInformer.AddIndexer(VMByImageIndex, VMByImage)
+	templateVersionInformer := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineTemplateVersion().Cache()
+	templateVersionInformer.AddIndexer(TemplateVersionByImageIndex, TemplateVersionByImage)
+	templateVersionInformer.AddIndexer(TemplateVersionByImageFamilyIndex, TemplateVersionByImageFamily)
+	imageInformer := management.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage().Cache()
+	imageInformer.AddIndexer(ImageByUsageIndex, imageByUsage)
+	imageInformer.AddIndexer(ImageByStorageClassIndex, ImageByStorageClass)
 }
 
 func rbByRoleAndSubject(obj *rbacv1.ClusterRoleBinding) ([]string, error) {
@@ -61,3 +84,65 @@ func VMByNetwork(obj *kubevirtv1.VirtualMachine) ([]string, error) {
 	}
 	return networkNameList, nil
 }
+
+// PVCByStorageClass indexes the PVCs by their storage class, the helper volumes exporting the images
+// aren't indexed since they don't use the images
+func PVCByStorageClass(obj *corev1.PersistentVolumeClaim) ([]string, error) {
+	if obj.Spec.StorageClassName == nil || obj.Labels[util.LabelImageExport] != "" {
+		return []string{}, nil
+	}
+	return []string{*obj.Spec.StorageClassName}, nil
+}
+
+// VMByImage indexes the VMs by the images of the PVCs in the volumeClaimTemplates annotation
+func VMByImage(obj *kubevirtv1.VirtualMachine) ([]string, error) {
+	return util.GetImageIDsFromVolumeClaimTemplates(obj.Annotations)
+}
+
+// TemplateVersionByImage indexes the template versions by their image and the images of the PVCs of their VM
+func TemplateVersionByImage(obj *harvesterv1.VirtualMachineTemplateVersion) ([]string, error) {
+	imageIDs, err := util.GetImageIDsFromVolumeClaimTemplates(obj.Spec.VM.ObjectMeta.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Spec.ImageID != "" {
+		imageIDs = append(imageIDs, obj.Spec.ImageID)
+	}
+	return imageIDs, nil
+}
+
+// TemplateVersionByImageFamily indexes the template versions by the <namespace>/<family> of the image families
+// of the PVCs of their VM
+func TemplateVersionByImageFamily(obj *harvesterv1.VirtualMachineTemplateVersion) ([]string, error) {
+	return util.GetImageFamiliesFromVolumeClaimTemplates(obj.Spec.VM.ObjectMeta.Annotations, obj.Namespace)
+}
+
+// imageByUsage indexes the images by the objects in their usage, e.g. a VM is indexed as vm:<namespace>/<name>
+func imageByUsage(obj *harvesterv1.VirtualMachineImage) ([]string, error) {
+	usage := obj.Status.Usage
+	if usage == nil {
+		return []string{}, nil
+	}
+	keys := make([]string, 0, len(usage.VirtualMachines)+len(usage.Volumes)+len(usage.TemplateVersions))
+	for _, vm := range usage.VirtualMachines {
+		keys = append(keys, ImageUsageKey(ImageUsageKindVM, vm))
+	}
+	for _, volume := range usage.Volumes {
+		keys = append(keys, ImageUsageKey(ImageUsageKindVolume, volume))
+	}
+	for _, templateVersion := range usage.TemplateVersions {
+		keys = append(keys, ImageUsageKey(ImageUsageKindTemplateVersion, templateVersion))
+	}
+	return keys, nil
+}
+
+func ImageByStorageClass(obj *harvesterv1.VirtualMachineImage) ([]string, error) {
+	if obj.Status.StorageClassName == "" {
+		return []string{}, nil
+	}
+	return []string{obj.Status.StorageClassName}, nil
+}
+
+func ImageUsageKey(kind, id string) string {
+	return kind + ":" + id
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rancher/apiserver/pkg/parse"
 	"github.com/rancher/apiserver/pkg/store/apiroot"
 	"github.com/rancher/apiserver/pkg/types"
@@ -167,11 +168,37 @@ func (s *HarvesterServer) ListenAndServe(listenerCfg *dynamiclistener.Config, op
 	if err := server.ListenAndServe(s.Context, opts.HTTPSListenPort, opts.HTTPListenPort, s.Handler, listenOpts); err != nil {
 		return err
 	}
+	s.startMetricsServer(opts.MetricsListenPort)
 
 	<-s.Context.Done()
 	return s.Context.Err()
 }
 
+// startMetricsServer serves the Prometheus metrics on a separate port, which is only exposed in the cluster by the
+// harvester-metrics service, since the metrics reveal the VMs of all namespaces
+func (s *HarvesterServer) startMetricsServer(port int) {
+	if port <= 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("failed to serve the metrics: %v", err)
+		}
+	}()
+	go func() {
+		<-s.Context.Done()
+		if err := metricsServer.Close(); err != nil {
+			logrus.Warnf("failed to close the metrics server: %v", err)
+		}
+	}()
+}
+
 // Scaled returns the *config.Scaled,
 // it should call after Start.
 func (s *HarvesterServer) Scaled() *config.Scaled {
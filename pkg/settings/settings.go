@@ -44,6 +44,14 @@ var (
 	OvercommitConfig        = NewSetting(OvercommitConfigSettingName, `{"cpu":1600,"memory":150,"storage":200}`)
 	VipPools                = NewSetting(VipPoolsConfigSettingName, "")
 	AutoDiskProvisionPaths  = NewSetting("auto-disk-provision-paths", "")
+
+	LocalVolumeSnapshotClass             = NewSetting(LocalVolumeSnapshotClassSettingName, "longhorn-snapshot")
+	DefaultVMImageStorageClassParameters = NewSetting(DefaultVMImageStorageClassParametersSettingName, `{"numberOfReplicas":3,"staleReplicaTimeout":30}`)
+	ImageDownloaderImage                 = NewSetting(ImageDownloaderImageSettingName, "{}")      // The image with curl and qemu-img, the harvester image is used if it's not set
+	ImageDownloadBandwidthLimit          = NewSetting(ImageDownloadBandwidthLimitSettingName, "") // Bytes per second of each image download, e.g. 10Mi. Empty or 0 means unlimited.
+	VMImageGCPolicy                      = NewSetting(VMImageGCPolicySettingName, `{"enabled":false,"unusedPeriod":"720h"}`)
+	VMMaxCPUs                            = NewSetting(VMMaxCPUsSettingName, "")          // The maximum number of vCPUs a VM can be resized to. Empty or 0 means unlimited.
+	FileRestoreImage                     = NewSetting(FileRestoreImageSettingName, "{}") // The image with sfdisk, mount, realpath, GNU find and tar, the harvester image is used if it's not set
 )
 
 const (
@@ -59,6 +67,16 @@ const (
 	VolumeSnapshotClassSettingName  = "volume-snapshot-class"
 	DefaultDashboardUIURL           = "https://releases.rancher.com/harvester-ui/dashboard/latest/index.html"
 	SupportBundleImageName          = "support-bundle-image"
+
+	LocalVolumeSnapshotClassSettingName             = "local-volume-snapshot-class"
+	DefaultVMImageStorageClassParametersSettingName = "default-vm-image-storage-class-parameters"
+	ImageDownloaderImageSettingName                 = "image-downloader-image"
+	ImageDownloadBandwidthLimitSettingName          = "image-download-bandwidth-limit"
+	VMImageGCPolicySettingName                      = "vm-image-gc-policy"
+	VMMaxCPUsSettingName                            = "vm-max-cpus"
+	FileRestoreImageSettingName                     = "file-restore-image"
+
+	harvesterImageRepository = "rancher/harvester"
 )
 
 func init() {
@@ -248,6 +266,30 @@ type SSLParameter struct {
 	Ciphers   string `json:"ciphers"`
 }
 
+// GetImageDownloaderImage returns the image with curl and qemu-img to download and export the VM images,
+// it's the harvester image if the image-downloader-image setting isn't set
+func GetImageDownloaderImage() (string, corev1.PullPolicy, error) {
+	return getImageSetting(ImageDownloaderImage, ImageDownloaderImageSettingName)
+}
+
+// GetFileRestoreImage returns the image of the helper pods browsing the files in the volume backups,
+// it's the harvester image if the file-restore-image setting isn't set
+func GetFileRestoreImage() (string, corev1.PullPolicy, error) {
+	return getImageSetting(FileRestoreImage, FileRestoreImageSettingName)
+}
+
+// getImageSetting returns the image of the Image setting, it's the harvester image if the setting isn't set
+func getImageSetting(setting Setting, name string) (string, corev1.PullPolicy, error) {
+	var image Image
+	if err := json.Unmarshal([]byte(setting.Get()), &image); err != nil {
+		return "", "", fmt.Errorf("failed to parse setting %s: %w", name, err)
+	}
+	if image.Repository == "" || image.Tag == "" {
+		return fmt.Sprintf("%s:%s", harvesterImageRepository, ServerVersion.Get()), corev1.PullIfNotPresent, nil
+	}
+	return fmt.Sprintf("%s:%s", image.Repository, image.Tag), image.ImagePullPolicy, nil
+}
+
 type Image struct {
 	Repository      string            `json:"repository"`
 	Tag             string            `json:"tag"`
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ParseBandwidthLimit parses a limit in bytes per second written as a quantity, e.g. 10Mi, 0 means unlimited
+func ParseBandwidthLimit(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth limit %q: %w", value, err)
+	}
+	if quantity.Sign() < 0 {
+		return 0, fmt.Errorf("bandwidth limit %q can't be negative", value)
+	}
+	return quantity.Value(), nil
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBandwidthLimit(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		value       string
+		expected    int64
+		expectError bool
+	}{
+		{
+			name:     "unlimited",
+			value:    "",
+			expected: 0,
+		},
+		{
+			name:     "binary suffix",
+			value:    "10Mi",
+			expected: 10 * 1024 * 1024,
+		},
+		{
+			name:     "decimal suffix",
+			value:    "500k",
+			expected: 500 * 1000,
+		},
+		{
+			name:        "negative",
+			value:       "-1M",
+			expectError: true,
+		},
+		{
+			name:        "invalid",
+			value:       "10MB/s",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		limit, err := ParseBandwidthLimit(tc.value)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		assert.Equal(t, tc.expected, limit, tc.name)
+	}
+}
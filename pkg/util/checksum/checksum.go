@@ -0,0 +1,183 @@
+package checksum
+
+// A checksum is written as <algorithm>:<hex digest>, the algorithm can be omitted and is then detected from
+// the length of the digest, e.g. a 64 characters digest is a sha256 one. Checksum files are the output of
+// sha256sum and the like, both in the GNU format and in the BSD format of the --tag option.
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type Algorithm string
+
+const (
+	MD5    Algorithm = "md5"
+	SHA1   Algorithm = "sha1"
+	SHA256 Algorithm = "sha256"
+	SHA512 Algorithm = "sha512"
+
+	maxChecksumFileSize = 1 << 20
+)
+
+var (
+	digestLengths = map[Algorithm]int{
+		MD5:    md5.Size * 2,
+		SHA1:   sha1.Size * 2,
+		SHA256: sha256.Size * 2,
+		SHA512: sha512.Size * 2,
+	}
+	hexDigest = regexp.MustCompile(`^[0-9a-f]+$`)
+	// bsdLine is a line of the BSD format, e.g. SHA256 (ubuntu.img) = <digest>
+	bsdLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) = ([0-9A-Fa-f]+)$`)
+
+	// ErrMismatch is returned when the digest of the data doesn't match the expected checksum
+	ErrMismatch = errors.New("checksum mismatch")
+)
+
+// Checksum is the expected digest of an image
+type Checksum struct {
+	Algorithm Algorithm
+	Digest    string
+}
+
+func (c *Checksum) String() string {
+	return fmt.Sprintf("%s:%s", c.Algorithm, c.Digest)
+}
+
+// NewHash returns the hash to compute the digest
+func (c *Checksum) NewHash() hash.Hash {
+	switch c.Algorithm {
+	case MD5:
+		return md5.New()
+	case SHA1:
+		return sha1.New()
+	case SHA256:
+		return sha256.New()
+	default:
+		return sha512.New()
+	}
+}
+
+// Parse parses <algorithm>:<digest> or a digest whose algorithm is detected from its length
+func Parse(s string) (*Checksum, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var algorithm Algorithm
+	if parts := strings.SplitN(s, ":", 2); len(parts) == 2 {
+		algorithm, s = Algorithm(strings.ReplaceAll(parts[0], "-", "")), parts[1]
+		if _, ok := digestLengths[algorithm]; !ok {
+			return nil, fmt.Errorf("unsupported checksum algorithm %q, the supported ones are md5, sha1, sha256 and sha512", parts[0])
+		}
+	}
+	if !hexDigest.MatchString(s) {
+		return nil, fmt.Errorf("invalid checksum %q, it must be a hex digest", s)
+	}
+
+	if algorithm == "" {
+		for a, length := range digestLengths {
+			if len(s) == length {
+				algorithm = a
+			}
+		}
+		if algorithm == "" {
+			return nil, fmt.Errorf("can't detect the algorithm of the %d characters checksum", len(s))
+		}
+	} else if len(s) != digestLengths[algorithm] {
+		return nil, fmt.Errorf("invalid %s checksum, it must be %d characters", algorithm, digestLengths[algorithm])
+	}
+	return &Checksum{Algorithm: algorithm, Digest: s}, nil
+}
+
+// FindInFile returns the checksum of the file in the content of a checksum file like SHA256SUMS
+func FindInFile(r io.Reader, fileName string) (*Checksum, error) {
+	fileName = path.Base(fileName)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var algorithm, name, digest string
+		if matches := bsdLine.FindStringSubmatch(line); matches != nil {
+			algorithm, name, digest = matches[1], matches[2], matches[3]
+		} else if fields := strings.Fields(line); len(fields) == 2 {
+			// the binary mode of the GNU format prefixes the file name with *
+			digest, name = fields[0], strings.TrimPrefix(fields[1], "*")
+		} else {
+			continue
+		}
+		if path.Base(name) != fileName {
+			continue
+		}
+		if algorithm != "" {
+			digest = algorithm + ":" + digest
+		}
+		return Parse(digest)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no checksum of %s in the checksum file", fileName)
+}
+
+// Verifier computes the digest of the data read through it and verifies it at the end, the end of
+// the data is reported as an ErrMismatch error instead of io.EOF if the digest doesn't match.
+type Verifier struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected *Checksum
+	err      error
+}
+
+func NewVerifier(r io.Reader, expected *Checksum) *Verifier {
+	return &Verifier{r: r, hash: expected.NewHash(), expected: expected}
+}
+
+func (v *Verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := hex.EncodeToString(v.hash.Sum(nil)); actual != v.expected.Digest {
+			v.err = fmt.Errorf("%w: expected %s %s, got %s", ErrMismatch, v.expected.Algorithm, v.expected.Digest, actual)
+			return n, v.err
+		}
+	}
+	return n, err
+}
+
+// Err returns the mismatch error once all the data is read, it's nil if the digest matches
+func (v *Verifier) Err() error {
+	return v.err
+}
+
+// FindInURL downloads the checksum file at the URL and returns the checksum of the file in it
+func FindInURL(ctx context.Context, client *http.Client, checksumURL, fileName string) (*Checksum, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the checksum file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("got %d status code from %s", resp.StatusCode, checksumURL)
+	}
+	// a checksum file is small, the limit avoids reading a wrong URL like the image itself
+	return FindInFile(io.LimitReader(resp.Body, maxChecksumFileSize), fileName)
+}
@@ -0,0 +1,136 @@
+package checksum
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	helloSHA512 = "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043"
+	helloMD5    = "5d41402abc4b2a76b9719d911017c592"
+)
+
+func Test_Parse(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		checksum    string
+		expected    *Checksum
+		expectError bool
+	}{
+		{
+			name:     "sha512 detected from the length",
+			checksum: helloSHA512,
+			expected: &Checksum{Algorithm: SHA512, Digest: helloSHA512},
+		},
+		{
+			name:     "sha256 detected from the length",
+			checksum: strings.ToUpper(helloSHA256),
+			expected: &Checksum{Algorithm: SHA256, Digest: helloSHA256},
+		},
+		{
+			name:     "md5 with the algorithm",
+			checksum: "md5:" + helloMD5,
+			expected: &Checksum{Algorithm: MD5, Digest: helloMD5},
+		},
+		{
+			name:     "algorithm with a dash",
+			checksum: "SHA-256:" + helloSHA256,
+			expected: &Checksum{Algorithm: SHA256, Digest: helloSHA256},
+		},
+		{
+			name:        "digest length doesn't match the algorithm",
+			checksum:    "sha512:" + helloSHA256,
+			expectError: true,
+		},
+		{
+			name:        "unsupported algorithm",
+			checksum:    "crc32:3610a686",
+			expectError: true,
+		},
+		{
+			name:        "unknown length",
+			checksum:    "abcdef",
+			expectError: true,
+		},
+		{
+			name:        "not hex",
+			checksum:    strings.Repeat("z", 64),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		checksum, err := Parse(tc.checksum)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		assert.Equal(t, tc.expected, checksum, tc.name)
+	}
+}
+
+func Test_FindInFile(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		content     string
+		fileName    string
+		expected    *Checksum
+		expectError bool
+	}{
+		{
+			name: "GNU format",
+			content: "# ubuntu images\n" +
+				"1111111111111111111111111111111111111111111111111111111111111111 *ubuntu-20.04-server-cloudimg-arm64.img\n" +
+				helloSHA256 + " *ubuntu-20.04-server-cloudimg-amd64.img\n",
+			fileName: "ubuntu-20.04-server-cloudimg-amd64.img",
+			expected: &Checksum{Algorithm: SHA256, Digest: helloSHA256},
+		},
+		{
+			name:     "BSD format",
+			content:  "SHA512 (images/opensuse.qcow2) = " + helloSHA512 + "\n",
+			fileName: "opensuse.qcow2",
+			expected: &Checksum{Algorithm: SHA512, Digest: helloSHA512},
+		},
+		{
+			name:        "file not in the checksum file",
+			content:     helloSHA256 + "  other.img\n",
+			fileName:    "ubuntu.img",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		checksum, err := FindInFile(strings.NewReader(tc.content), tc.fileName)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		assert.Equal(t, tc.expected, checksum, tc.name)
+	}
+}
+
+func Test_Verifier(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		checksum string
+		mismatch bool
+	}{
+		{
+			name:     "match",
+			checksum: helloSHA256,
+		},
+		{
+			name:     "mismatch",
+			checksum: "sha256:" + strings.Repeat("0", 64),
+			mismatch: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		expected, err := Parse(tc.checksum)
+		assert.Nil(t, err, tc.name)
+		verifier := NewVerifier(strings.NewReader("hello"), expected)
+		_, err = ioutil.ReadAll(verifier)
+		assert.Equal(t, tc.mismatch, errors.Is(err, ErrMismatch), tc.name)
+		assert.Equal(t, tc.mismatch, errors.Is(verifier.Err(), ErrMismatch), tc.name)
+	}
+}
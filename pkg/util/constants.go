@@ -8,11 +8,27 @@ const (
 	AnnotationMigrationTarget      = prefix + "/migrationTargetNodeName"
 	AnnotationMigrationUID         = prefix + "/migrationUID"
 	AnnotationMigrationState       = prefix + "/migrationState"
+	AnnotationMigrationHistory     = prefix + "/migrationHistory"
 	AnnotationTimestamp            = prefix + "/timestamp"
 	AnnotationVolumeClaimTemplates = prefix + "/volumeClaimTemplates"
 	AnnotationImageID              = prefix + "/imageId"
+	AnnotationImageFamily          = prefix + "/imageFamily"
 	AnnotationReservedMemory       = prefix + "/reservedMemory"
+	AnnotationRestartRequired      = prefix + "/restartRequired"
 	AnnotationHash                 = prefix + "/hash"
+	AnnotationBackupVerifyRequest  = prefix + "/backupVerifyRequest"
+	AnnotationBackupVerification   = prefix + "/backupVerification"
+	AnnotationFileRestoreAccessed  = prefix + "/fileRestoreAccessed"
+	AnnotationFileRestoreRequest   = prefix + "/fileRestoreRequest"
+	AnnotationFileRestoreVolume    = prefix + "/fileRestoreVolume"
+	LabelFileRestore               = prefix + "/fileRestore"
+	LabelFileRestoreNamespace      = prefix + "/fileRestoreNamespace"
+	LabelImageDownload             = prefix + "/imageDownload"
+	LabelImageExport               = prefix + "/imageExport"
+	LabelImageBuild                = prefix + "/imageBuild"
+	AnnotationImageExportAccessed  = prefix + "/imageExportAccessed"
+	LabelVMCloneNamespace          = prefix + "/vmCloneNamespace"
+	LabelVMCloneName               = prefix + "/vmCloneName"
 
 	BackupTargetSecretName      = "harvester-backup-target-secret"
 	InternalTLSSecretName       = "tls-rancher-internal"
@@ -21,6 +37,8 @@ const (
 	LonghornSystemNamespaceName = "longhorn-system"
 	KubeSystemNamespace         = "kube-system"
 
+	DefaultBackupTargetSecretName = "harvester-default-backup-target-secret"
+
 	HTTPProxyEnv  = "HTTP_PROXY"
 	HTTPSProxyEnv = "HTTPS_PROXY"
 	NoProxyEnv    = "NO_PROXY"
@@ -0,0 +1,64 @@
+package fakeclients
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	harv1type "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+)
+
+type VirtualMachineBackupClient func(string) harv1type.VirtualMachineBackupInterface
+
+func (c VirtualMachineBackupClient) Update(virtualMachineBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	return c(virtualMachineBackup.Namespace).Update(context.TODO(), virtualMachineBackup, metav1.UpdateOptions{})
+}
+func (c VirtualMachineBackupClient) Get(namespace, name string, options metav1.GetOptions) (*harvesterv1.VirtualMachineBackup, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+func (c VirtualMachineBackupClient) Create(virtualMachineBackup *harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	return c(virtualMachineBackup.Namespace).Create(context.TODO(), virtualMachineBackup, metav1.CreateOptions{})
+}
+func (c VirtualMachineBackupClient) Delete(namespace, name string, options *metav1.DeleteOptions) error {
+	panic("implement me")
+}
+func (c VirtualMachineBackupClient) List(namespace string, opts metav1.ListOptions) (*harvesterv1.VirtualMachineBackupList, error) {
+	panic("implement me")
+}
+func (c VirtualMachineBackupClient) UpdateStatus(*harvesterv1.VirtualMachineBackup) (*harvesterv1.VirtualMachineBackup, error) {
+	panic("implement me")
+}
+func (c VirtualMachineBackupClient) Watch(namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	panic("implement me")
+}
+func (c VirtualMachineBackupClient) Patch(namespace, name string, pt types.PatchType, data []byte, subresources ...string) (result *harvesterv1.VirtualMachineBackup, err error) {
+	panic("implement me")
+}
+
+type VirtualMachineBackupCache func(string) harv1type.VirtualMachineBackupInterface
+
+func (c VirtualMachineBackupCache) Get(namespace, name string) (*harvesterv1.VirtualMachineBackup, error) {
+	return c(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+func (c VirtualMachineBackupCache) List(namespace string, selector labels.Selector) ([]*harvesterv1.VirtualMachineBackup, error) {
+	list, err := c(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*harvesterv1.VirtualMachineBackup, 0, len(list.Items))
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, err
+}
+func (c VirtualMachineBackupCache) AddIndexer(indexName string, indexer ctlharvesterv1.VirtualMachineBackupIndexer) {
+	panic("implement me")
+}
+func (c VirtualMachineBackupCache) GetByIndex(indexName, key string) ([]*harvesterv1.VirtualMachineBackup, error) {
+	panic("implement me")
+}
@@ -12,6 +12,7 @@ import (
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	harv1type "github.com/harvester/harvester/pkg/generated/clientset/versioned/typed/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
 	"github.com/harvester/harvester/tests/framework/fuzz"
 )
 
@@ -65,5 +66,20 @@ func (c VirtualMachineImageCache) AddIndexer(indexName string, indexer ctlharves
 	panic("implement me")
 }
 func (c VirtualMachineImageCache) GetByIndex(indexName, key string) ([]*harvesterv1.VirtualMachineImage, error) {
-	panic("implement me")
+	switch indexName {
+	case indexeres.ImageByStorageClassIndex:
+		images, err := c.List("", labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		var result []*harvesterv1.VirtualMachineImage
+		for _, image := range images {
+			if image.Status.StorageClassName == key {
+				result = append(result, image)
+			}
+		}
+		return result, nil
+	default:
+		return nil, nil
+	}
 }
@@ -0,0 +1,191 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// ImageGCPolicy is the value of the vm-image-gc-policy setting, the images unused for the unused period are deleted
+// when it's enabled
+type ImageGCPolicy struct {
+	Enabled      bool            `json:"enabled"`
+	UnusedPeriod metav1.Duration `json:"unusedPeriod"`
+}
+
+// DecodeImageGCPolicy decodes the value of the vm-image-gc-policy setting
+func DecodeImageGCPolicy(value string) (*ImageGCPolicy, error) {
+	policy := &ImageGCPolicy{}
+	if value == "" {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	if policy.Enabled && policy.UnusedPeriod.Duration <= 0 {
+		return nil, fmt.Errorf("unused period must be greater than 0")
+	}
+	return policy, nil
+}
+
+// GetCollectTime returns when the image is deleted by the policy, it's false if the image is in use or isn't collected.
+// The images owned by other objects like the upgrade images are left to their owners.
+func (p *ImageGCPolicy) GetCollectTime(image *v1beta1.VirtualMachineImage) (time.Time, bool) {
+	if !p.Enabled || image.DeletionTimestamp != nil || len(image.OwnerReferences) > 0 {
+		return time.Time{}, false
+	}
+	usage := image.Status.Usage
+	if usage == nil || usage.UnusedSince == nil || (!v1beta1.ImageImported.IsTrue(image) && !v1beta1.ImageImported.IsFalse(image)) {
+		return time.Time{}, false
+	}
+	return usage.UnusedSince.Add(p.UnusedPeriod.Duration), true
+}
+
+// IsImageSharedWith returns true if the VMs in the namespace can use the image
+func IsImageSharedWith(image *v1beta1.VirtualMachineImage, namespace string) bool {
+	if image.Namespace == namespace {
+		return true
+	}
+	sharing := image.Spec.Sharing
+	if sharing == nil {
+		return false
+	}
+	if sharing.ClusterWide {
+		return true
+	}
+	for _, ns := range sharing.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// GetImageIDsFromVolumeClaimTemplates returns the <namespace>/<name> of the images of the PVCs
+// in the volumeClaimTemplates annotation of a VM or the VM of a template version
+func GetImageIDsFromVolumeClaimTemplates(annotations map[string]string) ([]string, error) {
+	volumeClaimTemplates := annotations[AnnotationVolumeClaimTemplates]
+	if volumeClaimTemplates == "" {
+		return nil, nil
+	}
+	var pvcs []*corev1.PersistentVolumeClaim
+	if err := json.Unmarshal([]byte(volumeClaimTemplates), &pvcs); err != nil {
+		return nil, err
+	}
+	var imageIDs []string
+	for _, pvc := range pvcs {
+		if imageID := pvc.Annotations[AnnotationImageID]; imageID != "" {
+			imageIDs = append(imageIDs, imageID)
+		}
+	}
+	return imageIDs, nil
+}
+
+// GetImageFamiliesFromVolumeClaimTemplates returns the <namespace>/<family> of the image families of the PVCs
+// in the volumeClaimTemplates annotation of a VM or the VM of a template version in the namespace
+func GetImageFamiliesFromVolumeClaimTemplates(annotations map[string]string, namespace string) ([]string, error) {
+	volumeClaimTemplates := annotations[AnnotationVolumeClaimTemplates]
+	if volumeClaimTemplates == "" {
+		return nil, nil
+	}
+	var pvcs []*corev1.PersistentVolumeClaim
+	if err := json.Unmarshal([]byte(volumeClaimTemplates), &pvcs); err != nil {
+		return nil, err
+	}
+	var families []string
+	for _, pvc := range pvcs {
+		if family := pvc.Annotations[AnnotationImageFamily]; family != "" {
+			familyNamespace, name := ParseImageFamily(family, namespace)
+			families = append(families, familyNamespace+"/"+name)
+		}
+	}
+	return families, nil
+}
+
+// ParseImageFamily returns the namespace and the name of an image family referenced by <namespace>/<family>,
+// or by the family name in the namespace
+func ParseImageFamily(family, namespace string) (string, string) {
+	if parts := strings.SplitN(family, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return namespace, family
+}
+
+// isNewerImageVersion returns true if the image version a is created after b, the names break the ties
+func isNewerImageVersion(a, b *v1beta1.VirtualMachineImage) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
+
+// GetLatestImageVersion returns the latest imported version of the family among the images, the deprecated
+// versions are skipped. It's nil if the family has no ready version.
+func GetLatestImageVersion(images []*v1beta1.VirtualMachineImage, family string) *v1beta1.VirtualMachineImage {
+	var latest *v1beta1.VirtualMachineImage
+	for _, image := range images {
+		if image.Spec.Family != family || image.Spec.Deprecated || image.DeletionTimestamp != nil ||
+			!v1beta1.ImageImported.IsTrue(image) {
+			continue
+		}
+		if latest == nil || isNewerImageVersion(image, latest) {
+			latest = image
+		}
+	}
+	return latest
+}
+
+// GetNewerImageVersions returns the versions of the family of the image that are created after it
+func GetNewerImageVersions(images []*v1beta1.VirtualMachineImage, image *v1beta1.VirtualMachineImage) []*v1beta1.VirtualMachineImage {
+	var newer []*v1beta1.VirtualMachineImage
+	for _, i := range images {
+		if i.Spec.Family == image.Spec.Family && i.Name != image.Name && isNewerImageVersion(i, image) {
+			newer = append(newer, i)
+		}
+	}
+	return newer
+}
+
+// ResolveImageFamilies sets the images of the PVCs referencing an image family in the volumeClaimTemplates annotation
+// to the version returned by resolve. It returns the updated annotation, or an empty string if no PVC references a family.
+func ResolveImageFamilies(annotations map[string]string, resolve func(family string) (*v1beta1.VirtualMachineImage, error)) (string, error) {
+	volumeClaimTemplates := annotations[AnnotationVolumeClaimTemplates]
+	if volumeClaimTemplates == "" {
+		return "", nil
+	}
+	var pvcs []*corev1.PersistentVolumeClaim
+	if err := json.Unmarshal([]byte(volumeClaimTemplates), &pvcs); err != nil {
+		return "", err
+	}
+	resolved := false
+	for _, pvc := range pvcs {
+		family := pvc.Annotations[AnnotationImageFamily]
+		if family == "" {
+			continue
+		}
+		image, err := resolve(family)
+		if err != nil {
+			return "", err
+		}
+		pvc.Annotations[AnnotationImageID] = fmt.Sprintf("%s/%s", image.Namespace, image.Name)
+		if image.Status.StorageClassName != "" {
+			storageClassName := image.Status.StorageClassName
+			pvc.Spec.StorageClassName = &storageClassName
+		}
+		resolved = true
+	}
+	if !resolved {
+		return "", nil
+	}
+	bytes, err := json.Marshal(pvcs)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,273 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_DecodeImageGCPolicy(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		value       string
+		expected    *ImageGCPolicy
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: &ImageGCPolicy{},
+		},
+		{
+			name:     "enabled",
+			value:    `{"enabled":true,"unusedPeriod":"168h"}`,
+			expected: &ImageGCPolicy{Enabled: true, UnusedPeriod: metav1.Duration{Duration: 168 * time.Hour}},
+		},
+		{
+			name:        "enabled without the unused period",
+			value:       `{"enabled":true}`,
+			expectError: true,
+		},
+		{
+			name:        "invalid duration",
+			value:       `{"enabled":true,"unusedPeriod":"a week"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		policy, err := DecodeImageGCPolicy(tc.value)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		assert.Equal(t, tc.expected, policy, tc.name)
+	}
+}
+
+func Test_ImageGCPolicy_GetCollectTime(t *testing.T) {
+	unusedSince := metav1.NewTime(time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+	newImage := func(imported bool, usage *v1beta1.VirtualMachineImageUsage, owners ...metav1.OwnerReference) *v1beta1.VirtualMachineImage {
+		image := &v1beta1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "image", OwnerReferences: owners},
+			Status:     v1beta1.VirtualMachineImageStatus{Usage: usage},
+		}
+		if imported {
+			v1beta1.ImageImported.True(image)
+		} else {
+			v1beta1.ImageImported.Unknown(image)
+		}
+		return image
+	}
+	enabled := &ImageGCPolicy{Enabled: true, UnusedPeriod: metav1.Duration{Duration: 24 * time.Hour}}
+
+	var testCases = []struct {
+		name     string
+		policy   *ImageGCPolicy
+		image    *v1beta1.VirtualMachineImage
+		expected time.Time
+		collect  bool
+	}{
+		{
+			name:     "unused image",
+			policy:   enabled,
+			image:    newImage(true, &v1beta1.VirtualMachineImageUsage{UnusedSince: &unusedSince}),
+			expected: unusedSince.Add(24 * time.Hour),
+			collect:  true,
+		},
+		{
+			name:   "disabled",
+			policy: &ImageGCPolicy{UnusedPeriod: metav1.Duration{Duration: 24 * time.Hour}},
+			image:  newImage(true, &v1beta1.VirtualMachineImageUsage{UnusedSince: &unusedSince}),
+		},
+		{
+			name:   "image in use",
+			policy: enabled,
+			image:  newImage(true, &v1beta1.VirtualMachineImageUsage{VirtualMachines: []string{"default/vm"}}),
+		},
+		{
+			name:   "image being imported",
+			policy: enabled,
+			image:  newImage(false, &v1beta1.VirtualMachineImageUsage{UnusedSince: &unusedSince}),
+		},
+		{
+			name:   "image owned by another object",
+			policy: enabled,
+			image: newImage(true, &v1beta1.VirtualMachineImageUsage{UnusedSince: &unusedSince},
+				metav1.OwnerReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Name: "upgrade-repo"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		collectTime, collect := tc.policy.GetCollectTime(tc.image)
+		assert.Equal(t, tc.collect, collect, tc.name)
+		assert.Equal(t, tc.expected, collectTime, tc.name)
+	}
+}
+
+func Test_GetImageIDsFromVolumeClaimTemplates(t *testing.T) {
+	pvcs := `[{"metadata":{"name":"vm-disk-0","annotations":{"harvesterhci.io/imageId":"default/image-abcde"}}},` +
+		`{"metadata":{"name":"vm-disk-1"}}]`
+	imageIDs, err := GetImageIDsFromVolumeClaimTemplates(map[string]string{AnnotationVolumeClaimTemplates: pvcs})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default/image-abcde"}, imageIDs)
+
+	imageIDs, err = GetImageIDsFromVolumeClaimTemplates(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, imageIDs)
+
+	_, err = GetImageIDsFromVolumeClaimTemplates(map[string]string{AnnotationVolumeClaimTemplates: "not json"})
+	assert.NotNil(t, err)
+}
+
+func Test_GetImageFamiliesFromVolumeClaimTemplates(t *testing.T) {
+	pvcs := `[{"metadata":{"name":"vm-disk-0","annotations":{"harvesterhci.io/imageFamily":"images/ubuntu"}}},` +
+		`{"metadata":{"name":"vm-disk-1","annotations":{"harvesterhci.io/imageFamily":"debian"}}},` +
+		`{"metadata":{"name":"vm-disk-2","annotations":{"harvesterhci.io/imageId":"default/image-abcde"}}}]`
+	families, err := GetImageFamiliesFromVolumeClaimTemplates(map[string]string{AnnotationVolumeClaimTemplates: pvcs}, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"images/ubuntu", "default/debian"}, families)
+
+	families, err = GetImageFamiliesFromVolumeClaimTemplates(nil, "default")
+	assert.Nil(t, err)
+	assert.Empty(t, families)
+
+	_, err = GetImageFamiliesFromVolumeClaimTemplates(map[string]string{AnnotationVolumeClaimTemplates: "not json"}, "default")
+	assert.NotNil(t, err)
+}
+
+func Test_IsImageSharedWith(t *testing.T) {
+	newImage := func(sharing *v1beta1.VirtualMachineImageSharing) *v1beta1.VirtualMachineImage {
+		return &v1beta1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "images", Name: "ubuntu"},
+			Spec:       v1beta1.VirtualMachineImageSpec{Sharing: sharing},
+		}
+	}
+
+	var testCases = []struct {
+		name      string
+		image     *v1beta1.VirtualMachineImage
+		namespace string
+		expected  bool
+	}{
+		{
+			name:      "same namespace",
+			image:     newImage(nil),
+			namespace: "images",
+			expected:  true,
+		},
+		{
+			name:      "not shared",
+			image:     newImage(nil),
+			namespace: "team-a",
+		},
+		{
+			name:      "shared with the namespace",
+			image:     newImage(&v1beta1.VirtualMachineImageSharing{Namespaces: []string{"team-a", "team-b"}}),
+			namespace: "team-b",
+			expected:  true,
+		},
+		{
+			name:      "shared with other namespaces",
+			image:     newImage(&v1beta1.VirtualMachineImageSharing{Namespaces: []string{"team-a"}}),
+			namespace: "team-b",
+		},
+		{
+			name:      "cluster wide",
+			image:     newImage(&v1beta1.VirtualMachineImageSharing{ClusterWide: true}),
+			namespace: "team-c",
+			expected:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, IsImageSharedWith(tc.image, tc.namespace), tc.name)
+	}
+}
+
+func Test_GetLatestImageVersion(t *testing.T) {
+	base := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	newVersion := func(name, family string, created time.Time, imported, deprecated bool) *v1beta1.VirtualMachineImage {
+		image := &v1beta1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       v1beta1.VirtualMachineImageSpec{Family: family, Version: name, Deprecated: deprecated},
+		}
+		if imported {
+			v1beta1.ImageImported.True(image)
+		}
+		return image
+	}
+	v1 := newVersion("v1", "ubuntu", base, true, false)
+	v2 := newVersion("v2", "ubuntu", base.Add(time.Hour), true, false)
+	v3Deprecated := newVersion("v3", "ubuntu", base.Add(2*time.Hour), true, true)
+	v4Importing := newVersion("v4", "ubuntu", base.Add(3*time.Hour), false, false)
+	otherFamily := newVersion("other", "centos", base.Add(4*time.Hour), true, false)
+
+	var testCases = []struct {
+		name     string
+		images   []*v1beta1.VirtualMachineImage
+		family   string
+		expected *v1beta1.VirtualMachineImage
+	}{
+		{
+			name:     "latest ready version",
+			images:   []*v1beta1.VirtualMachineImage{v2, v1, v3Deprecated, v4Importing, otherFamily},
+			family:   "ubuntu",
+			expected: v2,
+		},
+		{
+			name:     "other family",
+			images:   []*v1beta1.VirtualMachineImage{v2, v1, otherFamily},
+			family:   "centos",
+			expected: otherFamily,
+		},
+		{
+			name:   "no ready version",
+			images: []*v1beta1.VirtualMachineImage{v3Deprecated, v4Importing},
+			family: "ubuntu",
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, GetLatestImageVersion(tc.images, tc.family), tc.name)
+	}
+
+	newer := GetNewerImageVersions([]*v1beta1.VirtualMachineImage{v1, v2, v3Deprecated, v4Importing, otherFamily}, v2)
+	assert.ElementsMatch(t, []*v1beta1.VirtualMachineImage{v3Deprecated, v4Importing}, newer)
+}
+
+func Test_ResolveImageFamilies(t *testing.T) {
+	image := &v1beta1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "images", Name: "ubuntu-v2"},
+		Status:     v1beta1.VirtualMachineImageStatus{StorageClassName: "longhorn-ubuntu-v2"},
+	}
+	resolve := func(family string) (*v1beta1.VirtualMachineImage, error) {
+		assert.Equal(t, "images/ubuntu", family)
+		return image, nil
+	}
+
+	pvcs := `[{"metadata":{"name":"vm-disk-0","annotations":{"harvesterhci.io/imageFamily":"images/ubuntu",` +
+		`"harvesterhci.io/imageId":"images/ubuntu-v1"}},"spec":{"storageClassName":"longhorn-ubuntu-v1"}},` +
+		`{"metadata":{"name":"vm-disk-1"}}]`
+	resolved, err := ResolveImageFamilies(map[string]string{AnnotationVolumeClaimTemplates: pvcs}, resolve)
+	assert.Nil(t, err)
+	imageIDs, err := GetImageIDsFromVolumeClaimTemplates(map[string]string{AnnotationVolumeClaimTemplates: resolved})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"images/ubuntu-v2"}, imageIDs)
+	assert.Contains(t, resolved, `"storageClassName":"longhorn-ubuntu-v2"`)
+
+	resolved, err = ResolveImageFamilies(map[string]string{AnnotationVolumeClaimTemplates: `[{"metadata":{"name":"vm-disk-0"}}]`}, resolve)
+	assert.Nil(t, err)
+	assert.Empty(t, resolved)
+
+}
+
+func Test_ParseImageFamily(t *testing.T) {
+	namespace, family := ParseImageFamily("images/ubuntu", "default")
+	assert.Equal(t, "images", namespace)
+	assert.Equal(t, "ubuntu", family)
+
+	namespace, family = ParseImageFamily("ubuntu", "default")
+	assert.Equal(t, "default", namespace)
+	assert.Equal(t, "ubuntu", family)
+}
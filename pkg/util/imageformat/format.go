@@ -0,0 +1,191 @@
+package imageformat
+
+// The format of a disk image is detected from the magic numbers in its first bytes, the headers are
+// described in the qcow2 spec of qemu, the VMDK spec of VMware and the VHD/VHDX specs of Microsoft.
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+type Format string
+
+const (
+	Raw   Format = "raw"
+	ISO   Format = "iso"
+	QCOW2 Format = "qcow2"
+	VMDK  Format = "vmdk"
+	VHD   Format = "vhd"
+	VHDX  Format = "vhdx"
+	OVA   Format = "ova"
+
+	// HeaderSize is how many bytes of the image are needed to detect its format
+	HeaderSize = 64 * 1024
+
+	sectorSize = 512
+
+	vhdDynamic      = 3
+	vhdDifferencing = 4
+)
+
+var (
+	qcow2Magic       = []byte{'Q', 'F', 'I', 0xfb}
+	vmdkMagic        = []byte("KDMV")
+	vmdkDescriptor   = []byte("# Disk DescriptorFile")
+	vhdMagic         = []byte("conectix")
+	vhdxMagic        = []byte("vhdxfile")
+	isoMagic         = []byte("CD001")
+	tarMagic         = []byte("ustar")
+	compressedMagics = map[string][]byte{
+		"gzip":  {0x1f, 0x8b},
+		"xz":    {0xfd, '7', 'z', 'X', 'Z', 0x00},
+		"zip":   {'P', 'K', 0x03, 0x04},
+		"bzip2": []byte("BZh"),
+		"zstd":  {0x28, 0xb5, 0x2f, 0xfd},
+	}
+
+	// ErrCorrupt is returned when the image has the magic number of a format but an invalid header
+	ErrCorrupt = errors.New("corrupt image")
+	// ErrUnsupported is returned when the image isn't a disk image which can be imported
+	ErrUnsupported = errors.New("unsupported image")
+)
+
+// Info is the detected format of an image
+type Info struct {
+	Format Format
+	// VirtualSize is the size of the disk in bytes, it's 0 if it can't be read from the header
+	VirtualSize int64
+}
+
+// NeedsConversion returns true if longhorn can't use the image as a backing image without converting it
+func (f Format) NeedsConversion() bool {
+	switch f {
+	case VMDK, VHD, VHDX, OVA:
+		return true
+	}
+	return false
+}
+
+// Detect returns the format of the image from its first HeaderSize bytes, size is the size of the whole image
+func Detect(header []byte, size int64) (*Info, error) {
+	if len(header) < sectorSize || (size > 0 && size < sectorSize) {
+		return nil, fmt.Errorf("%w: the file is too small to be a disk image", ErrCorrupt)
+	}
+
+	switch {
+	case bytes.HasPrefix(header, qcow2Magic):
+		return detectQCOW2(header)
+	case bytes.HasPrefix(header, vmdkMagic):
+		return detectVMDK(header)
+	case bytes.HasPrefix(header, vmdkDescriptor):
+		return nil, fmt.Errorf("%w: the VMDK is a descriptor without the disk data, please use a monolithic VMDK or an OVA", ErrUnsupported)
+	case bytes.HasPrefix(header, vhdxMagic):
+		// the virtual size is in the metadata region, qemu-img reads it during the conversion
+		return &Info{Format: VHDX}, nil
+	case bytes.HasPrefix(header, vhdMagic):
+		return detectVHD(header)
+	case isTar(header):
+		return detectOVA(header)
+	case isISO(header):
+		return &Info{Format: ISO, VirtualSize: size}, nil
+	}
+
+	for name, magic := range compressedMagics {
+		if bytes.HasPrefix(header, magic) {
+			return nil, fmt.Errorf("%w: the image is %s compressed, please decompress it", ErrUnsupported, name)
+		}
+	}
+	return &Info{Format: Raw, VirtualSize: size}, nil
+}
+
+func detectQCOW2(header []byte) (*Info, error) {
+	if len(header) < 72 {
+		return nil, fmt.Errorf("%w: truncated qcow2 header", ErrCorrupt)
+	}
+	version := binary.BigEndian.Uint32(header[4:8])
+	if version != 2 && version != 3 {
+		return nil, fmt.Errorf("%w: unknown qcow2 version %d", ErrCorrupt, version)
+	}
+	clusterBits := binary.BigEndian.Uint32(header[20:24])
+	if clusterBits < 9 || clusterBits > 21 {
+		return nil, fmt.Errorf("%w: invalid qcow2 cluster size", ErrCorrupt)
+	}
+	virtualSize := binary.BigEndian.Uint64(header[24:32])
+	if virtualSize == 0 || virtualSize > 1<<62 {
+		return nil, fmt.Errorf("%w: invalid qcow2 virtual size", ErrCorrupt)
+	}
+	if binary.BigEndian.Uint64(header[8:16]) != 0 {
+		return nil, fmt.Errorf("%w: the qcow2 image has a backing file", ErrUnsupported)
+	}
+	if binary.BigEndian.Uint32(header[32:36]) != 0 {
+		return nil, fmt.Errorf("%w: the qcow2 image is encrypted", ErrUnsupported)
+	}
+	return &Info{Format: QCOW2, VirtualSize: int64(virtualSize)}, nil
+}
+
+func detectVMDK(header []byte) (*Info, error) {
+	version := binary.LittleEndian.Uint32(header[4:8])
+	if version < 1 || version > 3 {
+		return nil, fmt.Errorf("%w: unknown VMDK version %d", ErrCorrupt, version)
+	}
+	capacity := binary.LittleEndian.Uint64(header[12:20])
+	if capacity == 0 || capacity > 1<<53 {
+		return nil, fmt.Errorf("%w: invalid VMDK capacity", ErrCorrupt)
+	}
+	return &Info{Format: VMDK, VirtualSize: int64(capacity * sectorSize)}, nil
+}
+
+// detectVHD reads the copy of the footer at the beginning of a dynamic VHD,
+// a fixed VHD only has the footer at the end and is detected as a raw image.
+func detectVHD(header []byte) (*Info, error) {
+	switch diskType := binary.BigEndian.Uint32(header[60:64]); diskType {
+	case vhdDynamic:
+	case vhdDifferencing:
+		return nil, fmt.Errorf("%w: the VHD is a differencing disk of a parent disk", ErrUnsupported)
+	default:
+		return nil, fmt.Errorf("%w: invalid VHD disk type %d", ErrCorrupt, diskType)
+	}
+	currentSize := binary.BigEndian.Uint64(header[48:56])
+	if currentSize == 0 || currentSize > 1<<62 {
+		return nil, fmt.Errorf("%w: invalid VHD size", ErrCorrupt)
+	}
+	return &Info{Format: VHD, VirtualSize: int64(currentSize)}, nil
+}
+
+func isTar(header []byte) bool {
+	return len(header) >= 262 && bytes.Equal(header[257:262], tarMagic)
+}
+
+// detectOVA checks the tar archive is an OVA, the OVF descriptor must be its first file
+func detectOVA(header []byte) (*Info, error) {
+	name := string(bytes.TrimRight(header[:100], "\x00"))
+	if !strings.EqualFold(path.Ext(name), ".ovf") {
+		return nil, fmt.Errorf("%w: the tar archive is not an OVA, its first file %q is not an OVF descriptor", ErrUnsupported, name)
+	}
+	return &Info{Format: OVA}, nil
+}
+
+func isISO(header []byte) bool {
+	const offset = 16*2048 + 1
+	return len(header) >= offset+len(isoMagic) && bytes.Equal(header[offset:offset+len(isoMagic)], isoMagic)
+}
+
+// Peek detects the format of the image read from r, the returned reader reads the whole image including the
+// header consumed for the detection
+func Peek(r io.Reader, size int64) (*Info, io.Reader, error) {
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, nil, err
+	}
+	header = header[:n]
+	info, err := Detect(header, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, io.MultiReader(bytes.NewReader(header), r), nil
+}
@@ -0,0 +1,184 @@
+package imageformat
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gib = 1 << 30
+
+func newQCOW2Header(version uint32, virtualSize uint64, backingFileOffset uint64) []byte {
+	header := make([]byte, HeaderSize)
+	copy(header, qcow2Magic)
+	binary.BigEndian.PutUint32(header[4:8], version)
+	binary.BigEndian.PutUint64(header[8:16], backingFileOffset)
+	binary.BigEndian.PutUint32(header[20:24], 16)
+	binary.BigEndian.PutUint64(header[24:32], virtualSize)
+	return header
+}
+
+func newVMDKHeader(capacity uint64) []byte {
+	header := make([]byte, HeaderSize)
+	copy(header, vmdkMagic)
+	binary.LittleEndian.PutUint32(header[4:8], 1)
+	binary.LittleEndian.PutUint64(header[12:20], capacity)
+	return header
+}
+
+func newVHDHeader(diskType uint32, currentSize uint64) []byte {
+	header := make([]byte, HeaderSize)
+	copy(header, vhdMagic)
+	binary.BigEndian.PutUint64(header[48:56], currentSize)
+	binary.BigEndian.PutUint32(header[60:64], diskType)
+	return header
+}
+
+func newTarHeader(t *testing.T, name string) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 4, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("<?xm"))
+	assert.Nil(t, err)
+	assert.Nil(t, tw.Close())
+	return buf.Bytes()
+}
+
+func newISOHeader() []byte {
+	header := make([]byte, HeaderSize)
+	copy(header[16*2048+1:], isoMagic)
+	return header
+}
+
+func Test_Detect(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		header       []byte
+		size         int64
+		expected     *Info
+		expectedErr  error
+		needsConvert bool
+	}{
+		{
+			name:     "qcow2",
+			header:   newQCOW2Header(3, 10*gib, 0),
+			size:     gib,
+			expected: &Info{Format: QCOW2, VirtualSize: 10 * gib},
+		},
+		{
+			name:        "qcow2 with a backing file",
+			header:      newQCOW2Header(3, 10*gib, 512),
+			size:        gib,
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:        "qcow2 with an unknown version",
+			header:      newQCOW2Header(9, 10*gib, 0),
+			size:        gib,
+			expectedErr: ErrCorrupt,
+		},
+		{
+			name:         "vmdk",
+			header:       newVMDKHeader(2 * gib / sectorSize),
+			size:         gib,
+			expected:     &Info{Format: VMDK, VirtualSize: 2 * gib},
+			needsConvert: true,
+		},
+		{
+			name:        "vmdk without capacity",
+			header:      newVMDKHeader(0),
+			size:        gib,
+			expectedErr: ErrCorrupt,
+		},
+		{
+			name:        "vmdk descriptor",
+			header:      append([]byte("# Disk DescriptorFile\nversion=1\n"), make([]byte, 1024)...),
+			size:        1024,
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:         "vhdx",
+			header:       append([]byte("vhdxfile"), make([]byte, 1024)...),
+			size:         gib,
+			expected:     &Info{Format: VHDX},
+			needsConvert: true,
+		},
+		{
+			name:         "dynamic vhd",
+			header:       newVHDHeader(vhdDynamic, 4*gib),
+			size:         gib,
+			expected:     &Info{Format: VHD, VirtualSize: 4 * gib},
+			needsConvert: true,
+		},
+		{
+			name:        "differencing vhd",
+			header:      newVHDHeader(vhdDifferencing, 4*gib),
+			size:        gib,
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:         "ova",
+			header:       newTarHeader(t, "ubuntu.ovf"),
+			size:         gib,
+			expected:     &Info{Format: OVA},
+			needsConvert: true,
+		},
+		{
+			name:        "tar archive",
+			header:      newTarHeader(t, "ubuntu.img"),
+			size:        gib,
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:     "iso",
+			header:   newISOHeader(),
+			size:     gib,
+			expected: &Info{Format: ISO, VirtualSize: gib},
+		},
+		{
+			name:        "gzip compressed",
+			header:      append([]byte{0x1f, 0x8b, 0x08}, make([]byte, 1024)...),
+			size:        gib,
+			expectedErr: ErrUnsupported,
+		},
+		{
+			name:     "raw",
+			header:   make([]byte, HeaderSize),
+			size:     gib,
+			expected: &Info{Format: Raw, VirtualSize: gib},
+		},
+		{
+			name:        "too small",
+			header:      []byte("hello"),
+			size:        5,
+			expectedErr: ErrCorrupt,
+		},
+	}
+
+	for _, tc := range testCases {
+		info, err := Detect(tc.header, tc.size)
+		if tc.expectedErr != nil {
+			assert.True(t, errors.Is(err, tc.expectedErr), tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expected, info, tc.name)
+		assert.Equal(t, tc.needsConvert, info.Format.NeedsConversion(), tc.name)
+	}
+}
+
+func Test_Peek(t *testing.T) {
+	image := append(newQCOW2Header(3, 10*gib, 0), []byte("clusters")...)
+	info, r, err := Peek(bytes.NewReader(image), int64(len(image)))
+	assert.Nil(t, err)
+	assert.Equal(t, QCOW2, info.Format)
+
+	content, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, image, content)
+}
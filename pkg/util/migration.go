@@ -0,0 +1,99 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+const (
+	MigrationResultSucceeded = "Succeeded"
+	MigrationResultFailed    = "Failed"
+	MigrationResultAborted   = "Aborted"
+
+	// MaxMigrationHistory is the number of the latest migrations kept in the history of a VM
+	MaxMigrationHistory = 10
+)
+
+// MigrationRecord is a finished live migration of a VM. The data transferred by the migration isn't recorded, since
+// KubeVirt v0.49 reports it neither in the migration state of the VMI nor in the domain stats of the VMI.
+type MigrationRecord struct {
+	// UID is the UID of the VirtualMachineInstanceMigration
+	UID        string       `json:"uid"`
+	SourceNode string       `json:"sourceNode,omitempty"`
+	TargetNode string       `json:"targetNode,omitempty"`
+	StartTime  *metav1.Time `json:"startTime,omitempty"`
+	EndTime    *metav1.Time `json:"endTime,omitempty"`
+	// Mode is PreCopy or PostCopy
+	Mode string `json:"mode,omitempty"`
+	// Policy is the name of the migration policy of the migration
+	Policy string `json:"policy,omitempty"`
+	// Result is Succeeded, Failed or Aborted
+	Result string `json:"result"`
+	// Reason is why the migration failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// Duration returns the seconds the migration took, it returns 0 if the start or end time is unknown
+func (r MigrationRecord) Duration() float64 {
+	if r.StartTime == nil || r.EndTime == nil {
+		return 0
+	}
+	return r.EndTime.Sub(r.StartTime.Time).Seconds()
+}
+
+// GetMigrationHistory returns the migrations of the VM from the oldest to the latest
+func GetMigrationHistory(vm *kubevirtv1.VirtualMachine) ([]MigrationRecord, error) {
+	value := vm.Annotations[AnnotationMigrationHistory]
+	if value == "" {
+		return nil, nil
+	}
+	var history []MigrationRecord
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, fmt.Errorf("invalid migration history of VM %s/%s: %w", vm.Namespace, vm.Name, err)
+	}
+	return history, nil
+}
+
+// HasMigrationRecord returns true if the migration with the UID is in the history of the VM
+func HasMigrationRecord(vm *kubevirtv1.VirtualMachine, uid string) bool {
+	history, err := GetMigrationHistory(vm)
+	if err != nil {
+		return false
+	}
+	for _, record := range history {
+		if record.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// AddMigrationRecord adds the migration to the history of the VM, the oldest migrations are dropped to keep at most
+// MaxMigrationHistory migrations. It returns false if the migration is already in the history.
+func AddMigrationRecord(vm *kubevirtv1.VirtualMachine, record MigrationRecord) (bool, error) {
+	history, err := GetMigrationHistory(vm)
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range history {
+		if existing.UID == record.UID {
+			return false, nil
+		}
+	}
+	history = append(history, record)
+	if len(history) > MaxMigrationHistory {
+		history = history[len(history)-MaxMigrationHistory:]
+	}
+	bytes, err := json.Marshal(history)
+	if err != nil {
+		return false, err
+	}
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[AnnotationMigrationHistory] = string(bytes)
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func Test_AddMigrationRecord(t *testing.T) {
+	vm := &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vm"},
+	}
+	for i := 0; i < MaxMigrationHistory+2; i++ {
+		added, err := AddMigrationRecord(vm, MigrationRecord{
+			UID:        fmt.Sprintf("migration-%d", i),
+			SourceNode: "node1",
+			TargetNode: "node2",
+			Result:     MigrationResultSucceeded,
+		})
+		assert.Nil(t, err)
+		assert.True(t, added)
+	}
+
+	added, err := AddMigrationRecord(vm, MigrationRecord{UID: "migration-11", Result: MigrationResultFailed})
+	assert.Nil(t, err)
+	assert.False(t, added, "existing migration")
+	assert.True(t, HasMigrationRecord(vm, "migration-11"))
+	assert.False(t, HasMigrationRecord(vm, "migration-0"), "oldest migration dropped")
+
+	history, err := GetMigrationHistory(vm)
+	assert.Nil(t, err)
+	assert.Len(t, history, MaxMigrationHistory)
+	assert.Equal(t, "migration-2", history[0].UID)
+	assert.Equal(t, "migration-11", history[len(history)-1].UID)
+	assert.Equal(t, MigrationResultSucceeded, history[len(history)-1].Result)
+
+	vm.Annotations[AnnotationMigrationHistory] = "not json"
+	_, err = GetMigrationHistory(vm)
+	assert.NotNil(t, err)
+}
+
+func Test_MigrationRecord_Duration(t *testing.T) {
+	start := metav1.NewTime(time.Date(2022, 3, 1, 2, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+	assert.Equal(t, float64(90), MigrationRecord{StartTime: &start, EndTime: &end}.Duration())
+	assert.Equal(t, float64(0), MigrationRecord{StartTime: &start}.Duration())
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// GetMigrationPolicy returns the policy of a VM with the labels of its VMI and of its namespace, it returns nil if
+// no policy selects the VM. It picks the policy the same way as KubeVirt picks its migration policies: the one
+// matching most labels of the VMI, then the one matching most labels of the namespace, then the first by name.
+func GetMigrationPolicy(policies []*harvesterv1.MigrationPolicy, vmiLabels, namespaceLabels map[string]string) *harvesterv1.MigrationPolicy {
+	var matched *harvesterv1.MigrationPolicy
+	for _, policy := range policies {
+		selectors := policy.Spec.Selectors
+		if !labels.SelectorFromSet(selectors.VirtualMachineSelector).Matches(labels.Set(vmiLabels)) ||
+			!labels.SelectorFromSet(selectors.NamespaceSelector).Matches(labels.Set(namespaceLabels)) {
+			continue
+		}
+		if matched == nil || hasMigrationPolicyPrecedence(policy, matched) {
+			matched = policy
+		}
+	}
+	return matched
+}
+
+func hasMigrationPolicyPrecedence(policy, than *harvesterv1.MigrationPolicy) bool {
+	if a, b := len(policy.Spec.Selectors.VirtualMachineSelector), len(than.Spec.Selectors.VirtualMachineSelector); a != b {
+		return a > b
+	}
+	if a, b := len(policy.Spec.Selectors.NamespaceSelector), len(than.Spec.Selectors.NamespaceSelector); a != b {
+		return a > b
+	}
+	return policy.Name < than.Name
+}
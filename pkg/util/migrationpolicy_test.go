@@ -0,0 +1,84 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func newMigrationPolicy(name string, vmSelector, namespaceSelector map[string]string) *v1beta1.MigrationPolicy {
+	return &v1beta1.MigrationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1beta1.MigrationPolicySpec{
+			Selectors: v1beta1.MigrationPolicySelectors{
+				VirtualMachineSelector: vmSelector,
+				NamespaceSelector:      namespaceSelector,
+			},
+		},
+	}
+}
+
+func Test_GetMigrationPolicy(t *testing.T) {
+	policies := []*v1beta1.MigrationPolicy{
+		newMigrationPolicy("production", nil, map[string]string{"env": "production"}),
+		newMigrationPolicy("database", map[string]string{"app": "database"}, nil),
+		newMigrationPolicy("production-database", map[string]string{"app": "database"}, map[string]string{"env": "production"}),
+		newMigrationPolicy("large-database", map[string]string{"app": "database", "size": "large"}, nil),
+		newMigrationPolicy("b-web", map[string]string{"app": "web"}, nil),
+		newMigrationPolicy("a-web", map[string]string{"app": "web"}, nil),
+	}
+	var testCases = []struct {
+		name            string
+		vmiLabels       map[string]string
+		namespaceLabels map[string]string
+		expected        string
+	}{
+		{
+			name:            "no policy",
+			vmiLabels:       map[string]string{"app": "cache"},
+			namespaceLabels: map[string]string{"env": "dev"},
+		},
+		{
+			name:            "namespace selector",
+			vmiLabels:       map[string]string{"app": "cache"},
+			namespaceLabels: map[string]string{"env": "production"},
+			expected:        "production",
+		},
+		{
+			name:            "VM labels take precedence over namespace labels",
+			vmiLabels:       map[string]string{"app": "database"},
+			namespaceLabels: map[string]string{"env": "dev"},
+			expected:        "database",
+		},
+		{
+			name:            "namespace labels break ties",
+			vmiLabels:       map[string]string{"app": "database"},
+			namespaceLabels: map[string]string{"env": "production"},
+			expected:        "production-database",
+		},
+		{
+			name:            "most VM labels",
+			vmiLabels:       map[string]string{"app": "database", "size": "large"},
+			namespaceLabels: map[string]string{"env": "production"},
+			expected:        "large-database",
+		},
+		{
+			name:      "first by name",
+			vmiLabels: map[string]string{"app": "web"},
+			expected:  "a-web",
+		},
+	}
+	for _, tc := range testCases {
+		policy := GetMigrationPolicy(policies, tc.vmiLabels, tc.namespaceLabels)
+		if tc.expected == "" {
+			assert.Nil(t, policy, tc.name)
+			continue
+		}
+		if assert.NotNil(t, policy, tc.name) {
+			assert.Equal(t, tc.expected, policy.Name, tc.name)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package oci
+
+// The disk of a VM image can be published to a container registry in two ways: as an OCI artifact whose
+// layer is the disk itself, e.g. pushed by oras, or as a KubeVirt containerDisk, a container image with
+// the disk file in the /disk directory. The disk is streamed from the registry without being stored locally.
+import (
+	"archive/tar"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/containerd/containerd/archive/compression"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	containerDiskDir = "disk"
+	dockerHubHost    = "registry-1.docker.io"
+)
+
+// Disk is the disk image pulled from a registry
+type Disk struct {
+	io.ReadCloser
+	// Size is the size of the disk in bytes
+	Size int64
+	// Digest is the digest of the manifest the disk is pulled from
+	Digest string
+}
+
+// Credentials returns the username and the password of a registry host
+type Credentials func(host string) (string, string, error)
+
+// ParseReference returns the normalized reference, the reference is pinned to the digest if it's set
+func ParseReference(ref, dgst string) (string, error) {
+	named, err := reference.ParseDockerRef(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	if dgst == "" {
+		return named.String(), nil
+	}
+
+	d, err := digest.Parse(dgst)
+	if err != nil {
+		return "", fmt.Errorf("invalid digest %q: %w", dgst, err)
+	}
+	if canonical, ok := named.(reference.Canonical); ok && canonical.Digest() != d {
+		return "", fmt.Errorf("reference %q is pinned to another digest", ref)
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), d)
+	if err != nil {
+		return "", err
+	}
+	return pinned.String(), nil
+}
+
+// CredentialsFromDockerConfig returns the credentials in the content of a kubernetes.io/dockerconfigjson secret
+func CredentialsFromDockerConfig(data []byte) (Credentials, error) {
+	config := struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+
+	type credential struct{ username, password string }
+	credentials := map[string]credential{}
+	for server, auth := range config.Auths {
+		cred := credential{username: auth.Username, password: auth.Password}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of %s: %w", server, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid auth of %s", server)
+			}
+			cred = credential{username: parts[0], password: parts[1]}
+		}
+		credentials[registryHost(server)] = cred
+	}
+
+	return func(host string) (string, string, error) {
+		cred := credentials[host]
+		return cred.username, cred.password, nil
+	}, nil
+}
+
+// registryHost returns the host of a server in the docker config, which may be a URL
+func registryHost(server string) string {
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.SplitN(host, "/", 2)[0]
+	switch host {
+	case "docker.io", "index.docker.io":
+		return dockerHubHost
+	}
+	return host
+}
+
+// Open resolves the reference and returns the stream of the disk in it
+func Open(ctx context.Context, ref string, creds Credentials) (*Disk, error) {
+	authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(creds))
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(docker.WithAuthorizer(authorizer)),
+	})
+
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestDesc := desc
+	if manifestDesc, err = selectManifest(ctx, fetcher, desc); err != nil {
+		return nil, err
+	}
+	manifest := ocispec.Manifest{}
+	if err := fetchJSON(ctx, fetcher, manifestDesc, &manifest); err != nil {
+		return nil, err
+	}
+
+	disk, err := openDisk(ctx, fetcher, manifest.Layers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	disk.Digest = desc.Digest.String()
+	return disk, nil
+}
+
+// selectManifest returns the manifest of the platform of this node if the descriptor is an index
+func selectManifest(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, images.MediaTypeDockerSchema2ManifestList:
+	default:
+		return desc, nil
+	}
+
+	index := ocispec.Index{}
+	if err := fetchJSON(ctx, fetcher, desc, &index); err != nil {
+		return desc, err
+	}
+	matcher := platforms.Default()
+	for _, manifest := range index.Manifests {
+		if manifest.Platform == nil || matcher.Match(*manifest.Platform) {
+			return manifest, nil
+		}
+	}
+	return desc, errors.New("no manifest for the platform of the node")
+}
+
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(rc, desc.Size))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// openDisk returns the largest artifact layer, or the disk file in the layers of a containerDisk
+func openDisk(ctx context.Context, fetcher remotes.Fetcher, layers []ocispec.Descriptor) (*Disk, error) {
+	var artifact *ocispec.Descriptor
+	for i, layer := range layers {
+		if !images.IsLayerType(layer.MediaType) && (artifact == nil || layer.Size > artifact.Size) {
+			artifact = &layers[i]
+		}
+	}
+	if artifact != nil {
+		rc, err := fetcher.Fetch(ctx, *artifact)
+		if err != nil {
+			return nil, err
+		}
+		return &Disk{ReadCloser: rc, Size: artifact.Size}, nil
+	}
+
+	// the upper layers override the lower ones
+	for i := len(layers) - 1; i >= 0; i-- {
+		rc, err := fetcher.Fetch(ctx, layers[i])
+		if err != nil {
+			return nil, err
+		}
+		disk, err := findContainerDisk(rc)
+		if err != nil {
+			_ = rc.Close()
+			return nil, err
+		}
+		if disk != nil {
+			return disk, nil
+		}
+		_ = rc.Close()
+	}
+	return nil, errors.New("no disk in the image")
+}
+
+// findContainerDisk returns the disk file in the /disk directory of the layer, it's nil if there isn't one.
+// Closing the returned disk closes the layer.
+func findContainerDisk(layer io.ReadCloser) (*Disk, error) {
+	decompressed, err := compression.DecompressStream(layer)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(decompressed)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if path.Dir(path.Clean("/"+hdr.Name)) == "/"+containerDiskDir {
+			return &Disk{
+				ReadCloser: &layerReader{Reader: tr, closers: []io.Closer{decompressed, layer}},
+				Size:       hdr.Size,
+			}, nil
+		}
+	}
+}
+
+// layerReader reads a file in a layer and closes the layer
+type layerReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *layerReader) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseReference(t *testing.T) {
+	const dgst = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	var testCases = []struct {
+		name        string
+		ref         string
+		digest      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "docker hub image",
+			ref:      "ubuntu:20.04",
+			expected: "docker.io/library/ubuntu:20.04",
+		},
+		{
+			name:     "pinned to the digest",
+			ref:      "registry.example.com/images/ubuntu:20.04",
+			digest:   dgst,
+			expected: "registry.example.com/images/ubuntu@" + dgst,
+		},
+		{
+			name:        "invalid digest",
+			ref:         "registry.example.com/images/ubuntu:20.04",
+			digest:      "sha256:1234",
+			expectError: true,
+		},
+		{
+			name:        "reference has another digest",
+			ref:         "registry.example.com/images/ubuntu@sha256:486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7",
+			digest:      dgst,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		ref, err := ParseReference(tc.ref, tc.digest)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		assert.Equal(t, tc.expected, ref, tc.name)
+	}
+}
+
+func Test_CredentialsFromDockerConfig(t *testing.T) {
+	creds, err := CredentialsFromDockerConfig([]byte(`{"auths":{
+		"https://index.docker.io/v1/":{"auth":"dXNlcjpwYXNz"},
+		"registry.example.com":{"username":"admin","password":"secret"}}}`))
+	assert.Nil(t, err)
+
+	username, password, _ := creds("registry-1.docker.io")
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+	username, password, _ = creds("registry.example.com")
+	assert.Equal(t, "admin", username)
+	assert.Equal(t, "secret", password)
+	username, _, _ = creds("other.example.com")
+	assert.Empty(t, username)
+}
+
+func newLayer(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+	return buf.Bytes()
+}
+
+func newFetcher(blobs map[digest.Digest][]byte) remotes.Fetcher {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(blobs[desc.Digest])), nil
+	})
+}
+
+func Test_openDisk(t *testing.T) {
+	blobs := map[digest.Digest][]byte{}
+	newDescriptor := func(mediaType string, content []byte) ocispec.Descriptor {
+		dgst := digest.FromBytes(content)
+		blobs[dgst] = content
+		return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(content))}
+	}
+
+	base := newDescriptor(images.MediaTypeDockerSchema2LayerGzip, newLayer(t, map[string]string{"etc/os-release": "base"}))
+	containerDisk := newDescriptor(ocispec.MediaTypeImageLayerGzip, newLayer(t, map[string]string{"disk/ubuntu.qcow2": "qcow2 disk"}))
+	artifact := newDescriptor("application/vnd.harvesterhci.disk.raw", []byte("raw disk"))
+	readme := newDescriptor("text/markdown", []byte("#"))
+
+	var testCases = []struct {
+		name        string
+		layers      []ocispec.Descriptor
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "containerDisk",
+			layers:   []ocispec.Descriptor{base, containerDisk},
+			expected: "qcow2 disk",
+		},
+		{
+			name:     "the largest layer of an artifact",
+			layers:   []ocispec.Descriptor{readme, artifact},
+			expected: "raw disk",
+		},
+		{
+			name:        "no disk",
+			layers:      []ocispec.Descriptor{base},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		disk, err := openDisk(context.Background(), newFetcher(blobs), tc.layers)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+		if err != nil {
+			continue
+		}
+		content, err := ioutil.ReadAll(disk)
+		assert.Nil(t, err)
+		assert.Nil(t, disk.Close())
+		assert.Equal(t, tc.expected, string(content), tc.name)
+		assert.Equal(t, int64(len(tc.expected)), disk.Size, tc.name)
+	}
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// IsRestartRequired returns true if the VM is resized while it's running and has to be restarted to apply the new shape
+func IsRestartRequired(vm *kubevirtv1.VirtualMachine) bool {
+	return vm.Annotations[AnnotationRestartRequired] == "true"
+}
+
+// HasSameShape returns true if the VMI runs with the CPU topology and the CPU and memory limits in the template of the VM
+func HasSameShape(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) bool {
+	if vm.Spec.Template == nil {
+		return true
+	}
+	vmDomain := vm.Spec.Template.Spec.Domain
+	vmiDomain := vmi.Spec.Domain
+	if GetCPUTopology(vmDomain.CPU) != GetCPUTopology(vmiDomain.CPU) {
+		return false
+	}
+	vmLimits := vmDomain.Resources.Limits
+	vmiLimits := vmiDomain.Resources.Limits
+	return vmLimits.Cpu().Cmp(*vmiLimits.Cpu()) == 0 && vmLimits.Memory().Cmp(*vmiLimits.Memory()) == 0
+}
+
+// CPUTopology is the sockets, the cores per socket and the threads per core of a VM
+type CPUTopology struct {
+	Sockets uint32
+	Cores   uint32
+	Threads uint32
+}
+
+// GetCPUTopology returns the CPU topology of the domain, the unset sockets, cores and threads default to 1 like KubeVirt
+func GetCPUTopology(cpu *kubevirtv1.CPU) CPUTopology {
+	topology := CPUTopology{Sockets: 1, Cores: 1, Threads: 1}
+	if cpu == nil {
+		return topology
+	}
+	if cpu.Sockets > 0 {
+		topology.Sockets = cpu.Sockets
+	}
+	if cpu.Cores > 0 {
+		topology.Cores = cpu.Cores
+	}
+	if cpu.Threads > 0 {
+		topology.Threads = cpu.Threads
+	}
+	return topology
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+func Test_HasSameShape(t *testing.T) {
+	domain := func(cores uint32, cpu, memory string) kubevirtv1.DomainSpec {
+		return kubevirtv1.DomainSpec{
+			CPU: &kubevirtv1.CPU{Cores: cores},
+			Resources: kubevirtv1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		vmDomain  kubevirtv1.DomainSpec
+		vmiDomain kubevirtv1.DomainSpec
+		expected  bool
+	}{
+		{
+			name:      "same shape",
+			vmDomain:  domain(2, "2", "4Gi"),
+			vmiDomain: domain(2, "2000m", "4096Mi"),
+			expected:  true,
+		},
+		{
+			name:      "more cores",
+			vmDomain:  domain(4, "4", "4Gi"),
+			vmiDomain: domain(2, "2", "4Gi"),
+			expected:  false,
+		},
+		{
+			name:      "same vCPUs in another topology",
+			vmDomain:  kubevirtv1.DomainSpec{CPU: &kubevirtv1.CPU{Sockets: 2, Cores: 1}},
+			vmiDomain: kubevirtv1.DomainSpec{CPU: &kubevirtv1.CPU{Sockets: 1, Cores: 2}},
+			expected:  false,
+		},
+		{
+			name:      "default topology",
+			vmDomain:  kubevirtv1.DomainSpec{CPU: &kubevirtv1.CPU{Sockets: 1, Cores: 1, Threads: 1}},
+			vmiDomain: kubevirtv1.DomainSpec{},
+			expected:  true,
+		},
+		{
+			name:      "more memory",
+			vmDomain:  domain(2, "2", "8Gi"),
+			vmiDomain: domain(2, "2", "4Gi"),
+			expected:  false,
+		},
+		{
+			name:      "no cpu",
+			vmDomain:  kubevirtv1.DomainSpec{},
+			vmiDomain: kubevirtv1.DomainSpec{},
+			expected:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := &kubevirtv1.VirtualMachine{
+				Spec: kubevirtv1.VirtualMachineSpec{
+					Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+						Spec: kubevirtv1.VirtualMachineInstanceSpec{Domain: tc.vmDomain},
+					},
+				},
+			}
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{Domain: tc.vmiDomain},
+			}
+			assert.Equal(t, tc.expected, HasSameShape(vm, vmi))
+		})
+	}
+}
+
+func Test_GetCPUTopology(t *testing.T) {
+	assert.Equal(t, CPUTopology{Sockets: 1, Cores: 1, Threads: 1}, GetCPUTopology(nil))
+	assert.Equal(t, CPUTopology{Sockets: 2, Cores: 4, Threads: 1}, GetCPUTopology(&kubevirtv1.CPU{Sockets: 2, Cores: 4}))
+}
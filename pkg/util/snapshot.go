@@ -0,0 +1,38 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/longhorn/longhorn-manager/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	ctllonghornv1 "github.com/harvester/harvester/pkg/generated/controllers/longhorn.io/v1beta1"
+)
+
+// longhornLocalSnapshotVersion is the first longhorn version taking in-cluster snapshots for the VolumeSnapshotClasses
+// with the "type: snap" parameter, the older versions ignore the parameter and back up the volumes to the backup target.
+var longhornLocalSnapshotVersion = version.MustParseGeneric("v1.3.0")
+
+// CheckLocalSnapshotSupported returns an error if the installed longhorn can't take in-cluster volume snapshots,
+// they're used by the VM snapshots and the linked clones.
+func CheckLocalSnapshotSupported(longhornSettingCache ctllonghornv1.SettingCache) error {
+	setting, err := longhornSettingCache.Get(LonghornSystemNamespaceName, string(types.SettingNameCurrentLonghornVersion))
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("longhorn version is unknown, in-cluster volume snapshots require longhorn %s or later", longhornLocalSnapshotVersion)
+	} else if err != nil {
+		return err
+	}
+	return checkLocalSnapshotVersion(setting.Value)
+}
+
+func checkLocalSnapshotVersion(longhornVersion string) error {
+	v, err := version.ParseGeneric(longhornVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse longhorn version %q: %w", longhornVersion, err)
+	}
+	if v.LessThan(longhornLocalSnapshotVersion) {
+		return fmt.Errorf("longhorn %s doesn't support in-cluster volume snapshots, longhorn %s or later is required", longhornVersion, longhornLocalSnapshotVersion)
+	}
+	return nil
+}
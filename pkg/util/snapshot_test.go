@@ -0,0 +1,25 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkLocalSnapshotVersion(t *testing.T) {
+	var testCases = []struct {
+		version     string
+		expectError bool
+	}{
+		{version: "v1.2.4", expectError: true},
+		{version: "v1.3.0"},
+		{version: "v1.3.1-rc1"},
+		{version: "v1.4.0"},
+		{version: "master", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		err := checkLocalSnapshotVersion(tc.version)
+		assert.Equal(t, tc.expectError, err != nil, tc.version)
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta1"
+	"github.com/longhorn/longhorn-manager/types"
+	lhutil "github.com/longhorn/longhorn-manager/util"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+// ValidateImageStorageClassParameters checks the storage class parameters of an image are accepted by longhorn,
+// the unset parameters are valid since they are taken from the default.
+func ValidateImageStorageClassParameters(params *v1beta1.ImageStorageClassParameters) error {
+	if params == nil {
+		return nil
+	}
+	if params.NumberOfReplicas != 0 {
+		if err := types.ValidateReplicaCount(params.NumberOfReplicas); err != nil {
+			return err
+		}
+	}
+	if params.StaleReplicaTimeout < 0 {
+		return fmt.Errorf("stale replica timeout must be greater than 0")
+	}
+	if params.DataLocality != "" {
+		if err := types.ValidateDataLocality(longhorn.DataLocality(params.DataLocality)); err != nil {
+			return err
+		}
+	}
+	if _, err := lhutil.ValidateTags(params.NodeSelector); err != nil {
+		return fmt.Errorf("invalid node selector: %w", err)
+	}
+	if _, err := lhutil.ValidateTags(params.DiskSelector); err != nil {
+		return fmt.Errorf("invalid disk selector: %w", err)
+	}
+	return nil
+}
+
+// DecodeImageStorageClassParameters decodes the value of the default-vm-image-storage-class-parameters setting
+func DecodeImageStorageClassParameters(value string) (*v1beta1.ImageStorageClassParameters, error) {
+	params := &v1beta1.ImageStorageClassParameters{}
+	if value == "" {
+		return params, nil
+	}
+	if err := json.Unmarshal([]byte(value), params); err != nil {
+		return nil, fmt.Errorf("unmarshal failed, error: %w, value: %s", err, value)
+	}
+	return params, nil
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func Test_DecodeImageStorageClassParameters(t *testing.T) {
+	params, err := DecodeImageStorageClassParameters(`{"numberOfReplicas":2,"nodeSelector":["edge"],"dataLocality":"best-effort"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, &v1beta1.ImageStorageClassParameters{
+		NumberOfReplicas: 2,
+		NodeSelector:     []string{"edge"},
+		DataLocality:     "best-effort",
+	}, params)
+
+	_, err = DecodeImageStorageClassParameters(`{"numberOfReplicas":2`)
+	assert.NotNil(t, err)
+}
+
+func Test_ValidateImageStorageClassParameters(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		params      *v1beta1.ImageStorageClassParameters
+		expectError bool
+	}{
+		{
+			name: "no parameters",
+		},
+		{
+			name:   "valid parameters",
+			params: &v1beta1.ImageStorageClassParameters{NumberOfReplicas: 1, DataLocality: "disabled", NodeSelector: []string{"edge"}},
+		},
+		{
+			name:        "too many replicas",
+			params:      &v1beta1.ImageStorageClassParameters{NumberOfReplicas: 21},
+			expectError: true,
+		},
+		{
+			name:        "invalid data locality",
+			params:      &v1beta1.ImageStorageClassParameters{DataLocality: "strict"},
+			expectError: true,
+		},
+		{
+			name:        "invalid tag",
+			params:      &v1beta1.ImageStorageClassParameters{DiskSelector: []string{"ssd,nvme"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateImageStorageClassParameters(tc.params)
+		assert.Equal(t, tc.expectError, err != nil, tc.name)
+	}
+}
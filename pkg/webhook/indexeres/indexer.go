@@ -2,6 +2,7 @@ package indexeres
 
 import (
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
 	"github.com/harvester/harvester/pkg/webhook/clients"
 )
 
@@ -12,6 +13,8 @@ const (
 func RegisterIndexers(clients *clients.Clients) {
 	vmBackupCache := clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineBackup().Cache()
 	vmBackupCache.AddIndexer(VMBackupBySourceUIDIndex, vmBackupBySourceUID)
+	vmImageCache := clients.HarvesterFactory.Harvesterhci().V1beta1().VirtualMachineImage().Cache()
+	vmImageCache.AddIndexer(indexeres.ImageByStorageClassIndex, indexeres.ImageByStorageClass)
 }
 
 func vmBackupBySourceUID(obj *harvesterv1.VirtualMachineBackup) ([]string, error) {
@@ -0,0 +1,99 @@
+package backupschedule
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/robfig/cron"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldSchedule   = "spec.schedule"
+	fieldVMSelector = "spec.vmSelector"
+	fieldRetention  = "spec.retention"
+
+	fieldVerificationSchedule = "spec.verification.schedule"
+	fieldVerificationTimeout  = "spec.verification.timeout"
+)
+
+func NewValidator() types.Validator {
+	return &backupScheduleValidator{}
+}
+
+type backupScheduleValidator struct {
+	types.DefaultValidator
+}
+
+func (v *backupScheduleValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.VirtualMachineBackupScheduleResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.VirtualMachineBackupSchedule{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *backupScheduleValidator) Create(request *types.Request, newObj runtime.Object) error {
+	return v.checkSpec(newObj.(*v1beta1.VirtualMachineBackupSchedule))
+}
+
+func (v *backupScheduleValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	return v.checkSpec(newObj.(*v1beta1.VirtualMachineBackupSchedule))
+}
+
+func (v *backupScheduleValidator) checkSpec(schedule *v1beta1.VirtualMachineBackupSchedule) error {
+	if schedule.Spec.Schedule == "" {
+		return werror.NewInvalidError("schedule is empty", fieldSchedule)
+	}
+	if _, err := cron.ParseStandard(schedule.Spec.Schedule); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid schedule %q: %v", schedule.Spec.Schedule, err), fieldSchedule)
+	}
+
+	if err := checkVMSelector(schedule.Spec.VMSelector); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldVMSelector)
+	}
+
+	retention := schedule.Spec.Retention
+	if retention.KeepLast < 0 || retention.KeepDaily < 0 || retention.KeepWeekly < 0 {
+		return werror.NewInvalidError("retention counts can't be negative", fieldRetention)
+	}
+
+	if verification := schedule.Spec.Verification; verification != nil {
+		if _, err := cron.ParseStandard(verification.Schedule); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid verification schedule %q: %v", verification.Schedule, err), fieldVerificationSchedule)
+		}
+		if verification.Timeout != nil && verification.Timeout.Duration <= 0 {
+			return werror.NewInvalidError("verification timeout must be positive", fieldVerificationTimeout)
+		}
+	}
+	return nil
+}
+
+func checkVMSelector(selector v1beta1.VirtualMachineSelector) error {
+	if len(selector.Names) == 0 && selector.LabelSelector == nil {
+		return errors.New("either VM names or a label selector is required")
+	}
+	for _, name := range selector.Names {
+		if name == "" {
+			return errors.New("VM name is empty")
+		}
+	}
+	if selector.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector.LabelSelector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package backuptarget
+
+import (
+	"fmt"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/settings"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldSpec             = "spec"
+	fieldEndpoint         = "spec.endpoint"
+	fieldBucket           = "spec.bucketName"
+	fieldCredentialSecret = "spec.credentialSecret"
+	fieldEncryption       = "spec.encryption"
+)
+
+func NewValidator(
+	backupTargets ctlharvesterv1.BackupTargetCache,
+	vmBackups ctlharvesterv1.VirtualMachineBackupCache,
+	settingCache ctlharvesterv1.SettingCache,
+	secrets ctlcorev1.SecretCache,
+) types.Validator {
+	return &backupTargetValidator{
+		backupTargets: backupTargets,
+		vmBackups:     vmBackups,
+		settingCache:  settingCache,
+		secrets:       secrets,
+	}
+}
+
+type backupTargetValidator struct {
+	types.DefaultValidator
+
+	backupTargets ctlharvesterv1.BackupTargetCache
+	vmBackups     ctlharvesterv1.VirtualMachineBackupCache
+	settingCache  ctlharvesterv1.SettingCache
+	secrets       ctlcorev1.SecretCache
+}
+
+func (v *backupTargetValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.BackupTargetResourceName},
+		Scope:      admissionregv1.ClusterScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.BackupTarget{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+			admissionregv1.Delete,
+		},
+	}
+}
+
+func (v *backupTargetValidator) Create(request *types.Request, newObj runtime.Object) error {
+	return v.checkSpec(newObj.(*v1beta1.BackupTarget))
+}
+
+func (v *backupTargetValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	oldTarget := oldObj.(*v1beta1.BackupTarget)
+	newTarget := newObj.(*v1beta1.BackupTarget)
+
+	// the backups record where they are stored, so a target can't be moved to another location.
+	// The default target follows the backup-target setting.
+	if newTarget.Name != v1beta1.DefaultBackupTargetName && ctlbackup.GetBackupTargetURL(oldTarget) != ctlbackup.GetBackupTargetURL(newTarget) {
+		return werror.NewInvalidError("the location of a backup target can't be changed, please create a new backup target", fieldSpec)
+	}
+	if oldTarget.Spec.Encryption != nil && newTarget.Spec.Encryption == nil {
+		if err := v.checkNoEncryptedBackups(newTarget); err != nil {
+			return err
+		}
+	}
+	if oldTarget.Spec.Encryption == nil && newTarget.Spec.Encryption != nil {
+		if err := v.checkNoUnencryptedBackups(newTarget); err != nil {
+			return err
+		}
+	}
+	return v.checkSpec(newTarget)
+}
+
+func (v *backupTargetValidator) Delete(request *types.Request, oldObj runtime.Object) error {
+	target := oldObj.(*v1beta1.BackupTarget)
+
+	if target.Name == v1beta1.DefaultBackupTargetName {
+		setting, err := v.settingCache.Get(settings.BackupTargetSettingName)
+		if err != nil {
+			return werror.NewInternalError(err.Error())
+		}
+		if defaultTarget, err := settings.DecodeBackupTarget(setting.Value); err == nil && !defaultTarget.IsDefaultBackupTarget() {
+			return werror.NewBadRequest(fmt.Sprintf("the default backup target is configured by the %s setting", settings.BackupTargetSettingName))
+		}
+	}
+
+	vmBackups, err := v.vmBackups.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	for _, vmBackup := range vmBackups {
+		if vmBackup.Spec.Type == v1beta1.Snapshot || ctlbackup.GetBackupTargetName(vmBackup) != target.Name {
+			continue
+		}
+		if ctlbackup.IsBackupProgressing(vmBackup) && ctlbackup.GetVMBackupError(vmBackup) == nil {
+			return werror.NewBadRequest(fmt.Sprintf("VM backup %s/%s to the backup target is in progress", vmBackup.Namespace, vmBackup.Name))
+		}
+	}
+	return nil
+}
+
+func (v *backupTargetValidator) checkSpec(target *v1beta1.BackupTarget) error {
+	switch target.Spec.Type {
+	case v1beta1.BackupTargetTypeS3:
+		if target.Spec.BucketName == "" || target.Spec.BucketRegion == "" {
+			return werror.NewInvalidError("S3 backup target should have bucket name and region", fieldBucket)
+		}
+		if err := v.checkCredentialSecret(target.Spec.CredentialSecret); err != nil {
+			return err
+		}
+	case v1beta1.BackupTargetTypeNFS:
+		if target.Spec.Endpoint == "" {
+			return werror.NewInvalidError("NFS backup target should have endpoint", fieldEndpoint)
+		}
+		if target.Spec.BucketName != "" || target.Spec.BucketRegion != "" {
+			return werror.NewInvalidError("NFS backup target should not have bucket name or region", fieldBucket)
+		}
+		if target.Spec.CredentialSecret != nil {
+			return werror.NewInvalidError("NFS backup target should not have credential secret", fieldCredentialSecret)
+		}
+	default:
+		return werror.NewInvalidError(fmt.Sprintf("invalid backup target type %q", target.Spec.Type), "spec.type")
+	}
+	if err := v.checkEncryption(target.Spec.Encryption); err != nil {
+		return err
+	}
+
+	targets, err := v.backupTargets.List(labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	url := ctlbackup.GetBackupTargetURL(target)
+	for _, existing := range targets {
+		if existing.Name != target.Name && ctlbackup.GetBackupTargetURL(existing) == url {
+			return werror.NewConflict(fmt.Sprintf("backup target %s has the same location", existing.Name))
+		}
+	}
+	return nil
+}
+
+func (v *backupTargetValidator) checkCredentialSecret(ref *corev1.SecretReference) error {
+	if ref == nil || ref.Namespace == "" || ref.Name == "" {
+		return werror.NewInvalidError("S3 backup target should have the namespace and name of the credential secret", fieldCredentialSecret)
+	}
+
+	secret, err := v.secrets.Get(ref.Namespace, ref.Name)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get credential secret %s/%s: %v", ref.Namespace, ref.Name, err), fieldCredentialSecret)
+	}
+	if len(secret.Data[ctlbackup.AWSAccessKey]) == 0 || len(secret.Data[ctlbackup.AWSSecretKey]) == 0 {
+		return werror.NewInvalidError(fmt.Sprintf("credential secret should have %s and %s", ctlbackup.AWSAccessKey, ctlbackup.AWSSecretKey), fieldCredentialSecret)
+	}
+	return nil
+}
+
+func (v *backupTargetValidator) checkEncryption(encryption *v1beta1.BackupTargetEncryption) error {
+	if encryption == nil {
+		return nil
+	}
+	ref := encryption.KeySecret
+	if ref.Namespace == "" || ref.Name == "" {
+		return werror.NewInvalidError("encrypted backup target should have the namespace and name of the key secret", fieldEncryption)
+	}
+	if encryption.ActiveKeyID == "" {
+		return werror.NewInvalidError("encrypted backup target should have the active key ID", fieldEncryption)
+	}
+
+	secret, err := v.secrets.Get(ref.Namespace, ref.Name)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("can't get key secret %s/%s: %v", ref.Namespace, ref.Name, err), fieldEncryption)
+	}
+	if err := ctlbackup.ValidateEncryptionKeys(secret.Data, encryption.ActiveKeyID); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid key secret %s/%s: %v", ref.Namespace, ref.Name, err), fieldEncryption)
+	}
+	return nil
+}
+
+// checkNoEncryptedBackups checks there is no encrypted backup in the target, they can't be restored without the keys
+func (v *backupTargetValidator) checkNoEncryptedBackups(target *v1beta1.BackupTarget) error {
+	vmBackups, err := v.vmBackups.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	for _, vmBackup := range vmBackups {
+		if ctlbackup.GetBackupTargetName(vmBackup) == target.Name && vmBackup.Status != nil && vmBackup.Status.EncryptionKeyID != "" {
+			return werror.NewInvalidError(fmt.Sprintf("VM backup %s/%s in the backup target is encrypted, the encryption can't be removed", vmBackup.Namespace, vmBackup.Name), fieldEncryption)
+		}
+	}
+	return nil
+}
+
+// checkNoUnencryptedBackups checks there is no backup in the target before it's encrypted,
+// the volume data of the existing backups may be kept in plain.
+func (v *backupTargetValidator) checkNoUnencryptedBackups(target *v1beta1.BackupTarget) error {
+	vmBackups, err := v.vmBackups.List(metav1.NamespaceAll, labels.Everything())
+	if err != nil {
+		return werror.NewInternalError(err.Error())
+	}
+	for _, vmBackup := range vmBackups {
+		if vmBackup.Spec.Type == v1beta1.Backup && ctlbackup.GetBackupTargetName(vmBackup) == target.Name {
+			return werror.NewInvalidError(fmt.Sprintf("the volume data of VM backup %s/%s in the backup target may not be encrypted, "+
+				"please remove the existing backups or use a new backup target", vmBackup.Namespace, vmBackup.Name), fieldEncryption)
+		}
+	}
+	return nil
+}
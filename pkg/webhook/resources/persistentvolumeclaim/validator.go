@@ -10,23 +10,28 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	ctlkv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/indexeres"
 	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
-func NewValidator(pvcCache v1.PersistentVolumeClaimCache, vmCache ctlkv1.VirtualMachineCache) types.Validator {
+func NewValidator(pvcCache v1.PersistentVolumeClaimCache, vmCache ctlkv1.VirtualMachineCache, vmImageCache ctlharvesterv1.VirtualMachineImageCache) types.Validator {
 	return &pvcValidator{
-		pvcCache: pvcCache,
-		vmCache:  vmCache,
+		pvcCache:     pvcCache,
+		vmCache:      vmCache,
+		vmImageCache: vmImageCache,
 	}
 }
 
 type pvcValidator struct {
 	types.DefaultValidator
-	pvcCache v1.PersistentVolumeClaimCache
-	vmCache  ctlkv1.VirtualMachineCache
+	pvcCache     v1.PersistentVolumeClaimCache
+	vmCache      ctlkv1.VirtualMachineCache
+	vmImageCache ctlharvesterv1.VirtualMachineImageCache
 }
 
 func (v *pvcValidator) Resource() types.Resource {
@@ -37,12 +42,37 @@ func (v *pvcValidator) Resource() types.Resource {
 		APIVersion: corev1.SchemeGroupVersion.Version,
 		ObjectType: &corev1.PersistentVolumeClaim{},
 		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
 			admissionregv1.Delete,
 			admissionregv1.Update,
 		},
 	}
 }
 
+func (v *pvcValidator) Create(request *types.Request, newObj runtime.Object) error {
+	pvc := newObj.(*corev1.PersistentVolumeClaim)
+	return v.checkImageSharing(pvc)
+}
+
+// checkImageSharing rejects the PVCs created by the storage classes of the images not shared with their namespace,
+// the storage class of a PVC can't be changed after it's created
+func (v *pvcValidator) checkImageSharing(pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil
+	}
+	images, err := v.vmImageCache.GetByIndex(indexeres.ImageByStorageClassIndex, *pvc.Spec.StorageClassName)
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		if !util.IsImageSharedWith(image, pvc.Namespace) {
+			message := fmt.Sprintf("image %s/%s is not shared with namespace %s", image.Namespace, image.Spec.DisplayName, pvc.Namespace)
+			return werror.NewInvalidError(message, "spec.storageClassName")
+		}
+	}
+	return nil
+}
+
 func (v *pvcValidator) Delete(request *types.Request, oldObj runtime.Object) error {
 	if request.IsGarbageCollection() {
 		return nil
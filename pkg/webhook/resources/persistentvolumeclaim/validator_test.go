@@ -0,0 +1,75 @@
+package persistentvolumeclaim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util/fakeclients"
+)
+
+func TestCheckImageSharing(t *testing.T) {
+	image := &harvesterv1.VirtualMachineImage{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "images", Name: "image-abcde"},
+		Spec: harvesterv1.VirtualMachineImageSpec{
+			DisplayName: "ubuntu",
+			Sharing:     &harvesterv1.VirtualMachineImageSharing{Namespaces: []string{"shared"}},
+		},
+		Status: harvesterv1.VirtualMachineImageStatus{StorageClassName: "longhorn-image-abcde"},
+	}
+
+	var testCases = []struct {
+		name             string
+		namespace        string
+		storageClassName *string
+		expectedError    bool
+	}{
+		{
+			name:             "image in the namespace",
+			namespace:        "images",
+			storageClassName: pointer.StringPtr("longhorn-image-abcde"),
+		},
+		{
+			name:             "image shared with the namespace",
+			namespace:        "shared",
+			storageClassName: pointer.StringPtr("longhorn-image-abcde"),
+		},
+		{
+			name:             "image not shared with the namespace",
+			namespace:        "default",
+			storageClassName: pointer.StringPtr("longhorn-image-abcde"),
+			expectedError:    true,
+		},
+		{
+			name:             "storage class of no image",
+			namespace:        "default",
+			storageClassName: pointer.StringPtr("longhorn"),
+		},
+		{
+			name:      "default storage class",
+			namespace: "default",
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(image)
+	validator := &pvcValidator{
+		vmImageCache: fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages),
+	}
+	for _, tc := range testCases {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tc.namespace, Name: "pvc"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: tc.storageClassName},
+		}
+		err := validator.checkImageSharing(pvc)
+		if tc.expectedError {
+			assert.NotNil(t, err, tc.name)
+		} else {
+			assert.Nil(t, err, tc.name)
+		}
+	}
+}
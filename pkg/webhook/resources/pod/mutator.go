@@ -27,6 +27,10 @@ var matchingLabels = []labels.Set{
 	{
 		"app": "rancher",
 	},
+	{
+		"app.kubernetes.io/name":      "harvester",
+		"app.kubernetes.io/component": "image-download",
+	},
 }
 
 func NewMutator(settingCache v1beta1.SettingCache) types.Mutator {
@@ -36,7 +40,7 @@ func NewMutator(settingCache v1beta1.SettingCache) types.Mutator {
 }
 
 // podMutator injects Harvester settings like http proxy envs and trusted CA certs to system pods that may access
-// external services. It includes harvester apiserver and longhorn backing-image-data-source pods.
+// external services. It includes harvester apiserver, image download and longhorn backing-image-data-source pods.
 type podMutator struct {
 	types.DefaultMutator
 	setttingCache v1beta1.SettingCache
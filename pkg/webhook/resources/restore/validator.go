@@ -3,7 +3,10 @@ package restore
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	ctlstoragev1 "github.com/rancher/wrangler/pkg/generated/controllers/storage/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -11,8 +14,10 @@ import (
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlbackup "github.com/harvester/harvester/pkg/controller/master/backup"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlcniv1 "github.com/harvester/harvester/pkg/generated/controllers/k8s.cni.cncf.io/v1"
 	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
-	"github.com/harvester/harvester/pkg/settings"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
@@ -21,26 +26,44 @@ const (
 	fieldTargetName               = "spec.target.name"
 	fieldVirtualMachineBackupName = "spec.virtualMachineBackupName"
 	fieldNewVM                    = "spec.newVM"
+	fieldMode                     = "spec.mode"
+	fieldMapping                  = "spec.mapping"
+	fieldMappingNamespaces        = "spec.mapping.namespaces"
+	fieldMappingStorageClasses    = "spec.mapping.storageClasses"
+	fieldMappingImages            = "spec.mapping.images"
+	fieldMappingNetworks          = "spec.mapping.networks"
 )
 
 func NewValidator(
 	vms ctlkubevirtv1.VirtualMachineCache,
-	setting ctlharvesterv1.SettingCache,
+	backupTargets ctlharvesterv1.BackupTargetCache,
 	vmBackup ctlharvesterv1.VirtualMachineBackupCache,
+	images ctlharvesterv1.VirtualMachineImageCache,
+	storageClasses ctlstoragev1.StorageClassCache,
+	netAttachDefs ctlcniv1.NetworkAttachmentDefinitionCache,
+	secrets ctlcorev1.SecretCache,
 ) types.Validator {
 	return &restoreValidator{
-		vms:      vms,
-		setting:  setting,
-		vmBackup: vmBackup,
+		vms:            vms,
+		backupTargets:  backupTargets,
+		vmBackup:       vmBackup,
+		images:         images,
+		storageClasses: storageClasses,
+		netAttachDefs:  netAttachDefs,
+		secrets:        secrets,
 	}
 }
 
 type restoreValidator struct {
 	types.DefaultValidator
 
-	vms      ctlkubevirtv1.VirtualMachineCache
-	setting  ctlharvesterv1.SettingCache
-	vmBackup ctlharvesterv1.VirtualMachineBackupCache
+	vms            ctlkubevirtv1.VirtualMachineCache
+	backupTargets  ctlharvesterv1.BackupTargetCache
+	vmBackup       ctlharvesterv1.VirtualMachineBackupCache
+	images         ctlharvesterv1.VirtualMachineImageCache
+	storageClasses ctlstoragev1.StorageClassCache
+	netAttachDefs  ctlcniv1.NetworkAttachmentDefinitionCache
+	secrets        ctlcorev1.SecretCache
 }
 
 func (v *restoreValidator) Resource() types.Resource {
@@ -74,6 +97,10 @@ func (v *restoreValidator) Create(request *types.Request, newObj runtime.Object)
 		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
 	}
 
+	if err := v.checkMapping(newRestore); err != nil {
+		return err
+	}
+
 	vm, err := v.vms.Get(newRestore.Namespace, targetVM)
 	if err != nil {
 		if newVM && apierrors.IsNotFound(err) {
@@ -96,30 +123,106 @@ func (v *restoreValidator) Create(request *types.Request, newObj runtime.Object)
 }
 
 func (v *restoreValidator) checkBackupTarget(vmRestore *v1beta1.VirtualMachineRestore) error {
-	// get backup target
-	backupTargetSetting, err := v.setting.Get(settings.BackupTargetSettingName)
+	// get vmbackup
+	vmBackup, err := v.vmBackup.Get(vmRestore.Spec.VirtualMachineBackupNamespace, vmRestore.Spec.VirtualMachineBackupName)
 	if err != nil {
-		return fmt.Errorf("can't get backup target setting, err: %w", err)
+		return fmt.Errorf("can't get vmbackup %s/%s, err: %w", vmRestore.Spec.VirtualMachineBackupNamespace, vmRestore.Spec.VirtualMachineBackupName, err)
+	}
+
+	// snapshots are kept as VolumeSnapshots in the namespace of the VM, they don't depend on the backup target
+	if vmBackup.Spec.Type == v1beta1.Snapshot {
+		if vmRestore.Namespace != vmBackup.Namespace {
+			return fmt.Errorf("VM snapshot %s/%s can only be restored in namespace %s", vmBackup.Namespace, vmBackup.Name, vmBackup.Namespace)
+		}
+		return nil
 	}
 
-	backupTarget, err := settings.DecodeBackupTarget(backupTargetSetting.Value)
+	// get backup target
+	backupTargetName := ctlbackup.GetBackupTargetName(vmBackup)
+	backupTarget, err := v.backupTargets.Get(backupTargetName)
 	if err != nil {
-		return fmt.Errorf("unmarshal backup target failed, value: %s, err: %w", backupTargetSetting.Value, err)
+		return fmt.Errorf("can't get backup target %s, err: %w", backupTargetName, err)
 	}
 
-	if backupTarget.IsDefaultBackupTarget() {
-		return fmt.Errorf("backup target is invalid")
+	if vmBackup.Status == nil || vmBackup.Status.BackupTarget == nil || !ctlbackup.IsBackupTargetSame(vmBackup.Status.BackupTarget, backupTarget) {
+		return errors.New("VM Backup is not matched with Backup Target")
+	}
+
+	// the secrets of an encrypted backup can't be restored without its keys
+	return ctlbackup.CheckBackupDecryptable(v.secrets, backupTarget, vmBackup)
+}
+
+// checkMapping makes sure the references of a cross-cluster restore exist in this cluster after they are mapped
+func (v *restoreValidator) checkMapping(vmRestore *v1beta1.VirtualMachineRestore) error {
+	if vmRestore.Spec.Mode != v1beta1.RestoreModeCrossCluster {
+		if vmRestore.Spec.Mapping != nil {
+			return werror.NewInvalidError("mapping only applies to the crossCluster mode", fieldMapping)
+		}
+		return nil
 	}
 
-	// get vmbackup
 	vmBackup, err := v.vmBackup.Get(vmRestore.Spec.VirtualMachineBackupNamespace, vmRestore.Spec.VirtualMachineBackupName)
 	if err != nil {
-		return fmt.Errorf("can't get vmbackup %s/%s, err: %w", vmRestore.Spec.VirtualMachineBackupNamespace, vmRestore.Spec.VirtualMachineBackupName, err)
+		return werror.NewInvalidError(err.Error(), fieldVirtualMachineBackupName)
+	}
+	if vmBackup.Spec.Type == v1beta1.Snapshot {
+		return werror.NewInvalidError("VM snapshots are kept in the cluster, they can't be restored across clusters", fieldMode)
+	}
+	if vmBackup.Status == nil || vmBackup.Status.SourceSpec == nil {
+		return werror.NewInvalidError(fmt.Sprintf("VM backup %s/%s is not ready", vmBackup.Namespace, vmBackup.Name), fieldVirtualMachineBackupName)
 	}
 
-	if vmBackup.Status == nil || vmBackup.Status.BackupTarget == nil || !ctlbackup.IsBackupTargetSame(vmBackup.Status.BackupTarget, backupTarget) {
-		return errors.New("VM Backup is not matched with Backup Target")
+	mapping := ctlbackup.GetRestoreMapping(vmRestore)
+	if namespace := ctlbackup.MapNamespace(mapping, vmBackup.Namespace); namespace != vmBackup.Namespace && namespace != vmRestore.Namespace {
+		return werror.NewInvalidError(fmt.Sprintf("namespace %s of the backup is mapped to %s, but the restore is in namespace %s",
+			vmBackup.Namespace, namespace, vmRestore.Namespace), fieldMappingNamespaces)
+	}
+
+	var messages []string
+	for _, vb := range vmBackup.Status.VolumeBackups {
+		if imageID := vb.PersistentVolumeClaim.ObjectMeta.Annotations[util.AnnotationImageID]; imageID != "" {
+			mapped := ctlbackup.MapImageID(mapping, imageID)
+			namespace, name := ref.Parse(mapped)
+			if _, err := v.images.Get(namespace, name); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return werror.NewInternalError(err.Error())
+				}
+				messages = append(messages, fmt.Sprintf("image %s of volume %s should be mapped in %s", mapped, vb.VolumeName, fieldMappingImages))
+			}
+			continue
+		}
+
+		scName := ctlbackup.MapStorageClassName(mapping, vb.PersistentVolumeClaim)
+		if scName == nil {
+			continue
+		}
+		if _, err := v.storageClasses.Get(*scName); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return werror.NewInternalError(err.Error())
+			}
+			messages = append(messages, fmt.Sprintf("storage class %s of volume %s should be mapped in %s", *scName, vb.VolumeName, fieldMappingStorageClasses))
+		}
 	}
 
+	for _, network := range vmBackup.Status.SourceSpec.Spec.Template.Spec.Networks {
+		if network.Multus == nil {
+			continue
+		}
+		mapped := ctlbackup.MapNetworkName(mapping, vmBackup.Namespace, network.Multus.NetworkName)
+		namespace, name := ref.Parse(mapped)
+		if namespace == "" {
+			namespace = vmRestore.Namespace
+		}
+		if _, err := v.netAttachDefs.Get(namespace, name); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return werror.NewInternalError(err.Error())
+			}
+			messages = append(messages, fmt.Sprintf("network %s/%s of interface %s should be mapped in %s", namespace, name, network.Name, fieldMappingNetworks))
+		}
+	}
+
+	if len(messages) > 0 {
+		return werror.NewInvalidError(fmt.Sprintf("unmappable references: %s", strings.Join(messages, "; ")), fieldMapping)
+	}
 	return nil
 }
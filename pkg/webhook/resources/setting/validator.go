@@ -18,8 +18,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/http/httpproxy"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
@@ -46,26 +44,32 @@ type validateSettingFunc func(setting *v1beta1.Setting) error
 var validateSettingFuncs = map[string]validateSettingFunc{
 	settings.HttpProxySettingName:            validateHTTPProxy,
 	settings.VMForceResetPolicySettingName:   validateVMForceResetPolicy,
-	settings.SupportBundleImageName:          validateSupportBundleImage,
+	settings.SupportBundleImageName:          validateImage,
 	settings.SupportBundleTimeoutSettingName: validateSupportBundleTimeout,
 	settings.OvercommitConfigSettingName:     validateOvercommitConfig,
 	settings.VipPoolsConfigSettingName:       validateVipPoolsConfig,
 	settings.SSLCertificatesSettingName:      validateSSLCertificates,
 	settings.SSLParametersName:               validateSSLParameters,
+
+	settings.DefaultVMImageStorageClassParametersSettingName: validateDefaultVMImageStorageClassParameters,
+	settings.ImageDownloaderImageSettingName:                 validateImage,
+	settings.ImageDownloadBandwidthLimitSettingName:          validateImageDownloadBandwidthLimit,
+	settings.VMImageGCPolicySettingName:                      validateVMImageGCPolicy,
+	settings.VMMaxCPUsSettingName:                            validateVMMaxCPUs,
+	settings.FileRestoreImageSettingName:                     validateImage,
 }
 
 func NewValidator(
 	settingCache ctlv1beta1.SettingCache,
-	vmBackupCache ctlv1beta1.VirtualMachineBackupCache,
 	snapshotClassCache ctlsnapshotv1.VolumeSnapshotClassCache,
 ) types.Validator {
 	validator := &settingValidator{
 		settingCache:       settingCache,
-		vmBackupCache:      vmBackupCache,
 		snapshotClassCache: snapshotClassCache,
 	}
 	validateSettingFuncs[settings.BackupTargetSettingName] = validator.validateBackupTarget
 	validateSettingFuncs[settings.VolumeSnapshotClassSettingName] = validator.validateVolumeSnapshotClass
+	validateSettingFuncs[settings.LocalVolumeSnapshotClassSettingName] = validator.validateVolumeSnapshotClass
 	return validator
 }
 
@@ -73,7 +77,6 @@ type settingValidator struct {
 	types.DefaultValidator
 
 	settingCache       ctlv1beta1.SettingCache
-	vmBackupCache      ctlv1beta1.VirtualMachineBackupCache
 	snapshotClassCache ctlsnapshotv1.VolumeSnapshotClassCache
 }
 
@@ -144,6 +147,17 @@ func validateOvercommitConfig(setting *v1beta1.Setting) error {
 	return nil
 }
 
+func validateDefaultVMImageStorageClassParameters(setting *v1beta1.Setting) error {
+	params, err := util.DecodeImageStorageClassParameters(setting.Value)
+	if err != nil {
+		return werror.NewInvalidError(err.Error(), "value")
+	}
+	if err := util.ValidateImageStorageClassParameters(params); err != nil {
+		return werror.NewInvalidError(err.Error(), "value")
+	}
+	return nil
+}
+
 func validateVMForceResetPolicy(setting *v1beta1.Setting) error {
 	if setting.Value == "" {
 		return nil
@@ -226,13 +240,8 @@ func (v *settingValidator) validateBackupTarget(setting *v1beta1.Setting) error
 
 	logrus.Debugf("validate backup target:%s:%s", target.Type, target.Endpoint)
 
-	vmBackups, err := v.vmBackupCache.List(metav1.NamespaceAll, labels.Everything())
-	if err != nil {
-		return werror.NewInternalError(err.Error())
-	}
-	if hasVMBackupInCreatingOrDeletingProgress(vmBackups) {
-		return werror.NewBadRequest("There is VMBackup in creating or deleting progress")
-	}
+	// the default BackupTarget follows the setting, the longhorn backup target is switched
+	// by the controller after the in-progress backups and restores are finished.
 
 	// It is allowed to reset the current backup target setting to the default value
 	// when it is default, the validator will skip all remaining checks
@@ -418,16 +427,34 @@ func getSystemCerts() *x509.CertPool {
 	return certs
 }
 
-func hasVMBackupInCreatingOrDeletingProgress(vmBackups []*v1beta1.VirtualMachineBackup) bool {
-	for _, vmBackup := range vmBackups {
-		if vmBackup.DeletionTimestamp != nil || vmBackup.Status == nil || !*vmBackup.Status.ReadyToUse {
-			return true
-		}
+func validateImageDownloadBandwidthLimit(setting *v1beta1.Setting) error {
+	_, err := util.ParseBandwidthLimit(setting.Value)
+	return err
+}
+
+func validateVMMaxCPUs(setting *v1beta1.Setting) error {
+	if setting.Value == "" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(setting.Value)
+	if err != nil {
+		return werror.NewInvalidError(err.Error(), "value")
 	}
-	return false
+	if i < 0 {
+		return werror.NewInvalidError("the maximum number of vCPUs can't be negative", "value")
+	}
+	return nil
+}
+
+func validateVMImageGCPolicy(setting *v1beta1.Setting) error {
+	if _, err := util.DecodeImageGCPolicy(setting.Value); err != nil {
+		return werror.NewInvalidError(err.Error(), "value")
+	}
+	return nil
 }
 
-func validateSupportBundleImage(setting *v1beta1.Setting) error {
+func validateImage(setting *v1beta1.Setting) error {
 	if setting.Value == "" {
 		return nil
 	}
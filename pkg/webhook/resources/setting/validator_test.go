@@ -170,3 +170,40 @@ func Test_validateSSLProtocols(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateDefaultVMImageStorageClassParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expectErr bool
+	}{
+		{
+			name:  "default value",
+			value: settings.DefaultVMImageStorageClassParameters.Default,
+		},
+		{
+			name:  "two replicas for small clusters",
+			value: `{"numberOfReplicas":2,"staleReplicaTimeout":30}`,
+		},
+		{
+			name:      "invalid json",
+			value:     `{"numberOfReplicas":2`,
+			expectErr: true,
+		},
+		{
+			name:      "no replica",
+			value:     `{"numberOfReplicas":-1}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDefaultVMImageStorageClassParameters(&v1beta1.Setting{
+				ObjectMeta: v1.ObjectMeta{Name: settings.DefaultVMImageStorageClassParametersSettingName},
+				Value:      tt.value,
+			})
+			assert.Equal(t, tt.expectErr, err != nil)
+		})
+	}
+}
@@ -10,20 +10,25 @@ import (
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/types"
+	webhookutil "github.com/harvester/harvester/pkg/webhook/util"
 )
 
 const (
-	fieldTemplateID = "spec.templateId"
-	fieldKeyPairIds = "spec.keyPairIds"
+	fieldTemplateID    = "spec.templateId"
+	fieldKeyPairIds    = "spec.keyPairIds"
+	fieldVMAnnotations = "spec.vm.metadata.annotations"
 )
 
-func NewValidator(templateCache ctlharvesterv1.VirtualMachineTemplateCache, templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache, keypairs ctlharvesterv1.KeyPairCache) types.Validator {
+func NewValidator(templateCache ctlharvesterv1.VirtualMachineTemplateCache, templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache, keypairs ctlharvesterv1.KeyPairCache,
+	vmimages ctlharvesterv1.VirtualMachineImageCache) types.Validator {
 	return &templateVersionValidator{
 		templateCache:        templateCache,
 		templateVersionCache: templateVersionCache,
 		keypairs:             keypairs,
+		vmimages:             vmimages,
 	}
 }
 
@@ -33,6 +38,7 @@ type templateVersionValidator struct {
 	templateCache        ctlharvesterv1.VirtualMachineTemplateCache
 	templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache
 	keypairs             ctlharvesterv1.KeyPairCache
+	vmimages             ctlharvesterv1.VirtualMachineImageCache
 }
 
 func (v *templateVersionValidator) Resource() types.Resource {
@@ -73,7 +79,15 @@ func (v *templateVersionValidator) Create(request *types.Request, newObj runtime
 		}
 	}
 
-	return nil
+	return v.checkImageFamilies(vmTemplVersion)
+}
+
+// checkImageFamilies requires the image families referenced by the volume claim templates of the VM to have a ready
+// version, the families are resolved when the VMs are created from the template version
+func (v *templateVersionValidator) checkImageFamilies(vmTemplVersion *v1beta1.VirtualMachineTemplateVersion) error {
+	_, err := util.ResolveImageFamilies(vmTemplVersion.Spec.VM.ObjectMeta.Annotations,
+		webhookutil.ImageFamilyResolver(v.vmimages, vmTemplVersion.Namespace, fieldVMAnnotations))
+	return err
 }
 
 func (v *templateVersionValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
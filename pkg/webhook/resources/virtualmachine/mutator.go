@@ -3,6 +3,7 @@ package virtualmachine
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	v1 "k8s.io/api/core/v1"
@@ -15,19 +16,23 @@ import (
 	"github.com/harvester/harvester/pkg/settings"
 	"github.com/harvester/harvester/pkg/util"
 	"github.com/harvester/harvester/pkg/webhook/types"
+	webhookutil "github.com/harvester/harvester/pkg/webhook/util"
 )
 
 func NewMutator(
 	setting ctlharvesterv1.SettingCache,
+	vmImageCache ctlharvesterv1.VirtualMachineImageCache,
 ) types.Mutator {
 	return &vmMutator{
-		setting: setting,
+		setting:      setting,
+		vmImageCache: vmImageCache,
 	}
 }
 
 type vmMutator struct {
 	types.DefaultMutator
-	setting ctlharvesterv1.SettingCache
+	setting      ctlharvesterv1.SettingCache
+	vmImageCache ctlharvesterv1.VirtualMachineImageCache
 }
 
 func (m *vmMutator) Resource() types.Resource {
@@ -50,7 +55,11 @@ func (m *vmMutator) Create(request *types.Request, newObj runtime.Object) (types
 	if err != nil {
 		return patchOps, err
 	}
-	return patchOps, nil
+	imageFamilyPatchOps, err := m.patchImageFamilies(vm)
+	if err != nil {
+		return patchOps, err
+	}
+	return append(patchOps, imageFamilyPatchOps...), nil
 }
 
 func (m *vmMutator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) (types.PatchOps, error) {
@@ -155,6 +164,24 @@ func (m *vmMutator) patchResourceOvercommit(vm *kubevirtv1.VirtualMachine) ([]st
 	return patchOps, nil
 }
 
+// patchImageFamilies sets the images of the PVCs referencing an image family to the latest ready version of the family,
+// the family is only resolved when the VM is created so that the VM keeps the version it's created from
+func (m *vmMutator) patchImageFamilies(vm *kubevirtv1.VirtualMachine) (types.PatchOps, error) {
+	var patchOps types.PatchOps
+	volumeClaimTemplates, err := util.ResolveImageFamilies(vm.Annotations,
+		webhookutil.ImageFamilyResolver(m.vmImageCache, vm.Namespace, "metadata.annotations"))
+	if err != nil || volumeClaimTemplates == "" {
+		return patchOps, err
+	}
+	value, err := json.Marshal(volumeClaimTemplates)
+	if err != nil {
+		return patchOps, err
+	}
+	patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/metadata/annotations/%s", "value": %s}`,
+		strings.ReplaceAll(util.AnnotationVolumeClaimTemplates, "/", "~1"), value))
+	return patchOps, nil
+}
+
 func (m *vmMutator) getOvercommit() (*settings.Overcommit, error) {
 	s, err := m.setting.Get("overcommit-config")
 	if err != nil {
@@ -3,6 +3,7 @@ package virtualmachine
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -12,7 +13,9 @@ import (
 
 	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	"github.com/harvester/harvester/pkg/generated/clientset/versioned/fake"
+	"github.com/harvester/harvester/pkg/util"
 	"github.com/harvester/harvester/pkg/util/fakeclients"
+	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
 func Test_virtualmachine_mutator(t *testing.T) {
@@ -127,7 +130,8 @@ func Test_virtualmachine_mutator(t *testing.T) {
 			}
 			err := clientset.Tracker().Add(settingCpy)
 			assert.Nil(t, err, "Mock resource should add into fake controller tracker")
-			mutator := NewMutator(fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings))
+			mutator := NewMutator(fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings),
+				fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages))
 			vm := &kubevirtv1.VirtualMachine{
 				Spec: kubevirtv1.VirtualMachineSpec{
 					Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
@@ -151,3 +155,149 @@ func Test_virtualmachine_mutator(t *testing.T) {
 		})
 	}
 }
+
+func Test_virtualmachine_mutator_patchImageFamilies(t *testing.T) {
+	base := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	newVersion := func(name string, created time.Time, deprecated bool) *harvesterv1.VirtualMachineImage {
+		image := &harvesterv1.VirtualMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       harvesterv1.VirtualMachineImageSpec{Family: "ubuntu", Version: name, Deprecated: deprecated},
+			Status:     harvesterv1.VirtualMachineImageStatus{StorageClassName: "longhorn-" + name},
+		}
+		harvesterv1.ImageImported.True(image)
+		return image
+	}
+
+	tests := []struct {
+		name          string
+		images        []*harvesterv1.VirtualMachineImage
+		pvcs          string
+		expectedImage string
+		expectError   bool
+	}{
+		{
+			name:   "no image family",
+			images: []*harvesterv1.VirtualMachineImage{newVersion("v1", base, false)},
+			pvcs:   `[{"metadata":{"name":"disk-0","annotations":{"harvesterhci.io/imageId":"default/v1"}}}]`,
+		},
+		{
+			name:          "latest version",
+			images:        []*harvesterv1.VirtualMachineImage{newVersion("v1", base, false), newVersion("v2", base.Add(time.Hour), false)},
+			pvcs:          `[{"metadata":{"name":"disk-0","annotations":{"harvesterhci.io/imageFamily":"ubuntu"}}}]`,
+			expectedImage: "default/v2",
+		},
+		{
+			name:          "deprecated latest version",
+			images:        []*harvesterv1.VirtualMachineImage{newVersion("v1", base, false), newVersion("v2", base.Add(time.Hour), true)},
+			pvcs:          `[{"metadata":{"name":"disk-0","annotations":{"harvesterhci.io/imageFamily":"default/ubuntu"}}}]`,
+			expectedImage: "default/v1",
+		},
+		{
+			name:        "no ready version",
+			images:      []*harvesterv1.VirtualMachineImage{newVersion("v1", base, true)},
+			pvcs:        `[{"metadata":{"name":"disk-0","annotations":{"harvesterhci.io/imageFamily":"ubuntu"}}}]`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, image := range tc.images {
+				assert.Nil(t, clientset.Tracker().Add(image), "Mock resource should add into fake controller tracker")
+			}
+			mutator := NewMutator(fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings),
+				fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages))
+			vm := &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "vm",
+					Annotations: map[string]string{util.AnnotationVolumeClaimTemplates: tc.pvcs},
+				},
+			}
+
+			actual, err := mutator.(*vmMutator).patchImageFamilies(vm)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if tc.expectedImage == "" {
+				assert.Empty(t, actual)
+				return
+			}
+			assert.Len(t, actual, 1)
+			assert.Contains(t, actual[0], `/metadata/annotations/harvesterhci.io~1volumeClaimTemplates`)
+			assert.Contains(t, actual[0], `\"harvesterhci.io/imageId\":\"`+tc.expectedImage+`\"`)
+		})
+	}
+}
+
+func Test_virtualmachine_mutator_resize(t *testing.T) {
+	newVM := func(cpu, memory string) *kubevirtv1.VirtualMachine {
+		return &kubevirtv1.VirtualMachine{
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Domain: kubevirtv1.DomainSpec{
+							Resources: kubevirtv1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse(cpu),
+									v1.ResourceMemory: resource.MustParse(memory),
+								},
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("500m"),
+									v1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+							},
+							Memory: &kubevirtv1.Memory{Guest: resource.NewQuantity(int64(math.Pow(2, 30))-104857600, resource.BinarySI)},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		oldVM    *kubevirtv1.VirtualMachine
+		newVM    *kubevirtv1.VirtualMachine
+		patchOps []string
+	}{
+		{
+			name:     "not resized",
+			oldVM:    newVM("1", "1Gi"),
+			newVM:    newVM("1", "1Gi"),
+			patchOps: nil,
+		},
+		{
+			name:  "resized",
+			oldVM: newVM("1", "1Gi"),
+			newVM: newVM("2", "2Gi"),
+			patchOps: []string{
+				`{"op": "replace", "path": "/spec/template/spec/domain/resources/requests/cpu", "value": "1"}`,
+				`{"op": "replace", "path": "/spec/template/spec/domain/resources/requests/memory", "value": "512Mi"}`,
+				`{"op": "replace", "path": "/spec/template/spec/domain/memory/guest", "value": "1948Mi"}`, // 2Gi - 100Mi
+			},
+		},
+	}
+
+	setting := &harvesterv1.Setting{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "overcommit-config",
+		},
+		Default: `{"cpu":200,"memory":400,"storage":800}`,
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			assert.Nil(t, clientset.Tracker().Add(setting.DeepCopy()), "Mock resource should add into fake controller tracker")
+			mutator := NewMutator(fakeclients.HarvesterSettingCache(clientset.HarvesterhciV1beta1().Settings),
+				fakeclients.VirtualMachineImageCache(clientset.HarvesterhciV1beta1().VirtualMachineImages))
+
+			actual, err := mutator.Update(nil, tc.oldVM, tc.newVM)
+			assert.Nil(t, err)
+			assert.Equal(t, types.PatchOps(tc.patchOps), actual)
+		})
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/indexeres"
 	"github.com/harvester/harvester/pkg/ref"
 	"github.com/harvester/harvester/pkg/util"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
@@ -24,10 +26,12 @@ import (
 func NewValidator(
 	pvcCache v1.PersistentVolumeClaimCache,
 	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache,
+	vmImageCache ctlharvesterv1.VirtualMachineImageCache,
 ) types.Validator {
 	return &vmValidator{
 		pvcCache:      pvcCache,
 		vmBackupCache: vmBackupCache,
+		vmImageCache:  vmImageCache,
 	}
 }
 
@@ -35,6 +39,7 @@ type vmValidator struct {
 	types.DefaultValidator
 	pvcCache      v1.PersistentVolumeClaimCache
 	vmBackupCache ctlharvesterv1.VirtualMachineBackupCache
+	vmImageCache  ctlharvesterv1.VirtualMachineImageCache
 }
 
 func (v *vmValidator) Resource() types.Resource {
@@ -60,7 +65,7 @@ func (v *vmValidator) Create(request *types.Request, newObj runtime.Object) erro
 	if err := v.checkVMSpec(vm); err != nil {
 		return err
 	}
-	return nil
+	return v.checkImageSharing(nil, vm)
 }
 
 func (v *vmValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
@@ -78,6 +83,10 @@ func (v *vmValidator) Update(request *types.Request, oldObj runtime.Object, newO
 		return nil
 	}
 
+	if err := v.checkImageSharing(oldVM, newVM); err != nil {
+		return err
+	}
+
 	// Prevent users to stop/restart VM when there is VMBackup in progress.
 	if v.checkVMStoppingStatus(oldVM, newVM) {
 		if err := v.checkVMBackup(newVM); err != nil {
@@ -138,6 +147,68 @@ func (v *vmValidator) checkVolumeClaimTemplatesAnnotation(vm *kubevirtv1.Virtual
 	return nil
 }
 
+// checkImageSharing rejects the volumes created from the images not shared with the namespace of the VM,
+// the images already used by the old VM are allowed in case the image is no longer shared.
+func (v *vmValidator) checkImageSharing(oldVM, newVM *kubevirtv1.VirtualMachine) error {
+	newImages, err := v.getImagesOfVolumeClaimTemplates(newVM)
+	if err != nil {
+		return err
+	}
+	oldImages := map[string]*harvesterv1.VirtualMachineImage{}
+	if oldVM != nil {
+		if oldImages, err = v.getImagesOfVolumeClaimTemplates(oldVM); err != nil {
+			return err
+		}
+	}
+
+	for imageID, image := range newImages {
+		if _, ok := oldImages[imageID]; ok || image == nil {
+			continue
+		}
+		if !util.IsImageSharedWith(image, newVM.Namespace) {
+			message := fmt.Sprintf("image %s/%s is not shared with namespace %s", image.Namespace, image.Spec.DisplayName, newVM.Namespace)
+			return werror.NewInvalidError(message, "metadata.annotations")
+		}
+	}
+	return nil
+}
+
+// getImagesOfVolumeClaimTemplates returns the images referenced by the imageId annotation or the storage class
+// of the PVCs in the volumeClaimTemplates annotation, the image is nil if it doesn't exist
+func (v *vmValidator) getImagesOfVolumeClaimTemplates(vm *kubevirtv1.VirtualMachine) (map[string]*harvesterv1.VirtualMachineImage, error) {
+	images := map[string]*harvesterv1.VirtualMachineImage{}
+	volumeClaimTemplates, ok := vm.Annotations[util.AnnotationVolumeClaimTemplates]
+	if !ok || volumeClaimTemplates == "" {
+		return images, nil
+	}
+	var pvcs []*corev1.PersistentVolumeClaim
+	if err := json.Unmarshal([]byte(volumeClaimTemplates), &pvcs); err != nil {
+		return nil, err
+	}
+
+	for _, pvc := range pvcs {
+		if imageID := pvc.Annotations[util.AnnotationImageID]; imageID != "" {
+			namespace, name := ref.Parse(imageID)
+			image, err := v.vmImageCache.Get(namespace, name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			images[imageID] = image
+		}
+		if pvc.Spec.StorageClassName == nil {
+			continue
+		}
+		scImages, err := v.vmImageCache.GetByIndex(indexeres.ImageByStorageClassIndex, *pvc.Spec.StorageClassName)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range scImages {
+			images[ref.Construct(image.Namespace, image.Name)] = image
+		}
+	}
+	return images, nil
+}
+
 func (v *vmValidator) checkOccupiedPVCs(vm *kubevirtv1.VirtualMachine) error {
 	vmID := ref.Construct(vm.Namespace, vm.Name)
 	for _, volume := range vm.Spec.Template.Spec.Volumes {
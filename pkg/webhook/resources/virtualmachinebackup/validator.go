@@ -0,0 +1,112 @@
+package virtualmachinebackup
+
+import (
+	"fmt"
+	"reflect"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldQuiesce = "spec.quiesce"
+)
+
+func NewValidator(sar authorizationv1client.SubjectAccessReviewInterface) types.Validator {
+	return &virtualMachineBackupValidator{
+		sar: sar,
+	}
+}
+
+type virtualMachineBackupValidator struct {
+	types.DefaultValidator
+	sar authorizationv1client.SubjectAccessReviewInterface
+}
+
+func (v *virtualMachineBackupValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.VirtualMachineBackupResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.VirtualMachineBackup{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *virtualMachineBackupValidator) Create(request *types.Request, newObj runtime.Object) error {
+	return v.checkQuiesceHooks(request, nil, newObj.(*v1beta1.VirtualMachineBackup))
+}
+
+func (v *virtualMachineBackupValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	return v.checkQuiesceHooks(request, oldObj.(*v1beta1.VirtualMachineBackup), newObj.(*v1beta1.VirtualMachineBackup))
+}
+
+// checkQuiesceHooks requires the permission to update the source VM to set the quiesce hooks, since the hooks are
+// executed as root in the guest through the qemu guest agent
+func (v *virtualMachineBackupValidator) checkQuiesceHooks(request *types.Request, oldBackup, newBackup *v1beta1.VirtualMachineBackup) error {
+	newPreHook, newPostHook := getQuiesceHooks(newBackup)
+	if newPreHook == nil && newPostHook == nil {
+		return nil
+	}
+	if oldBackup != nil {
+		oldPreHook, oldPostHook := getQuiesceHooks(oldBackup)
+		if reflect.DeepEqual(oldPreHook, newPreHook) && reflect.DeepEqual(oldPostHook, newPostHook) {
+			return nil
+		}
+	}
+
+	sar, err := v.sar.Create(request.Context, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: newBackup.Namespace,
+				Verb:      "update",
+				Group:     kubevirtv1.SchemeGroupVersion.Group,
+				Resource:  "virtualmachines",
+				Name:      newBackup.Spec.Source.Name,
+			},
+			User:   request.UserInfo.Username,
+			Groups: request.UserInfo.Groups,
+			Extra:  getExtra(request),
+			UID:    request.UserInfo.UID,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("failed to check user permission, error: %s", err.Error()), fieldQuiesce)
+	}
+	if !sar.Status.Allowed || sar.Status.Denied {
+		message := fmt.Sprintf("user %s has no permission to update the VM %s/%s, which is required to run quiesce hooks in its guest",
+			request.UserInfo.Username, newBackup.Namespace, newBackup.Spec.Source.Name)
+		return werror.NewInvalidError(message, fieldQuiesce)
+	}
+	return nil
+}
+
+func getQuiesceHooks(vmBackup *v1beta1.VirtualMachineBackup) (preHook, postHook *v1beta1.BackupHook) {
+	if vmBackup.Spec.Quiesce == nil {
+		return nil, nil
+	}
+	return vmBackup.Spec.Quiesce.PreHook, vmBackup.Spec.Quiesce.PostHook
+}
+
+func getExtra(request *types.Request) map[string]authorizationv1.ExtraValue {
+	if len(request.UserInfo.Extra) == 0 {
+		return nil
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(request.UserInfo.Extra))
+	for k, v := range request.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	return extra
+}
@@ -0,0 +1,111 @@
+package virtualmachinebackup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher/wrangler/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func TestCheckQuiesceHooks(t *testing.T) {
+	newBackup := func(quiesce *harvesterv1.BackupQuiesce) *harvesterv1.VirtualMachineBackup {
+		return &harvesterv1.VirtualMachineBackup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+			Spec: harvesterv1.VirtualMachineBackupSpec{
+				Source:  corev1.TypedLocalObjectReference{Kind: "VirtualMachine", Name: "vm"},
+				Quiesce: quiesce,
+			},
+		}
+	}
+	hook := &harvesterv1.BackupHook{Command: []string{"sync"}}
+
+	var testCases = []struct {
+		name          string
+		oldBackup     *harvesterv1.VirtualMachineBackup
+		newBackup     *harvesterv1.VirtualMachineBackup
+		allowed       bool
+		expectedCheck bool
+		expectedError bool
+	}{
+		{
+			name:      "no quiesce",
+			newBackup: newBackup(nil),
+		},
+		{
+			name:      "quiesce without hooks",
+			newBackup: newBackup(&harvesterv1.BackupQuiesce{}),
+		},
+		{
+			name:          "hooks set by a user who can update the VM",
+			newBackup:     newBackup(&harvesterv1.BackupQuiesce{PreHook: hook}),
+			allowed:       true,
+			expectedCheck: true,
+		},
+		{
+			name:          "hooks set by a user who can't update the VM",
+			newBackup:     newBackup(&harvesterv1.BackupQuiesce{PostHook: hook}),
+			expectedCheck: true,
+			expectedError: true,
+		},
+		{
+			name:      "hooks unchanged on update",
+			oldBackup: newBackup(&harvesterv1.BackupQuiesce{PreHook: hook}),
+			newBackup: newBackup(&harvesterv1.BackupQuiesce{PreHook: hook}),
+		},
+		{
+			name:          "hooks changed on update",
+			oldBackup:     newBackup(&harvesterv1.BackupQuiesce{PreHook: hook}),
+			newBackup:     newBackup(&harvesterv1.BackupQuiesce{PreHook: hook, PostHook: hook}),
+			expectedCheck: true,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		var reviews []*authorizationv1.SubjectAccessReview
+		clientSet := fake.NewSimpleClientset()
+		clientSet.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			sar := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			reviews = append(reviews, sar)
+			sar.Status.Allowed = tc.allowed
+			return true, sar, nil
+		})
+		validator := NewValidator(clientSet.AuthorizationV1().SubjectAccessReviews()).(*virtualMachineBackupValidator)
+		request := types.NewRequest(&webhook.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: "alice", Groups: []string{"users"}},
+			},
+			Context: context.Background(),
+		}, nil)
+
+		err := validator.checkQuiesceHooks(request, tc.oldBackup, tc.newBackup)
+		assert.Equal(t, tc.expectedError, err != nil, tc.name)
+		if !tc.expectedCheck {
+			assert.Empty(t, reviews, tc.name)
+			continue
+		}
+		if assert.Len(t, reviews, 1, tc.name) {
+			assert.Equal(t, "alice", reviews[0].Spec.User, tc.name)
+			assert.Equal(t, []string{"users"}, reviews[0].Spec.Groups, tc.name)
+			assert.Equal(t, &authorizationv1.ResourceAttributes{
+				Namespace: "default",
+				Verb:      "update",
+				Group:     "kubevirt.io",
+				Resource:  "virtualmachines",
+				Name:      "vm",
+			}, reviews[0].Spec.ResourceAttributes, tc.name)
+		}
+	}
+}
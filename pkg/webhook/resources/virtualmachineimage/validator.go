@@ -2,40 +2,73 @@ package virtualmachineimage
 
 import (
 	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
 
 	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 
 	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
 	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/indexeres"
+	"github.com/harvester/harvester/pkg/ref"
+	"github.com/harvester/harvester/pkg/util"
+	"github.com/harvester/harvester/pkg/util/checksum"
+	"github.com/harvester/harvester/pkg/util/oci"
 	werror "github.com/harvester/harvester/pkg/webhook/error"
 	"github.com/harvester/harvester/pkg/webhook/types"
 )
 
 const (
-	fieldDisplayName = "spec.displayName"
+	fieldDisplayName            = "spec.displayName"
+	fieldStorageClassParameters = "spec.storageClassParameters"
+	fieldOCI                    = "spec.oci"
+	fieldBackupTarget           = "spec.backupTarget"
+	fieldChecksum               = "spec.checksum"
+	fieldChecksumURL            = "spec.checksumUrl"
+	fieldMirrors                = "spec.mirrors"
+	fieldBandwidthLimit         = "spec.bandwidthLimit"
+	fieldSharing                = "spec.sharing"
+	fieldFamily                 = "spec.family"
+	fieldVersion                = "spec.version"
 )
 
-func NewValidator(vmimages ctlharvesterv1.VirtualMachineImageCache, pvcCache ctlcorev1.PersistentVolumeClaimCache, ssar authorizationv1client.SelfSubjectAccessReviewInterface) types.Validator {
+func NewValidator(
+	vmimages ctlharvesterv1.VirtualMachineImageCache,
+	pvcCache ctlcorev1.PersistentVolumeClaimCache,
+	vmCache ctlkubevirtv1.VirtualMachineCache,
+	templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache,
+	ssar authorizationv1client.SelfSubjectAccessReviewInterface,
+) types.Validator {
+	pvcCache.AddIndexer(indexeres.PVCByStorageClassIndex, indexeres.PVCByStorageClass)
+	vmCache.AddIndexer(indexeres.VMByImageIndex, indexeres.VMByImage)
+	templateVersionCache.AddIndexer(indexeres.TemplateVersionByImageIndex, indexeres.TemplateVersionByImage)
 	return &virtualMachineImageValidator{
-		vmimages: vmimages,
-		pvcCache: pvcCache,
-		ssar:     ssar,
+		vmimages:             vmimages,
+		pvcCache:             pvcCache,
+		vmCache:              vmCache,
+		templateVersionCache: templateVersionCache,
+		ssar:                 ssar,
 	}
 }
 
 type virtualMachineImageValidator struct {
 	types.DefaultValidator
 
-	vmimages ctlharvesterv1.VirtualMachineImageCache
-	pvcCache ctlcorev1.PersistentVolumeClaimCache
-	ssar     authorizationv1client.SelfSubjectAccessReviewInterface
+	vmimages             ctlharvesterv1.VirtualMachineImageCache
+	pvcCache             ctlcorev1.PersistentVolumeClaimCache
+	vmCache              ctlkubevirtv1.VirtualMachineCache
+	templateVersionCache ctlharvesterv1.VirtualMachineTemplateVersionCache
+	ssar                 authorizationv1client.SelfSubjectAccessReviewInterface
 }
 
 func (v *virtualMachineImageValidator) Resource() types.Resource {
@@ -59,6 +92,34 @@ func (v *virtualMachineImageValidator) Create(request *types.Request, newObj run
 		return err
 	}
 
+	if err := util.ValidateImageStorageClassParameters(newImage.Spec.StorageClassParameters); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldStorageClassParameters)
+	}
+
+	if err := checkImageOCISource(newImage); err != nil {
+		return err
+	}
+
+	if err := checkImageBackupTargetSource(newImage); err != nil {
+		return err
+	}
+
+	if err := checkImageChecksum(newImage); err != nil {
+		return err
+	}
+
+	if err := checkImageDownloadOptions(newImage); err != nil {
+		return err
+	}
+
+	if err := v.checkImageSharing(request, nil, newImage); err != nil {
+		return err
+	}
+
+	if err := v.checkImageFamily(newImage); err != nil {
+		return err
+	}
+
 	return v.CheckImagePVC(request, newImage)
 }
 
@@ -91,6 +152,114 @@ func (v *virtualMachineImageValidator) CheckImageDisplayNameAndURL(newImage *v1b
 	return nil
 }
 
+// checkImageFamily requires a version for the image in a family, the version is unique in the family
+func (v *virtualMachineImageValidator) checkImageFamily(newImage *v1beta1.VirtualMachineImage) error {
+	if newImage.Spec.Family == "" {
+		if newImage.Spec.Version != "" {
+			return werror.NewInvalidError("version should be empty when family is not set", fieldVersion)
+		}
+		return nil
+	}
+	if errs := validation.IsDNS1123Label(newImage.Spec.Family); len(errs) > 0 {
+		return werror.NewInvalidError(fmt.Sprintf("invalid family %q: %s", newImage.Spec.Family, strings.Join(errs, ", ")), fieldFamily)
+	}
+	if newImage.Spec.Version == "" {
+		return werror.NewInvalidError("version is required when family is set", fieldVersion)
+	}
+
+	images, err := v.vmimages.List(newImage.Namespace, labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		if image.Name != newImage.Name && image.Spec.Family == newImage.Spec.Family && image.Spec.Version == newImage.Spec.Version {
+			return werror.NewConflict(fmt.Sprintf("version %s of image family %s already exists", newImage.Spec.Version, newImage.Spec.Family))
+		}
+	}
+	return nil
+}
+
+func checkImageOCISource(newImage *v1beta1.VirtualMachineImage) error {
+	source := newImage.Spec.OCI
+	if newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeOCI {
+		if source != nil {
+			return werror.NewInvalidError(`oci should be empty when image source type is not "oci"`, fieldOCI)
+		}
+		return nil
+	}
+
+	if source == nil || source.Reference == "" {
+		return werror.NewInvalidError(`oci reference is required when image source type is "oci"`, fieldOCI)
+	}
+	if _, err := oci.ParseReference(source.Reference, source.Digest); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldOCI)
+	}
+	return nil
+}
+
+func checkImageBackupTargetSource(newImage *v1beta1.VirtualMachineImage) error {
+	source := newImage.Spec.BackupTarget
+	if newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeBackupTarget {
+		if source != nil {
+			return werror.NewInvalidError(`backupTarget should be empty when image source type is not "backup-target"`, fieldBackupTarget)
+		}
+		return nil
+	}
+
+	if source == nil || source.Name == "" || source.Path == "" {
+		return werror.NewInvalidError(`backupTarget name and path are required when image source type is "backup-target"`, fieldBackupTarget)
+	}
+	return nil
+}
+
+func checkImageChecksum(newImage *v1beta1.VirtualMachineImage) error {
+	if newImage.Spec.ChecksumURL != "" {
+		if newImage.Spec.Checksum != "" {
+			return werror.NewInvalidError("checksum and checksumUrl can't be both set", fieldChecksumURL)
+		}
+		if newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeDownload &&
+			newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeUpload {
+			return werror.NewInvalidError(`checksumUrl is only supported when image source type is "download" or "upload"`, fieldChecksumURL)
+		}
+		if !isHTTPURL(newImage.Spec.ChecksumURL) {
+			return werror.NewInvalidError("checksumUrl must be a http or https URL", fieldChecksumURL)
+		}
+	}
+	if newImage.Spec.Checksum != "" {
+		if _, err := checksum.Parse(newImage.Spec.Checksum); err != nil {
+			return werror.NewInvalidError(err.Error(), fieldChecksum)
+		}
+	}
+	return nil
+}
+
+func checkImageDownloadOptions(newImage *v1beta1.VirtualMachineImage) error {
+	if newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeDownload {
+		if len(newImage.Spec.Mirrors) > 0 {
+			return werror.NewInvalidError(`mirrors should be empty when image source type is not "download"`, fieldMirrors)
+		}
+		if newImage.Spec.BandwidthLimit != "" {
+			return werror.NewInvalidError(`bandwidthLimit should be empty when image source type is not "download"`, fieldBandwidthLimit)
+		}
+		return nil
+	}
+
+	for _, mirror := range newImage.Spec.Mirrors {
+		if !isHTTPURL(mirror) {
+			return werror.NewInvalidError(fmt.Sprintf("mirror %q must be a http or https URL", mirror), fieldMirrors)
+		}
+	}
+	if _, err := util.ParseBandwidthLimit(newImage.Spec.BandwidthLimit); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldBandwidthLimit)
+	}
+	return nil
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func (v *virtualMachineImageValidator) CheckImagePVC(request *types.Request, newImage *v1beta1.VirtualMachineImage) error {
 	if newImage.Spec.SourceType != v1beta1.VirtualMachineImageSourceTypeExportVolume {
 		return nil
@@ -103,34 +272,135 @@ func (v *virtualMachineImageValidator) CheckImagePVC(request *types.Request, new
 		return werror.NewInvalidError(`pvcName is required when image source type is "export-from-volume"`, "spec.pvcName")
 	}
 
+	allowed, err := v.checkPermission(request, &authorizationv1.ResourceAttributes{
+		Namespace: newImage.Spec.PVCNamespace,
+		Verb:      "get",
+		Group:     "",
+		Version:   "*",
+		Resource:  "persistentvolumeclaims",
+		Name:      newImage.Spec.PVCName,
+	})
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		message := fmt.Sprintf("user has no permission to get the pvc resource %s/%s", newImage.Spec.PVCName, newImage.Spec.PVCNamespace)
+		return werror.NewInvalidError(message, "")
+	}
+
+	_, err = v.pvcCache.Get(newImage.Spec.PVCNamespace, newImage.Spec.PVCName)
+	if err != nil {
+		message := fmt.Sprintf("failed to get pvc %s/%s, error: %s", newImage.Spec.PVCName, newImage.Spec.PVCNamespace, err.Error())
+		return werror.NewInvalidError(message, "")
+	}
+
+	return nil
+}
+
+func (v *virtualMachineImageValidator) checkPermission(request *types.Request, attributes *authorizationv1.ResourceAttributes) (bool, error) {
 	ssar, err := v.ssar.Create(request.Context, &authorizationv1.SelfSubjectAccessReview{
 		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authorizationv1.ResourceAttributes{
-				Namespace: newImage.Spec.PVCNamespace,
-				Verb:      "get",
-				Group:     "",
-				Version:   "*",
-				Resource:  "persistentvolumeclaims",
-				Name:      newImage.Spec.PVCName,
-			},
+			ResourceAttributes: attributes,
 		},
 	}, metav1.CreateOptions{})
 	if err != nil {
 		message := fmt.Sprintf("failed to check user permission, error: %s", err.Error())
-		return werror.NewInvalidError(message, "")
+		return false, werror.NewInvalidError(message, "")
 	}
+	return ssar.Status.Allowed && !ssar.Status.Denied, nil
+}
 
-	if !ssar.Status.Allowed || ssar.Status.Denied {
-		message := fmt.Sprintf("user has no permission to get the pvc resource %s/%s", newImage.Spec.PVCName, newImage.Spec.PVCNamespace)
-		return werror.NewInvalidError(message, "")
+// checkImageSharing requires the permission to create images in the namespaces the image is newly shared with,
+// and prevents stopping sharing the image with the namespaces still using it
+func (v *virtualMachineImageValidator) checkImageSharing(request *types.Request, oldImage, newImage *v1beta1.VirtualMachineImage) error {
+	oldSharing := &v1beta1.VirtualMachineImageSharing{}
+	if oldImage != nil && oldImage.Spec.Sharing != nil {
+		oldSharing = oldImage.Spec.Sharing
+	}
+	newSharing := &v1beta1.VirtualMachineImageSharing{}
+	if newImage.Spec.Sharing != nil {
+		newSharing = newImage.Spec.Sharing
+	}
+	if reflect.DeepEqual(oldSharing, newSharing) {
+		return nil
 	}
 
-	_, err = v.pvcCache.Get(newImage.Spec.PVCNamespace, newImage.Spec.PVCName)
+	namespaces := sets.NewString()
+	for _, namespace := range newSharing.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", ")), fieldSharing)
+		}
+		if namespace == newImage.Namespace {
+			return werror.NewInvalidError(fmt.Sprintf("the image can't be shared with its own namespace %s", namespace), fieldSharing)
+		}
+		if namespaces.Has(namespace) {
+			return werror.NewInvalidError(fmt.Sprintf("namespace %s is duplicated", namespace), fieldSharing)
+		}
+		namespaces.Insert(namespace)
+	}
+
+	if newSharing.ClusterWide && !oldSharing.ClusterWide {
+		if err := v.checkSharingPermission(request, metav1.NamespaceAll); err != nil {
+			return err
+		}
+	}
+	for _, namespace := range namespaces.Difference(sets.NewString(oldSharing.Namespaces...)).List() {
+		if err := v.checkSharingPermission(request, namespace); err != nil {
+			return err
+		}
+	}
+
+	if oldImage == nil {
+		return nil
+	}
+	return v.checkImageSharingRevoked(newImage)
+}
+
+func (v *virtualMachineImageValidator) checkSharingPermission(request *types.Request, namespace string) error {
+	allowed, err := v.checkPermission(request, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "create",
+		Group:     v1beta1.SchemeGroupVersion.Group,
+		Version:   "*",
+		Resource:  v1beta1.VirtualMachineImageResourceName,
+	})
 	if err != nil {
-		message := fmt.Sprintf("failed to get pvc %s/%s, error: %s", newImage.Spec.PVCName, newImage.Spec.PVCNamespace, err.Error())
-		return werror.NewInvalidError(message, "")
+		return err
+	}
+	if allowed {
+		return nil
+	}
+	if namespace == metav1.NamespaceAll {
+		return werror.NewInvalidError("user has no permission to share the image with all the namespaces", fieldSharing)
+	}
+	return werror.NewInvalidError(fmt.Sprintf("user has no permission to share the image with namespace %s", namespace), fieldSharing)
+}
+
+// checkImageSharingRevoked rejects the sharing that removes the namespaces of the volumes and the VMs using the image
+func (v *virtualMachineImageValidator) checkImageSharingRevoked(newImage *v1beta1.VirtualMachineImage) error {
+	if newImage.Status.StorageClassName != "" {
+		pvcs, err := v.pvcCache.GetByIndex(indexeres.PVCByStorageClassIndex, newImage.Status.StorageClassName)
+		if err != nil {
+			return err
+		}
+		for _, pvc := range pvcs {
+			if !util.IsImageSharedWith(newImage, pvc.Namespace) {
+				message := fmt.Sprintf("Cannot stop sharing the image with namespace %s: being used by volume %s/%s", pvc.Namespace, pvc.Namespace, pvc.Name)
+				return werror.NewInvalidError(message, fieldSharing)
+			}
+		}
 	}
 
+	vms, err := v.vmCache.GetByIndex(indexeres.VMByImageIndex, ref.Construct(newImage.Namespace, newImage.Name))
+	if err != nil {
+		return err
+	}
+	for _, vm := range vms {
+		if !util.IsImageSharedWith(newImage, vm.Namespace) {
+			message := fmt.Sprintf("Cannot stop sharing the image with namespace %s: being used by virtual machine %s/%s", vm.Namespace, vm.Namespace, vm.Name)
+			return werror.NewInvalidError(message, fieldSharing)
+		}
+	}
 	return nil
 }
 
@@ -155,25 +425,84 @@ func (v *virtualMachineImageValidator) Update(request *types.Request, oldObj run
 		}
 	}
 
+	if !reflect.DeepEqual(oldImage.Spec.OCI, newImage.Spec.OCI) {
+		return werror.NewInvalidError("oci cannot be modified", fieldOCI)
+	}
+
+	if !reflect.DeepEqual(oldImage.Spec.BackupTarget, newImage.Spec.BackupTarget) {
+		return werror.NewInvalidError("backupTarget cannot be modified", fieldBackupTarget)
+	}
+
+	// the checksum is verified on import, it's changed along with the url to import another file
+	if oldImage.Spec.URL == newImage.Spec.URL {
+		if oldImage.Spec.Checksum != newImage.Spec.Checksum {
+			return werror.NewInvalidError("checksum cannot be modified without changing the url", fieldChecksum)
+		}
+		if oldImage.Spec.ChecksumURL != newImage.Spec.ChecksumURL {
+			return werror.NewInvalidError("checksumUrl cannot be modified without changing the url", fieldChecksumURL)
+		}
+	}
+	if err := checkImageChecksum(newImage); err != nil {
+		return err
+	}
+
+	// the mirrors and the bandwidth limit apply to the next download, e.g. after the url is changed
+	if err := checkImageDownloadOptions(newImage); err != nil {
+		return err
+	}
+
+	// the storage class parameters can be changed, the new ones apply to the volumes created afterwards
+	if err := util.ValidateImageStorageClassParameters(newImage.Spec.StorageClassParameters); err != nil {
+		return werror.NewInvalidError(err.Error(), fieldStorageClassParameters)
+	}
+
+	if err := v.checkImageSharing(request, oldImage, newImage); err != nil {
+		return err
+	}
+
+	// the VMs record the version they're created from by the image, so a version can only be deprecated
+	if oldImage.Spec.Family != newImage.Spec.Family {
+		return werror.NewInvalidError("family cannot be modified", fieldFamily)
+	}
+	if oldImage.Spec.Version != newImage.Spec.Version {
+		return werror.NewInvalidError("version cannot be modified", fieldVersion)
+	}
+
 	return v.CheckImageDisplayNameAndURL(newImage)
 }
 
 func (v *virtualMachineImageValidator) Delete(request *types.Request, oldObj runtime.Object) error {
 	image := oldObj.(*v1beta1.VirtualMachineImage)
+	imageID := ref.Construct(image.Namespace, image.Name)
 
-	if image.Status.StorageClassName == "" {
-		return nil
+	if image.Status.StorageClassName != "" {
+		pvcs, err := v.pvcCache.GetByIndex(indexeres.PVCByStorageClassIndex, image.Status.StorageClassName)
+		if err != nil {
+			return err
+		}
+		if len(pvcs) > 0 {
+			message := fmt.Sprintf("Cannot delete image %s/%s: being used by volume %s/%s", image.Namespace, image.Spec.DisplayName, pvcs[0].Namespace, pvcs[0].Name)
+			return werror.NewInvalidError(message, "")
+		}
 	}
-	pvcs, err := v.pvcCache.List(corev1.NamespaceAll, labels.Everything())
+
+	// the volumes of the VMs may not be created yet
+	vms, err := v.vmCache.GetByIndex(indexeres.VMByImageIndex, imageID)
 	if err != nil {
 		return err
 	}
+	if len(vms) > 0 {
+		message := fmt.Sprintf("Cannot delete image %s/%s: being used by virtual machine %s/%s", image.Namespace, image.Spec.DisplayName, vms[0].Namespace, vms[0].Name)
+		return werror.NewInvalidError(message, "")
+	}
 
-	for _, pvc := range pvcs {
-		if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == image.Status.StorageClassName {
-			message := fmt.Sprintf("Cannot delete image %s/%s: being used by volume %s/%s", image.Namespace, image.Spec.DisplayName, pvc.Namespace, pvc.Name)
-			return werror.NewInvalidError(message, "")
-		}
+	templateVersions, err := v.templateVersionCache.GetByIndex(indexeres.TemplateVersionByImageIndex, imageID)
+	if err != nil {
+		return err
+	}
+	if len(templateVersions) > 0 {
+		message := fmt.Sprintf("Cannot delete image %s/%s: being used by template version %s/%s", image.Namespace, image.Spec.DisplayName, templateVersions[0].Namespace, templateVersions[0].Name)
+		return werror.NewInvalidError(message, "")
 	}
 
 	return nil
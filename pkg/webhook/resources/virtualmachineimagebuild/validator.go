@@ -0,0 +1,164 @@
+package virtualmachineimagebuild
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/controller/master/imagebuild"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+const (
+	fieldSpec             = "spec"
+	fieldBaseImage        = "spec.baseImage"
+	fieldUserData         = "spec.userData"
+	fieldNetworkName      = "spec.networkName"
+	fieldCPU              = "spec.cpu"
+	fieldMemory           = "spec.memory"
+	fieldDiskSize         = "spec.diskSize"
+	fieldTimeout          = "spec.timeout"
+	fieldImageDisplayName = "spec.image.displayName"
+	fieldImageFamily      = "spec.image.family"
+)
+
+func NewValidator(vmimages ctlharvesterv1.VirtualMachineImageCache) types.Validator {
+	return &virtualMachineImageBuildValidator{
+		vmimages: vmimages,
+	}
+}
+
+type virtualMachineImageBuildValidator struct {
+	types.DefaultValidator
+
+	vmimages ctlharvesterv1.VirtualMachineImageCache
+}
+
+func (v *virtualMachineImageBuildValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{v1beta1.VirtualMachineImageBuildResourceName},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   v1beta1.SchemeGroupVersion.Group,
+		APIVersion: v1beta1.SchemeGroupVersion.Version,
+		ObjectType: &v1beta1.VirtualMachineImageBuild{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (v *virtualMachineImageBuildValidator) Create(request *types.Request, newObj runtime.Object) error {
+	build := newObj.(*v1beta1.VirtualMachineImageBuild)
+	if err := v.checkBaseImage(build); err != nil {
+		return err
+	}
+	if err := checkSpec(build); err != nil {
+		return err
+	}
+	return v.checkImageDisplayName(build)
+}
+
+// Update denies changing the spec, the build is done once with the spec it's created with
+func (v *virtualMachineImageBuildValidator) Update(request *types.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	oldBuild := oldObj.(*v1beta1.VirtualMachineImageBuild)
+	newBuild := newObj.(*v1beta1.VirtualMachineImageBuild)
+	if !reflect.DeepEqual(oldBuild.Spec, newBuild.Spec) {
+		return werror.NewInvalidError("spec of an image build is immutable", fieldSpec)
+	}
+	return nil
+}
+
+func (v *virtualMachineImageBuildValidator) checkBaseImage(build *v1beta1.VirtualMachineImageBuild) error {
+	if build.Spec.BaseImage == "" {
+		return werror.NewInvalidError("baseImage is required", fieldBaseImage)
+	}
+	namespace, name := imagebuild.GetBaseImage(build)
+	image, err := v.vmimages.Get(namespace, name)
+	if apierrors.IsNotFound(err) {
+		return werror.NewInvalidError(fmt.Sprintf("base image %s/%s is not found", namespace, name), fieldBaseImage)
+	} else if err != nil {
+		return err
+	}
+	if !util.IsImageSharedWith(image, build.Namespace) {
+		return werror.NewInvalidError(fmt.Sprintf("base image %s/%s is not shared with namespace %s", namespace, name, build.Namespace), fieldBaseImage)
+	}
+	return nil
+}
+
+func checkSpec(build *v1beta1.VirtualMachineImageBuild) error {
+	spec := build.Spec
+	if spec.UserData == "" {
+		return werror.NewInvalidError("userData is required", fieldUserData)
+	}
+	if spec.NetworkName != "" {
+		if parts := strings.Split(spec.NetworkName, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return werror.NewInvalidError("networkName must be in the form of <namespace>/<name>", fieldNetworkName)
+		}
+	}
+	if spec.CPU < 0 {
+		return werror.NewInvalidError("cpu can't be negative", fieldCPU)
+	}
+	if err := checkQuantity(spec.Memory, fieldMemory); err != nil {
+		return err
+	}
+	if err := checkQuantity(spec.DiskSize, fieldDiskSize); err != nil {
+		return err
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		return werror.NewInvalidError("timeout must be positive", fieldTimeout)
+	}
+	if spec.Image.DisplayName == "" {
+		return werror.NewInvalidError("image displayName is required", fieldImageDisplayName)
+	}
+	if spec.Image.Family != "" {
+		if errs := validation.IsDNS1123Label(spec.Image.Family); len(errs) > 0 {
+			return werror.NewInvalidError(fmt.Sprintf("invalid family %q: %s", spec.Image.Family, strings.Join(errs, ", ")), fieldImageFamily)
+		}
+	}
+	return nil
+}
+
+func checkQuantity(value, field string) error {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid quantity %q: %v", value, err), field)
+	}
+	if quantity.Sign() <= 0 {
+		return werror.NewInvalidError(fmt.Sprintf("%s must be positive", value), field)
+	}
+	return nil
+}
+
+// checkImageDisplayName denies the build if its image would conflict with an existing image, it's only known
+// beforehand when the version is specified
+func (v *virtualMachineImageBuildValidator) checkImageDisplayName(build *v1beta1.VirtualMachineImageBuild) error {
+	if build.Spec.Image.Version == "" {
+		return nil
+	}
+	displayName := fmt.Sprintf("%s-%s", build.Spec.Image.DisplayName, build.Spec.Image.Version)
+	images, err := v.vmimages.List(build.Namespace, labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		if image.Spec.DisplayName == displayName {
+			return werror.NewConflict(fmt.Sprintf("image %s already exists", displayName))
+		}
+	}
+	return nil
+}
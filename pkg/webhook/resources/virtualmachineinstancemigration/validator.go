@@ -0,0 +1,143 @@
+package virtualmachineinstancemigration
+
+import (
+	"fmt"
+
+	ctlcorev1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	ctlkubevirtv1 "github.com/harvester/harvester/pkg/generated/controllers/kubevirt.io/v1"
+	"github.com/harvester/harvester/pkg/util"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+	"github.com/harvester/harvester/pkg/webhook/types"
+)
+
+func NewValidator(
+	namespaces ctlcorev1.NamespaceCache,
+	vmis ctlkubevirtv1.VirtualMachineInstanceCache,
+	vmims ctlkubevirtv1.VirtualMachineInstanceMigrationCache,
+	policies ctlharvesterv1.MigrationPolicyCache,
+) types.Validator {
+	return &vmimValidator{
+		namespaces: namespaces,
+		vmis:       vmis,
+		vmims:      vmims,
+		policies:   policies,
+	}
+}
+
+type vmimValidator struct {
+	types.DefaultValidator
+
+	namespaces ctlcorev1.NamespaceCache
+	vmis       ctlkubevirtv1.VirtualMachineInstanceCache
+	vmims      ctlkubevirtv1.VirtualMachineInstanceMigrationCache
+	policies   ctlharvesterv1.MigrationPolicyCache
+}
+
+func (v *vmimValidator) Resource() types.Resource {
+	return types.Resource{
+		Names:      []string{"virtualmachineinstancemigrations"},
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   kubevirtv1.SchemeGroupVersion.Group,
+		APIVersion: kubevirtv1.SchemeGroupVersion.Version,
+		ObjectType: &kubevirtv1.VirtualMachineInstanceMigration{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+		},
+	}
+}
+
+// Create denies the migration when the parallel limits of the migration policy of the VM are reached. The denied
+// migrations of the maintenance mode are retried by KubeVirt.
+func (v *vmimValidator) Create(request *types.Request, newObj runtime.Object) error {
+	vmim := newObj.(*kubevirtv1.VirtualMachineInstanceMigration)
+	vmi, err := v.vmis.Get(vmim.Namespace, vmim.Spec.VMIName)
+	if apierrors.IsNotFound(err) {
+		// KubeVirt fails the migration
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	policies, err := v.policies.List(labels.Everything())
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+	policy, err := v.getPolicy(policies, vmi)
+	if err != nil || policy == nil {
+		return err
+	}
+	if policy.Spec.ParallelMigrationsPerCluster == nil && policy.Spec.ParallelOutboundMigrationsPerNode == nil {
+		return nil
+	}
+
+	migrating, err := v.getMigratingVMIs(policies, policy)
+	if err != nil {
+		return err
+	}
+	return checkParallelMigrations(policy, vmi, migrating)
+}
+
+func (v *vmimValidator) getPolicy(policies []*harvesterv1.MigrationPolicy, vmi *kubevirtv1.VirtualMachineInstance) (*harvesterv1.MigrationPolicy, error) {
+	namespace, err := v.namespaces.Get(vmi.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return util.GetMigrationPolicy(policies, vmi.Labels, namespace.Labels), nil
+}
+
+// getMigratingVMIs returns the VMIs of the running migrations with the policy
+func (v *vmimValidator) getMigratingVMIs(policies []*harvesterv1.MigrationPolicy, policy *harvesterv1.MigrationPolicy) ([]*kubevirtv1.VirtualMachineInstance, error) {
+	vmims, err := v.vmims.List("", labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var migrating []*kubevirtv1.VirtualMachineInstance
+	for _, vmim := range vmims {
+		if vmim.DeletionTimestamp != nil || vmim.Status.Phase == kubevirtv1.MigrationSucceeded ||
+			vmim.Status.Phase == kubevirtv1.MigrationFailed {
+			continue
+		}
+		vmi, err := v.vmis.Get(vmim.Namespace, vmim.Spec.VMIName)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		vmiPolicy, err := v.getPolicy(policies, vmi)
+		if err != nil {
+			return nil, err
+		}
+		if vmiPolicy != nil && vmiPolicy.Name == policy.Name {
+			migrating = append(migrating, vmi)
+		}
+	}
+	return migrating, nil
+}
+
+func checkParallelMigrations(policy *harvesterv1.MigrationPolicy, vmi *kubevirtv1.VirtualMachineInstance, migrating []*kubevirtv1.VirtualMachineInstance) error {
+	if limit := policy.Spec.ParallelMigrationsPerCluster; limit != nil && len(migrating) >= int(*limit) {
+		return werror.NewConflict(fmt.Sprintf("migration policy %s allows %d migrations in the cluster at the same time, retry later",
+			policy.Name, *limit))
+	}
+	if limit := policy.Spec.ParallelOutboundMigrationsPerNode; limit != nil {
+		count := 0
+		for _, migratingVMI := range migrating {
+			if migratingVMI.Status.NodeName == vmi.Status.NodeName {
+				count++
+			}
+		}
+		if count >= int(*limit) {
+			return werror.NewConflict(fmt.Sprintf("migration policy %s allows %d migrations from node %s at the same time, retry later",
+				policy.Name, *limit, vmi.Status.NodeName))
+		}
+	}
+	return nil
+}
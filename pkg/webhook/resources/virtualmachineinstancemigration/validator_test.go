@@ -0,0 +1,74 @@
+package virtualmachineinstancemigration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+)
+
+func uint32Ptr(i uint32) *uint32 {
+	return &i
+}
+
+func newVMI(name, nodeName string) *kubevirtv1.VirtualMachineInstance {
+	return &kubevirtv1.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Status:     kubevirtv1.VirtualMachineInstanceStatus{NodeName: nodeName},
+	}
+}
+
+func Test_checkParallelMigrations(t *testing.T) {
+	migrating := []*kubevirtv1.VirtualMachineInstance{
+		newVMI("vm1", "node1"),
+		newVMI("vm2", "node2"),
+	}
+	var testCases = []struct {
+		name          string
+		spec          harvesterv1.MigrationPolicySpec
+		vmi           *kubevirtv1.VirtualMachineInstance
+		expectedError bool
+	}{
+		{
+			name: "no limits",
+			vmi:  newVMI("vm3", "node1"),
+		},
+		{
+			name: "under the cluster limit",
+			spec: harvesterv1.MigrationPolicySpec{ParallelMigrationsPerCluster: uint32Ptr(3)},
+			vmi:  newVMI("vm3", "node1"),
+		},
+		{
+			name:          "cluster limit reached",
+			spec:          harvesterv1.MigrationPolicySpec{ParallelMigrationsPerCluster: uint32Ptr(2)},
+			vmi:           newVMI("vm3", "node3"),
+			expectedError: true,
+		},
+		{
+			name: "under the node limit",
+			spec: harvesterv1.MigrationPolicySpec{ParallelOutboundMigrationsPerNode: uint32Ptr(1)},
+			vmi:  newVMI("vm3", "node3"),
+		},
+		{
+			name:          "node limit reached",
+			spec:          harvesterv1.MigrationPolicySpec{ParallelOutboundMigrationsPerNode: uint32Ptr(1)},
+			vmi:           newVMI("vm3", "node1"),
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		policy := &harvesterv1.MigrationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec:       tc.spec,
+		}
+		err := checkParallelMigrations(policy, tc.vmi, migrating)
+		if tc.expectedError {
+			assert.NotNil(t, err, tc.name)
+		} else {
+			assert.Nil(t, err, tc.name)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	harvesterv1 "github.com/harvester/harvester/pkg/apis/harvesterhci.io/v1beta1"
+	ctlharvesterv1 "github.com/harvester/harvester/pkg/generated/controllers/harvesterhci.io/v1beta1"
+	"github.com/harvester/harvester/pkg/util"
+	werror "github.com/harvester/harvester/pkg/webhook/error"
+)
+
+// ImageFamilyResolver returns the latest ready version of an image family referenced in the namespace,
+// it's an invalid error of the field if the family has no ready version
+func ImageFamilyResolver(cache ctlharvesterv1.VirtualMachineImageCache, namespace, field string) func(string) (*harvesterv1.VirtualMachineImage, error) {
+	return func(family string) (*harvesterv1.VirtualMachineImage, error) {
+		familyNamespace, name := util.ParseImageFamily(family, namespace)
+		images, err := cache.List(familyNamespace, labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		latest := util.GetLatestImageVersion(images, name)
+		if latest == nil {
+			return nil, werror.NewInvalidError(fmt.Sprintf("image family %s/%s has no ready version", familyNamespace, name), field)
+		}
+		return latest, nil
+	}
+}